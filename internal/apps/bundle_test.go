@@ -153,8 +153,8 @@ func TestBundle_Save(t *testing.T) {
 		Description:  "A test application",
 		Dependencies: []string{"dep1", "dep2"},
 		Shell: &Shell{
-			Aliases: map[string]string{
-				"ta": "test-app",
+			Aliases: map[string]ConditionalValues{
+				"ta": Value("test-app"),
 			},
 		},
 	}
@@ -306,6 +306,194 @@ func TestBundle_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown dotfile format",
+			bundle: Bundle{
+				Name:     "test",
+				Dotfiles: []Dotfile{{Source: "test/rc", Target: "~/.testrc", Format: "xml"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shell path entries",
+			bundle: Bundle{
+				Name: "test",
+				Shell: &Shell{
+					Path: []PathEntry{{Dir: "~/.local/bin"}, {Dir: "/opt/tool/bin", Position: PathAppend}},
+				},
+			},
+		},
+		{
+			name: "shell path invalid position",
+			bundle: Bundle{
+				Name:  "test",
+				Shell: &Shell{Path: []PathEntry{{Dir: "~/bin", Position: "middle"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shell path with separator",
+			bundle: Bundle{
+				Name:  "test",
+				Shell: &Shell{Path: []PathEntry{{Dir: "/a:/b"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shell function per-shell bodies",
+			bundle: Bundle{
+				Name: "test",
+				Shell: &Shell{Functions: map[string]ConditionalValues{
+					"mkcd": {{Value: "mkdir -p $argv[1]", Shell: "fish"}, {Value: "mkdir -p $1"}},
+				}},
+			},
+		},
+		{
+			name: "shell function unknown shell",
+			bundle: Bundle{
+				Name: "test",
+				Shell: &Shell{Functions: map[string]ConditionalValues{
+					"mkcd": {{Value: "mkdir -p $1", Shell: "tcsh"}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shell env value unknown shell",
+			bundle: Bundle{
+				Name: "test",
+				Shell: &Shell{Env: map[string]EnvVar{
+					"EDITOR": {Value: "vi", Values: []ConditionalValue{{Value: "nvim", Shell: "tcsh"}}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "brew cask from a tap",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Brew: &BrewPackage{Name: "homebrew/cask-fonts/font-fira-code", Cask: true, Tap: "homebrew/cask-fonts"}},
+			},
+		},
+		{
+			name: "brew invalid tap",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Brew: &BrewPackage{Name: "terraform", Tap: "hashicorp"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "flatpak from a custom remote and classic snap",
+			bundle: Bundle{
+				Name: "test",
+				Package: &Package{
+					Flatpak: &FlatpakPackage{ID: "org.gnome.Builder", Remote: "gnome-nightly", RemoteURL: "https://nightly.gnome.org/gnome-nightly.flatpakrepo"},
+					Snap:    &SnapPackage{Name: "code", Classic: true},
+				},
+			},
+		},
+		{
+			name: "snap without name",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Snap: &SnapPackage{Classic: true}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "release archive",
+			bundle: Bundle{
+				Name: "test",
+				Package: &Package{Release: &ReleaseInstall{
+					URL:          "https://github.com/derailed/k9s/releases/download/v{{.Version}}/k9s_{{.OS}}_{{.Arch}}.tar.gz",
+					Version:      "0.32.5",
+					Binary:       "k9s",
+					SHA256:       map[string]string{"linux/amd64": "33c31bf5feba292b59b8dabe5547cb7ab565521ee59619b52eb4ee8d6cef39c8"},
+					Replacements: map[string]string{"linux": "Linux"},
+				}},
+			},
+		},
+		{
+			name: "release with an unknown platform checksum",
+			bundle: Bundle{
+				Name: "test",
+				Package: &Package{Release: &ReleaseInstall{
+					URL:     "https://example.com/tool.tar.gz",
+					Version: "1.0.0",
+					Binary:  "tool",
+					SHA256:  map[string]string{"linux-amd64": "33c31bf5feba292b59b8dabe5547cb7ab565521ee59619b52eb4ee8d6cef39c8"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "release binary glob without name",
+			bundle: Bundle{
+				Name: "test",
+				Package: &Package{Release: &ReleaseInstall{
+					URL:     "https://example.com/tool.tar.gz",
+					Version: "1.0.0",
+					Binary:  "tool-*",
+					SHA256:  map[string]string{"linux/amd64": "33c31bf5feba292b59b8dabe5547cb7ab565521ee59619b52eb4ee8d6cef39c8"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "go package with major version and version",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Go: "github.com/mikefarah/yq/v4@v4.44.1"},
+			},
+		},
+		{
+			name: "go relative package path",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Go: "./cmd/tool"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "apt repo with pinned key",
+			bundle: Bundle{
+				Name: "test",
+				Package: &Package{Apt: &AptPackage{
+					Name:        "azure-cli",
+					Repo:        "https://packages.microsoft.com/repos/azure-cli/",
+					Key:         "https://packages.microsoft.com/keys/microsoft.asc",
+					Fingerprint: "BC52 8686 B50D 79E3 39D3  721C EB3E 94AD BE12 29CF",
+					Keyring:     "microsoft",
+				}},
+			},
+		},
+		{
+			name: "apt key without repo",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Apt: &AptPackage{Name: "tool", Key: "https://example.com/key.asc"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "apt invalid fingerprint",
+			bundle: Bundle{
+				Name: "test",
+				Package: &Package{Apt: &AptPackage{
+					Name: "tool", Repo: "https://example.com/apt", Key: "https://example.com/key.asc", Fingerprint: "ABCD",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "apt keyring with path",
+			bundle: Bundle{
+				Name:    "test",
+				Package: &Package{Apt: &AptPackage{Name: "tool", Repo: "https://example.com/apt", Keyring: "../evil"}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "custom install missing script",
 			bundle: Bundle{
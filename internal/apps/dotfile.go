@@ -30,6 +30,10 @@ type Dotfile struct {
 	// - Added to .gitignore
 	// - User is warned about committing
 	Secret bool `yaml:"secret,omitempty"`
+
+	// Format overrides syntax detection for validation before linking.
+	// Example: "toml", "gitconfig", or "none" to skip the check.
+	Format string `yaml:"format,omitempty"`
 }
 
 // TargetMap provides platform-specific target paths for dotfiles.
@@ -77,6 +81,7 @@ func (d *Dotfile) UnmarshalYAML(node *yaml.Node) error {
 		When     string    `yaml:"when,omitempty"`
 		Template bool      `yaml:"template,omitempty"`
 		Secret   bool      `yaml:"secret,omitempty"`
+		Format   string    `yaml:"format,omitempty"`
 	}
 
 	if err := node.Decode(&aux); err != nil {
@@ -87,6 +92,7 @@ func (d *Dotfile) UnmarshalYAML(node *yaml.Node) error {
 	d.When = aux.When
 	d.Template = aux.Template
 	d.Secret = aux.Secret
+	d.Format = aux.Format
 	d.Target = ""
 	d.TargetMap = nil
 
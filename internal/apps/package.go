@@ -1,10 +1,18 @@
 package apps
 
+import (
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Package defines how to install the app's package.
 // The package field is optional - omit for package-less bundles.
 type Package struct {
-	// Brew is the Homebrew/Linuxbrew package name (string).
-	Brew string `yaml:"brew,omitempty"`
+	// Brew is the Homebrew/Linuxbrew formula or cask. Can be a simple
+	// formula name or a BrewPackage.
+	Brew *BrewPackage `yaml:"brew,omitempty"`
 
 	// Apt is the Debian/Ubuntu package. Can be a simple string or AptConfig.
 	Apt *AptPackage `yaml:"apt,omitempty"`
@@ -15,6 +23,41 @@ type Package struct {
 	// Pacman is the Arch Linux package name.
 	Pacman string `yaml:"pacman,omitempty"`
 
+	// Cargo is the Rust crate installed with cargo install.
+	Cargo string `yaml:"cargo,omitempty"`
+
+	// Go is the package path installed with go install, optionally with an
+	// @version (default: @latest).
+	Go string `yaml:"go,omitempty"`
+
+	// Pipx is the Python package installed with pipx.
+	Pipx string `yaml:"pipx,omitempty"`
+
+	// Npm is the npm package installed globally with npm install -g.
+	Npm string `yaml:"npm,omitempty"`
+
+	// Uv is the Python package installed with uv tool install.
+	Uv string `yaml:"uv,omitempty"`
+
+	// Flatpak is the Flatpak application. Can be a simple application ID
+	// or a FlatpakPackage.
+	Flatpak *FlatpakPackage `yaml:"flatpak,omitempty"`
+
+	// Snap is the snap. Can be a simple snap name or a SnapPackage.
+	Snap *SnapPackage `yaml:"snap,omitempty"`
+
+	// Nix is the flake installable added with nix profile install, e.g.
+	// "nixpkgs#ripgrep" (a bare attribute is looked up in nixpkgs).
+	Nix string `yaml:"nix,omitempty"`
+
+	// Release installs a prebuilt binary from an upstream release archive
+	// into ~/.local/bin.
+	Release *ReleaseInstall `yaml:"release,omitempty"`
+
+	// Managers maps user-defined package managers, declared under
+	// package_manager.managers in config.yaml, to the package name.
+	Managers map[string]string `yaml:"managers,omitempty"`
+
 	// Custom defines a custom installation script.
 	Custom *CustomInstall `yaml:"custom,omitempty"`
 
@@ -22,6 +65,141 @@ type Package struct {
 	Prefer *Prefer `yaml:"prefer,omitempty"`
 }
 
+// BrewPackage is a Homebrew formula or cask and the tap that provides it.
+type BrewPackage struct {
+	// Name is the formula or cask name.
+	Name string `yaml:"name" json:"name"`
+
+	// Cask installs Name as a cask (GUI apps, fonts) instead of a formula.
+	Cask bool `yaml:"cask,omitempty" json:"cask,omitempty"`
+
+	// Tap is a third-party tap to add before installing, e.g. "hashicorp/tap".
+	Tap string `yaml:"tap,omitempty" json:"tap,omitempty"`
+
+	// TapURL is the clone URL of a Tap that is not on GitHub.
+	TapURL string `yaml:"tap_url,omitempty" json:"tap_url,omitempty"`
+
+	// Args are extra arguments for brew install, e.g. ["--HEAD"].
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+}
+
+// BrewFormula returns a BrewPackage for a plain formula.
+func BrewFormula(name string) *BrewPackage {
+	return &BrewPackage{Name: name}
+}
+
+// UnmarshalYAML supports both string and map forms.
+func (b *BrewPackage) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*b = BrewPackage{Name: node.Value}
+		return nil
+	case yaml.MappingNode:
+		type rawBrewPackage BrewPackage
+		var raw rawBrewPackage
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		*b = BrewPackage(raw)
+		return nil
+	default:
+		return fmt.Errorf("brew package must be a string or map")
+	}
+}
+
+// MarshalYAML writes a plain formula in the short string form.
+func (b BrewPackage) MarshalYAML() (interface{}, error) {
+	if !b.Cask && b.Tap == "" && b.TapURL == "" && len(b.Args) == 0 {
+		return b.Name, nil
+	}
+	type rawBrewPackage BrewPackage
+	return rawBrewPackage(b), nil
+}
+
+// FlatpakPackage is a Flatpak application and the remote that provides it.
+type FlatpakPackage struct {
+	// ID is the application ID, e.g. "org.mozilla.firefox".
+	ID string `yaml:"id"`
+
+	// Remote is the remote to install from (default: flathub).
+	Remote string `yaml:"remote,omitempty"`
+
+	// RemoteURL is the .flatpakrepo URL used to add Remote when it is
+	// missing (default: the Flathub URL for flathub).
+	RemoteURL string `yaml:"remote_url,omitempty"`
+}
+
+// UnmarshalYAML supports both string and map forms.
+func (f *FlatpakPackage) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*f = FlatpakPackage{ID: node.Value}
+		return nil
+	case yaml.MappingNode:
+		type rawFlatpakPackage FlatpakPackage
+		var raw rawFlatpakPackage
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		*f = FlatpakPackage(raw)
+		return nil
+	default:
+		return fmt.Errorf("flatpak package must be a string or map")
+	}
+}
+
+// MarshalYAML writes an application from the default remote in the short
+// string form.
+func (f FlatpakPackage) MarshalYAML() (interface{}, error) {
+	if f.Remote == "" && f.RemoteURL == "" {
+		return f.ID, nil
+	}
+	type rawFlatpakPackage FlatpakPackage
+	return rawFlatpakPackage(f), nil
+}
+
+// SnapPackage is a snap and how to install it.
+type SnapPackage struct {
+	// Name is the snap name.
+	Name string `yaml:"name"`
+
+	// Classic installs the snap with classic confinement (--classic),
+	// required by snaps such as code or go.
+	Classic bool `yaml:"classic,omitempty"`
+
+	// Channel is the channel to track, e.g. "latest/edge" (default: stable).
+	Channel string `yaml:"channel,omitempty"`
+}
+
+// UnmarshalYAML supports both string and map forms.
+func (s *SnapPackage) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*s = SnapPackage{Name: node.Value}
+		return nil
+	case yaml.MappingNode:
+		type rawSnapPackage SnapPackage
+		var raw rawSnapPackage
+		if err := node.Decode(&raw); err != nil {
+			return err
+		}
+		*s = SnapPackage(raw)
+		return nil
+	default:
+		return fmt.Errorf("snap package must be a string or map")
+	}
+}
+
+// MarshalYAML writes a strictly confined stable snap in the short string
+// form.
+func (s SnapPackage) MarshalYAML() (interface{}, error) {
+	if !s.Classic && s.Channel == "" {
+		return s.Name, nil
+	}
+	type rawSnapPackage SnapPackage
+	return rawSnapPackage(s), nil
+}
+
 // AptPackage represents apt package configuration.
 // Can be a simple name or include repo/key for external packages.
 type AptPackage struct {
@@ -31,8 +209,61 @@ type AptPackage struct {
 	// Repo is the APT repository URL (optional).
 	Repo string `yaml:"repo,omitempty"`
 
-	// Key is the GPG key URL for the repository (optional).
+	// Key is the GPG key URL for the repository (optional). The key is
+	// stored in /etc/apt/keyrings and trusted only for Repo (signed-by).
 	Key string `yaml:"key,omitempty"`
+
+	// Fingerprint pins the repository key (optional): setup fails unless
+	// the downloaded key has this fingerprint.
+	Fingerprint string `yaml:"fingerprint,omitempty"`
+
+	// Suite is the repository suite, e.g. "stable" (default: the release
+	// codename from /etc/os-release).
+	Suite string `yaml:"suite,omitempty"`
+
+	// Components are the repository components (default: main).
+	Components []string `yaml:"components,omitempty"`
+
+	// Keyring names the keyring and sources files. By default apps reuse
+	// the files already written for the same repository URI, or name new
+	// ones after the repository host and a hash of its URI.
+	Keyring string `yaml:"keyring,omitempty"`
+}
+
+// ReleaseInstall describes a prebuilt binary from an upstream release.
+type ReleaseInstall struct {
+	// URL is the download URL template. {{.OS}}, {{.Arch}} and {{.Version}}
+	// expand to the Go OS (linux, darwin) and architecture (amd64, arm64),
+	// after Replacements, and Version.
+	URL string `yaml:"url"`
+
+	// Version is the release version. Changing it reinstalls the binary.
+	Version string `yaml:"version"`
+
+	// SHA256 maps "<os>/<arch>" (Go names, e.g. "linux/amd64") to the
+	// SHA-256 checksum of the download for that platform.
+	SHA256 map[string]string `yaml:"sha256"`
+
+	// Binary is the path of the binary inside the archive, with the same
+	// template variables. A name without "/" matches at any depth; path
+	// patterns may use * and ?. For a plain binary download it is only the
+	// installed name.
+	Binary string `yaml:"binary"`
+
+	// Name is the installed command name (default: the base name of Binary).
+	Name string `yaml:"name,omitempty"`
+
+	// Replacements rename OS and architecture values in templates, e.g.
+	// {darwin: Darwin, amd64: x86_64}.
+	Replacements map[string]string `yaml:"replacements,omitempty"`
+}
+
+// InstalledName returns the command name the binary is installed as.
+func (r *ReleaseInstall) InstalledName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return path.Base(r.Binary)
 }
 
 // CustomInstall defines a custom installation script.
@@ -45,6 +276,14 @@ type CustomInstall struct {
 
 	// Confirm requires user confirmation before running (default: true).
 	Confirm *bool `yaml:"confirm,omitempty"`
+
+	// Check is a shell command that exits 0 when the app is already
+	// installed, so the script is not run again.
+	Check string `yaml:"check,omitempty"`
+
+	// Creates is a path the script creates. The script is not run again
+	// while it exists.
+	Creates string `yaml:"creates,omitempty"`
 }
 
 // ConfirmDefault returns the effective confirm value (default: true).
@@ -71,8 +310,8 @@ func (p *Package) ResolveName(manager string) (string, bool) {
 	}
 	switch manager {
 	case "brew":
-		if p.Brew != "" {
-			return p.Brew, true
+		if p.Brew != nil && p.Brew.Name != "" {
+			return p.Brew.Name, true
 		}
 	case "apt":
 		if p.Apt != nil && p.Apt.Name != "" {
@@ -86,6 +325,46 @@ func (p *Package) ResolveName(manager string) (string, bool) {
 		if p.Pacman != "" {
 			return p.Pacman, true
 		}
+	case "cargo":
+		if p.Cargo != "" {
+			return p.Cargo, true
+		}
+	case "go":
+		if p.Go != "" {
+			return p.Go, true
+		}
+	case "pipx":
+		if p.Pipx != "" {
+			return p.Pipx, true
+		}
+	case "npm":
+		if p.Npm != "" {
+			return p.Npm, true
+		}
+	case "uv":
+		if p.Uv != "" {
+			return p.Uv, true
+		}
+	case "flatpak":
+		if p.Flatpak != nil && p.Flatpak.ID != "" {
+			return p.Flatpak.ID, true
+		}
+	case "snap":
+		if p.Snap != nil && p.Snap.Name != "" {
+			return p.Snap.Name, true
+		}
+	case "nix":
+		if p.Nix != "" {
+			return p.Nix, true
+		}
+	case "release":
+		if p.Release != nil && p.Release.Binary != "" {
+			return p.Release.InstalledName(), true
+		}
+	default:
+		if name := p.Managers[manager]; name != "" {
+			return name, true
+		}
 	}
 	return "", false
 }
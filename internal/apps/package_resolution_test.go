@@ -1,9 +1,11 @@
 package apps
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestResolveName(t *testing.T) {
@@ -17,7 +19,7 @@ func TestResolveName(t *testing.T) {
 		{
 			name: "All defined, request brew",
 			pkg: &Package{
-				Brew: "brew-pkg",
+				Brew: BrewFormula("brew-pkg"),
 				Apt:  &AptPackage{Name: "apt-pkg"},
 				Dnf:  "dnf-pkg",
 			},
@@ -28,7 +30,7 @@ func TestResolveName(t *testing.T) {
 		{
 			name: "All defined, request apt",
 			pkg: &Package{
-				Brew: "brew-pkg",
+				Brew: BrewFormula("brew-pkg"),
 				Apt:  &AptPackage{Name: "apt-pkg"},
 			},
 			manager:     "apt",
@@ -38,7 +40,7 @@ func TestResolveName(t *testing.T) {
 		{
 			name: "Only brew defined, request apt (should be empty)",
 			pkg: &Package{
-				Brew: "brew-pkg",
+				Brew: BrewFormula("brew-pkg"),
 			},
 			manager:     "apt",
 			wantName:    "",
@@ -53,6 +55,26 @@ func TestResolveName(t *testing.T) {
 			wantName:    "",
 			wantDefined: false,
 		},
+		{
+			name: "Language manager",
+			pkg: &Package{
+				Apt:   &AptPackage{Name: "ripgrep"},
+				Cargo: "ripgrep",
+				Go:    "github.com/mikefarah/yq/v4",
+			},
+			manager:     "go",
+			wantName:    "github.com/mikefarah/yq/v4",
+			wantDefined: true,
+		},
+		{
+			name: "User-defined manager",
+			pkg: &Package{
+				Managers: map[string]string{"vscode": "ms-python.python"},
+			},
+			manager:     "vscode",
+			wantName:    "ms-python.python",
+			wantDefined: true,
+		},
 		{
 			name:        "No package defined",
 			pkg:         &Package{},
@@ -63,7 +85,7 @@ func TestResolveName(t *testing.T) {
 		{
 			name: "Request unknown manager",
 			pkg: &Package{
-				Brew: "foo",
+				Brew: BrewFormula("foo"),
 			},
 			manager:     "unknown",
 			wantName:    "",
@@ -79,3 +101,72 @@ func TestResolveName(t *testing.T) {
 		})
 	}
 }
+
+func TestBrewPackage_YAML(t *testing.T) {
+	input := `
+brew: ripgrep
+---
+brew:
+  name: iterm2
+  cask: true
+---
+brew:
+  name: hashicorp/tap/terraform
+  tap: hashicorp/tap
+  args: [--HEAD]
+`
+	want := []*BrewPackage{
+		{Name: "ripgrep"},
+		{Name: "iterm2", Cask: true},
+		{Name: "hashicorp/tap/terraform", Tap: "hashicorp/tap", Args: []string{"--HEAD"}},
+	}
+	dec := yaml.NewDecoder(strings.NewReader(input))
+	for i, w := range want {
+		var pkg Package
+		if err := dec.Decode(&pkg); err != nil {
+			t.Fatalf("Decode(%d) error = %v", i, err)
+		}
+		assert.Equal(t, w, pkg.Brew)
+
+		out, err := yaml.Marshal(&pkg)
+		if err != nil {
+			t.Fatalf("Marshal(%d) error = %v", i, err)
+		}
+		var roundTrip Package
+		if err := yaml.Unmarshal(out, &roundTrip); err != nil {
+			t.Fatalf("Unmarshal(%d round trip) error = %v", i, err)
+		}
+		assert.Equal(t, w, roundTrip.Brew)
+	}
+
+	out, err := yaml.Marshal(&Package{Brew: BrewFormula("ripgrep")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "brew: ripgrep\n", string(out), "plain formulae keep the short form")
+}
+
+func TestFlatpakSnapPackage_YAML(t *testing.T) {
+	input := `
+flatpak: org.mozilla.firefox
+snap:
+  name: code
+  classic: true
+`
+	var pkg Package
+	if err := yaml.Unmarshal([]byte(input), &pkg); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &FlatpakPackage{ID: "org.mozilla.firefox"}, pkg.Flatpak)
+	assert.Equal(t, &SnapPackage{Name: "code", Classic: true}, pkg.Snap)
+
+	name, ok := pkg.ResolveName("snap")
+	assert.True(t, ok)
+	assert.Equal(t, "code", name)
+
+	out, err := yaml.Marshal(&pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "flatpak: org.mozilla.firefox\nsnap:\n    name: code\n    classic: true\n", string(out))
+}
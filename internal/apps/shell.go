@@ -1,16 +1,26 @@
 package apps
 
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Shell defines shell integration for an app bundle.
 type Shell struct {
 	// Aliases maps alias names to commands.
 	// Example: {"k": "kubectl", "kgp": "kubectl get pods"}
-	Aliases map[string]string `yaml:"aliases,omitempty"`
+	Aliases map[string]ConditionalValues `yaml:"aliases,omitempty"`
 
 	// Functions maps function names to their bodies.
-	Functions map[string]string `yaml:"functions,omitempty"`
+	Functions map[string]ConditionalValues `yaml:"functions,omitempty"`
 
 	// Env maps environment variable names to values.
-	Env map[string]string `yaml:"env,omitempty"`
+	Env map[string]EnvVar `yaml:"env,omitempty"`
+
+	// Path lists directories to add to PATH. Entries from all resolved
+	// bundles are merged in bundle order and deduplicated.
+	Path []PathEntry `yaml:"path,omitempty"`
 
 	// Completions defines shell completion generation commands.
 	Completions *Completions `yaml:"completions,omitempty"`
@@ -20,6 +30,203 @@ type Shell struct {
 	Init []InitSnippet `yaml:"init,omitempty"`
 }
 
+// EnvVar is an environment variable value. In YAML it is either a plain
+// string or a map with `value`, per-shell overrides, conditional `values`
+// and `expand`.
+type EnvVar struct {
+	// Value is the default variable value.
+	Value string `yaml:"value,omitempty"`
+
+	// Bash overrides Value for bash shells when set.
+	Bash string `yaml:"bash,omitempty"`
+
+	// Zsh overrides Value for zsh shells when set.
+	Zsh string `yaml:"zsh,omitempty"`
+
+	// Fish overrides Value for fish shells when set.
+	Fish string `yaml:"fish,omitempty"`
+
+	// Values are conditional values. The first entry whose condition
+	// matches wins over Bash, Zsh, Fish and Value; an entry without a
+	// condition always matches.
+	Values []ConditionalValue `yaml:"values,omitempty"`
+
+	// When is an optional condition; the variable is not set by this app
+	// when it is false.
+	When string `yaml:"when,omitempty"`
+
+	// Expand controls whether $VAR, ${VAR} and $(cmd) in the value are
+	// expanded by the shell. When false the value is exported literally.
+	// Default: true.
+	Expand *bool `yaml:"expand,omitempty"`
+}
+
+// ConditionalValue is a value used when its condition matches.
+type ConditionalValue struct {
+	Value string `yaml:"value"`
+	When  string `yaml:"when,omitempty"`
+	// Shell limits the value to one shell (bash, zsh or fish). Function
+	// bodies without it are POSIX shell and are not used for fish.
+	Shell string `yaml:"shell,omitempty"`
+}
+
+// ConditionalValues is an alias command or function body with optional
+// conditions. In YAML it is a plain string, a map with `value`, `when` and
+// `shell`, or a list of such maps; the first entry that matches is used.
+type ConditionalValues []ConditionalValue
+
+// Value returns a ConditionalValues holding a single unconditional value.
+func Value(s string) ConditionalValues {
+	return ConditionalValues{{Value: s}}
+}
+
+// String returns the first value, which is the only one for the short form.
+func (c ConditionalValues) String() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[0].Value
+}
+
+// UnmarshalYAML supports string, map and list forms.
+func (c *ConditionalValues) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var s string
+		if err := node.Decode(&s); err != nil {
+			return err
+		}
+		*c = Value(s)
+		return nil
+	case yaml.MappingNode:
+		var v ConditionalValue
+		if err := node.Decode(&v); err != nil {
+			return fmt.Errorf("decoding conditional value: %w", err)
+		}
+		*c = ConditionalValues{v}
+		return nil
+	case yaml.SequenceNode:
+		var list []ConditionalValue
+		if err := node.Decode(&list); err != nil {
+			return fmt.Errorf("decoding conditional values: %w", err)
+		}
+		*c = list
+		return nil
+	default:
+		return fmt.Errorf("invalid value: expected string, map or list")
+	}
+}
+
+// MarshalYAML writes the shortest form that preserves the values.
+func (c ConditionalValues) MarshalYAML() (interface{}, error) {
+	switch {
+	case len(c) == 1 && c[0].When == "" && c[0].Shell == "":
+		return c[0].Value, nil
+	case len(c) == 1:
+		return c[0], nil
+	default:
+		return []ConditionalValue(c), nil
+	}
+}
+
+// ExpandDefault returns whether the value is expanded by the shell.
+func (e EnvVar) ExpandDefault() bool {
+	return e.Expand == nil || *e.Expand
+}
+
+// UnmarshalYAML supports both string and map forms for env values.
+func (e *EnvVar) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*e = EnvVar{}
+		return node.Decode(&e.Value)
+	case yaml.MappingNode:
+		type plain EnvVar
+		var aux plain
+		if err := node.Decode(&aux); err != nil {
+			return fmt.Errorf("decoding env value: %w", err)
+		}
+		*e = EnvVar(aux)
+		return nil
+	default:
+		return fmt.Errorf("invalid env value: expected string or map")
+	}
+}
+
+// MarshalYAML writes the short string form unless options are set.
+func (e EnvVar) MarshalYAML() (interface{}, error) {
+	if e.Bash == "" && e.Zsh == "" && e.Fish == "" && len(e.Values) == 0 && e.When == "" && e.Expand == nil {
+		return e.Value, nil
+	}
+	type plain EnvVar
+	return plain(e), nil
+}
+
+// PATH positions for PathEntry.Position.
+const (
+	PathPrepend = "prepend"
+	PathAppend  = "append"
+)
+
+// PathEntry is a directory added to PATH. In YAML it is either a plain
+// string or a map with `dir`, `position`, `when` and `if_exists`.
+type PathEntry struct {
+	// Dir is the directory to add. A leading ~ and $VAR references are
+	// expanded by the shell.
+	Dir string `yaml:"dir"`
+
+	// Position is "prepend" (default) or "append".
+	Position string `yaml:"position,omitempty"`
+
+	// When is an optional condition; the entry is skipped when it is false.
+	When string `yaml:"when,omitempty"`
+
+	// IfExists adds the directory only if it exists when the shell starts.
+	// Default: true.
+	IfExists *bool `yaml:"if_exists,omitempty"`
+}
+
+// PositionDefault returns the PATH position, defaulting to prepend.
+func (p PathEntry) PositionDefault() string {
+	if p.Position == "" {
+		return PathPrepend
+	}
+	return p.Position
+}
+
+// IfExistsDefault returns whether the directory must exist to be added.
+func (p PathEntry) IfExistsDefault() bool {
+	return p.IfExists == nil || *p.IfExists
+}
+
+// UnmarshalYAML supports both string and map forms for PATH entries.
+func (p *PathEntry) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*p = PathEntry{}
+		return node.Decode(&p.Dir)
+	case yaml.MappingNode:
+		type plain PathEntry
+		var aux plain
+		if err := node.Decode(&aux); err != nil {
+			return fmt.Errorf("decoding path entry: %w", err)
+		}
+		*p = PathEntry(aux)
+		return nil
+	default:
+		return fmt.Errorf("invalid path entry: expected string or map")
+	}
+}
+
+// MarshalYAML writes the short string form unless options are set.
+func (p PathEntry) MarshalYAML() (interface{}, error) {
+	if p.Position == "" && p.When == "" && p.IfExists == nil {
+		return p.Dir, nil
+	}
+	type plain PathEntry
+	return plain(p), nil
+}
+
 // Completions defines commands to generate shell completions.
 type Completions struct {
 	// Bash is the command to generate bash completions.
@@ -43,6 +250,15 @@ type InitSnippet struct {
 	// Zsh overrides Common for zsh shells when set.
 	Zsh string `yaml:"zsh,omitempty"`
 
+	// Fish is the snippet for fish shells. Common is POSIX syntax and is
+	// not used for fish.
+	Fish string `yaml:"fish,omitempty"`
+
 	// Guard is an optional shell condition checked before executing the snippet.
 	Guard string `yaml:"guard,omitempty"`
+
+	// Lazy lists commands that load the snippet on first use. When set, the
+	// snippet does not run at startup; each command gets a stub function that
+	// runs the snippet once and then re-runs the command.
+	Lazy []string `yaml:"lazy,omitempty"`
 }
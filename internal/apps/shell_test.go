@@ -0,0 +1,177 @@
+package apps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnvVar_UnmarshalYAML(t *testing.T) {
+	input := `
+env:
+  EDITOR: vim
+  PROMPT_RAW:
+    value: "$USER@$HOST"
+    expand: false
+  GOPATH:
+    value: $HOME/go
+`
+	var s Shell
+	if err := yaml.Unmarshal([]byte(input), &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		wantValue  string
+		wantExpand bool
+	}{
+		{name: "EDITOR", wantValue: "vim", wantExpand: true},
+		{name: "PROMPT_RAW", wantValue: "$USER@$HOST", wantExpand: false},
+		{name: "GOPATH", wantValue: "$HOME/go", wantExpand: true},
+	}
+	for _, tt := range tests {
+		got, ok := s.Env[tt.name]
+		if !ok {
+			t.Fatalf("env %s missing", tt.name)
+		}
+		if got.Value != tt.wantValue {
+			t.Errorf("%s value = %q, want %q", tt.name, got.Value, tt.wantValue)
+		}
+		if got.ExpandDefault() != tt.wantExpand {
+			t.Errorf("%s ExpandDefault() = %v, want %v", tt.name, got.ExpandDefault(), tt.wantExpand)
+		}
+	}
+}
+
+func TestEnvVar_UnmarshalYAML_ShellAndConditionalValues(t *testing.T) {
+	input := `
+env:
+  BROWSER:
+    value: firefox
+    zsh: lynx
+    values:
+      - value: open
+        when: os == 'macos'
+`
+	var s Shell
+	if err := yaml.Unmarshal([]byte(input), &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	got := s.Env["BROWSER"]
+	if got.Value != "firefox" || got.Zsh != "lynx" || got.Bash != "" {
+		t.Errorf("BROWSER = %+v", got)
+	}
+	if len(got.Values) != 1 || got.Values[0].Value != "open" || got.Values[0].When != "os == 'macos'" {
+		t.Errorf("BROWSER.Values = %+v", got.Values)
+	}
+}
+
+func TestEnvVar_MarshalYAML(t *testing.T) {
+	noExpand := false
+	s := Shell{Env: map[string]EnvVar{
+		"A": {Value: "plain"},
+		"B": {Value: "$raw", Expand: &noExpand},
+	}}
+	out, err := yaml.Marshal(&s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := "env:\n    A: plain\n    B:\n        value: $raw\n        expand: false\n"
+	if string(out) != want {
+		t.Errorf("Marshal() = %q, want %q", out, want)
+	}
+}
+
+func TestPathEntry_UnmarshalYAML(t *testing.T) {
+	input := `
+path:
+  - ~/.cargo/bin
+  - dir: /opt/homebrew/bin
+    when: os == 'macos'
+  - dir: $HOME/go/bin
+    position: append
+    if_exists: false
+`
+	var s Shell
+	if err := yaml.Unmarshal([]byte(input), &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(s.Path) != 3 {
+		t.Fatalf("len(Path) = %d, want 3", len(s.Path))
+	}
+
+	tests := []struct {
+		dir          string
+		wantPosition string
+		wantWhen     string
+		wantIfExists bool
+	}{
+		{dir: "~/.cargo/bin", wantPosition: PathPrepend, wantIfExists: true},
+		{dir: "/opt/homebrew/bin", wantPosition: PathPrepend, wantWhen: "os == 'macos'", wantIfExists: true},
+		{dir: "$HOME/go/bin", wantPosition: PathAppend, wantIfExists: false},
+	}
+	for i, tt := range tests {
+		got := s.Path[i]
+		if got.Dir != tt.dir || got.PositionDefault() != tt.wantPosition || got.When != tt.wantWhen || got.IfExistsDefault() != tt.wantIfExists {
+			t.Errorf("Path[%d] = %+v, want dir=%q position=%q when=%q if_exists=%v", i, got, tt.dir, tt.wantPosition, tt.wantWhen, tt.wantIfExists)
+		}
+	}
+
+	out, err := yaml.Marshal(&Shell{Path: s.Path[:1]})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := "path:\n    - ~/.cargo/bin\n"; string(out) != want {
+		t.Errorf("Marshal() = %q, want %q", out, want)
+	}
+}
+
+func TestConditionalValues_YAML(t *testing.T) {
+	input := `
+aliases:
+  k: kubectl
+  open:
+    value: xdg-open
+    when: os == 'linux'
+  copy:
+    - value: pbcopy
+      when: os == 'macos'
+    - value: xclip -selection clipboard
+`
+	var s Shell
+	if err := yaml.Unmarshal([]byte(input), &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want ConditionalValues
+	}{
+		{name: "k", want: Value("kubectl")},
+		{name: "open", want: ConditionalValues{{Value: "xdg-open", When: "os == 'linux'"}}},
+		{name: "copy", want: ConditionalValues{{Value: "pbcopy", When: "os == 'macos'"}, {Value: "xclip -selection clipboard"}}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(s.Aliases[tt.name], tt.want) {
+			t.Errorf("aliases[%s] = %+v, want %+v", tt.name, s.Aliases[tt.name], tt.want)
+		}
+	}
+
+	out, err := yaml.Marshal(&s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var roundTrip Shell
+	if err := yaml.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatalf("Unmarshal(round trip) error = %v", err)
+	}
+	if !reflect.DeepEqual(roundTrip.Aliases, s.Aliases) {
+		t.Errorf("round trip aliases = %+v, want %+v", roundTrip.Aliases, s.Aliases)
+	}
+	if !strings.Contains(string(out), "k: kubectl\n") {
+		t.Errorf("Marshal() did not keep the short form:\n%s", out)
+	}
+}
@@ -4,6 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/rztaylor/GoDotFiles/internal/syntax"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 )
 
 // ValidationError represents a validation failure.
@@ -48,6 +53,12 @@ func (b *Bundle) Validate() error {
 				Message: "is required",
 			})
 		}
+		if df.Format != "" && !syntax.IsKnown(df.Format) {
+			errs = append(errs, &ValidationError{
+				Field:   fmt.Sprintf("dotfiles[%d].format", i),
+				Message: fmt.Sprintf("must be one of: %s", strings.Join(syntax.Formats(), ", ")),
+			})
+		}
 	}
 
 	// Validate plugins
@@ -76,6 +87,106 @@ func (b *Bundle) Validate() error {
 		}
 	}
 
+	// Validate brew package
+	if b.Package != nil && b.Package.Brew != nil {
+		if b.Package.Brew.Name == "" {
+			errs = append(errs, &ValidationError{
+				Field:   "package.brew.name",
+				Message: "is required",
+			})
+		}
+		if tap := b.Package.Brew.Tap; tap != "" && !tapRegex.MatchString(tap) {
+			errs = append(errs, &ValidationError{
+				Field:   "package.brew.tap",
+				Message: "must be user/repo",
+			})
+		}
+	}
+
+	// Validate flatpak and snap packages
+	if b.Package != nil && b.Package.Flatpak != nil && b.Package.Flatpak.ID == "" {
+		errs = append(errs, &ValidationError{
+			Field:   "package.flatpak.id",
+			Message: "is required",
+		})
+	}
+	if b.Package != nil && b.Package.Snap != nil && b.Package.Snap.Name == "" {
+		errs = append(errs, &ValidationError{
+			Field:   "package.snap.name",
+			Message: "is required",
+		})
+	}
+
+	// Validate apt repository settings
+	if b.Package != nil && b.Package.Apt != nil {
+		errs = append(errs, validateAptPackage(b.Package.Apt)...)
+	}
+
+	// Validate release archive installs
+	if b.Package != nil && b.Package.Release != nil {
+		errs = append(errs, validateReleaseInstall(b.Package.Release)...)
+	}
+
+	// Validate go install package path
+	if b.Package != nil && b.Package.Go != "" && !goPackageRegex.MatchString(b.Package.Go) {
+		errs = append(errs, &ValidationError{
+			Field:   "package.go",
+			Message: "must be a remote package path such as golang.org/x/tools/gopls",
+		})
+	}
+
+	// Validate per-shell alias, function and env values
+	if b.Shell != nil {
+		for _, kind := range []struct {
+			field  string
+			values map[string]ConditionalValues
+		}{{"aliases", b.Shell.Aliases}, {"functions", b.Shell.Functions}} {
+			for _, name := range util.SortedKeys(kind.values) {
+				for i, v := range kind.values[name] {
+					if !validValueShell(v.Shell) {
+						errs = append(errs, &ValidationError{
+							Field:   fmt.Sprintf("shell.%s.%s[%d].shell", kind.field, name, i),
+							Message: "must be bash, zsh or fish",
+						})
+					}
+				}
+			}
+		}
+		for _, name := range util.SortedKeys(b.Shell.Env) {
+			for i, v := range b.Shell.Env[name].Values {
+				if !validValueShell(v.Shell) {
+					errs = append(errs, &ValidationError{
+						Field:   fmt.Sprintf("shell.env.%s.values[%d].shell", name, i),
+						Message: "must be bash, zsh or fish",
+					})
+				}
+			}
+		}
+	}
+
+	// Validate PATH entries
+	if b.Shell != nil {
+		for i, entry := range b.Shell.Path {
+			if strings.TrimSpace(entry.Dir) == "" {
+				errs = append(errs, &ValidationError{
+					Field:   fmt.Sprintf("shell.path[%d].dir", i),
+					Message: "is required",
+				})
+			} else if strings.Contains(entry.Dir, ":") {
+				errs = append(errs, &ValidationError{
+					Field:   fmt.Sprintf("shell.path[%d].dir", i),
+					Message: "must be a single directory (no ':')",
+				})
+			}
+			if p := entry.PositionDefault(); p != PathPrepend && p != PathAppend {
+				errs = append(errs, &ValidationError{
+					Field:   fmt.Sprintf("shell.path[%d].position", i),
+					Message: fmt.Sprintf("must be %s or %s", PathPrepend, PathAppend),
+				})
+			}
+		}
+	}
+
 	// Validate shell init snippets
 	if b.Shell != nil && len(b.Shell.Init) > 0 {
 		seenInitNames := make(map[string]struct{}, len(b.Shell.Init))
@@ -95,10 +206,10 @@ func (b *Bundle) Validate() error {
 				seenInitNames[snippet.Name] = struct{}{}
 			}
 
-			if snippet.Common == "" && snippet.Bash == "" && snippet.Zsh == "" {
+			if snippet.Common == "" && snippet.Bash == "" && snippet.Zsh == "" && snippet.Fish == "" {
 				errs = append(errs, &ValidationError{
 					Field:   fmt.Sprintf("shell.init[%d]", i),
-					Message: "must define at least one of common, bash, or zsh",
+					Message: "must define at least one of common, bash, zsh, or fish",
 				})
 			}
 		}
@@ -114,6 +225,86 @@ func (b *Bundle) Validate() error {
 // Names must be lowercase alphanumeric with hyphens.
 var nameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
 
+var (
+	fingerprintRegex     = regexp.MustCompile(`^[0-9A-Fa-f]{40}$`)
+	keyringRegex         = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	tapRegex             = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	releasePlatformRegex = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9]+$`)
+	sha256Regex          = regexp.MustCompile(`^[0-9A-Fa-f]{64}$`)
+	goPackageRegex       = regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_.-]+(/[A-Za-z0-9_.~+-]+)*(@[A-Za-z0-9_.+-]+)?$`)
+)
+
+func validateReleaseInstall(r *ReleaseInstall) []error {
+	var errs []error
+	add := func(field, msg string) {
+		errs = append(errs, &ValidationError{Field: "package.release." + field, Message: msg})
+	}
+
+	for _, tmpl := range []struct{ field, text string }{{"url", r.URL}, {"binary", r.Binary}} {
+		if tmpl.text == "" {
+			add(tmpl.field, "is required")
+		} else if _, err := template.New(tmpl.field).Parse(tmpl.text); err != nil {
+			add(tmpl.field, fmt.Sprintf("invalid template: %v", err))
+		}
+	}
+	if r.Version == "" {
+		add("version", "is required")
+	}
+	if len(r.SHA256) == 0 {
+		add("sha256", "is required: map each \"<os>/<arch>\" to the SHA-256 of its download")
+	}
+	for _, key := range util.SortedKeys(r.SHA256) {
+		if !releasePlatformRegex.MatchString(key) {
+			add("sha256."+key, "key must be <os>/<arch>, e.g. linux/amd64")
+		}
+		if !sha256Regex.MatchString(r.SHA256[key]) {
+			add("sha256."+key, "must be 64 hex characters")
+		}
+	}
+	if r.Binary != "" {
+		if name := r.InstalledName(); name == "" || strings.ContainsAny(name, "/*?[{") {
+			add("name", "is required when the binary's base name is a pattern")
+		}
+	}
+	return errs
+}
+
+func validateAptPackage(apt *AptPackage) []error {
+	var errs []error
+	if apt.Key != "" && apt.Repo == "" {
+		errs = append(errs, &ValidationError{
+			Field:   "package.apt.key",
+			Message: "requires repo: keys are trusted only for their repository",
+		})
+	}
+	if apt.Fingerprint != "" {
+		if apt.Key == "" {
+			errs = append(errs, &ValidationError{
+				Field:   "package.apt.fingerprint",
+				Message: "requires key",
+			})
+		} else if !fingerprintRegex.MatchString(strings.ReplaceAll(apt.Fingerprint, " ", "")) {
+			errs = append(errs, &ValidationError{
+				Field:   "package.apt.fingerprint",
+				Message: "must be a 40-character hex key fingerprint",
+			})
+		}
+	}
+	if apt.Keyring != "" && !keyringRegex.MatchString(apt.Keyring) {
+		errs = append(errs, &ValidationError{
+			Field:   "package.apt.keyring",
+			Message: "must be a file name (letters, digits, '.', '_', '-')",
+		})
+	}
+	return errs
+}
+
 func isValidName(name string) bool {
 	return nameRegex.MatchString(name)
 }
+
+// validValueShell reports whether shell is empty or a shell GDF generates
+// init scripts for.
+func validValueShell(shell string) bool {
+	return shell == "" || shell == "bash" || shell == "zsh" || shell == "fish"
+}
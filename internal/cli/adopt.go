@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
+	"github.com/rztaylor/GoDotFiles/internal/git"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [target...]",
+	Short: "Accept drifted target content back into the repository",
+	Long: `Adopt copies the content of drifted managed targets into their repository
+sources, restores the managed symlinks, and commits the updated sources.
+
+Only targets that exist as regular files can be adopted (for example when an
+editor or installer replaced the managed symlink). With no arguments, every
+adoptable drift target reported by 'gdf status diff' is processed.
+
+Use --interactive to review each changed hunk and keep only the selected ones.
+When a hunk is rejected, the target is left as a regular file so the rejected
+edits stay in place; it is still reported as drift until it matches the source.
+Replaced targets are snapshotted first, so 'gdf recover rollback' can restore them.`,
+	Example: `  gdf adopt
+  gdf adopt ~/.gitconfig
+  gdf adopt --interactive ~/.zshrc
+  gdf adopt --no-commit`,
+	RunE: runAdopt,
+}
+
+var adoptInteractive bool
+var adoptDryRun bool
+
+// adoptHunkPrompt asks whether to adopt one hunk; tests replace it.
+var adoptHunkPrompt = promptAdoptHunk
+var adoptNoCommit bool
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().BoolVar(&adoptInteractive, "interactive", false, "Choose which changed hunks to adopt")
+	adoptCmd.Flags().BoolVar(&adoptDryRun, "dry-run", false, "Show which targets would be adopted without making changes")
+	adoptCmd.Flags().BoolVar(&adoptNoCommit, "no-commit", false, "Stage adopted sources without committing them")
+}
+
+// adoptCandidate is a managed target whose on-disk file should replace the repo source.
+type adoptCandidate struct {
+	App       string
+	Source    string // relative to ~/.gdf/dotfiles
+	SourceAbs string
+	Target    string
+}
+
+// adoptHunk is one changed region between the repo source and the target.
+type adoptHunk struct {
+	SourceStart int
+	TargetStart int
+	Removed     []string
+	Added       []string
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	gdfDir := platform.ConfigDir()
+	if adoptInteractive && globalNonInteractive {
+		return withExitCode(fmt.Errorf("--interactive requires interactive input"), exitCodeNonInteractiveStop)
+	}
+
+	report, err := collectStatusReport(gdfDir, driftOptions{IncludeIssues: true})
+	if err != nil {
+		return err
+	}
+	candidates, err := selectAdoptCandidates(gdfDir, report.Drift.Issues, args)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		printStatusLine(outputStatusOK, "No drifted targets to adopt.")
+		return nil
+	}
+
+	printSectionHeading("Adopt Plan")
+	for _, c := range candidates {
+		fmt.Printf("  - %s -> %s (app=%s)\n", c.Target, c.Source, c.App)
+	}
+	if adoptDryRun {
+		fmt.Println("Dry run only. No changes were made.")
+		return nil
+	}
+	if !adoptInteractive {
+		ok, err := confirmPromptDefaultYes(fmt.Sprintf("Adopt %d target(s) into the repository? [Y/n]: ", len(candidates)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	repo, err := git.Open(gdfDir)
+	if err != nil {
+		return fmt.Errorf("opening repository: %w", err)
+	}
+	cfg, err := config.LoadConfig(filepath.Join(gdfDir, "config.yaml"))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	// Targets are known to be real files, so "replace" only swaps them for the
+	// managed link after the history manager has captured a snapshot.
+	linker := engine.NewLinker("replace")
+	linker.SetHistoryManager(engine.NewHistoryManager(gdfDir, cfg.History.MaxSizeMBDefault()))
+	logger := engine.NewLogger(false)
+
+	adopted := make([]adoptCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		ok, err := adoptTarget(c, gdfDir, linker, logger)
+		if err != nil {
+			return err
+		}
+		if ok {
+			adopted = append(adopted, c)
+		}
+	}
+
+	if logPath, err := logger.Save(gdfDir); err != nil {
+		fmt.Printf("! Warning: failed to save adopt operation log: %v\n", err)
+	} else if logPath != "" {
+		fmt.Printf("Logged adopt operations for rollback: %s\n", logPath)
+	}
+
+	if len(adopted) == 0 {
+		fmt.Println("Nothing adopted.")
+		return nil
+	}
+
+	paths := make([]string, 0, len(adopted))
+	for _, c := range adopted {
+		paths = append(paths, filepath.Join("dotfiles", c.Source))
+	}
+	if err := repo.Add(paths...); err != nil {
+		return fmt.Errorf("staging adopted sources: %w", err)
+	}
+
+	message := adoptCommitMessage(adopted, platform.Detect().Home)
+	if adoptNoCommit {
+		printStatusLine(outputStatusOK, fmt.Sprintf("Adopted and staged %d file(s).", len(adopted)))
+		printNextStep(fmt.Sprintf("gdf save %q", strings.SplitN(message, "\n", 2)[0]))
+		return nil
+	}
+	if err := repo.CommitPaths(message, paths...); err != nil {
+		return fmt.Errorf("committing adopted sources: %w", err)
+	}
+	printStatusLine(outputStatusOK, fmt.Sprintf("Adopted %d file(s): %s", len(adopted), strings.SplitN(message, "\n", 2)[0]))
+	return nil
+}
+
+// selectAdoptCandidates filters drift issues down to regular-file targets,
+// optionally restricted to the requested target paths.
+func selectAdoptCandidates(gdfDir string, issues []driftIssue, requested []string) ([]adoptCandidate, error) {
+	dotfilesDir := filepath.Join(gdfDir, "dotfiles")
+	wanted := make(map[string]bool, len(requested))
+	for _, r := range requested {
+		wanted[platform.ExpandPath(r)] = false
+	}
+
+	out := make([]adoptCandidate, 0)
+	for _, issue := range issues {
+		if issue.Type != "target_not_symlink" && issue.Type != "source_missing" {
+			continue
+		}
+		if len(wanted) > 0 {
+			if _, ok := wanted[issue.Target]; !ok {
+				continue
+			}
+		}
+		info, err := os.Lstat(issue.Target)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		rel, err := filepath.Rel(dotfilesDir, issue.Source)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if len(wanted) > 0 {
+			wanted[issue.Target] = true
+		}
+		out = append(out, adoptCandidate{
+			App:       issue.App,
+			Source:    rel,
+			SourceAbs: issue.Source,
+			Target:    issue.Target,
+		})
+	}
+
+	for _, r := range requested {
+		if !wanted[platform.ExpandPath(r)] {
+			return nil, fmt.Errorf("no adoptable drift for %s (target must be a managed path that is a regular file)", r)
+		}
+	}
+	return out, nil
+}
+
+// adoptTarget writes the selected target content into the repo source and
+// relinks the target. It returns false when the user adopted no hunks. When
+// some hunks were rejected the target is not relinked, as that would discard
+// the rejected edits.
+func adoptTarget(c adoptCandidate, gdfDir string, linker *engine.Linker, logger *engine.Logger) (bool, error) {
+	targetData, err := os.ReadFile(c.Target)
+	if err != nil {
+		return false, fmt.Errorf("reading target %s: %w", c.Target, err)
+	}
+	sourceData, err := os.ReadFile(c.SourceAbs)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("reading source %s: %w", c.SourceAbs, err)
+	}
+
+	content := targetData
+	rejected := 0
+	if adoptInteractive && len(sourceData) > 0 {
+		fmt.Println()
+		printSectionHeading(c.Target)
+		merged, accepted, total, err := mergeAdoptHunks(string(sourceData), string(targetData), adoptHunkPrompt)
+		if err != nil {
+			return false, err
+		}
+		if accepted == 0 {
+			fmt.Printf("  - skipped %s (no hunks selected)\n", c.Target)
+			return false, nil
+		}
+		content = []byte(merged)
+		rejected = total - accepted
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(c.SourceAbs); err == nil {
+		mode = info.Mode().Perm()
+	} else if info, err := os.Stat(c.Target); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(c.SourceAbs), 0755); err != nil {
+		return false, fmt.Errorf("creating source directory for %s: %w", c.Source, err)
+	}
+	if err := util.WriteFileAtomic(c.SourceAbs, content, mode); err != nil {
+		return false, fmt.Errorf("writing source %s: %w", c.Source, err)
+	}
+
+	if rejected > 0 {
+		fmt.Printf("  ✓ %s → %s (partial: %d rejected hunk(s) kept in %s, which is left unlinked)\n", c.Target, c.Source, rejected, c.Target)
+		return true, nil
+	}
+
+	if err := linker.Link(apps.Dotfile{Source: c.Source, Target: c.Target}, gdfDir); err != nil {
+		return false, fmt.Errorf("restoring managed link for %s: %w", c.Target, err)
+	}
+	details := map[string]string{
+		"source":     c.Source,
+		"app":        c.App,
+		"source_abs": c.SourceAbs,
+		"reason":     "adopt",
+	}
+	addSnapshotLogDetails(details, linker.ConsumeConflictSnapshot(c.Target))
+	logger.Log("link", c.Target, details)
+
+	fmt.Printf("  ✓ %s → %s\n", c.Target, c.Source)
+	return true, nil
+}
+
+// mergeAdoptHunks rebuilds the source, taking target lines only for the hunks
+// accepted by choose. It returns the merged content, the accepted count and
+// the total number of hunks.
+func mergeAdoptHunks(source, target string, choose func(adoptHunk) (bool, error)) (string, int, int, error) {
+	a := util.SplitLinesKeepEnds(source)
+	b := util.SplitLinesKeepEnds(target)
+	matcher := difflib.NewMatcher(a, b)
+
+	var out strings.Builder
+	accepted, total := 0, 0
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			out.WriteString(strings.Join(a[op.I1:op.I2], ""))
+			continue
+		}
+		hunk := adoptHunk{
+			SourceStart: op.I1 + 1,
+			TargetStart: op.J1 + 1,
+			Removed:     a[op.I1:op.I2],
+			Added:       b[op.J1:op.J2],
+		}
+		total++
+		ok, err := choose(hunk)
+		if err != nil {
+			return "", 0, 0, err
+		}
+		if ok {
+			accepted++
+			out.WriteString(strings.Join(hunk.Added, ""))
+		} else {
+			out.WriteString(strings.Join(hunk.Removed, ""))
+		}
+	}
+	return out.String(), accepted, total, nil
+}
+
+func promptAdoptHunk(h adoptHunk) (bool, error) {
+	fmt.Printf("@@ -%d,%d +%d,%d @@\n", h.SourceStart, len(h.Removed), h.TargetStart, len(h.Added))
+	for _, line := range h.Removed {
+		fmt.Printf("-%s\n", strings.TrimSuffix(line, "\n"))
+	}
+	for _, line := range h.Added {
+		fmt.Printf("+%s\n", strings.TrimSuffix(line, "\n"))
+	}
+	return confirmPromptUnsafe("Adopt this hunk? [y/N]: ")
+}
+
+func adoptCommitMessage(adopted []adoptCandidate, home string) string {
+	display := func(c adoptCandidate) string {
+		target := c.Target
+		if home != "" && strings.HasPrefix(target, home+string(filepath.Separator)) {
+			target = "~" + strings.TrimPrefix(target, home)
+		}
+		return fmt.Sprintf("%s (%s)", target, c.App)
+	}
+
+	if len(adopted) == 1 {
+		return "Adopt local changes to " + display(adopted[0])
+	}
+	lines := []string{fmt.Sprintf("Adopt local changes to %d files", len(adopted)), ""}
+	for _, c := range adopted {
+		lines = append(lines, "- "+display(c))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/state"
+)
+
+// setupAdoptTest creates a repo whose tool app manages ~/.toolrc with
+// sourceContent, and a drifted regular-file target with targetContent.
+func setupAdoptTest(t *testing.T, sourceContent, targetContent string) (gdfDir, sourcePath, target string) {
+	t.Helper()
+	home := t.TempDir()
+	gdfDir = filepath.Join(home, ".gdf")
+	t.Setenv("HOME", home)
+	configureGitUserGlobal(t, home)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+
+	sourcePath = filepath.Join(gdfDir, "dotfiles", "tool", "toolrc")
+	if err := os.MkdirAll(filepath.Dir(sourcePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sourcePath, []byte(sourceContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bundle := &apps.Bundle{Name: "tool", Dotfiles: []apps.Dotfile{{Source: "tool/toolrc", Target: "~/.toolrc"}}}
+	if err := bundle.Save(filepath.Join(gdfDir, "apps", "tool.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	st := &state.State{AppliedProfiles: []state.AppliedProfile{{Name: "default", Apps: []string{"tool"}, AppliedAt: time.Now()}}}
+	if err := st.Save(filepath.Join(gdfDir, "state.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	target = filepath.Join(home, ".toolrc")
+	if err := os.WriteFile(target, []byte(targetContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return gdfDir, sourcePath, target
+}
+
+func TestRunAdopt_CopiesTargetIntoRepoAndRelinks(t *testing.T) {
+	gdfDir, sourcePath, target := setupAdoptTest(t, "a=1\n", "a=2\n")
+
+	oldYes := globalYes
+	globalYes = true
+	defer func() { globalYes = oldYes }()
+
+	if err := runAdopt(nil, []string{"~/.toolrc"}); err != nil {
+		t.Fatalf("runAdopt() error = %v", err)
+	}
+
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "a=2\n" {
+		t.Fatalf("source content = %q, want adopted target content", string(content))
+	}
+
+	info, err := os.Lstat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("target should be relinked to the repo source")
+	}
+
+	out, err := exec.Command("git", "-C", gdfDir, "log", "-1", "--format=%s").Output()
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	if !strings.Contains(string(out), "Adopt local changes to ~/.toolrc (tool)") {
+		t.Fatalf("last commit = %q, want generated adopt message", strings.TrimSpace(string(out)))
+	}
+}
+
+func TestRunAdopt_InteractiveRejectedHunkKeepsTarget(t *testing.T) {
+	_, sourcePath, target := setupAdoptTest(t, "one\ntwo\nthree\n", "ONE\ntwo\nTHREE\n")
+
+	oldInteractive, oldPrompt := adoptInteractive, adoptHunkPrompt
+	adoptInteractive = true
+	calls := 0
+	adoptHunkPrompt = func(adoptHunk) (bool, error) {
+		calls++
+		return calls == 1, nil // adopt the first hunk, reject the second
+	}
+	defer func() { adoptInteractive, adoptHunkPrompt = oldInteractive, oldPrompt }()
+
+	if err := runAdopt(nil, []string{"~/.toolrc"}); err != nil {
+		t.Fatalf("runAdopt() error = %v", err)
+	}
+
+	if content, _ := os.ReadFile(sourcePath); string(content) != "ONE\ntwo\nthree\n" {
+		t.Errorf("source content = %q, want only the adopted hunk", content)
+	}
+	info, err := os.Lstat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		t.Fatal("target was relinked, discarding the rejected hunk")
+	}
+	if content, _ := os.ReadFile(target); string(content) != "ONE\ntwo\nTHREE\n" {
+		t.Errorf("target content = %q, want the rejected edit kept", content)
+	}
+}
+
+func TestRunAdopt_UnknownTarget(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configureGitUserGlobal(t, home)
+	if err := createNewRepo(filepath.Join(home, ".gdf")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runAdopt(nil, []string{"~/.not-managed"}); err == nil {
+		t.Fatal("expected error for target without adoptable drift")
+	}
+}
+
+func TestMergeAdoptHunks(t *testing.T) {
+	source := "one\ntwo\nthree\nfour\n"
+	target := "one\nTWO\nthree\nfour\nfive\n"
+
+	tests := []struct {
+		name     string
+		accept   []bool
+		want     string
+		accepted int
+	}{
+		{"accept all", []bool{true, true}, target, 2},
+		{"reject all", []bool{false, false}, source, 0},
+		{"accept second only", []bool{false, true}, "one\ntwo\nthree\nfour\nfive\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := 0
+			got, accepted, total, err := mergeAdoptHunks(source, target, func(adoptHunk) (bool, error) {
+				ok := tt.accept[i]
+				i++
+				return ok, nil
+			})
+			if err != nil {
+				t.Fatalf("mergeAdoptHunks() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("merged = %q, want %q", got, tt.want)
+			}
+			if accepted != tt.accepted || total != 2 {
+				t.Errorf("accepted, total = %d, %d, want %d, 2", accepted, total, tt.accepted)
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"sort"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
 	"github.com/spf13/cobra"
 )
 
@@ -24,11 +26,17 @@ var aliasAddCmd = &cobra.Command{
 
 If --app is specified, the alias is added to that app's bundle.
 Otherwise, GDF checks whether the command's first word matches an existing app bundle.
-If no match is found, the alias is stored as a global (unassociated) alias.`,
+If no match is found, the alias is stored as a global (unassociated) alias.
+
+With --when, the command is stored as a conditional variant of the alias and
+is only defined where the condition matches. Variants with other conditions
+are kept; conditional aliases must belong to an app.`,
 	Args: cobra.ExactArgs(2),
 	Example: `  gdf alias add k kubectl
   gdf alias add gco "git checkout" -a git
-  gdf alias add ll "ls -la"`,
+  gdf alias add ll "ls -la"
+  gdf alias add copy pbcopy -a clipboard --when "os == 'macos'"
+  gdf alias add copy "xclip -selection clipboard" -a clipboard --when "os == 'linux'"`,
 	RunE: runAliasAdd,
 }
 
@@ -50,6 +58,7 @@ Searches all app bundles and the global aliases file to find and remove the alia
 }
 
 var aliasApp string
+var aliasWhen string
 
 func init() {
 	rootCmd.AddCommand(aliasCmd)
@@ -58,11 +67,20 @@ func init() {
 	aliasCmd.AddCommand(aliasRemoveCmd)
 
 	aliasAddCmd.Flags().StringVarP(&aliasApp, "app", "a", "", "App bundle to add this alias to")
+	aliasAddCmd.Flags().StringVar(&aliasWhen, "when", "", "Only define the alias where this condition matches (e.g. \"os == 'macos'\")")
 }
 
 func runAliasAdd(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	command := args[1]
+	if err := shell.ValidateAliasName(aliasShellType(), name); err != nil {
+		return err
+	}
+	if aliasWhen != "" {
+		if _, err := config.EvaluateCondition(aliasWhen, platform.Detect()); err != nil {
+			return fmt.Errorf("invalid --when condition: %w", err)
+		}
+	}
 
 	gdfDir := platform.ConfigDir()
 	appsDir := filepath.Join(gdfDir, "apps")
@@ -76,6 +94,9 @@ func runAliasAdd(cmd *cobra.Command, args []string) error {
 
 	// 2a. No app match → store as global alias
 	if appName == "" {
+		if aliasWhen != "" {
+			return fmt.Errorf("conditional aliases must belong to an app; use --app")
+		}
 		aliasesPath := filepath.Join(gdfDir, "aliases.yaml")
 		ga, err := apps.LoadGlobalAliases(aliasesPath)
 		if err != nil {
@@ -122,14 +143,10 @@ func runAliasAdd(cmd *cobra.Command, args []string) error {
 		bundle.Shell = &apps.Shell{}
 	}
 	if bundle.Shell.Aliases == nil {
-		bundle.Shell.Aliases = make(map[string]string)
+		bundle.Shell.Aliases = make(map[string]apps.ConditionalValues)
 	}
 
-	if existing, ok := bundle.Shell.Aliases[name]; ok {
-		fmt.Printf("Overwriting existing alias '%s' (was '%s')\n", name, existing)
-	}
-
-	bundle.Shell.Aliases[name] = command
+	bundle.Shell.Aliases[name] = addAliasVariant(name, bundle.Shell.Aliases[name], command, aliasWhen)
 
 	// 4. Save
 	if err := os.MkdirAll(filepath.Dir(appPath), 0755); err != nil {
@@ -180,7 +197,13 @@ func runAliasList(cmd *cobra.Command, args []string) error {
 			sort.Strings(names)
 
 			for _, name := range names {
-				fmt.Printf("    %s = \"%s\"\n", name, bundle.Shell.Aliases[name])
+				for _, v := range bundle.Shell.Aliases[name] {
+					if v.When != "" {
+						fmt.Printf("    %s = \"%s\" (when: %s)\n", name, v.Value, v.When)
+					} else {
+						fmt.Printf("    %s = \"%s\"\n", name, v.Value)
+					}
+				}
 			}
 		}
 	}
@@ -258,3 +281,41 @@ func runAliasRemove(cmd *cobra.Command, args []string) error {
 
 	return fmt.Errorf("alias '%s' not found in any app bundle or global aliases", name)
 }
+
+// aliasShellType returns the shell used to validate alias names, falling
+// back to bash rules when the login shell is not recognised.
+func aliasShellType() shell.ShellType {
+	if st := shell.ParseShellType(platform.DetectShell()); st != shell.Unknown {
+		return st
+	}
+	return shell.Bash
+}
+
+// addAliasVariant stores command for the given condition. An unconditional
+// add replaces the alias; a conditional add replaces only the variant with
+// the same condition.
+func addAliasVariant(name string, existing apps.ConditionalValues, command, when string) apps.ConditionalValues {
+	if when == "" {
+		if len(existing) > 0 {
+			fmt.Printf("Overwriting existing alias '%s' (was '%s')\n", name, existing.String())
+		}
+		return apps.Value(command)
+	}
+	out := make(apps.ConditionalValues, 0, len(existing)+1)
+	for _, v := range existing {
+		if v.When == when {
+			fmt.Printf("Overwriting existing alias '%s' for '%s' (was '%s')\n", name, when, v.Value)
+			continue
+		}
+		out = append(out, v)
+	}
+	// Keep conditional variants ahead of an unconditional fallback, since
+	// the first matching variant wins.
+	variant := apps.ConditionalValue{Value: command, When: when}
+	for i, v := range out {
+		if v.When == "" {
+			return append(out[:i], append(apps.ConditionalValues{variant}, out[i:]...)...)
+		}
+	}
+	return append(out, variant)
+}
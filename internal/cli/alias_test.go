@@ -3,6 +3,7 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
@@ -48,8 +49,8 @@ func TestAliasAdd(t *testing.T) {
 	if err != nil {
 		t.Fatal("kubectl app not found")
 	}
-	if bundle.Shell.Aliases["k"] != "kubectl get pods" {
-		t.Errorf("alias k = %q, want 'kubectl get pods'", bundle.Shell.Aliases["k"])
+	if bundle.Shell.Aliases["k"].String() != "kubectl get pods" {
+		t.Errorf("alias k = %q, want 'kubectl get pods'", bundle.Shell.Aliases["k"].String())
 	}
 
 	// Test 2: Add alias with explicit app
@@ -65,8 +66,8 @@ func TestAliasAdd(t *testing.T) {
 	if err != nil {
 		t.Fatal("git app not created")
 	}
-	if bundle.Shell.Aliases["gco"] != "git checkout" {
-		t.Errorf("alias gco = %q, want 'git checkout'", bundle.Shell.Aliases["gco"])
+	if bundle.Shell.Aliases["gco"].String() != "git checkout" {
+		t.Errorf("alias gco = %q, want 'git checkout'", bundle.Shell.Aliases["gco"].String())
 	}
 }
 
@@ -107,6 +108,94 @@ func TestAliasAddGlobal(t *testing.T) {
 	}
 }
 
+func TestAliasAdd_RejectsInvalidName(t *testing.T) {
+	tmpDir := t.TempDir()
+	homeDir := filepath.Join(tmpDir, "home")
+	gdfDir := filepath.Join(homeDir, ".gdf")
+
+	os.Setenv("HOME", homeDir)
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	configureGitUserGlobal(t, homeDir)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+
+	aliasApp = ""
+	for _, name := range []string{"g st", "it's", "-x", "if"} {
+		if err := runAliasAdd(nil, []string{name, "git status"}); err == nil {
+			t.Errorf("runAliasAdd(%q) error = nil, want invalid name error", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(gdfDir, "aliases.yaml")); err == nil {
+		ga, err := apps.LoadGlobalAliases(filepath.Join(gdfDir, "aliases.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ga.Aliases) != 0 {
+			t.Errorf("global aliases = %v, want none", ga.Aliases)
+		}
+	}
+}
+
+func TestAliasAdd_When(t *testing.T) {
+	tmpDir := t.TempDir()
+	homeDir := filepath.Join(tmpDir, "home")
+	gdfDir := filepath.Join(homeDir, ".gdf")
+
+	os.Setenv("HOME", homeDir)
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	configureGitUserGlobal(t, homeDir)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { aliasApp, aliasWhen = "", "" }()
+
+	aliasApp = "clipboard"
+	steps := []struct {
+		command string
+		when    string
+	}{
+		{command: "cat", when: ""},
+		{command: "pbcopy", when: "os == 'macos'"},
+		{command: "xclip", when: "os == 'linux'"},
+		{command: "xclip -selection clipboard", when: "os == 'linux'"},
+	}
+	for _, step := range steps {
+		aliasWhen = step.when
+		if err := runAliasAdd(nil, []string{"copy", step.command}); err != nil {
+			t.Fatalf("runAliasAdd(%q, when=%q) error = %v", step.command, step.when, err)
+		}
+	}
+
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "clipboard.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := apps.ConditionalValues{
+		{Value: "pbcopy", When: "os == 'macos'"},
+		{Value: "xclip -selection clipboard", When: "os == 'linux'"},
+		{Value: "cat"},
+	}
+	if got := bundle.Shell.Aliases["copy"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("alias copy = %+v, want %+v", got, want)
+	}
+
+	aliasApp, aliasWhen = "", "os == 'macos'"
+	if err := runAliasAdd(nil, []string{"ll", "ls -la"}); err == nil {
+		t.Error("runAliasAdd() with --when for a global alias error = nil, want error")
+	}
+	aliasApp, aliasWhen = "clipboard", "os =="
+	if err := runAliasAdd(nil, []string{"paste", "pbpaste"}); err == nil {
+		t.Error("runAliasAdd() with invalid --when error = nil, want error")
+	}
+}
+
 func TestAliasAddGlobal_PipelineCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 	homeDir := filepath.Join(tmpDir, "home")
@@ -176,8 +265,8 @@ func TestAliasConflict(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if bundle.Shell.Aliases["gco"] != "git commit" {
-		t.Errorf("alias gco = %q, want 'git commit' (overwrite)", bundle.Shell.Aliases["gco"])
+	if bundle.Shell.Aliases["gco"].String() != "git commit" {
+		t.Errorf("alias gco = %q, want 'git commit' (overwrite)", bundle.Shell.Aliases["gco"].String())
 	}
 }
 
@@ -200,8 +289,8 @@ func TestAliasRemove(t *testing.T) {
 	bundle := &apps.Bundle{
 		Name: "git",
 		Shell: &apps.Shell{
-			Aliases: map[string]string{
-				"gco": "git checkout",
+			Aliases: map[string]apps.ConditionalValues{
+				"gco": apps.Value("git checkout"),
 			},
 		},
 	}
@@ -291,8 +380,8 @@ func TestAliasRemoveMultiApp(t *testing.T) {
 		bundle := &apps.Bundle{
 			Name: app,
 			Shell: &apps.Shell{
-				Aliases: map[string]string{
-					"test": app + " test",
+				Aliases: map[string]apps.ConditionalValues{
+					"test": apps.Value(app + " test"),
 				},
 			},
 		}
@@ -378,9 +467,9 @@ func TestAliasListShowsAllAppsAndGlobal(t *testing.T) {
 	}
 
 	// Create apps with aliases — NOT necessarily in any profile
-	appsData := map[string]map[string]string{
-		"git":     {"gco": "git checkout", "gst": "git status"},
-		"kubectl": {"k": "kubectl", "kgp": "kubectl get pods"},
+	appsData := map[string]map[string]apps.ConditionalValues{
+		"git":     {"gco": apps.Value("git checkout"), "gst": apps.Value("git status")},
+		"kubectl": {"k": apps.Value("kubectl"), "kgp": apps.Value("kubectl get pods")},
 	}
 
 	for appName, aliases := range appsData {
@@ -443,7 +532,7 @@ func TestAliasAddAndRemoveRoundTrip(t *testing.T) {
 
 	gitBundle := &apps.Bundle{
 		Name:  "git",
-		Shell: &apps.Shell{Aliases: map[string]string{"g": "git"}},
+		Shell: &apps.Shell{Aliases: map[string]apps.ConditionalValues{"g": apps.Value("git")}},
 	}
 	if err := gitBundle.Save(filepath.Join(gdfDir, "apps", "git.yaml")); err != nil {
 		t.Fatal(err)
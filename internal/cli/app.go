@@ -632,16 +632,7 @@ func executeAppRemovalCleanup(gdfDir, appName string, plan *appRemovalPlan) erro
 				"app":        appName,
 				"source_abs": filepath.Join(gdfDir, "dotfiles", dotfile.Source),
 			}
-			if snapshot != nil {
-				details["snapshot_id"] = snapshot.ID
-				details["snapshot_path"] = snapshot.Path
-				details["snapshot_kind"] = snapshot.Kind
-				details["snapshot_link_target"] = snapshot.LinkTarget
-				details["snapshot_mode"] = fmt.Sprintf("%#o", uint32(snapshot.Mode.Perm()))
-				details["snapshot_checksum"] = snapshot.Checksum
-				details["snapshot_size_bytes"] = fmt.Sprintf("%d", snapshot.SizeBytes)
-				details["snapshot_captured_at"] = snapshot.CapturedAt.Format("2006-01-02T15:04:05.999999999Z07:00")
-			}
+			addSnapshotLogDetails(details, snapshot)
 			logger.Log("link", target, details)
 			linkedRemoved++
 		}
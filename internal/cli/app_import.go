@@ -11,6 +11,7 @@ import (
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +23,11 @@ var importCmd = &cobra.Command{
 Modes:
   - preview-only: show what would be imported
   - guided mapping: interactively choose app and secret handling (default)
-  - apply: import directly using defaults/flags`,
+  - apply: import directly using defaults/flags
+
+With --brewfile, the taps, formulae and casks of a 'brew bundle' Brewfile are
+imported instead: each becomes the brew package of an app bundle named after
+it.`,
 	RunE: runAppImport,
 }
 
@@ -32,6 +37,7 @@ var (
 	importJSON              bool
 	importProfile           string
 	importSensitiveHandling string
+	importBrewfile          string
 )
 
 type importDotfileCandidate struct {
@@ -60,6 +66,7 @@ func init() {
 	importCmd.Flags().BoolVar(&importJSON, "json", false, "Output discovery/import data as JSON")
 	importCmd.Flags().StringVarP(&importProfile, "profile", "p", "", "Profile to add imported apps to")
 	importCmd.Flags().StringVar(&importSensitiveHandling, "sensitive-handling", "", "Default handling for sensitive files in apply mode: ignore|secret|plain")
+	importCmd.Flags().StringVar(&importBrewfile, "brewfile", "", "Import packages from a Brewfile instead of discovering dotfiles")
 }
 
 func runAppImport(cmd *cobra.Command, args []string) error {
@@ -83,8 +90,19 @@ func runAppImport(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if importBrewfile != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--brewfile cannot be combined with paths")
+		}
+		return runBrewfileImport(platform.ConfigDir(), importBrewfile, mode, profileName)
+	}
+
 	home := platform.Detect().Home
-	dotfiles, aliases, err := discoverImportCandidates(home, args)
+	ignore, err := loadGdfIgnore(platform.ConfigDir())
+	if err != nil {
+		return err
+	}
+	dotfiles, aliases, err := discoverImportCandidates(home, args, ignore)
 	if err != nil {
 		return err
 	}
@@ -242,7 +260,9 @@ func printImportPreview(mode string, dotfiles []importDotfileCandidate, aliases
 	return nil
 }
 
-func discoverImportCandidates(home string, extraPaths []string) ([]importDotfileCandidate, []importAliasCandidate, error) {
+// discoverImportCandidates returns known and explicitly requested dotfiles plus
+// shell aliases. Files matching ignore rules are left out of the results.
+func discoverImportCandidates(home string, extraPaths []string, ignore *util.IgnoreMatcher) ([]importDotfileCandidate, []importAliasCandidate, error) {
 	knownFiles := []string{
 		".gitconfig",
 		".zshrc",
@@ -274,6 +294,9 @@ func discoverImportCandidates(home string, extraPaths []string) ([]importDotfile
 			continue
 		}
 		seen[p] = true
+		if isGdfIgnored(ignore, home, p) {
+			continue
+		}
 		info, err := os.Stat(p)
 		if err != nil || info.IsDir() {
 			continue
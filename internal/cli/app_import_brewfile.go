@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// Import statuses of Brewfile entries.
+const (
+	brewImportNewApp     = "new app"
+	brewImportAddPackage = "add package"
+	brewImportUnchanged  = "unchanged"
+	brewImportConflict   = "conflict"
+)
+
+type importBrewCandidate struct {
+	App     string            `json:"app"`
+	Package *apps.BrewPackage `json:"package"`
+	Status  string            `json:"status"`
+}
+
+type importBrewfileOutput struct {
+	Mode     string                `json:"mode"`
+	Packages []importBrewCandidate `json:"packages"`
+	Skipped  []string              `json:"skipped,omitempty"`
+}
+
+// runBrewfileImport maps each formula and cask of a Brewfile to an app bundle
+// named after it, creating the bundle or adding the brew package to it.
+// Bundles that already define a different brew package are left alone.
+func runBrewfileImport(gdfDir, path, mode, profileName string) error {
+	f, err := os.Open(platform.ExpandPath(path))
+	if err != nil {
+		return fmt.Errorf("opening Brewfile: %w", err)
+	}
+	defer f.Close()
+	brewfile, err := packages.ParseBrewfile(f)
+	if err != nil {
+		return err
+	}
+	var tapOf map[*apps.BrewPackage]string
+	if brew := packages.NewBrew(); len(brewfile.Taps) > 0 && brew.IsAvailable() {
+		// Packages brew cannot find keep no tap; they are reported below.
+		tapOf, _ = brew.PackageTaps(brewfile.Packages)
+	}
+	unusedTaps := brewfile.AttachTaps(tapOf)
+
+	appsDir := filepath.Join(gdfDir, "apps")
+	candidates := make([]importBrewCandidate, 0, len(brewfile.Packages))
+	for _, pkg := range brewfile.Packages {
+		candidate, err := classifyBrewCandidate(appsDir, pkg)
+		if err != nil {
+			return err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if mode == "preview" {
+		return printBrewfileImport(mode, candidates, brewfile.Skipped)
+	}
+
+	audit := newDecisionAudit("gdf app import", false)
+	imported := make([]importBrewCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Status == brewImportUnchanged || c.Status == brewImportConflict {
+			continue
+		}
+		if mode == "guided" {
+			ok, err := confirmPromptDefaultYes(fmt.Sprintf("\nImport %s into app '%s' (%s)? [Y/n]: ", describeBrewPackage(c.Package), c.App, c.Status))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				audit.Record(c.Package.Name, "brewfile-import", "skip")
+				continue
+			}
+		}
+
+		appPath := filepath.Join(appsDir, c.App+".yaml")
+		bundle, err := loadOrNewBundle(appPath, c.App)
+		if err != nil {
+			return err
+		}
+		if bundle.Package == nil {
+			bundle.Package = &apps.Package{}
+		}
+		bundle.Package.Brew = c.Package
+		if err := os.MkdirAll(appsDir, 0755); err != nil {
+			return fmt.Errorf("creating apps directory: %w", err)
+		}
+		if err := bundle.Save(appPath); err != nil {
+			return fmt.Errorf("saving app '%s': %w", c.App, err)
+		}
+		if err := addAppToProfile(gdfDir, profileName, c.App); err != nil {
+			return err
+		}
+		imported = append(imported, c)
+	}
+
+	if logPath, err := audit.Save(gdfDir); err != nil {
+		return err
+	} else if logPath != "" {
+		fmt.Printf("Logged import decisions: %s\n", logPath)
+	}
+
+	if importJSON {
+		return printBrewfileImport(mode, imported, brewfile.Skipped)
+	}
+	for _, c := range candidates {
+		if c.Status == brewImportConflict {
+			fmt.Printf("! Skipped %s: app '%s' already installs a different brew package\n", describeBrewPackage(c.Package), c.App)
+		}
+	}
+	for _, tap := range unusedTaps {
+		command := "brew tap " + tap.Name
+		if tap.URL != "" {
+			command += " " + tap.URL
+		}
+		fmt.Printf("! Tap %s is not linked to any imported package; run '%s' on new machines if you need it\n", tap.Name, command)
+	}
+	printBrewfileSkipped(brewfile.Skipped)
+	fmt.Printf("Imported %d package(s) from Brewfile.\n", len(imported))
+	return nil
+}
+
+// classifyBrewCandidate picks the app for pkg and how importing changes it.
+func classifyBrewCandidate(appsDir string, pkg *apps.BrewPackage) (importBrewCandidate, error) {
+	name := pkg.Name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	c := importBrewCandidate{App: AppName(name), Package: pkg, Status: brewImportNewApp}
+
+	appPath := filepath.Join(appsDir, c.App+".yaml")
+	if _, err := os.Stat(appPath); os.IsNotExist(err) {
+		return c, nil
+	}
+	bundle, err := apps.Load(appPath)
+	if err != nil {
+		return c, fmt.Errorf("loading app '%s': %w", c.App, err)
+	}
+	switch {
+	case bundle.Package == nil || bundle.Package.Brew == nil:
+		c.Status = brewImportAddPackage
+	case reflect.DeepEqual(bundle.Package.Brew, pkg):
+		c.Status = brewImportUnchanged
+	default:
+		c.Status = brewImportConflict
+	}
+	return c, nil
+}
+
+func printBrewfileImport(mode string, candidates []importBrewCandidate, skipped []string) error {
+	if importJSON {
+		data, err := json.MarshalIndent(importBrewfileOutput{Mode: mode, Packages: candidates, Skipped: skipped}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Discovered %d package(s) in Brewfile.\n", len(candidates))
+	for _, c := range candidates {
+		fmt.Printf("  %s -> app=%s (%s)\n", describeBrewPackage(c.Package), c.App, c.Status)
+	}
+	printBrewfileSkipped(skipped)
+	return nil
+}
+
+func printBrewfileSkipped(skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Printf("Skipped %d unsupported Brewfile entries:\n", len(skipped))
+	for _, line := range skipped {
+		fmt.Printf("  - %s\n", line)
+	}
+}
+
+// describeBrewPackage renders a brew package for messages.
+func describeBrewPackage(pkg *apps.BrewPackage) string {
+	kind := "formula"
+	if pkg.Cask {
+		kind = "cask"
+	}
+	desc := fmt.Sprintf("%s %s", kind, pkg.Name)
+	if len(pkg.Args) > 0 {
+		desc += " " + strings.Join(pkg.Args, " ")
+	}
+	return desc
+}
@@ -23,7 +23,7 @@ func TestDiscoverImportCandidates(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	dotfiles, aliases, err := discoverImportCandidates(home, nil)
+	dotfiles, aliases, err := discoverImportCandidates(home, nil, nil)
 	if err != nil {
 		t.Fatalf("discoverImportCandidates() error = %v", err)
 	}
@@ -120,7 +120,7 @@ func TestRunAppImport_ApplySecretAndAlias(t *testing.T) {
 	if err != nil {
 		t.Fatalf("loading git bundle for alias: %v", err)
 	}
-	if gitBundle.Shell == nil || gitBundle.Shell.Aliases["gs"] != "git status" {
+	if gitBundle.Shell == nil || gitBundle.Shell.Aliases["gs"].String() != "git status" {
 		t.Fatalf("expected imported alias in git bundle, got %#v", gitBundle.Shell)
 	}
 }
@@ -131,6 +131,7 @@ type importFlags struct {
 	json              bool
 	profile           string
 	sensitiveHandling string
+	brewfile          string
 }
 
 func importFlagSnapshot() importFlags {
@@ -140,6 +141,7 @@ func importFlagSnapshot() importFlags {
 		json:              importJSON,
 		profile:           importProfile,
 		sensitiveHandling: importSensitiveHandling,
+		brewfile:          importBrewfile,
 	}
 }
 
@@ -149,4 +151,33 @@ func restoreImportFlags(s importFlags) {
 	importJSON = s.json
 	importProfile = s.profile
 	importSensitiveHandling = s.sensitiveHandling
+	importBrewfile = s.brewfile
+}
+
+func TestDiscoverImportCandidates_RespectsGdfIgnore(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".vimrc"), []byte("set nu\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history := filepath.Join(home, ".python_history")
+	if err := os.WriteFile(history, []byte("print(1)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ignore, err := loadGdfIgnore(filepath.Join(home, ".gdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dotfiles, _, err := discoverImportCandidates(home, []string{history}, ignore)
+	if err != nil {
+		t.Fatalf("discoverImportCandidates() error = %v", err)
+	}
+	for _, d := range dotfiles {
+		if d.Path == history {
+			t.Fatal("history file should be excluded by default .gdfignore rules")
+		}
+	}
+	if len(dotfiles) != 1 {
+		t.Fatalf("dotfiles = %#v, want only .vimrc", dotfiles)
+	}
 }
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <app>",
+	Short: "Show the details of an app bundle",
+	Long: `Show an app bundle's packages, dotfiles, and shell integration.
+
+PATH entries are listed in bundle order with their status on this machine:
+whether the 'when' condition matches and whether the directory exists.
+Variables in a directory resolve against the app's env vars; entries that
+depend on a command or an unset variable are shown as unknown.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShow,
+}
+
+func init() {
+	appCmd.AddCommand(showCmd)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	appName := args[0]
+	gdfDir := platform.ConfigDir()
+	appPath := filepath.Join(gdfDir, "apps", appName+".yaml")
+	bundle, err := apps.Load(appPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("app '%s' not found (expected %s)", appName, appPath)
+		}
+		return fmt.Errorf("loading app: %w", err)
+	}
+
+	plat := platform.Detect()
+	printSectionHeading("Summary")
+	summary := []keyValue{{Key: "Name", Value: bundle.Name}}
+	if bundle.Description != "" {
+		summary = append(summary, keyValue{Key: "Description", Value: bundle.Description})
+	}
+	if len(bundle.Dependencies) > 0 {
+		summary = append(summary, keyValue{Key: "Dependencies", Value: strings.Join(bundle.Dependencies, ", ")})
+	}
+	if pkgs := describePackages(bundle.Package); pkgs != "" {
+		summary = append(summary, keyValue{Key: "Packages", Value: pkgs})
+	}
+	printKeyValueLines(summary)
+
+	if len(bundle.Dotfiles) > 0 {
+		fmt.Println()
+		printSectionHeading("Dotfiles")
+		for _, dotfile := range bundle.Dotfiles {
+			line := fmt.Sprintf("  - %s -> %s", dotfile.Source, dotfile.EffectiveTarget(plat.OS))
+			if dotfile.When != "" {
+				line += fmt.Sprintf(" (when: %s)", dotfile.When)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if bundle.Shell == nil {
+		return nil
+	}
+	if len(bundle.Shell.Path) > 0 {
+		fmt.Println()
+		printSectionHeading("PATH")
+		exports, _ := shell.ResolveEnv([]*apps.Bundle{bundle}, aliasShellType(), plat)
+		for _, entry := range bundle.Shell.Path {
+			fmt.Printf("  - %s %s [%s]\n", entry.PositionDefault(), entry.Dir, pathEntryStatus(entry, exports, plat))
+		}
+	}
+	if len(bundle.Shell.Aliases) > 0 {
+		fmt.Println()
+		printSectionHeading("Aliases")
+		for _, name := range util.SortedKeys(bundle.Shell.Aliases) {
+			for _, v := range bundle.Shell.Aliases[name] {
+				fmt.Printf("  - %s = %s%s\n", name, v.Value, whenSuffix(v.When, plat))
+			}
+		}
+	}
+	if len(bundle.Shell.Env) > 0 {
+		fmt.Println()
+		printSectionHeading("Environment")
+		resolved := make(map[string]string)
+		if exports, err := shell.ResolveEnv([]*apps.Bundle{bundle}, aliasShellType(), plat); err == nil {
+			for _, e := range exports {
+				resolved[e.Name] = e.Value
+			}
+		}
+		for _, name := range util.SortedKeys(bundle.Shell.Env) {
+			env := bundle.Shell.Env[name]
+			value, ok := resolved[name]
+			if !ok {
+				value = "(no value on this machine)"
+			}
+			line := fmt.Sprintf("  - %s = %s", name, value)
+			var notes []string
+			if env.Bash != "" || env.Zsh != "" || env.Fish != "" {
+				notes = append(notes, "per-shell")
+			}
+			if len(env.Values) > 0 {
+				notes = append(notes, "conditional")
+			}
+			if !env.ExpandDefault() {
+				notes = append(notes, "literal")
+			}
+			if len(notes) > 0 {
+				line += fmt.Sprintf(" (%s)", strings.Join(notes, ", "))
+			}
+			fmt.Println(line)
+		}
+	}
+	if len(bundle.Shell.Functions) > 0 {
+		fmt.Println()
+		printSectionHeading("Functions")
+		for _, name := range util.SortedKeys(bundle.Shell.Functions) {
+			for _, v := range bundle.Shell.Functions[name] {
+				fmt.Printf("  - %s%s\n", name, whenSuffix(v.When, plat))
+			}
+		}
+	}
+	if len(bundle.Shell.Init) > 0 {
+		fmt.Println()
+		printSectionHeading("Init")
+		for _, snippet := range bundle.Shell.Init {
+			fmt.Printf("  - %s\n", snippet.Name)
+		}
+	}
+	return nil
+}
+
+// pathEntryStatus describes whether a PATH entry takes effect on this machine.
+// Variables in the directory resolve against the app's env vars, as in the
+// generated script; when one cannot be resolved the status is unknown.
+func pathEntryStatus(entry apps.PathEntry, envExports []shell.EnvExport, plat *platform.Platform) string {
+	if entry.When != "" {
+		ok, err := config.EvaluateCondition(entry.When, plat)
+		if err != nil {
+			return fmt.Sprintf("invalid condition: %v", err)
+		}
+		if !ok {
+			return "skipped: when " + entry.When
+		}
+	}
+	dir, ok := shell.ExpandPathDir(entry.Dir, envExports)
+	if !ok {
+		return "unknown: directory depends on a variable set at shell startup"
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if entry.IfExistsDefault() {
+			return "skipped: directory missing"
+		}
+		return "added, directory missing"
+	}
+	return "added"
+}
+
+// whenSuffix annotates a conditional entry with its condition and whether
+// it matches on this machine.
+func whenSuffix(when string, plat *platform.Platform) string {
+	if when == "" {
+		return ""
+	}
+	ok, err := config.EvaluateCondition(when, plat)
+	switch {
+	case err != nil:
+		return fmt.Sprintf(" (when: %s, invalid: %v)", when, err)
+	case ok:
+		return fmt.Sprintf(" (when: %s, active)", when)
+	default:
+		return fmt.Sprintf(" (when: %s, inactive)", when)
+	}
+}
+
+// describePackages lists the package managers an app can be installed with.
+func describePackages(pkg *apps.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	var parts []string
+	for _, manager := range []string{"brew", "apt", "dnf", "pacman", "flatpak", "snap", "nix", "cargo", "go", "pipx", "uv", "npm", "release"} {
+		if name, ok := pkg.ResolveName(manager); ok {
+			parts = append(parts, fmt.Sprintf("%s: %s", manager, name))
+		}
+	}
+	for _, manager := range util.SortedKeys(pkg.Managers) {
+		parts = append(parts, fmt.Sprintf("%s: %s", manager, pkg.Managers[manager]))
+	}
+	if pkg.Custom != nil {
+		parts = append(parts, "custom script")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppShow_ListsPathEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	gdfDir := filepath.Join(tmpDir, ".gdf")
+	t.Setenv("HOME", tmpDir)
+	if err := os.MkdirAll(filepath.Join(gdfDir, "apps"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{".cargo/bin", "rust/tools"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bundle := `kind: App/v1
+name: rust
+description: Rust toolchain
+shell:
+  path:
+    - ~/.cargo/bin
+    - ~/.rustup/bin
+    - dir: /opt/rust/bin
+      when: os == 'plan9'
+    - $RUST_HOME/tools
+    - $RUST_PREFIX/bin
+  env:
+    RUST_HOME: $HOME/rust
+    RUST_PREFIX: $(rustc --print sysroot)
+  aliases:
+    cb: cargo build
+`
+	if err := os.WriteFile(filepath.Join(gdfDir, "apps", "rust.yaml"), []byte(bundle), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runShow(nil, []string{"rust"}); err != nil {
+			t.Fatalf("runShow() error = %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Rust toolchain",
+		"prepend ~/.cargo/bin [added]",
+		"prepend ~/.rustup/bin [skipped: directory missing]",
+		"prepend /opt/rust/bin [skipped: when os == 'plan9']",
+		"prepend $RUST_HOME/tools [added]",
+		"prepend $RUST_PREFIX/bin [unknown: directory depends on a variable set at shell startup]",
+		"cb = cargo build",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+
+	if err := runShow(nil, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("runShow(missing) error = %v, want not found", err)
+	}
+}
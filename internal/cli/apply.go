@@ -15,6 +15,7 @@ import (
 	"github.com/rztaylor/GoDotFiles/internal/config"
 	"github.com/rztaylor/GoDotFiles/internal/engine"
 	"github.com/rztaylor/GoDotFiles/internal/library"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
 	"github.com/rztaylor/GoDotFiles/internal/shell"
 	"github.com/rztaylor/GoDotFiles/internal/state"
@@ -48,6 +49,8 @@ var applyDryRun bool
 var applyAllowRisky bool
 var applyJSON bool
 var applyRunHooks bool
+var applyLocked bool
+var applyAllowDowngrades bool
 var applyHookTimeout time.Duration
 var applyRiskConfirmationPrompt = defaultRiskConfirmationPrompt
 
@@ -57,6 +60,8 @@ func init() {
 	applyCmd.Flags().BoolVar(&applyAllowRisky, "allow-risky", false, "Proceed without confirmation when high-risk scripts are detected")
 	applyCmd.Flags().BoolVar(&applyJSON, "json", false, "Output dry-run plan as JSON")
 	applyCmd.Flags().BoolVar(&applyRunHooks, "run-apply-hooks", false, "Execute hooks.apply commands (disabled by default)")
+	applyCmd.Flags().BoolVar(&applyLocked, "locked", false, "Install the package versions recorded in gdf.lock")
+	applyCmd.Flags().BoolVar(&applyAllowDowngrades, "allow-downgrades", false, "With --locked, let apt install a locked version older than the installed one")
 	applyCmd.Flags().DurationVar(&applyHookTimeout, "apply-hook-timeout", 30*time.Second, "Per-hook timeout when running hooks.apply")
 }
 
@@ -68,20 +73,19 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Load config for conflict strategy
+	// A missing config.yaml loads as defaults; an invalid one is an error
+	// rather than silently dropping its managers and conflict strategy.
 	cfg, err := config.LoadConfig(filepath.Join(gdfDir, "config.yaml"))
 	if err != nil {
-		// Fallback to default if config missing
-		cfg = &config.Config{
-			ConflictResolution: &config.ConflictResolution{
-				Dotfiles: "backup_and_replace",
-			},
-		}
+		return fmt.Errorf("loading config: %w", err)
 	}
 
 	if applyJSON && !applyDryRun {
 		return fmt.Errorf("--json is currently only supported with --dry-run")
 	}
+	if applyAllowDowngrades && !applyLocked {
+		return fmt.Errorf("--allow-downgrades requires --locked")
+	}
 	if applyRunHooks && applyHookTimeout <= 0 {
 		return fmt.Errorf("--apply-hook-timeout must be greater than 0")
 	}
@@ -259,88 +263,39 @@ func runApply(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Syntax check before linking, so a broken source never reaches targets.
+	if err := checkResolvedDotfileSyntax(gdfDir, resolvedApps, plat); err != nil {
+		return err
+	}
+
 	// Phase 5: Apply each app
 	conflictStrategy := "error"
 	if cfg.ConflictResolution != nil {
 		conflictStrategy = cfg.ConflictResolution.DotfilesDefault()
 	}
 	linker := engine.NewLinker(conflictStrategy)
-	linker.SetHistoryManager(engine.NewHistoryManager(gdfDir, cfg.History.MaxSizeMBDefault()))
+	history := engine.NewHistoryManager(gdfDir, cfg.History.MaxSizeMBDefault())
+	linker.SetHistoryManager(history)
+	linker.SetConflictPrompter(promptDotfileConflict)
+
+	// 5a. Install packages, batched per manager
+	var locked map[string]packages.LockEntry
+	if applyLocked {
+		pkgLock, err := packages.LoadLock(filepath.Join(gdfDir, packages.LockFileName))
+		if err != nil {
+			return err
+		}
+		if locked = pkgLock.Entries(packages.LockPlatformKey(plat)); len(locked) == 0 {
+			return fmt.Errorf("%s has no packages for %s; run 'gdf lock refresh' first", packages.LockFileName, packages.LockPlatformKey(plat))
+		}
+	}
+	if err := installResolvedPackages(resolvedApps, plat, cfg, locked, logger); err != nil {
+		return err
+	}
 
 	for _, bundle := range resolvedApps {
 		fmt.Printf("Processing app: %s\n", bundle.Name)
 
-		// 5a. Install package (if defined)
-		if bundle.Package != nil {
-			plan := resolvePackageManagerPlan(bundle.Package, plat, cfg)
-			if plan == nil {
-				fmt.Printf("      ! App '%s' has no supported package manager configuration for this system. Skipping package install.\n", bundle.Name)
-				goto SkipPackageInstall
-			}
-
-			selected := plan.Selected
-			if selected.Name == "custom" {
-				fmt.Println("   Package: custom install script")
-				fmt.Println("      - Skipping custom script execution during apply")
-				logger.Log("package_install_skipped", "custom_script", map[string]string{
-					"manager": "custom",
-					"app":     bundle.Name,
-					"reason":  "custom_script_not_executed_in_apply",
-				})
-				goto SkipPackageInstall
-			}
-
-			fmt.Printf("   Package: %s (via %s)\n", selected.PackageName, selected.Name)
-
-			if selected.Name != "none" {
-				alreadyInstalled := false
-				detectedBy := ""
-				if !applyDryRun {
-					for _, probe := range plan.Probes {
-						installed, checkErr := probe.Manager.IsInstalled(probe.PackageName)
-						if checkErr != nil {
-							fmt.Printf("      ! Could not verify install status for '%s' via %s: %v\n", probe.PackageName, probe.Name, checkErr)
-							continue
-						}
-						if installed {
-							alreadyInstalled = true
-							detectedBy = probe.Name
-							break
-						}
-					}
-					if alreadyInstalled {
-						if detectedBy != "" && detectedBy != selected.Name {
-							fmt.Printf("      - Skipping install (already installed via %s)\n", detectedBy)
-						} else {
-							fmt.Printf("      - Skipping install (already installed)\n")
-						}
-					}
-				}
-
-				if alreadyInstalled {
-					logger.Log("package_install_skipped", selected.PackageName, map[string]string{
-						"manager":          selected.Name,
-						"app":              bundle.Name,
-						"reason":           "already_installed",
-						"detected_manager": detectedBy,
-					})
-				} else {
-					if !applyDryRun {
-						if err := selected.Manager.Install(selected.PackageName); err != nil {
-							return fmt.Errorf("installing package %s: %w", selected.PackageName, err)
-						}
-					}
-					logger.Log("package_install", selected.PackageName, map[string]string{
-						"manager": selected.Name,
-						"app":     bundle.Name,
-					})
-				}
-			} else {
-				fmt.Printf("      - Skipping (no package manager)\n")
-			}
-		}
-
-	SkipPackageInstall:
 		// 5b. Link dotfiles
 		if len(bundle.Dotfiles) > 0 {
 			fmt.Printf("   Dotfiles: %d file(s)\n", len(bundle.Dotfiles))
@@ -369,23 +324,34 @@ func runApply(cmd *cobra.Command, args []string) error {
 						return fmt.Errorf("linking %s: %w", dotfile.Source, err)
 					}
 				}
-				fmt.Printf("      ✓ %s → %s\n", effectiveTarget, dotfile.Source)
 				details := map[string]string{
 					"source": dotfile.Source,
 					"app":    bundle.Name,
 					// Absolute source allows safer rollback checks.
 					"source_abs": filepath.Join(gdfDir, "dotfiles", dotfile.Source),
 				}
-				if snap := linker.ConsumeConflictSnapshot(platform.ExpandPath(effectiveTarget)); snap != nil {
-					details["snapshot_id"] = snap.ID
-					details["snapshot_path"] = snap.Path
-					details["snapshot_kind"] = snap.Kind
-					details["snapshot_link_target"] = snap.LinkTarget
-					details["snapshot_mode"] = fmt.Sprintf("%#o", uint32(snap.Mode.Perm()))
-					details["snapshot_checksum"] = snap.Checksum
-					details["snapshot_size_bytes"] = fmt.Sprintf("%d", snap.SizeBytes)
-					details["snapshot_captured_at"] = snap.CapturedAt.Format("2006-01-02T15:04:05.999999999Z07:00")
+				outcome := linker.ConsumeConflictOutcome(platform.ExpandPath(effectiveTarget))
+				note, linked := applyConflictNote(outcome)
+				if !linked {
+					fmt.Printf("      ! %s not linked: %s\n", effectiveTarget, note)
+					details["reason"] = outcome.Action
+					if outcome.MergeFile != "" {
+						details["merge_file"] = outcome.MergeFile
+					}
+					logger.Log("link_skipped", effectiveTarget, details)
+					continue
 				}
+				if note != "" {
+					if outcome.Warning != "" {
+						fmt.Printf("      ! %s: %s\n", effectiveTarget, outcome.Warning)
+						details["warning"] = outcome.Warning
+					}
+					fmt.Printf("      ✓ %s → %s (%s)\n", effectiveTarget, dotfile.Source, note)
+					details["conflict_action"] = outcome.Action
+				} else {
+					fmt.Printf("      ✓ %s → %s\n", effectiveTarget, dotfile.Source)
+				}
+				addSnapshotLogDetails(details, linker.ConsumeConflictSnapshot(platform.ExpandPath(effectiveTarget)))
 				logger.Log("link", effectiveTarget, details)
 			}
 		}
@@ -450,12 +416,15 @@ func runApply(cmd *cobra.Command, args []string) error {
 	fmt.Println("Generating shell integration...")
 	shellGen := shell.NewGenerator()
 
-	// Detect shell type
-	detectedShell := platform.DetectShell()
-	shellType := shell.ParseShellType(detectedShell)
+	shellTypes, unsupportedShells := configuredShells(cfg)
+	for _, name := range unsupportedShells {
+		fmt.Printf("   ! Skipping unsupported shell %q in shell_integration.shells\n", name)
+	}
 
-	// Generate to ~/.gdf/generated/init.sh
-	shellPath := filepath.Join(gdfDir, "generated", "init.sh")
+	// Generate ~/.gdf/generated/init.<shell> for each configured shell
+	generatedDir := filepath.Join(gdfDir, "generated")
+	var shellErr *shell.InitValidationError
+	var shellPath string
 	if !applyDryRun {
 		compCount, compWarnings, err := generateManagedCompletionFiles(resolvedApps, gdfDir)
 		if err != nil {
@@ -478,14 +447,49 @@ func runApply(cmd *cobra.Command, args []string) error {
 		opts := shell.GenerateOptions{
 			EnableAutoReload:          cfg.ShellIntegration.AutoReloadEnabledDefault(),
 			DisableCompletionCommands: true,
+			Platform:                  plat,
+			Split:                     true,
+		}
+		for _, shellType := range shellTypes {
+			path := filepath.Join(generatedDir, shellType.InitFileName())
+			if err := shellGen.GenerateWithOptions(resolvedApps, shellType, path, ga.Aliases, opts); err != nil {
+				// Keep going so links and state are recorded; the previous init
+				// script stays in place and apply fails at the end.
+				var verr *shell.InitValidationError
+				if !errors.As(err, &verr) {
+					return fmt.Errorf("generating %s shell integration: %w", shellType, err)
+				}
+				if shellErr == nil {
+					shellErr, shellPath = verr, path
+				}
+				continue
+			}
+			logger.Log("shell_generate", path, nil)
 		}
-		if err := shellGen.GenerateWithOptions(resolvedApps, shellType, shellPath, ga.Aliases, opts); err != nil {
+		if err := shell.WriteDispatcher(generatedDir); err != nil {
 			return fmt.Errorf("generating shell integration: %w", err)
 		}
 	}
-	fmt.Println("   ✓ Shell integration updated")
-	fmt.Println("   Next: source ~/.gdf/generated/init.sh")
-	logger.Log("shell_generate", shellPath, nil)
+	if shellErr != nil {
+		fmt.Printf("   ! %v\n", shellErr)
+		fmt.Printf("   ! Kept the previous %s\n", filepath.Base(shellPath))
+		if shellErr.RejectedPath != "" {
+			fmt.Printf("   Rejected script: %s\n", shellErr.RejectedPath)
+		}
+		logger.Log("shell_generate_rejected", shellPath, map[string]string{
+			"error":         shellErr.Error(),
+			"rejected_path": shellErr.RejectedPath,
+		})
+	} else {
+		fmt.Println("   ✓ Shell integration updated")
+		if current := shell.ParseShellType(platform.DetectShell()); current != shell.Unknown {
+			fmt.Printf("   Next: source ~/.gdf/generated/%s\n", current.InitFileName())
+		}
+	}
+
+	if err := applyLoginEnv(gdfDir, resolvedApps, plat, cfg.ShellIntegration.LoginEnvDefault(), history, logger, applyDryRun); err != nil {
+		return err
+	}
 
 	// Phase 7: Save operation log
 	if !applyDryRun {
@@ -515,6 +519,10 @@ func runApply(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if shellErr != nil {
+		return fmt.Errorf("generating shell integration: %w", shellErr)
+	}
+
 	fmt.Println("\n✓ Apply complete!")
 	if applyDryRun {
 		fmt.Println("   (No changes were made - this was a dry run)")
@@ -560,6 +568,22 @@ func resolveApplyProfileNames(requested []string, gdfDir string) ([]string, erro
 	return []string{selected}, nil
 }
 
+// addSnapshotLogDetails records snapshot metadata on a link operation so
+// rollback can restore the pre-change target.
+func addSnapshotLogDetails(details map[string]string, snap *engine.Snapshot) {
+	if snap == nil {
+		return
+	}
+	details["snapshot_id"] = snap.ID
+	details["snapshot_path"] = snap.Path
+	details["snapshot_kind"] = snap.Kind
+	details["snapshot_link_target"] = snap.LinkTarget
+	details["snapshot_mode"] = fmt.Sprintf("%#o", uint32(snap.Mode.Perm()))
+	details["snapshot_checksum"] = snap.Checksum
+	details["snapshot_size_bytes"] = fmt.Sprintf("%d", snap.SizeBytes)
+	details["snapshot_captured_at"] = snap.CapturedAt.Format("2006-01-02T15:04:05.999999999Z07:00")
+}
+
 func defaultRiskConfirmationPrompt(findings []engine.RiskFinding) (bool, error) {
 	if globalNonInteractive && !globalYes {
 		return false, withExitCode(
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rztaylor/GoDotFiles/internal/engine"
+)
+
+// applyConflictDiffMaxBytes bounds the diff shown while resolving a conflict.
+const applyConflictDiffMaxBytes = 1024 * 1024
+
+// promptDotfileConflict implements the "prompt" conflict strategy. Choosing
+// "diff" shows the target/source differences and asks again.
+func promptDotfileConflict(info engine.ConflictInfo) (string, error) {
+	options := []string{engine.ConflictKeep, engine.ConflictReplace}
+	if info.Mergeable {
+		options = append(options, engine.ConflictMerge, "diff")
+	}
+	conflict := "target exists and is not the managed link"
+	if info.Mergeable && !info.HasBase {
+		conflict += " (no previous apply recorded; merge uses shared lines as the base)"
+	}
+
+	for {
+		choice, err := chooseTrackConflictDecision(info.Target, conflict, options)
+		if err != nil {
+			return "", err
+		}
+		if choice != "diff" {
+			return choice, nil
+		}
+		patch, reason := unifiedPatch(info.Source, info.Target, applyConflictDiffMaxBytes)
+		if patch == "" {
+			fmt.Printf("  (no diff: %s)\n", reason)
+			continue
+		}
+		fmt.Print(patch)
+	}
+}
+
+// applyConflictNote describes a resolved conflict for apply output, and
+// reports whether the dotfile ended up linked.
+func applyConflictNote(outcome *engine.ConflictOutcome) (string, bool) {
+	if outcome == nil {
+		return "", true
+	}
+	switch outcome.Action {
+	case engine.ConflictActionKept:
+		return "kept existing file", false
+	case engine.ConflictActionMergeConflict:
+		return fmt.Sprintf("%d merge conflict(s) written to %s", outcome.Conflicts, outcome.MergeFile), false
+	case engine.ConflictActionMerged:
+		if outcome.SourceUpdated {
+			return "merged local edits into source", true
+		}
+		return "merged", true
+	case engine.ConflictActionBackedUp:
+		return "existing file backed up", true
+	default:
+		return "", true
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+// applyLoginEnv writes the login environment files enabled in
+// shell_integration.login_env and removes generated files for targets that
+// are no longer enabled. Every change is snapshotted and logged as a
+// login_env operation so rollback can undo it.
+func applyLoginEnv(gdfDir string, bundles []*apps.Bundle, plat *platform.Platform, targets []string, history *engine.HistoryManager, logger *engine.Logger, dryRun bool) error {
+	enabled := make(map[string]bool)
+	for _, target := range targets {
+		switch {
+		case !contains(shell.LoginEnvTargets(), target):
+			fmt.Printf("   ! Skipping unknown login_env target %q\n", target)
+		case !shell.LoginEnvSupported(target, plat):
+			fmt.Printf("   ! Skipping login_env target %q (not supported on %s)\n", target, plat.OS)
+		default:
+			enabled[target] = true
+		}
+	}
+
+	gen := shell.NewGenerator()
+	for _, target := range shell.LoginEnvTargets() {
+		path := shell.LoginEnvPath(target, gdfDir)
+		current, readErr := os.ReadFile(path)
+		exists := readErr == nil
+
+		if !enabled[target] {
+			if !exists || !shell.IsGeneratedLoginEnv(current) {
+				continue
+			}
+			if dryRun {
+				fmt.Printf("   Would remove login environment file: %s\n", path)
+				continue
+			}
+			details, err := snapshotLoginEnv(history, target, path, "remove")
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("removing login environment file %s: %w", path, err)
+			}
+			logger.Log("login_env", path, details)
+			fmt.Printf("   ✓ Removed login environment file: %s\n", path)
+			continue
+		}
+
+		content, warnings, err := gen.GenerateLoginEnv(target, bundles, plat)
+		if err != nil {
+			return fmt.Errorf("generating %s login environment: %w", target, err)
+		}
+		for _, warning := range warnings {
+			fmt.Printf("   ! %s login environment: %s\n", target, warning)
+		}
+		if exists && bytes.Equal(current, content) {
+			continue
+		}
+		if exists && !shell.IsGeneratedLoginEnv(current) {
+			fmt.Printf("   ! Replacing %s, which was not generated by gdf (snapshot kept for rollback)\n", path)
+		}
+		if dryRun {
+			fmt.Printf("   Would write login environment file: %s\n", path)
+			continue
+		}
+		details, err := snapshotLoginEnv(history, target, path, "write")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", path, err)
+		}
+		if err := util.WriteFileAtomic(path, content, 0644); err != nil {
+			return fmt.Errorf("writing login environment file %s: %w", path, err)
+		}
+		logger.Log("login_env", path, details)
+		fmt.Printf("   ✓ Login environment updated (%s): %s\n", target, path)
+		if target == shell.LoginEnvProfile && !exists {
+			fmt.Println("     Source it from ~/.profile: [ -f ~/.gdf/generated/env.sh ] && . ~/.gdf/generated/env.sh")
+		}
+		if target == shell.LoginEnvLaunchd {
+			// gdf does not load the agent; launchd picks it up at the next login.
+			fmt.Println("     Takes effect at next login. To load it now:")
+			fmt.Printf("       launchctl bootout gui/$(id -u)/%s 2>/dev/null; launchctl bootstrap gui/$(id -u) %q\n", shell.LoginEnvLabel, path)
+		}
+	}
+	return nil
+}
+
+// snapshotLoginEnv captures path before it changes and returns the log
+// details for the operation.
+func snapshotLoginEnv(history *engine.HistoryManager, target, path, action string) (map[string]string, error) {
+	details := map[string]string{"target": target, "action": action}
+	snap, err := history.Capture(path)
+	if err != nil {
+		return nil, fmt.Errorf("snapshotting %s: %w", path, err)
+	}
+	addSnapshotLogDetails(details, snap)
+	return details, nil
+}
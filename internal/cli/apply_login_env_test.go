@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+)
+
+func TestApply_LoginEnvWriteRemoveAndRollback(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd environment.d target is Linux-only")
+	}
+	homeDir := t.TempDir()
+	gdfDir := filepath.Join(homeDir, ".gdf")
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("SHELL", "/bin/bash")
+	configureGitUserGlobal(t, homeDir)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatalf("createNewRepo: %v", err)
+	}
+
+	bundle := &apps.Bundle{
+		Name:  "tool",
+		Shell: &apps.Shell{Env: map[string]apps.EnvVar{"TOOL_HOME": {Value: "$HOME/tool"}}},
+	}
+	if err := bundle.Save(filepath.Join(gdfDir, "apps", "tool.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	profilePath := filepath.Join(gdfDir, "profiles", "default", "profile.yaml")
+	profile, err := config.LoadProfile(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile.Apps = append(profile.Apps, "tool")
+	if err := profile.Save(profilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	setLoginEnv := func(targets ...string) {
+		t.Helper()
+		cfgPath := filepath.Join(gdfDir, "config.yaml")
+		cfg, err := config.LoadConfig(cfgPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.ShellIntegration == nil {
+			cfg.ShellIntegration = &config.ShellIntegrationConfig{}
+		}
+		cfg.ShellIntegration.LoginEnv = targets
+		if err := cfg.Save(cfgPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profileEnv := filepath.Join(gdfDir, "generated", "env.sh")
+	systemdEnv := filepath.Join(homeDir, ".config", "environment.d", "60-gdf.conf")
+
+	setLoginEnv("profile", "systemd")
+	if err := runApply(nil, []string{"default"}); err != nil {
+		t.Fatalf("runApply: %v", err)
+	}
+	if data, err := os.ReadFile(profileEnv); err != nil || !strings.Contains(string(data), `export TOOL_HOME="$HOME/tool"`) {
+		t.Fatalf("env.sh = %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(systemdEnv); err != nil || !strings.Contains(string(data), `TOOL_HOME="$HOME/tool"`) {
+		t.Fatalf("environment.d file = %q, %v", data, err)
+	}
+
+	setLoginEnv("profile")
+	if err := runApply(nil, []string{"default"}); err != nil {
+		t.Fatalf("runApply: %v", err)
+	}
+	if _, err := os.Stat(systemdEnv); !os.IsNotExist(err) {
+		t.Fatalf("environment.d file still exists after disabling systemd target: %v", err)
+	}
+
+	rollbackYes = true
+	defer func() { rollbackYes = false }()
+	if err := runRollback(nil, nil); err != nil {
+		t.Fatalf("runRollback: %v", err)
+	}
+	if _, err := os.Stat(systemdEnv); err != nil {
+		t.Fatalf("rollback did not restore environment.d file: %v", err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
@@ -30,6 +33,25 @@ func TestApplyPackageInstallBehavior(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid config is reported instead of ignored", func(t *testing.T) {
+		_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+		mgr := &MockPackageManager{mgrName: "apt"}
+		packages.Override = mgr
+		defer func() { packages.Override = nil }()
+		cfg := "kind: Config/v1\npackage_manager:\n  managers:\n    vscode:\n      list: code --list-extensions\n"
+		if err := os.WriteFile(filepath.Join(gdfDir, "config.yaml"), []byte(cfg), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := runApply(nil, []string{"default"})
+		if err == nil || !strings.Contains(err.Error(), "loading config") {
+			t.Fatalf("runApply() error = %v, want config error", err)
+		}
+		if mgr.installCalls != 0 {
+			t.Fatalf("installCalls = %d, want 0", mgr.installCalls)
+		}
+	})
+
 	t.Run("installs when package is not installed", func(t *testing.T) {
 		_, _ = setupApplyPackageInstallTest(t, "pkg-app", "git")
 		mgr := &MockPackageManager{
@@ -66,7 +88,7 @@ func TestApplyPackageInstallBehavior(t *testing.T) {
 	t.Run("skips preferred manager install when package is installed via alternate manager", func(t *testing.T) {
 		_, _ = setupApplyPackageInstallBundleTest(t, "pkg-app", &apps.Package{
 			Apt:  &apps.AptPackage{Name: "git"},
-			Brew: "git",
+			Brew: apps.BrewFormula("git"),
 		})
 
 		aptMgr := &MockPackageManager{mgrName: "apt"}
@@ -106,13 +128,55 @@ func TestApplyPackageInstallBehavior(t *testing.T) {
 		}
 	})
 
+	t.Run("installs pending packages of several apps in one batch", func(t *testing.T) {
+		_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+		for _, name := range []string{"curl", "jq"} {
+			bundle := &apps.Bundle{Name: name, Package: &apps.Package{Apt: &apps.AptPackage{Name: name}}}
+			if err := bundle.Save(filepath.Join(gdfDir, "apps", name+".yaml")); err != nil {
+				t.Fatal(err)
+			}
+			if err := addAppToProfile(gdfDir, "default", name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		mgr := &MockPackageManager{mgrName: "apt", installed: []string{"curl"}}
+		packages.Override = mgr
+		defer func() { packages.Override = nil }()
+
+		if err := runApply(nil, []string{"default"}); err != nil {
+			t.Fatalf("runApply() error = %v", err)
+		}
+		if mgr.installCalls != 0 || len(mgr.batchCalls) != 1 {
+			t.Fatalf("installCalls = %d, batchCalls = %v, want one batch", mgr.installCalls, mgr.batchCalls)
+		}
+		got := append([]string(nil), mgr.batchCalls[0]...)
+		sort.Strings(got)
+		if want := []string{"git", "jq"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("batch = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns error for failed batch installs", func(t *testing.T) {
+		_, _ = setupApplyPackageInstallTest(t, "pkg-app", "git")
+		mgr := &MockPackageManager{mgrName: "apt", installErr: errors.New("no such package")}
+		packages.Override = mgr
+		defer func() { packages.Override = nil }()
+
+		err := runApply(nil, []string{"default"})
+		if err == nil || !strings.Contains(err.Error(), "installing package git") {
+			t.Fatalf("runApply() error = %v, want install error for git", err)
+		}
+	})
+
 	t.Run("treats custom install as valid and skips execution during apply", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		marker := filepath.Join(tmpDir, "custom-installed")
+		checkMarker := filepath.Join(tmpDir, "custom-checked")
 		confirm := false
 		_, _ = setupApplyPackageInstallBundleTest(t, "pkg-app", &apps.Package{
 			Custom: &apps.CustomInstall{
 				Script:  "printf custom > " + marker,
+				Check:   "printf checked > " + checkMarker,
 				Confirm: &confirm,
 			},
 		})
@@ -123,9 +187,73 @@ func TestApplyPackageInstallBehavior(t *testing.T) {
 		if _, err := os.Stat(marker); !os.IsNotExist(err) {
 			t.Fatalf("custom install script should not execute during apply")
 		}
+		if _, err := os.Stat(checkMarker); !os.IsNotExist(err) {
+			t.Fatalf("custom check command should not execute during apply")
+		}
 	})
 }
 
+func TestPlanPackageInstallBatches(t *testing.T) {
+	pending := func(app, manager string) pendingPackageInstall {
+		return pendingPackageInstall{App: app, Candidate: packageManagerCandidate{Name: manager, PackageName: app}}
+	}
+	tests := []struct {
+		name    string
+		apps    []*apps.Bundle
+		pending []pendingPackageInstall
+		want    []string
+	}{
+		{
+			name:    "one batch per manager",
+			apps:    []*apps.Bundle{{Name: "git"}, {Name: "jq"}, {Name: "fd"}},
+			pending: []pendingPackageInstall{pending("git", "apt"), pending("jq", "apt"), pending("fd", "brew")},
+			want:    []string{"apt: git jq", "brew: fd"},
+		},
+		{
+			name: "dependency on another manager starts a new batch",
+			apps: []*apps.Bundle{
+				{Name: "node"},
+				{Name: "git"},
+				{Name: "tool", Dependencies: []string{"node"}},
+			},
+			pending: []pendingPackageInstall{pending("node", "brew"), pending("git", "apt"), pending("tool", "apt")},
+			want:    []string{"brew: node", "apt: git tool"},
+		},
+		{
+			name: "transitive dependency through an app without a package",
+			apps: []*apps.Bundle{
+				{Name: "git"},
+				{Name: "node"},
+				{Name: "base", Dependencies: []string{"node"}},
+				{Name: "tool", Dependencies: []string{"base"}},
+			},
+			pending: []pendingPackageInstall{pending("git", "apt"), pending("node", "brew"), pending("tool", "apt")},
+			want:    []string{"apt: git", "brew: node", "apt: tool"},
+		},
+		{
+			name:    "same manager dependency shares a batch",
+			apps:    []*apps.Bundle{{Name: "lib"}, {Name: "tool", Dependencies: []string{"lib"}}},
+			pending: []pendingPackageInstall{pending("lib", "apt"), pending("tool", "apt")},
+			want:    []string{"apt: lib tool"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, batch := range planPackageInstallBatches(tt.apps, tt.pending) {
+				var names []string
+				for _, item := range batch.Items {
+					names = append(names, item.App)
+				}
+				got = append(got, batch.Manager+": "+strings.Join(names, " "))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planPackageInstallBatches() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func setupApplyPackageInstallTest(t *testing.T, appName, pkgName string) (string, string) {
 	return setupApplyPackageInstallBundleTest(t, appName, &apps.Package{
 		Apt: &apps.AptPackage{Name: pkgName},
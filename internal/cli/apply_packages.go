@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// pendingPackageInstall is a package that apply still has to install.
+type pendingPackageInstall struct {
+	App       string
+	Candidate packageManagerCandidate
+	// Locked is the app's gdf.lock entry when apply runs with --locked.
+	Locked *packages.LockEntry
+	// Pinned is set when the manager installs the locked version.
+	Pinned bool
+	// Replaces is the installed version a pinned install replaces.
+	Replaces string
+}
+
+// packageInstallBatch is a set of packages installed with one manager call.
+type packageInstallBatch struct {
+	Manager string
+	Items   []pendingPackageInstall
+}
+
+// installResolvedPackages installs the packages of resolvedApps, batched per
+// package manager. Packages that are already installed (via any configured
+// manager) are skipped; custom install scripts are never run by apply.
+// With locked entries (apply --locked), managers that can pin versions
+// install the locked version, replacing a different installed one; for other
+// managers a differing version is reported.
+func installResolvedPackages(resolvedApps []*apps.Bundle, plat *platform.Platform, cfg *config.Config, locked map[string]packages.LockEntry, logger *engine.Logger) error {
+	var pending []pendingPackageInstall
+	headerPrinted := false
+	for _, bundle := range resolvedApps {
+		if bundle.Package == nil {
+			continue
+		}
+		if !headerPrinted {
+			fmt.Println("Installing packages...")
+			headerPrinted = true
+		}
+
+		plan := resolvePackageManagerPlan(bundle.Package, plat, cfg)
+		if plan == nil {
+			fmt.Printf("   ! App '%s' has no supported package manager configuration for this system. Skipping package install.\n", bundle.Name)
+			continue
+		}
+
+		selected := plan.Selected
+		if selected.Name == "custom" {
+			fmt.Printf("   %s: custom install script\n", bundle.Name)
+			// apply runs no bundle commands, so only the creates path is
+			// consulted; check commands are left to 'gdf app install'.
+			if created, err := packages.NewCustom().Created(selected.Custom); err == nil && created {
+				fmt.Println("      - Already installed (creates path exists)")
+				continue
+			}
+			if selected.Custom != nil && selected.Custom.Check != "" {
+				fmt.Println("      - Not verified: apply does not run custom check commands")
+			}
+			fmt.Println("      - Skipping custom script execution during apply")
+			logger.Log("package_install_skipped", "custom_script", map[string]string{
+				"manager": "custom",
+				"app":     bundle.Name,
+				"reason":  "custom_script_not_executed_in_apply",
+			})
+			continue
+		}
+
+		fmt.Printf("   %s: %s (via %s)\n", bundle.Name, selected.PackageName, selected.Name)
+		if selected.Name == "none" {
+			fmt.Printf("      - Skipping (no package manager)\n")
+			continue
+		}
+
+		p := pendingPackageInstall{App: bundle.Name, Candidate: selected}
+		if entry, ok := locked[bundle.Name]; ok {
+			if entry.Manager == selected.Name && entry.Package == selected.PackageName {
+				p.Locked = &entry
+				_, p.Pinned = selected.Manager.(packages.VersionPinner)
+			} else {
+				fmt.Printf("      ! Ignoring gdf.lock entry (locked %s via %s)\n", entry.Package, entry.Manager)
+			}
+		}
+
+		if !applyDryRun {
+			if detectedBy, installed := probeInstalledPackage(plan); installed {
+				if p.Locked != nil && detectedBy == selected.Name {
+					if reinstall := checkLockedVersion(&p); reinstall {
+						pending = append(pending, p)
+						continue
+					}
+				}
+				if detectedBy != selected.Name {
+					fmt.Printf("      - Skipping install (already installed via %s)\n", detectedBy)
+				} else {
+					fmt.Printf("      - Skipping install (already installed)\n")
+				}
+				logger.Log("package_install_skipped", selected.PackageName, map[string]string{
+					"manager":          selected.Name,
+					"app":              bundle.Name,
+					"reason":           "already_installed",
+					"detected_manager": detectedBy,
+				})
+				continue
+			}
+		}
+		pending = append(pending, p)
+	}
+
+	for _, batch := range planPackageInstallBatches(resolvedApps, pending) {
+		if err := installPackageBatch(batch, logger); err != nil {
+			return err
+		}
+	}
+	if headerPrinted {
+		fmt.Println()
+	}
+	return nil
+}
+
+// probeInstalledPackage checks the plan's managers in probe order and returns
+// the first one that has the package installed.
+func probeInstalledPackage(plan *packageManagerPlan) (string, bool) {
+	for _, probe := range plan.Probes {
+		installed, err := packages.IsPackageInstalled(probe.Manager, probe.Package, probe.PackageName)
+		if err != nil {
+			fmt.Printf("      ! Could not verify install status for '%s' via %s: %v\n", probe.PackageName, probe.Name, err)
+			continue
+		}
+		if installed {
+			return probe.Name, true
+		}
+	}
+	return "", false
+}
+
+// checkLockedVersion compares the installed version of a locked package with
+// the lock and reports whether it has to be reinstalled at the locked version.
+// Drift that the manager cannot correct is reported.
+func checkLockedVersion(p *pendingPackageInstall) bool {
+	c := p.Candidate
+	version, err := packages.InstalledPackageVersion(c.Manager, c.Package, c.PackageName)
+	if err != nil {
+		fmt.Printf("      ! Could not read installed version of '%s': %v\n", c.PackageName, err)
+		return false
+	}
+	if version == p.Locked.Version {
+		return false
+	}
+	if p.Pinned {
+		fmt.Printf("      - Installing locked version %s (installed: %s)\n", p.Locked.Version, version)
+		p.Replaces = version
+		return true
+	}
+	fmt.Printf("      ! Installed version %s differs from locked %s (%s cannot install pinned versions)\n", version, p.Locked.Version, c.Name)
+	return false
+}
+
+// planPackageInstallBatches groups pending installs into batches, one per
+// manager where dependencies allow. resolvedApps is in dependency order; a
+// package joins the earliest batch of its manager that is not before the
+// batch of any of its app's (transitive) dependencies, so dependencies are
+// always installed in an earlier or the same manager call.
+func planPackageInstallBatches(resolvedApps []*apps.Bundle, pending []pendingPackageInstall) []packageInstallBatch {
+	pendingByApp := make(map[string]pendingPackageInstall, len(pending))
+	for _, p := range pending {
+		pendingByApp[p.App] = p
+	}
+
+	var batches []packageInstallBatch
+	// level is the index of the last batch an app or its dependencies use.
+	level := make(map[string]int, len(resolvedApps))
+	for _, bundle := range resolvedApps {
+		minBatch := -1
+		for _, dep := range bundle.Dependencies {
+			if l, ok := level[dep]; ok && l > minBatch {
+				minBatch = l
+			}
+		}
+		level[bundle.Name] = minBatch
+
+		p, ok := pendingByApp[bundle.Name]
+		if !ok {
+			continue
+		}
+		target := -1
+		for i := max(minBatch, 0); i < len(batches); i++ {
+			if batches[i].Manager == p.Candidate.Name {
+				target = i
+				break
+			}
+		}
+		if target < 0 {
+			batches = append(batches, packageInstallBatch{Manager: p.Candidate.Name})
+			target = len(batches) - 1
+		}
+		batches[target].Items = append(batches[target].Items, p)
+		level[bundle.Name] = target
+	}
+	return batches
+}
+
+// installPackageBatch installs one batch and logs a package_install operation
+// for each package that was installed. Failed packages are reported together
+// after the successful ones are logged, so rollback still covers them.
+func installPackageBatch(batch packageInstallBatch, logger *engine.Logger) error {
+	names := make([]string, len(batch.Items))
+	items := make([]packages.BatchItem, len(batch.Items))
+	for i, p := range batch.Items {
+		names[i] = p.Candidate.PackageName
+		items[i] = packages.BatchItem{Name: p.Candidate.PackageName, Package: p.Candidate.Package}
+		if p.Pinned {
+			names[i] += " " + p.Locked.Version
+			items[i].Version = p.Locked.Version
+		}
+	}
+	fmt.Printf("   Installing %d package(s) via %s: %s\n", len(names), batch.Manager, strings.Join(names, ", "))
+
+	var failures map[string]error
+	if !applyDryRun {
+		mgr := batch.Items[0].Candidate.Manager
+		if apt, ok := mgr.(*packages.Apt); ok {
+			apt.SetAllowDowngrades(applyAllowDowngrades)
+		}
+		failures = packages.InstallPackages(mgr, items)
+	}
+
+	var errs []error
+	for _, p := range batch.Items {
+		name := p.Candidate.PackageName
+		if err, failed := failures[name]; failed {
+			fmt.Printf("      ✗ %s\n", name)
+			if p.Pinned && p.Replaces != "" && batch.Manager == "apt" && !applyAllowDowngrades {
+				fmt.Printf("        (a locked version older than the installed %s needs --allow-downgrades)\n", p.Replaces)
+			}
+			errs = append(errs, fmt.Errorf("installing package %s: %w", name, err))
+			continue
+		}
+		if !applyDryRun {
+			fmt.Printf("      ✓ %s\n", name)
+		}
+		details := map[string]string{
+			"manager": batch.Manager,
+			"app":     p.App,
+		}
+		if p.Pinned {
+			details["version"] = p.Locked.Version
+			if p.Replaces != "" {
+				details["previous_version"] = p.Replaces
+			}
+		} else if p.Locked != nil && !applyDryRun {
+			checkLockedVersion(&p)
+		}
+		logger.Log("package_install", name, details)
+	}
+	return errors.Join(errs...)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
 	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
 	"github.com/rztaylor/GoDotFiles/internal/state"
 )
 
@@ -19,6 +20,7 @@ func TestApply(t *testing.T) {
 
 	// Mock environment
 	os.Setenv("HOME", homeDir)
+	t.Setenv("SHELL", "/bin/bash")
 	if err := os.MkdirAll(homeDir, 0755); err != nil {
 		t.Fatal(err)
 	}
@@ -92,14 +94,86 @@ func TestApply(t *testing.T) {
 	}
 
 	// Verify generated shell init contains app startup snippet
-	generatedPath := filepath.Join(gdfDir, "generated", "init.sh")
-	script, err := os.ReadFile(generatedPath)
+	script := readGeneratedShell(t, gdfDir, "init.bash")
+	if !strings.Contains(script, `export PATH="$HOME/.testapp/bin:$PATH"`) {
+		t.Errorf("generated init missing app startup snippet:\n%s", string(script))
+	}
+}
+
+func TestApply_GeneratesInitForConfiguredShells(t *testing.T) {
+	homeDir := t.TempDir()
+	gdfDir := filepath.Join(homeDir, ".gdf")
+	t.Setenv("HOME", homeDir)
+	t.Setenv("SHELL", "/bin/zsh")
+	configureGitUserGlobal(t, homeDir)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatalf("createNewRepo: %v", err)
+	}
+
+	cfgPath := filepath.Join(gdfDir, "config.yaml")
+	cfg, err := config.LoadConfig(cfgPath)
 	if err != nil {
-		t.Fatalf("reading generated init script: %v", err)
+		t.Fatal(err)
 	}
-	if !strings.Contains(string(script), `export PATH="$HOME/.testapp/bin:$PATH"`) {
-		t.Errorf("generated init missing app startup snippet:\n%s", string(script))
+	cfg.ShellIntegration = &config.ShellIntegrationConfig{Shells: []string{"bash", "zsh", "fish"}}
+	if err := cfg.Save(cfgPath); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := &apps.Bundle{
+		Name:  "tool",
+		Shell: &apps.Shell{Env: map[string]apps.EnvVar{"TOOL_HOME": {Value: "/opt/tool"}}},
+	}
+	if err := bundle.Save(filepath.Join(gdfDir, "apps", "tool.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	profilePath := filepath.Join(gdfDir, "profiles", "default", "profile.yaml")
+	profile, err := config.LoadProfile(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile.Apps = append(profile.Apps, "tool")
+	if err := profile.Save(profilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runApply(nil, []string{"default"}); err != nil {
+		t.Fatalf("runApply: %v", err)
+	}
+
+	for file, want := range map[string]string{
+		"init.bash": `export TOOL_HOME="/opt/tool"`,
+		"init.zsh":  `export TOOL_HOME="/opt/tool"`,
+		"init.fish": `set -gx TOOL_HOME "/opt/tool"`,
+		"init.sh":   "init.zsh",
+	} {
+		data := readGeneratedShell(t, gdfDir, file)
+		if !strings.Contains(data, want) {
+			t.Errorf("%s missing %q:\n%s", file, want, data)
+		}
+	}
+}
+
+// readGeneratedShell returns a generated init script followed by the
+// fragments in generated/shell for the same shell.
+func readGeneratedShell(t *testing.T, gdfDir, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(gdfDir, "generated", file))
+	if err != nil {
+		t.Fatalf("reading %s: %v", file, err)
+	}
+	fragments, err := filepath.Glob(filepath.Join(gdfDir, "generated", shell.FragmentDirName, "*"+filepath.Ext(file)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range fragments {
+		fragment, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, fragment...)
 	}
+	return string(data)
 }
 
 func TestApplyRecursiveDependencies(t *testing.T) {
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/syntax"
+)
+
+// dotfileSyntaxIssue is a dotfile source that failed (or could not run) its
+// syntax check.
+type dotfileSyntaxIssue struct {
+	App       string
+	Source    string // relative to ~/.gdf/dotfiles
+	Path      string // absolute file that was checked
+	Err       error
+	Unchecked bool // the checker for this format is not installed
+}
+
+// checkDotfileSyntax validates a dotfile source before it is linked. Files in
+// directory sources are checked by detected format. Template sources and
+// "format: none" are skipped, as are missing sources (reported elsewhere).
+func checkDotfileSyntax(gdfDir, appName string, dotfile apps.Dotfile) []dotfileSyntaxIssue {
+	if dotfile.Template || dotfile.Format == syntax.FormatNone {
+		return nil
+	}
+	sourcePath := filepath.Join(gdfDir, "dotfiles", dotfile.Source)
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return nil
+	}
+
+	var issues []dotfileSyntaxIssue
+	check := func(path, format string) {
+		err := syntax.CheckFile(path, format)
+		if err == nil {
+			return
+		}
+		issues = append(issues, dotfileSyntaxIssue{
+			App:       appName,
+			Source:    dotfile.Source,
+			Path:      path,
+			Err:       err,
+			Unchecked: errors.Is(err, syntax.ErrCheckerUnavailable),
+		})
+	}
+
+	if !info.IsDir() {
+		check(sourcePath, dotfile.Format)
+		return issues
+	}
+	_ = filepath.WalkDir(sourcePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		check(path, "")
+		return nil
+	})
+	return issues
+}
+
+// checkResolvedDotfileSyntax checks every dotfile apply would link on this
+// platform and returns an error listing the sources with syntax errors.
+func checkResolvedDotfileSyntax(gdfDir string, bundles []*apps.Bundle, plat *platform.Platform) error {
+	var failed []dotfileSyntaxIssue
+	for _, bundle := range bundles {
+		for _, dotfile := range bundle.Dotfiles {
+			if dotfile.When != "" {
+				// Invalid conditions are reported by the link phase.
+				if match, err := config.EvaluateCondition(dotfile.When, plat); err != nil || !match {
+					continue
+				}
+			}
+			for _, issue := range checkDotfileSyntax(gdfDir, bundle.Name, dotfile) {
+				if !issue.Unchecked {
+					failed = append(failed, issue)
+				}
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	fmt.Println("\n! Dotfile syntax errors:")
+	for _, issue := range failed {
+		fmt.Printf("   - app=%s, source=%s\n", issue.App, issue.Source)
+		fmt.Printf("     %v\n", issue.Err)
+	}
+	fmt.Println("   Fix the files or set 'format: none' on the dotfile to skip the check.")
+	return withExitCode(fmt.Errorf("apply blocked by %d dotfile syntax error(s)", len(failed)), exitCodeHealthIssues)
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+)
+
+func TestDotfileSyntax_BlocksApplyAndReportsInValidate(t *testing.T) {
+	home := t.TempDir()
+	gdfDir := filepath.Join(home, ".gdf")
+	t.Setenv("HOME", home)
+	configureGitUserGlobal(t, home)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+
+	sourcePath := filepath.Join(gdfDir, "dotfiles", "alacritty", "alacritty.toml")
+	if err := os.MkdirAll(filepath.Dir(sourcePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sourcePath, []byte("[font]\nsize 11\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appPath := filepath.Join(gdfDir, "apps", "alacritty.yaml")
+	bundle := &apps.Bundle{
+		Name:     "alacritty",
+		Dotfiles: []apps.Dotfile{{Source: "alacritty/alacritty.toml", Target: "~/.config/alacritty/alacritty.toml"}},
+	}
+	if err := bundle.Save(appPath); err != nil {
+		t.Fatal(err)
+	}
+	profilePath := filepath.Join(gdfDir, "profiles", "default", "profile.yaml")
+	profile, err := config.LoadProfile(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile.Apps = append(profile.Apps, "alacritty")
+	if err := profile.Save(profilePath); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := runHealthValidateReport(gdfDir)
+	if err != nil {
+		t.Fatalf("runHealthValidateReport() error = %v", err)
+	}
+	if !hasHealthFinding(report, "dotfile_syntax_invalid") {
+		t.Fatalf("expected dotfile_syntax_invalid finding, got %#v", report.Findings)
+	}
+
+	err = runApply(nil, []string{"default"})
+	if err == nil {
+		t.Fatal("expected apply to be blocked by the syntax error")
+	}
+	if ExitCode(err) != exitCodeHealthIssues {
+		t.Fatalf("ExitCode(err) = %d, want %d", ExitCode(err), exitCodeHealthIssues)
+	}
+	target := filepath.Join(home, ".config", "alacritty", "alacritty.toml")
+	if _, err := os.Lstat(target); !os.IsNotExist(err) {
+		t.Fatalf("target should not be linked, lstat err = %v", err)
+	}
+
+	// format: none opts the dotfile out of checking.
+	bundle.Dotfiles[0].Format = "none"
+	if err := bundle.Save(appPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := runApply(nil, []string{"default"}); err != nil {
+		t.Fatalf("runApply() with format none error = %v", err)
+	}
+}
+
+func hasHealthFinding(report *healthReport, code string) bool {
+	for _, f := range report.Findings {
+		if f.Code == code {
+			return true
+		}
+	}
+	return false
+}
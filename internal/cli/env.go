@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage app environment variables",
+	Long:  `Set, list, or unset environment variables within your app bundles.`,
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set <NAME> <value>",
+	Short: "Set an environment variable",
+	Long: `Set an environment variable on an app bundle.
+
+Without --app, the variable is updated in the one app that already defines
+it; new variables need --app.
+
+--shell stores a bash, zsh or fish override of the value. --when stores a
+conditional value, used where the condition matches; values with other
+conditions are kept. Values are expanded by the shell ($HOME, $(cmd)) unless
+--literal is set; --literal covers every value of the variable, and setting a
+value without it turns expansion back on.`,
+	Args: cobra.ExactArgs(2),
+	Example: `  gdf env set GOPATH '$HOME/go' -a go
+  gdf env set EDITOR nvim -a neovim
+  gdf env set BROWSER open -a desktop --when "os == 'macos'"
+  gdf env set PROMPT_MARK '$ ' -a prompt --literal`,
+	RunE: runEnvSet,
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset <NAME>",
+	Short: "Remove an environment variable",
+	Long:  `Remove an environment variable, with all its values, from its app bundle.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnvUnset,
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List environment variables",
+	Long:  `List environment variables from all app bundles.`,
+	Args:  cobra.NoArgs,
+	RunE:  runEnvList,
+}
+
+var (
+	envApp     string
+	envWhen    string
+	envShell   string
+	envLiteral bool
+)
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envSetCmd)
+	envCmd.AddCommand(envUnsetCmd)
+	envCmd.AddCommand(envListCmd)
+
+	envSetCmd.Flags().StringVarP(&envApp, "app", "a", "", "App bundle the variable belongs to")
+	envSetCmd.Flags().StringVar(&envWhen, "when", "", "Only use this value where the condition matches (e.g. \"os == 'macos'\")")
+	envSetCmd.Flags().StringVar(&envShell, "shell", "", "Store the value as an override for one shell: bash, zsh or fish")
+	envSetCmd.Flags().BoolVar(&envLiteral, "literal", false, "Export the value literally, without shell expansion")
+	envUnsetCmd.Flags().StringVarP(&envApp, "app", "a", "", "App bundle the variable belongs to")
+}
+
+func runEnvSet(cmd *cobra.Command, args []string) error {
+	name, value := args[0], args[1]
+	if err := shell.ValidateEnvName(aliasShellType(), name); err != nil {
+		return err
+	}
+	if envShell != "" && envShell != "bash" && envShell != "zsh" && envShell != "fish" {
+		return fmt.Errorf("invalid --shell %q: expected bash, zsh or fish", envShell)
+	}
+	if envShell != "" && envWhen != "" {
+		return fmt.Errorf("--shell and --when cannot be combined")
+	}
+	if envWhen != "" {
+		if _, err := config.EvaluateCondition(envWhen, platform.Detect()); err != nil {
+			return fmt.Errorf("invalid --when condition: %w", err)
+		}
+	}
+
+	appsDir := filepath.Join(platform.ConfigDir(), "apps")
+	appName := envApp
+	if appName == "" {
+		ref, err := findAppWith(appsDir, "", "environment variable", name, func(s *apps.Shell) bool {
+			_, ok := s.Env[name]
+			return ok
+		})
+		if errors.Is(err, errNotInApps) {
+			return fmt.Errorf("%s is not set by any app; use --app to choose one", name)
+		}
+		if err != nil {
+			return err
+		}
+		appName = ref.bundle.Name
+	}
+
+	appPath := filepath.Join(appsDir, appName+".yaml")
+	bundle, err := loadOrNewBundle(appPath, appName)
+	if err != nil {
+		return err
+	}
+	if bundle.Shell == nil {
+		bundle.Shell = &apps.Shell{}
+	}
+	if bundle.Shell.Env == nil {
+		bundle.Shell.Env = make(map[string]apps.EnvVar)
+	}
+
+	env := bundle.Shell.Env[name]
+	switch {
+	case envShell == "bash":
+		env.Bash = value
+	case envShell == "zsh":
+		env.Zsh = value
+	case envShell == "fish":
+		env.Fish = value
+	case envWhen != "":
+		values := make([]apps.ConditionalValue, 0, len(env.Values)+1)
+		for _, v := range env.Values {
+			if v.When != envWhen {
+				values = append(values, v)
+			}
+		}
+		env.Values = append(values, apps.ConditionalValue{Value: value, When: envWhen})
+	default:
+		env.Value = value
+	}
+	// The flag applies to every value of the variable, so setting one
+	// without it turns expansion back on.
+	env.Expand = nil
+	if envLiteral {
+		expand := false
+		env.Expand = &expand
+	}
+	bundle.Shell.Env[name] = env
+
+	if err := os.MkdirAll(filepath.Dir(appPath), 0755); err != nil {
+		return fmt.Errorf("creating apps directory: %w", err)
+	}
+	if err := bundle.Save(appPath); err != nil {
+		return fmt.Errorf("saving app bundle: %w", err)
+	}
+	fmt.Printf("✓ Set %s=%q%s in app '%s'\n", name, value, describeVariant(apps.ConditionalValue{When: envWhen, Shell: envShell}), appName)
+	return nil
+}
+
+func runEnvUnset(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ref, err := findAppWith(filepath.Join(platform.ConfigDir(), "apps"), envApp, "environment variable", name, func(s *apps.Shell) bool {
+		_, ok := s.Env[name]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	delete(ref.bundle.Shell.Env, name)
+	if err := ref.bundle.Save(ref.path); err != nil {
+		return fmt.Errorf("saving app '%s': %w", ref.bundle.Name, err)
+	}
+	fmt.Printf("✓ Removed %s from app '%s'\n", name, ref.bundle.Name)
+	return nil
+}
+
+func runEnvList(cmd *cobra.Command, args []string) error {
+	bundles, err := loadAppBundles(filepath.Join(platform.ConfigDir(), "apps"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Environment variables by app:")
+	found := false
+	for _, ref := range bundles {
+		if ref.bundle.Shell == nil || len(ref.bundle.Shell.Env) == 0 {
+			continue
+		}
+		found = true
+		fmt.Printf("\n  %s:\n", ref.bundle.Name)
+		names := make([]string, 0, len(ref.bundle.Shell.Env))
+		for name := range ref.bundle.Shell.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("    %s\n", describeEnv(name, ref.bundle.Shell.Env[name]))
+		}
+	}
+	if !found {
+		fmt.Println("\n  (none)")
+	}
+	return nil
+}
+
+// describeEnv renders an env var and its overrides on one line.
+func describeEnv(name string, env apps.EnvVar) string {
+	var notes []string
+	if env.Bash != "" {
+		notes = append(notes, fmt.Sprintf("bash: %q", env.Bash))
+	}
+	if env.Zsh != "" {
+		notes = append(notes, fmt.Sprintf("zsh: %q", env.Zsh))
+	}
+	if env.Fish != "" {
+		notes = append(notes, fmt.Sprintf("fish: %q", env.Fish))
+	}
+	for _, v := range env.Values {
+		note := fmt.Sprintf("%q", v.Value)
+		if v.Shell != "" {
+			note += " in " + v.Shell
+		}
+		if v.When != "" {
+			note += " when " + v.When
+		}
+		notes = append(notes, note)
+	}
+	if env.When != "" {
+		notes = append(notes, "when: "+env.When)
+	}
+	if !env.ExpandDefault() {
+		notes = append(notes, "literal")
+	}
+	line := fmt.Sprintf("%s = %q", name, env.Value)
+	if len(notes) > 0 {
+		line += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return line
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func setupEnvTest(t *testing.T) string {
+	t.Helper()
+	homeDir := filepath.Join(t.TempDir(), "home")
+	gdfDir := filepath.Join(homeDir, ".gdf")
+
+	t.Setenv("HOME", homeDir)
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	configureGitUserGlobal(t, homeDir)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { envApp, envWhen, envShell, envLiteral = "", "", "", false })
+	return gdfDir
+}
+
+func TestEnvSet(t *testing.T) {
+	gdfDir := setupEnvTest(t)
+
+	envApp = "go"
+	if err := runEnvSet(nil, []string{"GOPATH", "$HOME/go"}); err != nil {
+		t.Fatalf("runEnvSet() error = %v", err)
+	}
+
+	// The app is found from the existing variable.
+	envApp = ""
+	if err := runEnvSet(nil, []string{"GOPATH", "$HOME/src/go"}); err != nil {
+		t.Fatalf("runEnvSet() without --app error = %v", err)
+	}
+	envShell = "zsh"
+	if err := runEnvSet(nil, []string{"GOPATH", "$HOME/zgo"}); err != nil {
+		t.Fatal(err)
+	}
+	envShell = "fish"
+	if err := runEnvSet(nil, []string{"GOPATH", "$HOME/fgo"}); err != nil {
+		t.Fatal(err)
+	}
+	envShell, envWhen = "", "os == 'macos'"
+	if err := runEnvSet(nil, []string{"GOPATH", "$HOME/Developer/go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runEnvSet(nil, []string{"GOPATH", "$HOME/Library/go"}); err != nil {
+		t.Fatal(err)
+	}
+	envWhen, envLiteral = "", true
+	if err := runEnvSet(nil, []string{"GOFLAGS", "-mod=mod"}); err == nil {
+		t.Error("runEnvSet() of new variable without --app error = nil, want error")
+	}
+	envApp = "go"
+	if err := runEnvSet(nil, []string{"GOFLAGS", "-mod=mod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "go.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath := bundle.Shell.Env["GOPATH"]
+	if gopath.Value != "$HOME/src/go" || gopath.Zsh != "$HOME/zgo" || gopath.Fish != "$HOME/fgo" || !gopath.ExpandDefault() {
+		t.Errorf("GOPATH = %+v", gopath)
+	}
+	wantValues := []apps.ConditionalValue{{Value: "$HOME/Library/go", When: "os == 'macos'"}}
+	if !reflect.DeepEqual([]apps.ConditionalValue(gopath.Values), wantValues) {
+		t.Errorf("GOPATH values = %+v, want %+v", gopath.Values, wantValues)
+	}
+	if goflags := bundle.Shell.Env["GOFLAGS"]; goflags.Value != "-mod=mod" || goflags.ExpandDefault() {
+		t.Errorf("GOFLAGS = %+v, want literal -mod=mod", goflags)
+	}
+
+	// Setting the value again without --literal turns expansion back on.
+	envLiteral = false
+	if err := runEnvSet(nil, []string{"GOFLAGS", "-mod=$GOMODMODE"}); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err = apps.Load(filepath.Join(gdfDir, "apps", "go.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goflags := bundle.Shell.Env["GOFLAGS"]; goflags.Expand != nil {
+		t.Errorf("GOFLAGS expand = %v, want unset after set without --literal", *goflags.Expand)
+	}
+}
+
+func TestEnvSet_InvalidFlags(t *testing.T) {
+	setupEnvTest(t)
+
+	tests := []struct {
+		name  string
+		shell string
+		when  string
+		args  []string
+	}{
+		{name: "invalid name", args: []string{"GO PATH", "x"}},
+		{name: "unknown shell", shell: "tcsh", args: []string{"GOPATH", "x"}},
+		{name: "shell and when", shell: "zsh", when: "os == 'linux'", args: []string{"GOPATH", "x"}},
+		{name: "invalid when", when: "os ==", args: []string{"GOPATH", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envApp, envShell, envWhen = "go", tt.shell, tt.when
+			if err := runEnvSet(nil, tt.args); err == nil {
+				t.Error("runEnvSet() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestEnvUnset(t *testing.T) {
+	gdfDir := setupEnvTest(t)
+
+	for _, app := range []string{"go", "rust"} {
+		envApp = app
+		if err := runEnvSet(nil, []string{"EDITOR", "nvim"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	envApp = ""
+	if err := runEnvUnset(nil, []string{"EDITOR"}); err == nil || !strings.Contains(err.Error(), "use --app") {
+		t.Errorf("runEnvUnset() of ambiguous variable error = %v, want use --app", err)
+	}
+	envApp = "rust"
+	if err := runEnvUnset(nil, []string{"EDITOR"}); err != nil {
+		t.Fatalf("runEnvUnset() error = %v", err)
+	}
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "rust.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bundle.Shell.Env["EDITOR"]; ok {
+		t.Error("EDITOR still set in rust after unset")
+	}
+	if err := runEnvUnset(nil, []string{"EDITOR"}); err == nil {
+		t.Error("runEnvUnset() of missing variable error = nil, want error")
+	}
+}
+
+func TestDescribeEnv(t *testing.T) {
+	expand := false
+	tests := []struct {
+		name string
+		env  apps.EnvVar
+		want string
+	}{
+		{name: "plain", env: apps.EnvVar{Value: "nvim"}, want: `EDITOR = "nvim"`},
+		{
+			name: "overrides",
+			env: apps.EnvVar{
+				Value:  "vi",
+				Zsh:    "nvim",
+				Fish:   "hx",
+				Values: apps.ConditionalValues{{Value: "code", When: "os == 'macos'"}, {Value: "kak", Shell: "fish"}},
+				Expand: &expand,
+			},
+			want: `EDITOR = "vi" (zsh: "nvim", fish: "hx", "code" when os == 'macos', "kak" in fish, literal)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeEnv("EDITOR", tt.env); got != tt.want {
+				t.Errorf("describeEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export app data for other tools",
+	Long:  `Export data from your app bundles in formats other tools understand.`,
+}
+
+var exportBrewfileCmd = &cobra.Command{
+	Use:   "brewfile",
+	Short: "Export brew packages as a Brewfile",
+	Long: `Write the brew formulae and casks of all app bundles as a Brewfile for
+'brew bundle'. Taps come first, with their clone URL when set, then
+formulae, then casks.
+
+Without --output, the Brewfile is written to stdout.`,
+	Args: cobra.NoArgs,
+	Example: `  gdf export brewfile > Brewfile
+  gdf export brewfile -o ~/Brewfile`,
+	RunE: runExportBrewfile,
+}
+
+var exportOutput string
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportBrewfileCmd)
+	exportBrewfileCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the Brewfile to this path")
+}
+
+func runExportBrewfile(cmd *cobra.Command, args []string) error {
+	bundles, err := apps.LoadAll(filepath.Join(platform.ConfigDir(), "apps"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("loading app bundles: %w", err)
+	}
+
+	var pkgs []*apps.BrewPackage
+	for _, bundle := range bundles {
+		if bundle.Package != nil && bundle.Package.Brew != nil {
+			pkgs = append(pkgs, bundle.Package.Brew)
+		}
+	}
+	content := packages.FormatBrewfile(pkgs)
+
+	if exportOutput == "" {
+		fmt.Print(content)
+		return nil
+	}
+	path := platform.ExpandPath(exportOutput)
+	if err := util.WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing Brewfile: %w", err)
+	}
+	fmt.Printf("✓ Exported %d brew package(s) to %s\n", len(pkgs), path)
+	return nil
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+)
+
+func TestBrewfileImportExportRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configureGitUserGlobal(t, home)
+	gdfDir := filepath.Join(home, ".gdf")
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// An existing app keeps its own brew package.
+	existing := &apps.Bundle{Name: "neovim", Package: &apps.Package{Brew: apps.BrewFormula("neovim")}}
+	if err := existing.Save(filepath.Join(gdfDir, "apps", "neovim.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	brewfile := filepath.Join(home, "Brewfile")
+	content := `tap "hashicorp/tap"
+brew "ripgrep"
+brew "neovim", args: ["HEAD"]
+brew "hashicorp/tap/terraform"
+cask "iterm2"
+mas "Xcode", id: 497799835
+`
+	if err := os.WriteFile(brewfile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old := importFlagSnapshot()
+	defer restoreImportFlags(old)
+	importApply, importPreview, importProfile, importBrewfile = true, false, "", brewfile
+
+	if err := runAppImport(nil, nil); err != nil {
+		t.Fatalf("runAppImport(--brewfile) error = %v", err)
+	}
+
+	want := map[string]*apps.BrewPackage{
+		"ripgrep":   {Name: "ripgrep"},
+		"neovim":    {Name: "neovim"},
+		"terraform": {Name: "hashicorp/tap/terraform", Tap: "hashicorp/tap"},
+		"iterm2":    {Name: "iterm2", Cask: true},
+	}
+	for app, wantPkg := range want {
+		bundle, err := apps.Load(filepath.Join(gdfDir, "apps", app+".yaml"))
+		if err != nil {
+			t.Fatalf("loading %s: %v", app, err)
+		}
+		if !reflect.DeepEqual(bundle.Package.Brew, wantPkg) {
+			t.Errorf("%s brew = %+v, want %+v", app, bundle.Package.Brew, wantPkg)
+		}
+	}
+	profile, err := config.LoadProfile(filepath.Join(gdfDir, "profiles", "default", "profile.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, app := range []string{"ripgrep", "terraform", "iterm2"} {
+		if !contains(profile.Apps, app) {
+			t.Errorf("profile apps = %v, want %s", profile.Apps, app)
+		}
+	}
+
+	out := filepath.Join(home, "Exported")
+	exportOutput = out
+	defer func() { exportOutput = "" }()
+	if err := runExportBrewfile(nil, nil); err != nil {
+		t.Fatalf("runExportBrewfile() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBrewfile := `tap "hashicorp/tap"
+brew "hashicorp/tap/terraform"
+brew "neovim"
+brew "ripgrep"
+cask "iterm2"
+`
+	if string(data) != wantBrewfile {
+		t.Errorf("exported Brewfile =\n%s\nwant\n%s", data, wantBrewfile)
+	}
+	if strings.Contains(string(data), "mas") {
+		t.Error("exported Brewfile contains skipped mas entry")
+	}
+}
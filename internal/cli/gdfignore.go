@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+const gdfIgnoreFileName = ".gdfignore"
+
+// defaultGdfIgnoreContent lists files that churn constantly or hold machine-local
+// state, so tracking them would only produce noisy commits.
+const defaultGdfIgnoreContent = `# Files GDF never tracks or imports.
+# Gitignore-style patterns, matched against paths relative to $HOME.
+
+# Caches
+.cache/
+__pycache__/
+node_modules/
+
+# Lock and runtime files
+*.lock
+*.lck
+*.pid
+*.sock
+
+# Shell and tool history
+*_history
+.zhistory
+.lesshst
+.viminfo
+
+# Editor and OS noise
+*.swp
+*~
+.DS_Store
+`
+
+// loadGdfIgnore returns the matcher for the repository .gdfignore file.
+// Built-in defaults apply when the file does not exist.
+func loadGdfIgnore(gdfDir string) (*util.IgnoreMatcher, error) {
+	m, err := util.LoadIgnoreFile(filepath.Join(gdfDir, gdfIgnoreFileName))
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", gdfIgnoreFileName, err)
+	}
+	if m != nil {
+		return m, nil
+	}
+	return util.ParseIgnorePatterns(strings.Split(defaultGdfIgnoreContent, "\n"))
+}
+
+// isGdfIgnored reports whether an absolute path matches the ignore rules.
+// Paths under home are matched relative to home; others by their full path.
+func isGdfIgnored(m *util.IgnoreMatcher, home, path string) bool {
+	if m == nil {
+		return false
+	}
+	rel := path
+	if home != "" {
+		if r, err := filepath.Rel(home, path); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			rel = r
+		}
+	}
+	return m.Match(filepath.ToSlash(rel))
+}
+
+func createGdfIgnore(gdfDir string) error {
+	path := filepath.Join(gdfDir, gdfIgnoreFileName)
+	if err := os.WriteFile(path, []byte(defaultGdfIgnoreContent), 0644); err != nil {
+		return fmt.Errorf("creating %s: %w", gdfIgnoreFileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+func TestIsGdfIgnored(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator), "home", "me")
+	m, err := util.ParseIgnorePatterns([]string{"/..hidden", "/.cache/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		// Names starting with ".." are still under home.
+		{path: filepath.Join(home, "..hidden"), want: true},
+		{path: filepath.Join(home, ".cache", "x"), want: true},
+		{path: filepath.Join(home, ".config"), want: false},
+		// Paths outside home are matched by their full path.
+		{path: filepath.Join(string(filepath.Separator), "etc", "..hidden"), want: false},
+	}
+	for _, tt := range tests {
+		if got := isGdfIgnored(m, home, tt.path); got != tt.want {
+			t.Errorf("isGdfIgnored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
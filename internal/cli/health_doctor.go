@@ -1,15 +1,15 @@
 package cli
 
 import (
-	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/rztaylor/GoDotFiles/internal/config"
 	"github.com/rztaylor/GoDotFiles/internal/git"
 	"github.com/rztaylor/GoDotFiles/internal/packages"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
 	"github.com/rztaylor/GoDotFiles/internal/state"
 )
 
@@ -34,6 +34,7 @@ func runHealthDoctorReport(gdfDir string) (*healthReport, error) {
 	checkRequiredPaths(gdfDir, report)
 	checkConfigAndState(gdfDir, report)
 	checkShellIntegration(gdfDir, report)
+	checkShellStartup(gdfDir, report)
 	checkPackageManager(report)
 	checkWritePermissions(gdfDir, report)
 	report.sort()
@@ -107,46 +108,62 @@ func checkConfigAndState(gdfDir string, report *healthReport) {
 }
 
 func checkShellIntegration(gdfDir string, report *healthReport) {
-	initPath := filepath.Join(gdfDir, "generated", "init.sh")
-	if _, err := os.Stat(initPath); os.IsNotExist(err) {
-		report.add(healthFinding{
-			Code:     "generated_init_missing",
-			Severity: healthSeverityWarning,
-			Title:    "Generated shell init script is missing",
-			Path:     initPath,
-			Hint:     "Run 'gdf apply <profile>' to regenerate shell integration",
-		})
+	for _, shellType := range loadConfiguredShells(gdfDir) {
+		initPath := filepath.Join(gdfDir, "generated", shellType.InitFileName())
+		if _, err := os.Stat(initPath); os.IsNotExist(err) {
+			report.add(healthFinding{
+				Code:     "generated_init_missing",
+				Severity: healthSeverityWarning,
+				Title:    fmt.Sprintf("Generated %s init script is missing", shellType),
+				Path:     initPath,
+				Hint:     "Run 'gdf apply <profile>' to regenerate shell integration",
+			})
+		}
 	}
 
-	shellName := platform.DetectShell()
-	if shellName != "bash" && shellName != "zsh" {
-		return
-	}
+	targets := loadRCTargets(gdfDir)
+	for _, target := range targets {
+		hasSource, err := shell.HasSourceLine(target.Path)
+		if err != nil {
+			report.add(healthFinding{
+				Code:     "rc_unreadable",
+				Severity: healthSeverityWarning,
+				Title:    "Shell RC file is unreadable",
+				Path:     target.Path,
+				Detail:   err.Error(),
+			})
+			continue
+		}
 
-	rcPath := detectRCPath(shellName)
-	if rcPath == "" {
-		return
+		if !hasSource {
+			report.add(healthFinding{
+				Code:     "rc_source_missing",
+				Severity: healthSeverityWarning,
+				Title:    fmt.Sprintf("Shell RC file does not source the GDF %s init script", target.Shell),
+				Path:     target.Path,
+				Hint:     "Run 'gdf health fix' or add: " + shell.SourceLine(target.Shell),
+			})
+		}
 	}
 
-	hasSource, err := rcHasGDFSourceLine(rcPath)
+	issues, err := rcChainIssues(targets)
 	if err != nil {
 		report.add(healthFinding{
 			Code:     "rc_unreadable",
 			Severity: healthSeverityWarning,
-			Title:    "Shell RC file is unreadable",
-			Path:     rcPath,
+			Title:    "Shell startup file is unreadable",
 			Detail:   err.Error(),
 		})
 		return
 	}
-
-	if !hasSource {
+	for _, issue := range issues {
 		report.add(healthFinding{
-			Code:     "rc_source_missing",
+			Code:     "rc_chain_broken",
 			Severity: healthSeverityWarning,
-			Title:    "Shell RC file does not source GDF init script",
-			Path:     rcPath,
-			Hint:     "Run 'gdf health fix' or add: [ -f ~/.gdf/generated/init.sh ] && source ~/.gdf/generated/init.sh",
+			Title:    fmt.Sprintf("%s startup file does not reach the GDF init script", issue.Shell),
+			Path:     issue.Path,
+			Detail:   issue.Detail,
+			Hint:     "Run 'gdf health fix --guarded' to inject the source line there, or add it to shell_integration.rc_files",
 		})
 	}
 }
@@ -180,41 +197,3 @@ func checkWritePermissions(gdfDir string, report *healthReport) {
 	_ = f.Close()
 	_ = os.Remove(name)
 }
-
-func detectRCPath(shellName string) string {
-	home := os.Getenv("HOME")
-	if home == "" {
-		return ""
-	}
-
-	if shellName == "bash" {
-		bashrc := filepath.Join(home, ".bashrc")
-		if _, err := os.Stat(bashrc); err == nil {
-			return bashrc
-		}
-		return filepath.Join(home, ".bash_profile")
-	}
-
-	return filepath.Join(home, ".zshrc")
-}
-
-func rcHasGDFSourceLine(rcPath string) (bool, error) {
-	f, err := os.Open(rcPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.Contains(line, "~/.gdf/generated/init.sh") {
-			return true, nil
-		}
-	}
-
-	return false, scanner.Err()
-}
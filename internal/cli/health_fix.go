@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
 	"github.com/rztaylor/GoDotFiles/internal/shell"
 	"github.com/rztaylor/GoDotFiles/internal/state"
@@ -21,15 +22,29 @@ type fixAction struct {
 	Run         func() error
 }
 
-func buildFixActions(gdfDir string, findings []healthFinding, includeGuarded bool) []fixAction {
+// buildFixActions plans fixes for findings. Fixes that edit files outside
+// the repository snapshot them first and record the change in logger, so
+// 'gdf recover rollback' can undo them.
+func buildFixActions(gdfDir string, findings []healthFinding, includeGuarded bool, logger *engine.Logger) []fixAction {
 	actions := make([]fixAction, 0)
 	seen := map[string]bool{}
+	injector := shell.NewInjector()
+	history := engine.NewHistoryManager(gdfDir, historyMaxSizeMB(gdfDir))
+	// rcSnap holds the snapshot of the RC file the running fix injects into.
+	var rcSnap *engine.Snapshot
+	injector.SetSnapshotFunc(func(path string) error {
+		snap, err := history.Capture(path)
+		rcSnap = snap
+		return err
+	})
 
 	add := func(code, description string, guarded bool, preview string, run func() error) {
-		if seen[code] {
+		// Findings about different files get one action each.
+		key := code + "\x00" + preview
+		if seen[key] {
 			return
 		}
-		seen[code] = true
+		seen[key] = true
 		if guarded && !includeGuarded {
 			return
 		}
@@ -70,26 +85,33 @@ func buildFixActions(gdfDir string, findings []healthFinding, includeGuarded boo
 				return st.Save(filepath.Join(gdfDir, "state.yaml"))
 			})
 		case "generated_init_missing":
-			add("generated_init_missing", "Create placeholder generated/init.sh", false, "write placeholder ~/.gdf/generated/init.sh", func() error {
-				if err := os.MkdirAll(filepath.Join(gdfDir, "generated"), 0755); err != nil {
+			path := f.Path
+			add("generated_init_missing", "Create placeholder "+filepath.Base(path), false, "write placeholder "+path, func() error {
+				generatedDir := filepath.Dir(path)
+				if err := os.MkdirAll(generatedDir, 0755); err != nil {
 					return err
 				}
-				content := "#!/usr/bin/env sh\n# Generated by gdf health fix\n"
-				return os.WriteFile(filepath.Join(gdfDir, "generated", "init.sh"), []byte(content), 0644)
-			})
-		case "rc_source_missing":
-			add("rc_source_missing", "Inject GDF source line into shell RC file (with backup)", true, "backup shell RC file, then inject GDF source line", func() error {
-				shellName := platform.DetectShell()
-				shellType := shell.ParseShellType(shellName)
-				if shellType == shell.Unknown {
-					return fmt.Errorf("could not detect supported shell for RC injection")
+				content := "# Generated by gdf health fix\n"
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					return err
 				}
-				if rcPath := detectRCPath(shellName); rcPath != "" {
-					if _, err := backupFileIfExists(rcPath); err != nil {
-						return err
-					}
+				return shell.WriteDispatcher(generatedDir)
+			})
+		case "rc_source_missing", "rc_chain_broken":
+			shellType, ok := rcFindingShell(gdfDir, f)
+			if !ok {
+				continue
+			}
+			path := f.Path
+			add(f.Code, fmt.Sprintf("Inject GDF %s source line into %s (snapshot kept for rollback)", shellType, path), f.Code == "rc_chain_broken", "snapshot "+path+", then append: "+shell.SourceLine(shellType), func() error {
+				rcSnap = nil
+				if _, err := injector.InjectInto(shellType, path); err != nil {
+					return err
 				}
-				return shell.NewInjector().InjectSourceLine(shellType)
+				details := map[string]string{"shell": shellType.String()}
+				addSnapshotLogDetails(details, rcSnap)
+				logger.Log("rc_inject", path, details)
+				return nil
 			})
 		case "config_invalid":
 			add("config_invalid", "Reset invalid config.yaml to defaults (with backup)", true, "backup invalid ~/.gdf/config.yaml, then write default Config/v1", func() error {
@@ -120,7 +142,8 @@ func runHealthFix(gdfDir string, w io.Writer) error {
 		return err
 	}
 
-	actions := buildFixActions(gdfDir, doctor.Findings, healthFixGuarded)
+	logger := engine.NewLogger(healthFixDryRun)
+	actions := buildFixActions(gdfDir, doctor.Findings, healthFixGuarded, logger)
 	if len(actions) == 0 {
 		if !healthFixGuarded {
 			fmt.Fprintln(w, "No safe auto-fixable issues found. Re-run with --guarded to include higher-impact fixes.")
@@ -164,6 +187,12 @@ func runHealthFix(gdfDir string, w io.Writer) error {
 		fmt.Fprintf(w, "✓ %s\n", action.Description)
 	}
 
+	if logPath, err := logger.Save(gdfDir); err != nil {
+		fmt.Fprintf(w, "! Warning: could not save operation log: %v\n", err)
+	} else if logPath != "" {
+		fmt.Fprintf(w, "Operations logged to: %s\n", logPath)
+	}
+
 	if failed > 0 {
 		return withExitCode(fmt.Errorf("%d fix action(s) failed", failed), exitCodeFixFailure)
 	}
@@ -171,6 +200,38 @@ func runHealthFix(gdfDir string, w io.Writer) error {
 	return nil
 }
 
+// rcFindingShell returns the shell whose RC target or startup file the
+// finding is about.
+func rcFindingShell(gdfDir string, f healthFinding) (shell.ShellType, bool) {
+	targets := loadRCTargets(gdfDir)
+	if f.Code == "rc_source_missing" {
+		for _, t := range targets {
+			if t.Path == f.Path {
+				return t.Shell, true
+			}
+		}
+		return shell.Unknown, false
+	}
+	issues, err := rcChainIssues(targets)
+	if err != nil {
+		return shell.Unknown, false
+	}
+	for _, issue := range issues {
+		if issue.Path == f.Path {
+			return issue.Shell, true
+		}
+	}
+	return shell.Unknown, false
+}
+
+// historyMaxSizeMB returns the configured snapshot history quota.
+func historyMaxSizeMB(gdfDir string) int {
+	if cfg := loadConfigOrNil(gdfDir); cfg != nil {
+		return cfg.History.MaxSizeMBDefault()
+	}
+	return 0
+}
+
 func backupFileIfExists(path string) (string, error) {
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+)
+
+const (
+	shellStartupTimeout       = 15 * time.Second
+	shellStartupSlowThreshold = 500 * time.Millisecond
+	shellSegmentSlowThreshold = 100 * time.Millisecond
+)
+
+// checkShellStartup times an interactive startup of the user's shell and the
+// generated init script, reporting slow or failing snippets per app.
+func checkShellStartup(gdfDir string, report *healthReport) {
+	shellType := shell.ParseShellType(platform.DetectShell())
+	if shellType != shell.Bash && shellType != shell.Zsh {
+		return
+	}
+	initPath := filepath.Join(gdfDir, "generated", shellType.InitFileName())
+	if _, err := os.Stat(initPath); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shellStartupTimeout)
+	defer cancel()
+	profile, err := shell.ProfileStartup(ctx, shellType, initPath)
+	if err != nil {
+		if errors.Is(err, shell.ErrShellUnavailable) {
+			return
+		}
+		report.add(healthFinding{
+			Code:     "shell_startup_failed",
+			Severity: healthSeverityWarning,
+			Title:    fmt.Sprintf("Could not time %s startup", shellType),
+			Path:     initPath,
+			Detail:   err.Error(),
+		})
+		return
+	}
+
+	report.add(healthFinding{
+		Code:     "shell_startup_time",
+		Severity: healthSeverityInfo,
+		Title:    fmt.Sprintf("Interactive %s startup took %s (%s alone: %s)", shellType, roundDuration(profile.Total), shellType.InitFileName(), roundDuration(profile.Init)),
+		Path:     initPath,
+	})
+	if profile.Total > shellStartupSlowThreshold {
+		report.add(healthFinding{
+			Code:     "shell_startup_slow",
+			Severity: healthSeverityWarning,
+			Title:    fmt.Sprintf("Interactive %s startup is slow (%s)", shellType, roundDuration(profile.Total)),
+			Path:     initPath,
+			Hint:     "Check the shell_snippet_slow findings, or time your rc files outside GDF",
+		})
+	}
+
+	for _, seg := range profile.Segments {
+		if seg.Failed() {
+			detail := fmt.Sprintf("exit status %d", seg.Status)
+			if seg.Output != "" {
+				detail += ": " + firstOutputLine(seg.Output)
+			}
+			report.add(healthFinding{
+				Code:     "shell_snippet_failed",
+				Severity: healthSeverityWarning,
+				Title:    fmt.Sprintf("Shell init %s reported errors", segmentLabel(seg)),
+				Path:     initPath,
+				Detail:   detail,
+			})
+		}
+		if seg.Duration > shellSegmentSlowThreshold {
+			report.add(healthFinding{
+				Code:     "shell_snippet_slow",
+				Severity: healthSeverityWarning,
+				Title:    fmt.Sprintf("Shell init %s took %s", segmentLabel(seg), roundDuration(seg.Duration)),
+				Path:     initPath,
+				Hint:     "Add 'lazy: [<command>...]' to the snippet, or guard it, so it does not run on every shell start; see 'gdf shell profile'",
+			})
+		}
+	}
+}
+
+func segmentLabel(seg shell.StartupSegment) string {
+	if seg.App != "" {
+		return fmt.Sprintf("snippet %s (app %s)", seg.Name, seg.App)
+	}
+	return fmt.Sprintf("section %q", seg.Name)
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
+
+func firstOutputLine(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			return s[:i]
+		}
+	}
+	return s
+}
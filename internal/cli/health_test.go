@@ -3,9 +3,13 @@ package cli
 import (
 	"bytes"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
 )
 
 func TestHealthValidateReport_UninitializedRepo(t *testing.T) {
@@ -205,3 +209,111 @@ func TestHealthCIExitCodeOnErrors(t *testing.T) {
 		t.Fatalf("ExitCode(err) = %d, want %d", got, exitCodeHealthIssues)
 	}
 }
+
+func TestHealthDoctor_ReportsFailingShellSnippet(t *testing.T) {
+	bashPath, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	gdfDir := filepath.Join(home, ".gdf")
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", bashPath)
+	configureGitUserGlobal(t, home)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+
+	initPath := filepath.Join(gdfDir, "generated", "init.bash")
+	if err := os.MkdirAll(filepath.Dir(initPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(initPath, []byte("# Init\n# tool:setup\necho broken >&2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := runHealthDoctorReport(gdfDir)
+	if err != nil {
+		t.Fatalf("runHealthDoctorReport() error = %v", err)
+	}
+	if !hasHealthFinding(report, "shell_startup_time") {
+		t.Fatalf("expected shell_startup_time finding, got %#v", report.Findings)
+	}
+	profileOut, _ := exec.Command(bashPath, "-c", `echo "${EPOCHREALTIME:-}"`).Output()
+	if strings.TrimSpace(string(profileOut)) == "" {
+		t.Skip("bash has no EPOCHREALTIME; per-snippet findings unavailable")
+	}
+	if !hasHealthFinding(report, "shell_snippet_failed") {
+		t.Fatalf("expected shell_snippet_failed finding, got %#v", report.Findings)
+	}
+}
+
+func TestHealthFixRepairsBrokenRCChain(t *testing.T) {
+	home := t.TempDir()
+	gdfDir := filepath.Join(home, ".gdf")
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	configureGitUserGlobal(t, home)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfigFromDir(gdfDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ShellIntegration.Shells = []string{"bash"}
+	if err := cfg.Save(filepath.Join(gdfDir, "config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	// ~/.bashrc has the source line, but login shells read ~/.bash_profile,
+	// which never sources ~/.bashrc.
+	bashrc := filepath.Join(home, ".bashrc")
+	bashProfile := filepath.Join(home, ".bash_profile")
+	if err := os.WriteFile(bashrc, []byte(shell.SourceLine(shell.Bash)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bashProfile, []byte("export EDITOR=vi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := runHealthDoctorReport(gdfDir)
+	if err != nil {
+		t.Fatalf("runHealthDoctorReport() error = %v", err)
+	}
+	if !hasHealthFinding(report, "rc_chain_broken") {
+		t.Fatalf("expected rc_chain_broken finding, got %#v", report.Findings)
+	}
+	if hasHealthFinding(report, "rc_source_missing") {
+		t.Fatalf("unexpected rc_source_missing finding, got %#v", report.Findings)
+	}
+
+	oldYes, oldGuarded, oldDryRun := globalYes, healthFixGuarded, healthFixDryRun
+	defer func() {
+		globalYes, healthFixGuarded, healthFixDryRun = oldYes, oldGuarded, oldDryRun
+	}()
+	globalYes, healthFixGuarded, healthFixDryRun = true, true, false
+
+	var out bytes.Buffer
+	if err := runHealthFix(gdfDir, &out); err != nil {
+		t.Fatalf("runHealthFix() error = %v", err)
+	}
+	content, err := os.ReadFile(bashProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "export EDITOR=vi\n") || !strings.Contains(string(content), "init.bash") {
+		t.Fatalf("expected source line appended to .bash_profile, got:\n%s", content)
+	}
+	if _, err := os.Stat(bashProfile + ".gdf.backup"); err == nil {
+		t.Fatal("unexpected .gdf.backup; rc changes are kept as history snapshots")
+	}
+
+	report, err = runHealthDoctorReport(gdfDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasHealthFinding(report, "rc_chain_broken") {
+		t.Fatalf("rc_chain_broken still reported after fix: %#v", report.Findings)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/rztaylor/GoDotFiles/internal/git"
 	"github.com/rztaylor/GoDotFiles/internal/library"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 )
 
 func runHealthValidateReport(gdfDir string) (*healthReport, error) {
@@ -49,7 +51,8 @@ func validateConfig(gdfDir string, report *healthReport) {
 		})
 		return
 	}
-	if _, err := config.LoadConfig(cfgPath); err != nil {
+	cfg, err := config.LoadConfig(cfgPath)
+	if err != nil {
 		report.add(healthFinding{
 			Code:     "config_invalid",
 			Severity: healthSeverityError,
@@ -57,6 +60,21 @@ func validateConfig(gdfDir string, report *healthReport) {
 			Path:     cfgPath,
 			Detail:   err.Error(),
 		})
+		return
+	}
+	if cfg.PackageManager == nil {
+		return
+	}
+	for _, name := range supportedManagersInProbeOrder() {
+		if _, ok := cfg.PackageManager.Managers[name]; ok {
+			report.add(healthFinding{
+				Code:     "package_manager_shadowed",
+				Severity: healthSeverityWarning,
+				Title:    fmt.Sprintf("User-defined package manager %s is shadowed by the built-in one", name),
+				Path:     cfgPath,
+				Hint:     fmt.Sprintf("Rename package_manager.managers.%s", name),
+			})
+		}
 	}
 }
 
@@ -179,6 +197,48 @@ func validateAppBundles(gdfDir string, profiles []*config.Profile, _ map[string]
 			})
 		}
 
+		if err := shell.ValidateNames([]*apps.Bundle{bundle}, nil, aliasShellType()); err != nil {
+			report.add(healthFinding{
+				Code:     "app_shell_name_invalid",
+				Severity: healthSeverityError,
+				Title:    fmt.Sprintf("Invalid shell names in app %s", bundle.Name),
+				Path:     appPath,
+				Detail:   err.Error(),
+				Hint:     "Rename the alias, function or environment variable; 'gdf apply' refuses to generate it",
+			})
+		}
+
+		if bundle.Shell != nil {
+			for _, issue := range shellConditionIssues(bundle.Shell, plat) {
+				report.add(healthFinding{
+					Code:     "app_shell_condition_invalid",
+					Severity: healthSeverityError,
+					Title:    fmt.Sprintf("Invalid shell condition in app %s", bundle.Name),
+					Path:     appPath,
+					Detail:   issue,
+				})
+			}
+			if _, err := shell.ResolveEnv([]*apps.Bundle{bundle}, aliasShellType(), plat); err != nil {
+				report.add(healthFinding{
+					Code:     "app_shell_env_invalid",
+					Severity: healthSeverityError,
+					Title:    fmt.Sprintf("Invalid environment variables in app %s", bundle.Name),
+					Path:     appPath,
+					Detail:   err.Error(),
+				})
+			}
+			if _, ok := bundle.Shell.Env["PATH"]; ok {
+				report.add(healthFinding{
+					Code:     "app_env_path",
+					Severity: healthSeverityWarning,
+					Title:    fmt.Sprintf("App %s sets PATH in shell.env", bundle.Name),
+					Path:     appPath,
+					Detail:   "shell.env keeps one value per variable, so PATH set here overrides PATH entries from other apps",
+					Hint:     "Move the directories to shell.path",
+				})
+			}
+		}
+
 		for i, dotfile := range bundle.Dotfiles {
 			if dotfile.When == "" {
 				continue
@@ -193,6 +253,29 @@ func validateAppBundles(gdfDir string, profiles []*config.Profile, _ map[string]
 				})
 			}
 		}
+
+		for _, dotfile := range bundle.Dotfiles {
+			for _, issue := range checkDotfileSyntax(gdfDir, bundle.Name, dotfile) {
+				if issue.Unchecked {
+					report.add(healthFinding{
+						Code:     "dotfile_syntax_unchecked",
+						Severity: healthSeverityInfo,
+						Title:    fmt.Sprintf("Syntax of %s not checked (app %s)", issue.Source, bundle.Name),
+						Path:     issue.Path,
+						Detail:   issue.Err.Error(),
+					})
+					continue
+				}
+				report.add(healthFinding{
+					Code:     "dotfile_syntax_invalid",
+					Severity: healthSeverityError,
+					Title:    fmt.Sprintf("Syntax error in %s (app %s)", issue.Source, bundle.Name),
+					Path:     issue.Path,
+					Detail:   issue.Err.Error(),
+					Hint:     "Fix the file, or set 'format: none' on the dotfile to skip the check",
+				})
+			}
+		}
 	}
 
 	lib := library.New()
@@ -214,3 +297,31 @@ func validateAppBundles(gdfDir string, profiles []*config.Profile, _ map[string]
 		}
 	}
 }
+
+// shellConditionIssues reports invalid `when` conditions on PATH entries,
+// aliases and functions. Env conditions are checked by shell.ResolveEnv.
+func shellConditionIssues(sh *apps.Shell, plat *platform.Platform) []string {
+	var issues []string
+	check := func(field, when string) {
+		if when == "" {
+			return
+		}
+		if _, err := config.EvaluateCondition(when, plat); err != nil {
+			issues = append(issues, fmt.Sprintf("%s: %v", field, err))
+		}
+	}
+	for i, entry := range sh.Path {
+		check(fmt.Sprintf("shell.path[%d]", i), entry.When)
+	}
+	for _, name := range util.SortedKeys(sh.Aliases) {
+		for i, v := range sh.Aliases[name] {
+			check(fmt.Sprintf("shell.aliases.%s[%d]", name, i), v.When)
+		}
+	}
+	for _, name := range util.SortedKeys(sh.Functions) {
+		for i, v := range sh.Functions[name] {
+			check(fmt.Sprintf("shell.functions.%s[%d]", name, i), v.When)
+		}
+	}
+	return issues
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
 	"github.com/rztaylor/GoDotFiles/internal/git"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
 	"github.com/rztaylor/GoDotFiles/internal/shell"
@@ -190,6 +191,11 @@ func createNewRepo(gdfDir string) error {
 		return err
 	}
 
+	// Create .gdfignore
+	if err := createGdfIgnore(gdfDir); err != nil {
+		return err
+	}
+
 	// Create initial config.yaml
 	if err := createInitialConfig(gdfDir); err != nil {
 		return err
@@ -276,18 +282,16 @@ func ensureGeneratedInitScript(gdfDir string) error {
 		return fmt.Errorf("creating generated directory: %w", err)
 	}
 
-	scriptPath := filepath.Join(generatedDir, "init.sh")
+	scriptPath := filepath.Join(generatedDir, shell.DispatcherFileName)
 	if _, err := os.Stat(scriptPath); err == nil {
 		return nil
 	} else if !os.IsNotExist(err) {
 		return fmt.Errorf("checking generated init script: %w", err)
 	}
 
-	content := `#!/usr/bin/env sh
-# Placeholder created by gdf init.
-# Run 'gdf apply' to generate shell integration.
-`
-	if err := os.WriteFile(scriptPath, []byte(content), 0644); err != nil {
+	// The dispatcher skips init.<shell> files that do not exist yet, so it is
+	// safe to source before the first 'gdf apply'.
+	if err := shell.WriteDispatcher(generatedDir); err != nil {
 		return fmt.Errorf("creating generated init script: %w", err)
 	}
 
@@ -351,7 +355,7 @@ func offerShellInjection() error {
 	if shellType == shell.Unknown {
 		fmt.Println("\n! Could not detect your shell")
 		fmt.Println("  To enable shell integration, add to your shell config:")
-		fmt.Println("  [ -f ~/.gdf/generated/init.sh ] && source ~/.gdf/generated/init.sh")
+		fmt.Printf("  [ -f ~/.gdf/generated/%[1]s ] && source ~/.gdf/generated/%[1]s\n", shell.DispatcherFileName)
 		return nil
 	}
 
@@ -360,7 +364,7 @@ func offerShellInjection() error {
 	if globalNonInteractive {
 		fmt.Println("Skipping interactive shell setup in non-interactive mode.")
 		fmt.Println("To add it manually, add this to your shell config:")
-		fmt.Println("  [ -f ~/.gdf/generated/init.sh ] && source ~/.gdf/generated/init.sh")
+		fmt.Printf("  %s\n", shell.SourceLine(shellType))
 		return nil
 	}
 
@@ -373,18 +377,37 @@ func offerShellInjection() error {
 	if !confirmInjection {
 		fmt.Println("\nSkipped shell integration.")
 		fmt.Println("To add it manually, add this to your shell config:")
-		fmt.Println("  [ -f ~/.gdf/generated/init.sh ] && source ~/.gdf/generated/init.sh")
+		fmt.Printf("  %s\n", shell.SourceLine(shellType))
 		return nil
 	}
 
-	// Inject
+	// Inject into each RC target of the detected shell. Existing files are
+	// snapshotted to history first.
+	gdfDir := platform.ConfigDir()
 	injector := shell.NewInjector()
-	if err := injector.InjectSourceLine(shellType); err != nil {
+	history := engine.NewHistoryManager(gdfDir, historyMaxSizeMB(gdfDir))
+	injector.SetSnapshotFunc(func(path string) error {
+		_, err := history.Capture(path)
 		return err
+	})
+	var injected []string
+	for _, target := range loadRCTargets(gdfDir) {
+		if target.Shell != shellType {
+			continue
+		}
+		if _, err := injector.InjectInto(shellType, target.Path); err != nil {
+			return err
+		}
+		injected = append(injected, target.Path)
 	}
 
 	fmt.Println("✓ Added shell integration")
-	fmt.Printf("  Source line added to %s\n", getRCFileName(shellType))
+	if len(injected) == 0 {
+		fmt.Printf("  Source line added to %s\n", getRCFileName(shellType))
+	}
+	for _, path := range injected {
+		fmt.Printf("  Source line added to %s\n", path)
+	}
 
 	if shellType == shell.Bash || shellType == shell.Zsh {
 		fmt.Println()
@@ -422,7 +445,7 @@ func offerShellInjection() error {
 	}
 
 	fmt.Println("\nTo activate in current session:")
-	fmt.Println("  source ~/.gdf/generated/init.sh")
+	fmt.Printf("  source ~/.gdf/generated/%s\n", shellType.InitFileName())
 	fmt.Println("Or restart your shell.")
 
 	return nil
@@ -503,6 +526,8 @@ func getRCFileName(shellType shell.ShellType) string {
 		return "~/.bashrc"
 	case shell.Zsh:
 		return "~/.zshrc"
+	case shell.Fish:
+		return "~/.config/fish/config.fish"
 	default:
 		return "RC file"
 	}
@@ -54,14 +54,14 @@ func TestCreateNewRepo(t *testing.T) {
 		t.Error("default profile was not created")
 	}
 
-	// Check generated init placeholder exists
+	// Check the generated init dispatcher exists
 	generatedInitPath := filepath.Join(gdfDir, "generated", "init.sh")
 	content, err := os.ReadFile(generatedInitPath)
 	if err != nil {
-		t.Fatalf("reading generated init placeholder: %v", err)
+		t.Fatalf("reading generated init dispatcher: %v", err)
 	}
-	if !containsString(string(content), "Placeholder created by gdf init.") {
-		t.Errorf("generated init placeholder content missing marker:\n%s", string(content))
+	if !containsString(string(content), "init.bash") || !containsString(string(content), "init.zsh") {
+		t.Errorf("generated init dispatcher does not source per-shell scripts:\n%s", string(content))
 	}
 
 	// Check config includes full default sections.
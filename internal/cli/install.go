@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
 	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
 	"github.com/rztaylor/GoDotFiles/internal/packages"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
 	"github.com/spf13/cobra"
@@ -167,10 +169,32 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 	// 5. Install Phase
 	if useCustom {
-		fmt.Printf("Installing %s via custom script...\n", appName)
 		custom := packages.NewCustom()
-		if err := custom.Execute(bundle.Package.Custom); err != nil {
-			return fmt.Errorf("executing custom install script: %w", err)
+		if installed, err := custom.IsInstalled(bundle.Package.Custom); err != nil {
+			return fmt.Errorf("checking custom install: %w", err)
+		} else if installed {
+			fmt.Printf("✓ %s is already installed (check passed)\n", appName)
+			return nil
+		}
+
+		cfg, err := config.LoadConfig(filepath.Join(gdfDir, "config.yaml"))
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		logger := engine.NewLogger(false)
+		if cfg.Security == nil || cfg.Security.LogScriptsDefault() {
+			custom.SetLogFunc(scriptRunLogger(logger))
+		}
+
+		fmt.Printf("Installing %s via custom script...\n", appName)
+		execErr := custom.Execute(bundle.Package.Custom)
+		if logPath, err := logger.Save(gdfDir); err != nil {
+			fmt.Printf("! Warning: failed to save script audit log: %v\n", err)
+		} else if logPath != "" {
+			fmt.Printf("Logged script execution: %s\n", logPath)
+		}
+		if execErr != nil {
+			return fmt.Errorf("executing custom install script: %w", execErr)
 		}
 		fmt.Println("✓ Installed successfully")
 	} else if defined && pkgName != "" {
@@ -186,6 +210,20 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// scriptRunLogger records custom script runs, with their output, as
+// custom_script operations in logger.
+func scriptRunLogger(logger *engine.Logger) func(packages.ScriptRun) error {
+	return func(run packages.ScriptRun) error {
+		logger.Log("custom_script", run.Script, map[string]string{
+			"sudo":      strconv.FormatBool(run.Sudo),
+			"exit_code": strconv.Itoa(run.ExitCode),
+			"stdout":    run.Stdout,
+			"stderr":    run.Stderr,
+		})
+		return nil
+	}
+}
+
 func ensureAppInProfile(gdfDir, appName, selectedProfile string) error {
 	// Check if already in any profile
 	profilesDir := filepath.Join(gdfDir, "profiles")
@@ -241,7 +279,10 @@ func addAppToProfile(gdfDir, profileName, appName string) error {
 func updateBundlePackage(bundle *apps.Bundle, mgrName string, pkgName string) {
 	switch mgrName {
 	case "brew":
-		bundle.Package.Brew = pkgName
+		if bundle.Package.Brew == nil {
+			bundle.Package.Brew = &apps.BrewPackage{}
+		}
+		bundle.Package.Brew.Name = pkgName
 	case "apt":
 		if bundle.Package.Apt == nil {
 			bundle.Package.Apt = &apps.AptPackage{}
@@ -251,5 +292,7 @@ func updateBundlePackage(bundle *apps.Bundle, mgrName string, pkgName string) {
 		bundle.Package.Dnf = pkgName
 	case "pacman":
 		bundle.Package.Pacman = pkgName
+	case "nix":
+		bundle.Package.Nix = pkgName
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -187,17 +188,33 @@ func TestInstall_UsesCustomScriptWhenNoPackageMapping(t *testing.T) {
 	if _, err := os.Stat(marker); err != nil {
 		t.Fatalf("expected custom install marker file: %v", err)
 	}
+
+	logs, err := filepath.Glob(filepath.Join(gdfDir, ".operations", "*.json"))
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("operation logs = %v, %v, want one", logs, err)
+	}
+	data, err := os.ReadFile(logs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"type": "custom_script"`) || !strings.Contains(string(data), `"exit_code": "0"`) {
+		t.Errorf("operation log = %s, want a custom_script entry", data)
+	}
 }
 
 // MockPackageManager for testing
 type MockPackageManager struct {
 	mgrName          string
 	installed        []string
+	versions         map[string]string
+	available        map[string]string
+	upgraded         []string
 	uninstalled      []string
 	isInstalledErr   error
 	installErr       error
 	uninstallErr     error
 	installCalls     int
+	batchCalls       [][]string
 	uninstallCalls   int
 	isInstalledCalls int
 }
@@ -215,6 +232,66 @@ func (m *MockPackageManager) Install(pkg string) error {
 	return nil
 }
 
+func (m *MockPackageManager) InstallBatch(pkgs []string) map[string]error {
+	m.batchCalls = append(m.batchCalls, pkgs)
+	errs := make(map[string]error)
+	for _, pkg := range pkgs {
+		if m.installErr != nil {
+			errs[pkg] = m.installErr
+			continue
+		}
+		m.installed = append(m.installed, pkg)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (m *MockPackageManager) InstalledVersion(pkg string) (string, error) {
+	installed, err := m.IsInstalled(pkg)
+	if err != nil || !installed {
+		return "", err
+	}
+	if v, ok := m.versions[pkg]; ok {
+		return v, nil
+	}
+	return "1.0.0", nil
+}
+
+func (m *MockPackageManager) Outdated(pkgs []string) ([]packages.OutdatedPackage, error) {
+	var outdated []packages.OutdatedPackage
+	for _, pkg := range pkgs {
+		current, err := m.InstalledVersion(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if available, ok := m.available[pkg]; ok && current != "" && available != current {
+			outdated = append(outdated, packages.OutdatedPackage{Name: pkg, Current: current, Available: available})
+		}
+	}
+	return outdated, nil
+}
+
+func (m *MockPackageManager) Upgrade(pkgs []string) map[string]error {
+	errs := make(map[string]error)
+	for _, pkg := range pkgs {
+		if m.installErr != nil {
+			errs[pkg] = m.installErr
+			continue
+		}
+		m.upgraded = append(m.upgraded, pkg)
+		if m.versions == nil {
+			m.versions = make(map[string]string)
+		}
+		m.versions[pkg] = m.available[pkg]
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
 func (m *MockPackageManager) Uninstall(pkg string) error {
 	m.uninstallCalls++
 	if m.uninstallErr != nil {
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Record and check installed package versions",
+	Long: `Manage gdf.lock, which records the installed package version of each app
+per platform. Platforms are keyed by OS, distribution and release on Linux,
+and architecture: for example "macos/arm64" or "linux/ubuntu/noble/amd64".
+
+Use 'gdf apply --locked' to install the locked versions on another machine.`,
+}
+
+var lockRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Record installed package versions in gdf.lock",
+	Long: `Record the installed version of every app package on this platform in
+gdf.lock. Entries for other platforms are kept; apps whose package is not
+installed are left out.`,
+	Args: cobra.NoArgs,
+	RunE: runLockRefresh,
+}
+
+var lockVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check installed package versions against gdf.lock",
+	Long: `Compare the installed package versions on this platform with gdf.lock.
+
+Exits with code 2 when a package is missing, has a different version, or an
+app package is not in the lock file, so it can gate CI checks.`,
+	Args: cobra.NoArgs,
+	RunE: runLockVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+	lockCmd.AddCommand(lockRefreshCmd)
+	lockCmd.AddCommand(lockVerifyCmd)
+}
+
+func runLockRefresh(cmd *cobra.Command, args []string) error {
+	gdfDir := platform.ConfigDir()
+	plat := platform.Detect()
+	bundles, cfg, err := loadLockInputs(gdfDir)
+	if err != nil {
+		return err
+	}
+
+	lockPath := filepath.Join(gdfDir, packages.LockFileName)
+	lock, err := packages.LoadLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	key := packages.LockPlatformKey(plat)
+	entries := make(map[string]packages.LockEntry)
+	for _, bundle := range bundles {
+		entry, ok, err := installedLockEntry(bundle, plat, cfg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Printf("- %s: not installed, not locked\n", bundle.Name)
+			continue
+		}
+		entries[bundle.Name] = entry
+		fmt.Printf("✓ %s: %s %s (via %s)\n", bundle.Name, entry.Package, entry.Version, entry.Manager)
+	}
+
+	if len(entries) == 0 {
+		delete(lock.Platforms, key)
+	} else {
+		lock.Platforms[key] = entries
+	}
+	if err := lock.Save(lockPath); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Locked %d package(s) for %s in %s\n", len(entries), key, packages.LockFileName)
+	return nil
+}
+
+func runLockVerify(cmd *cobra.Command, args []string) error {
+	gdfDir := platform.ConfigDir()
+	plat := platform.Detect()
+	bundles, cfg, err := loadLockInputs(gdfDir)
+	if err != nil {
+		return err
+	}
+
+	lock, err := packages.LoadLock(filepath.Join(gdfDir, packages.LockFileName))
+	if err != nil {
+		return err
+	}
+	key := packages.LockPlatformKey(plat)
+	locked := lock.Entries(key)
+	if len(locked) == 0 {
+		return fmt.Errorf("%s has no packages for %s; run 'gdf lock refresh' first", packages.LockFileName, key)
+	}
+
+	byName := make(map[string]*apps.Bundle, len(bundles))
+	for _, bundle := range bundles {
+		byName[bundle.Name] = bundle
+	}
+
+	names := make([]string, 0, len(locked))
+	for name := range locked {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	issues := 0
+	for _, name := range names {
+		entry := locked[name]
+		var pkg *apps.Package
+		if bundle, ok := byName[name]; ok {
+			pkg = bundle.Package
+		}
+		mgr, available := packageManagerByName(entry.Manager, cfg)
+		if !available || mgr == nil {
+			fmt.Printf("! %s: %s is not available\n", name, entry.Manager)
+			issues++
+			continue
+		}
+		version, err := packages.InstalledPackageVersion(mgr, pkg, entry.Package)
+		switch {
+		case err != nil:
+			fmt.Printf("! %s: could not read version of %s: %v\n", name, entry.Package, err)
+			issues++
+		case version == "":
+			fmt.Printf("! %s: %s is not installed (locked %s)\n", name, entry.Package, entry.Version)
+			issues++
+		case version != entry.Version:
+			fmt.Printf("! %s: %s %s installed, locked %s\n", name, entry.Package, version, entry.Version)
+			issues++
+		default:
+			fmt.Printf("✓ %s: %s %s\n", name, entry.Package, version)
+		}
+	}
+
+	for _, bundle := range bundles {
+		if _, ok := locked[bundle.Name]; ok {
+			continue
+		}
+		if _, ok, err := installedLockEntry(bundle, plat, cfg); err != nil {
+			return err
+		} else if ok {
+			fmt.Printf("! %s: installed but not in %s\n", bundle.Name, packages.LockFileName)
+			issues++
+		}
+	}
+
+	if issues > 0 {
+		return withExitCode(fmt.Errorf("%d package(s) differ from %s; run 'gdf apply --locked' or 'gdf lock refresh'", issues, packages.LockFileName), exitCodeHealthIssues)
+	}
+	fmt.Printf("✓ %d package(s) match %s\n", len(names), packages.LockFileName)
+	return nil
+}
+
+// loadLockInputs loads the app bundles with a package, sorted by name, and
+// the config used to select their package managers.
+func loadLockInputs(gdfDir string) ([]*apps.Bundle, *config.Config, error) {
+	all, err := apps.LoadAll(filepath.Join(gdfDir, "apps"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("loading app bundles: %w", err)
+	}
+	var bundles []*apps.Bundle
+	for _, bundle := range all {
+		if bundle.Package != nil {
+			bundles = append(bundles, bundle)
+		}
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Name < bundles[j].Name })
+
+	cfg, err := config.LoadConfig(filepath.Join(gdfDir, "config.yaml"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading config: %w", err)
+	}
+	return bundles, cfg, nil
+}
+
+// installedLockEntry returns the lock entry for the installed package of
+// bundle, checking its managers in probe order. Custom install scripts have
+// no version and are never locked.
+func installedLockEntry(bundle *apps.Bundle, plat *platform.Platform, cfg *config.Config) (packages.LockEntry, bool, error) {
+	plan := resolvePackageManagerPlan(bundle.Package, plat, cfg)
+	if plan == nil || plan.Selected.Name == "custom" || plan.Selected.Name == "none" {
+		return packages.LockEntry{}, false, nil
+	}
+	for _, probe := range plan.Probes {
+		version, err := packages.InstalledPackageVersion(probe.Manager, probe.Package, probe.PackageName)
+		if err != nil {
+			return packages.LockEntry{}, false, fmt.Errorf("reading installed version of %s via %s: %w", probe.PackageName, probe.Name, err)
+		}
+		if version != "" {
+			return packages.LockEntry{Manager: probe.Name, Package: probe.PackageName, Version: version}, true, nil
+		}
+	}
+	return packages.LockEntry{}, false, nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+)
+
+// pinningPackageManager is a mock manager that can install exact versions.
+type pinningPackageManager struct {
+	*MockPackageManager
+}
+
+func (m *pinningPackageManager) PinVersion(pkg, version string) string {
+	return pkg + "=" + version
+}
+
+func TestLockRefreshVerify(t *testing.T) {
+	_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+	mgr := &MockPackageManager{mgrName: "apt", installed: []string{"git"}, versions: map[string]string{"git": "2.43.0"}}
+	packages.Override = mgr
+	defer func() { packages.Override = nil }()
+
+	if err := runLockRefresh(nil, nil); err != nil {
+		t.Fatalf("runLockRefresh() error = %v", err)
+	}
+	lock, err := packages.LoadLock(filepath.Join(gdfDir, packages.LockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := packages.LockEntry{Manager: "apt", Package: "git", Version: "2.43.0"}
+	if got := lock.Entries("linux/ubuntu")["pkg-app"]; got != want {
+		t.Fatalf("locked entry = %+v, want %+v", got, want)
+	}
+
+	if err := runLockVerify(nil, nil); err != nil {
+		t.Fatalf("runLockVerify() error = %v", err)
+	}
+
+	mgr.versions["git"] = "2.44.0"
+	err = runLockVerify(nil, nil)
+	if err == nil || ExitCode(err) != exitCodeHealthIssues {
+		t.Fatalf("runLockVerify() with drift error = %v (exit %d), want exit %d", err, ExitCode(err), exitCodeHealthIssues)
+	}
+
+	mgr.installed = nil
+	if err := runLockVerify(nil, nil); err == nil {
+		t.Fatal("runLockVerify() with missing package error = nil, want error")
+	}
+}
+
+func TestLockRefresh_InvalidConfig(t *testing.T) {
+	_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+	packages.Override = &MockPackageManager{mgrName: "apt", installed: []string{"git"}, versions: map[string]string{"git": "2.43.0"}}
+	defer func() { packages.Override = nil }()
+	cfg := "kind: Config/v1\npackage_manager:\n  managers:\n    vscode:\n      list: code --list-extensions\n"
+	if err := os.WriteFile(filepath.Join(gdfDir, "config.yaml"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runLockRefresh(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "loading config") {
+		t.Fatalf("runLockRefresh() error = %v, want config error", err)
+	}
+	if _, err := os.Stat(filepath.Join(gdfDir, packages.LockFileName)); !os.IsNotExist(err) {
+		t.Errorf("gdf.lock written despite invalid config, stat error = %v", err)
+	}
+}
+
+func TestApplyLocked(t *testing.T) {
+	setLocked := func(t *testing.T) {
+		old := applyLocked
+		applyLocked = true
+		t.Cleanup(func() { applyLocked = old })
+	}
+	writeLock := func(t *testing.T, gdfDir, version string) {
+		lock := &packages.Lock{Platforms: map[string]map[string]packages.LockEntry{
+			"linux/ubuntu": {"pkg-app": {Manager: "apt", Package: "git", Version: version}},
+		}}
+		if err := lock.Save(filepath.Join(gdfDir, packages.LockFileName)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("installs the locked version over a different one", func(t *testing.T) {
+		_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+		setLocked(t)
+		writeLock(t, gdfDir, "2.43.0")
+		mgr := &pinningPackageManager{&MockPackageManager{mgrName: "apt", installed: []string{"git"}, versions: map[string]string{"git": "2.44.0"}}}
+		packages.Override = mgr
+		defer func() { packages.Override = nil }()
+
+		if err := runApply(nil, []string{"default"}); err != nil {
+			t.Fatalf("runApply() error = %v", err)
+		}
+		if got := mgr.installed[len(mgr.installed)-1]; got != "git=2.43.0" {
+			t.Fatalf("installed = %v, want git=2.43.0", mgr.installed)
+		}
+	})
+
+	t.Run("skips a package at the locked version", func(t *testing.T) {
+		_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+		setLocked(t)
+		writeLock(t, gdfDir, "2.43.0")
+		mgr := &pinningPackageManager{&MockPackageManager{mgrName: "apt", installed: []string{"git"}, versions: map[string]string{"git": "2.43.0"}}}
+		packages.Override = mgr
+		defer func() { packages.Override = nil }()
+
+		if err := runApply(nil, []string{"default"}); err != nil {
+			t.Fatalf("runApply() error = %v", err)
+		}
+		if mgr.installCalls != 0 {
+			t.Fatalf("installCalls = %d, want 0", mgr.installCalls)
+		}
+	})
+
+	t.Run("reports drift for managers that cannot pin", func(t *testing.T) {
+		_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+		setLocked(t)
+		writeLock(t, gdfDir, "2.43.0")
+		mgr := &MockPackageManager{mgrName: "apt", installed: []string{"git"}, versions: map[string]string{"git": "2.44.0"}}
+		packages.Override = mgr
+		defer func() { packages.Override = nil }()
+
+		if err := runApply(nil, []string{"default"}); err != nil {
+			t.Fatalf("runApply() error = %v", err)
+		}
+		if mgr.installCalls != 0 {
+			t.Fatalf("installCalls = %d, want 0", mgr.installCalls)
+		}
+	})
+
+	t.Run("requires a lock for the platform", func(t *testing.T) {
+		_, _ = setupApplyPackageInstallTest(t, "pkg-app", "git")
+		setLocked(t)
+		packages.Override = &MockPackageManager{mgrName: "apt"}
+		defer func() { packages.Override = nil }()
+
+		err := runApply(nil, []string{"default"})
+		if err == nil || !strings.Contains(err.Error(), "gdf lock refresh") {
+			t.Fatalf("runApply() error = %v, want missing lock error", err)
+		}
+	})
+}
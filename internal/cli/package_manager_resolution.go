@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
@@ -13,6 +14,7 @@ type packageManagerCandidate struct {
 	Name        string
 	Manager     packages.Manager
 	PackageName string
+	Package     *apps.Package
 	Custom      *apps.CustomInstall
 }
 
@@ -46,7 +48,7 @@ func resolvePackageManagerPlan(pkg *apps.Package, plat *platform.Platform, cfg *
 		if !defined || pkgName == "" {
 			return false
 		}
-		manager, available := packageManagerFactory(name)
+		manager, available := packageManagerByName(name, cfg)
 		if !available || manager == nil {
 			return false
 		}
@@ -54,6 +56,7 @@ func resolvePackageManagerPlan(pkg *apps.Package, plat *platform.Platform, cfg *
 			Name:        name,
 			Manager:     manager,
 			PackageName: pkgName,
+			Package:     pkg,
 		}
 		return true
 	}
@@ -66,10 +69,14 @@ func resolvePackageManagerPlan(pkg *apps.Package, plat *platform.Platform, cfg *
 		}
 	}
 
-	// Fallback to first available configured manager for this app.
-	for _, name := range supportedManagersInProbeOrder() {
+	probeOrder := managersInProbeOrder(cfg)
+
+	// Fallback to first available configured manager for this app. Nix
+	// replaces packages from the system manager, so it is only used when
+	// preferred.
+	for _, name := range probeOrder {
 		addCandidate(name)
-		if selectedName == "" {
+		if selectedName == "" && name != "nix" {
 			if _, ok := candidates[name]; ok {
 				selectedName = name
 			}
@@ -89,7 +96,7 @@ func resolvePackageManagerPlan(pkg *apps.Package, plat *platform.Platform, cfg *
 	}
 
 	probes := []packageManagerCandidate{candidates[selectedName]}
-	for _, name := range supportedManagersInProbeOrder() {
+	for _, name := range probeOrder {
 		if name == selectedName {
 			continue
 		}
@@ -121,11 +128,55 @@ func defaultPackageManagerFactory(name string) (packages.Manager, bool) {
 	case "dnf":
 		mgr := packages.NewDnf()
 		return mgr, mgr.IsAvailable()
+	case "flatpak":
+		mgr := packages.NewFlatpak()
+		return mgr, mgr.IsAvailable()
+	case "snap":
+		mgr := packages.NewSnap()
+		return mgr, mgr.IsAvailable()
+	case "nix":
+		mgr := packages.NewNix()
+		return mgr, mgr.IsAvailable()
+	case "cargo":
+		mgr := packages.NewCargo()
+		return mgr, mgr.IsAvailable()
+	case "go":
+		mgr := packages.NewGoInstall()
+		return mgr, mgr.IsAvailable()
+	case "pipx":
+		mgr := packages.NewPipx()
+		return mgr, mgr.IsAvailable()
+	case "npm":
+		mgr := packages.NewNpm()
+		return mgr, mgr.IsAvailable()
+	case "uv":
+		mgr := packages.NewUv()
+		return mgr, mgr.IsAvailable()
+	case "release":
+		mgr := packages.NewRelease()
+		return mgr, mgr.IsAvailable()
 	default:
 		return nil, false
 	}
 }
 
+// packageManagerByName returns a built-in package manager, or the
+// user-defined one declared in config.yaml when no built-in has the name.
+func packageManagerByName(name string, cfg *config.Config) (packages.Manager, bool) {
+	if manager, available := packageManagerFactory(name); manager != nil {
+		return manager, available
+	}
+	if cfg == nil || cfg.PackageManager == nil {
+		return nil, false
+	}
+	cmds, ok := cfg.PackageManager.Managers[name]
+	if !ok || cmds == nil {
+		return nil, false
+	}
+	mgr := packages.NewCommandManager(name, cmds)
+	return mgr, mgr.IsAvailable()
+}
+
 func appPreferredManagerName(prefer *apps.Prefer, plat *platform.Platform) string {
 	if prefer == nil || plat == nil {
 		return ""
@@ -165,6 +216,31 @@ func normalizeManagerName(name string) string {
 	return strings.ToLower(strings.TrimSpace(name))
 }
 
+// supportedManagersInProbeOrder lists system package managers, then the
+// cross-distribution flatpak, snap and nix, then the user-level language
+// managers, which are fallbacks for apps without a system package. nix is
+// probed for installed packages but never selected as a fallback.
 func supportedManagersInProbeOrder() []string {
-	return []string{"brew", "apt", "dnf"}
+	return []string{"brew", "apt", "dnf", "flatpak", "snap", "nix", "cargo", "go", "pipx", "uv", "npm", "release"}
+}
+
+// managersInProbeOrder lists the built-in package managers followed by the
+// user-defined ones from config.yaml, sorted by name.
+func managersInProbeOrder(cfg *config.Config) []string {
+	names := supportedManagersInProbeOrder()
+	if cfg == nil || cfg.PackageManager == nil {
+		return names
+	}
+	builtin := make(map[string]bool, len(names))
+	for _, name := range names {
+		builtin[name] = true
+	}
+	var custom []string
+	for name := range cfg.PackageManager.Managers {
+		if !builtin[name] {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
 }
@@ -24,7 +24,7 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 		{
 			name: "app prefer overrides global prefer",
 			pkg: &apps.Package{
-				Brew: "git",
+				Brew: apps.BrewFormula("git"),
 				Apt:  &apps.AptPackage{Name: "git"},
 				Prefer: &apps.Prefer{
 					Linux: "brew",
@@ -43,7 +43,7 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 		{
 			name: "global prefer used when app prefer unset",
 			pkg: &apps.Package{
-				Brew: "git",
+				Brew: apps.BrewFormula("git"),
 				Apt:  &apps.AptPackage{Name: "git"},
 			},
 			cfg: &config.Config{
@@ -59,7 +59,7 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 		{
 			name: "fallback to auto when preferred manager unavailable",
 			pkg: &apps.Package{
-				Brew: "git",
+				Brew: apps.BrewFormula("git"),
 				Apt:  &apps.AptPackage{Name: "git"},
 			},
 			cfg: &config.Config{
@@ -75,7 +75,7 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 		{
 			name: "fallback to first available configured manager when auto has no mapping",
 			pkg: &apps.Package{
-				Brew: "git",
+				Brew: apps.BrewFormula("git"),
 			},
 			cfg:          &config.Config{},
 			auto:         "apt",
@@ -83,6 +83,60 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 			wantSelected: "brew",
 			wantProbes:   []string{"brew"},
 		},
+		{
+			name: "falls back to language manager when system package is unavailable",
+			pkg: &apps.Package{
+				Brew:   apps.BrewFormula("ripgrep"),
+				Cargo:  "ripgrep",
+				Custom: &apps.CustomInstall{Script: "echo install"},
+			},
+			cfg:          &config.Config{},
+			auto:         "apt",
+			available:    map[string]bool{"apt": true, "cargo": true},
+			wantSelected: "cargo",
+			wantProbes:   []string{"cargo"},
+		},
+		{
+			name: "app prefer selects language manager",
+			pkg: &apps.Package{
+				Apt:    &apps.AptPackage{Name: "python3-httpie"},
+				Pipx:   "httpie",
+				Prefer: &apps.Prefer{Linux: "pipx"},
+			},
+			cfg:          &config.Config{},
+			auto:         "apt",
+			available:    map[string]bool{"apt": true, "pipx": true},
+			wantSelected: "pipx",
+			wantProbes:   []string{"pipx", "apt"},
+		},
+		{
+			name: "nix is not selected as a fallback",
+			pkg: &apps.Package{
+				Nix:   "nixpkgs#ripgrep",
+				Cargo: "ripgrep",
+			},
+			cfg:          &config.Config{},
+			auto:         "apt",
+			available:    map[string]bool{"apt": true, "nix": true, "cargo": true},
+			wantSelected: "cargo",
+			wantProbes:   []string{"cargo", "nix"},
+		},
+		{
+			name: "global prefer selects nix",
+			pkg: &apps.Package{
+				Apt: &apps.AptPackage{Name: "ripgrep"},
+				Nix: "nixpkgs#ripgrep",
+			},
+			cfg: &config.Config{
+				PackageManager: &config.PackageManagerConfig{
+					Prefer: &config.PackageManagerPrefer{Linux: "nix"},
+				},
+			},
+			auto:         "apt",
+			available:    map[string]bool{"apt": true, "nix": true},
+			wantSelected: "nix",
+			wantProbes:   []string{"nix", "apt"},
+		},
 		{
 			name: "falls back to custom install when no package manager mapping exists",
 			pkg: &apps.Package{
@@ -99,9 +153,12 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			managers := map[string]*MockPackageManager{
-				"brew": {mgrName: "brew"},
-				"apt":  {mgrName: "apt"},
-				"dnf":  {mgrName: "dnf"},
+				"brew":  {mgrName: "brew"},
+				"apt":   {mgrName: "apt"},
+				"dnf":   {mgrName: "dnf"},
+				"cargo": {mgrName: "cargo"},
+				"pipx":  {mgrName: "pipx"},
+				"nix":   {mgrName: "nix"},
 			}
 
 			oldFactory := packageManagerFactory
@@ -141,3 +198,39 @@ func TestResolvePackageManagerPlan(t *testing.T) {
 		})
 	}
 }
+
+func TestResolvePackageManagerPlan_UserDefined(t *testing.T) {
+	plat := &platform.Platform{OS: "linux", Distro: "ubuntu"}
+	cfg := &config.Config{PackageManager: &config.PackageManagerConfig{
+		Managers: map[string]*config.ManagerCommands{
+			"vscode":   {Install: "sh -c 'code --install-extension \"$1\"' sh {{.Package}}", List: "code --list-extensions"},
+			"missing":  {Install: "gdf-no-such-command {{.Package}}", List: "true"},
+			"unneeded": {Install: "true", List: "true"},
+		},
+	}}
+
+	oldAuto := packageAutoManagerForPlatform
+	packageAutoManagerForPlatform = func(_ *platform.Platform) packages.Manager {
+		return &MockPackageManager{mgrName: "apt"}
+	}
+	defer func() { packageAutoManagerForPlatform = oldAuto }()
+
+	pkg := &apps.Package{Managers: map[string]string{"missing": "x", "vscode": "ms-python.python"}}
+	plan := resolvePackageManagerPlan(pkg, plat, cfg)
+	if plan == nil {
+		t.Fatal("resolvePackageManagerPlan() returned nil")
+	}
+	if plan.Selected.Name != "vscode" || plan.Selected.PackageName != "ms-python.python" {
+		t.Fatalf("selected = %s %s, want vscode ms-python.python", plan.Selected.Name, plan.Selected.PackageName)
+	}
+	if _, ok := plan.Selected.Manager.(*packages.CommandManager); !ok {
+		t.Fatalf("selected manager = %T, want *packages.CommandManager", plan.Selected.Manager)
+	}
+	if len(plan.Probes) != 1 {
+		t.Fatalf("probes = %d, want 1 (unavailable managers are skipped)", len(plan.Probes))
+	}
+
+	if got := managersInProbeOrder(cfg); got[len(got)-3] != "missing" || got[len(got)-1] != "vscode" {
+		t.Errorf("managersInProbeOrder() = %v, want user-defined managers last, sorted", got)
+	}
+}
@@ -25,8 +25,8 @@ func TestCollectAppStats_LocalBundle(t *testing.T) {
 			{Source: "git/secret", Target: "~/.gitsecret", Secret: true},
 		},
 		Shell: &apps.Shell{
-			Aliases: map[string]string{
-				"g": "git",
+			Aliases: map[string]apps.ConditionalValues{
+				"g": apps.Value("git"),
 			},
 		},
 	}
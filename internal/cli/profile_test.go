@@ -335,8 +335,8 @@ func TestProfileShow_PrintsAppCounters(t *testing.T) {
 			{Source: "git/secret", Target: "~/.gitsecret", Secret: true},
 		},
 		Shell: &apps.Shell{
-			Aliases: map[string]string{
-				"g": "git",
+			Aliases: map[string]apps.ConditionalValues{
+				"g": apps.Value("git"),
 			},
 		},
 	}
@@ -99,13 +99,17 @@ UpdateRC:
 	fmt.Println("Updating shell configuration...")
 	injector := shell.NewInjector()
 
-	// Detect shell type
-	detectedShell := platform.DetectShell()
-	shellType := shell.ParseShellType(detectedShell)
+	// Rewrite every RC file the source line was injected into.
+	var rcPaths []string
+	for _, target := range loadRCTargets(gdfDir) {
+		if !contains(rcPaths, target.Path) {
+			rcPaths = append(rcPaths, target.Path)
+		}
+	}
 
-	if shellType != shell.Unknown {
+	if len(rcPaths) > 0 {
 		// Use the flag value (e.g. ~/.aliases) for portability in RC file
-		if err := injector.RestoreSourceLine(aliasesFile, shellType); err != nil {
+		if err := injector.RestoreSourceLine(aliasesFile, rcPaths); err != nil {
 			fmt.Printf("  x Failed to update RC file: %v\n", err)
 		} else {
 			fmt.Println("  ✓ Updated shell configuration")
@@ -53,7 +53,17 @@ func runRollback(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Using operation log: %s\n", logPath)
 	linkOps := 0
 	withSnapshots := 0
+	loginEnvOps := 0
+	rcOps := 0
 	for _, op := range ops {
+		switch op.Type {
+		case "login_env":
+			loginEnvOps++
+			continue
+		case "rc_inject":
+			rcOps++
+			continue
+		}
 		if op.Type != "link" {
 			continue
 		}
@@ -63,6 +73,12 @@ func runRollback(cmd *cobra.Command, args []string) error {
 		}
 	}
 	fmt.Printf("Rollback plan: %d link operations (%d with historical snapshots)\n", linkOps, withSnapshots)
+	if loginEnvOps > 0 {
+		fmt.Printf("               %d login environment file changes\n", loginEnvOps)
+	}
+	if rcOps > 0 {
+		fmt.Printf("               %d RC file source line injections\n", rcOps)
+	}
 
 	if !rollbackYes {
 		ok, err := rollbackConfirmPrompt("Proceed with rollback? [y/N]: ")
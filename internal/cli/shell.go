@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
 
+	"github.com/rztaylor/GoDotFiles/internal/config"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
 	"github.com/rztaylor/GoDotFiles/internal/shell"
 	"github.com/spf13/cobra"
@@ -40,18 +42,15 @@ func init() {
 }
 
 func runShellReload(cmd *cobra.Command, args []string) error {
-	detectedShell := platform.DetectShell()
-	shellType := shell.ParseShellType(detectedShell)
-
-	initPath := "~/.gdf/generated/init.sh"
+	shellType := shell.ParseShellType(platform.DetectShell())
 
 	fmt.Println("To reload shell integration, run:")
 	fmt.Println()
 
-	if shellType == shell.Bash || shellType == shell.Zsh {
-		fmt.Printf("  source %s\n", initPath)
+	if shellType != shell.Unknown {
+		fmt.Printf("  source ~/.gdf/generated/%s\n", shellType.InitFileName())
 	} else {
-		fmt.Printf("  source %s  # (for bash/zsh)\n", initPath)
+		fmt.Printf("  source ~/.gdf/generated/%s  # (for bash/zsh)\n", shell.DispatcherFileName)
 	}
 
 	fmt.Println()
@@ -70,3 +69,92 @@ func runShellCompletion(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unsupported shell %q: expected bash or zsh", args[0])
 	}
 }
+
+// configuredShells returns the shells listed in shell_integration.shells,
+// defaulting to the detected shell. Unsupported names are returned separately.
+func configuredShells(cfg *config.Config) ([]shell.ShellType, []string) {
+	var sic *config.ShellIntegrationConfig
+	if cfg != nil {
+		sic = cfg.ShellIntegration
+	}
+	var shells []shell.ShellType
+	var unsupported []string
+	seen := make(map[shell.ShellType]bool)
+	for _, name := range sic.ShellsDefault(platform.DetectShell()) {
+		st := shell.ParseShellType(name)
+		if st == shell.Unknown {
+			unsupported = append(unsupported, name)
+			continue
+		}
+		if !seen[st] {
+			seen[st] = true
+			shells = append(shells, st)
+		}
+	}
+	return shells, unsupported
+}
+
+// loadConfiguredShells is configuredShells for the config in gdfDir. An
+// unreadable config falls back to the detected shell.
+func loadConfiguredShells(gdfDir string) []shell.ShellType {
+	shells, _ := configuredShells(loadConfigOrNil(gdfDir))
+	return shells
+}
+
+// rcTarget is an RC file the GDF source line is injected into.
+type rcTarget struct {
+	Shell shell.ShellType
+	Path  string
+}
+
+// loadRCTargets returns the RC files to inject into for every configured
+// shell: shell_integration.rc_files when set, else each shell's default.
+func loadRCTargets(gdfDir string) []rcTarget {
+	cfg := loadConfigOrNil(gdfDir)
+	var sic *config.ShellIntegrationConfig
+	if cfg != nil {
+		sic = cfg.ShellIntegration
+	}
+	shells, _ := configuredShells(cfg)
+	injector := shell.NewInjector()
+	var targets []rcTarget
+	for _, shellType := range shells {
+		for _, path := range injector.RCPaths(shellType, sic.RCFilesDefault(shellType.String())) {
+			targets = append(targets, rcTarget{Shell: shellType, Path: path})
+		}
+	}
+	return targets
+}
+
+// rcChainIssues checks, per configured shell, that the startup files the
+// shell reads reach one of its RC targets.
+func rcChainIssues(targets []rcTarget) ([]shell.RCChainIssue, error) {
+	byShell := make(map[shell.ShellType][]string)
+	var order []shell.ShellType
+	for _, t := range targets {
+		if _, ok := byShell[t.Shell]; !ok {
+			order = append(order, t.Shell)
+		}
+		byShell[t.Shell] = append(byShell[t.Shell], t.Path)
+	}
+	injector := shell.NewInjector()
+	var issues []shell.RCChainIssue
+	for _, shellType := range order {
+		found, err := injector.CheckRCChain(shellType, byShell[shellType])
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
+
+// loadConfigOrNil loads config.yaml from gdfDir, returning nil when it is
+// missing or invalid so callers fall back to defaults.
+func loadConfigOrNil(gdfDir string) *config.Config {
+	cfg, err := config.LoadConfig(filepath.Join(gdfDir, "config.yaml"))
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
@@ -0,0 +1,481 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var shellFnCmd = &cobra.Command{
+	Use:     "fn",
+	Aliases: []string{"function"},
+	Short:   "Manage shell functions",
+	Long:    `Add, list, edit, or remove shell functions within your app bundles.`,
+}
+
+var shellFnAddCmd = &cobra.Command{
+	Use:   "add <name> [body]",
+	Short: "Add a shell function",
+	Long: `Add a shell function to an app bundle.
+
+Without a body argument, $VISUAL or $EDITOR opens so a multi-line body can be
+written. The body is syntax-checked with the target shell before it is saved.
+
+If --app is not specified, GDF checks whether the first word of the body
+matches an existing app bundle. Functions must belong to an app.
+
+Bodies are POSIX shell and are used for bash and zsh. With --shell, the body
+is used only for that shell; use --shell fish to give fish its own body.
+With --when, the body is only used where the condition matches.`,
+	Args: cobra.RangeArgs(1, 2),
+	Example: `  gdf shell fn add mkcd 'mkdir -p "$1" && cd "$1"' -a coreutils
+  gdf shell fn add kctx -a kubectl
+  gdf shell fn add mkcd 'mkdir -p $argv[1]; and cd $argv[1]' -a coreutils --shell fish`,
+	RunE: runShellFnAdd,
+}
+
+var shellFnListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all shell functions",
+	Long:  `List shell functions from all app bundles.`,
+	Args:  cobra.NoArgs,
+	RunE:  runShellFnList,
+}
+
+var shellFnEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit a shell function in $EDITOR",
+	Long: `Open the body of a shell function in $VISUAL or $EDITOR.
+
+--shell and --when select which body to edit. The edited body is
+syntax-checked before it is saved; a body that does not parse is not saved
+and the edited text is kept in a temporary file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShellFnEdit,
+}
+
+var shellFnRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a shell function",
+	Long:  `Remove a shell function, with all its bodies, from its app bundle.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShellFnRemove,
+}
+
+var (
+	shellFnApp   string
+	shellFnWhen  string
+	shellFnShell string
+)
+
+func init() {
+	shellCmd.AddCommand(shellFnCmd)
+	shellFnCmd.AddCommand(shellFnAddCmd)
+	shellFnCmd.AddCommand(shellFnListCmd)
+	shellFnCmd.AddCommand(shellFnEditCmd)
+	shellFnCmd.AddCommand(shellFnRemoveCmd)
+
+	for _, c := range []*cobra.Command{shellFnAddCmd, shellFnEditCmd, shellFnRemoveCmd} {
+		c.Flags().StringVarP(&shellFnApp, "app", "a", "", "App bundle the function belongs to")
+	}
+	for _, c := range []*cobra.Command{shellFnAddCmd, shellFnEditCmd} {
+		c.Flags().StringVar(&shellFnWhen, "when", "", "Only use this body where the condition matches (e.g. \"os == 'macos'\")")
+		c.Flags().StringVar(&shellFnShell, "shell", "", "Only use this body for one shell: bash, zsh or fish")
+	}
+}
+
+func runShellFnAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	variant, err := shellFnVariantFromFlags()
+	if err != nil {
+		return err
+	}
+	if err := shell.ValidateFunctionName(aliasShellType(), name); err != nil {
+		return err
+	}
+
+	gdfDir := platform.ConfigDir()
+	appsDir := filepath.Join(gdfDir, "apps")
+
+	if len(args) == 2 {
+		variant.Value = args[1]
+	} else {
+		body, err := editText(name, "", shellFnExtension(variant.Shell))
+		if err != nil {
+			return err
+		}
+		variant.Value = body
+	}
+	if strings.TrimSpace(variant.Value) == "" {
+		return fmt.Errorf("function body is empty; nothing saved")
+	}
+	if err := checkFunctionBody(name, variant); err != nil {
+		return err
+	}
+
+	appName := shellFnApp
+	if appName == "" {
+		appName = apps.DetectAppFromCommandIfExists(variant.Value, appsDir)
+		if appName == "" {
+			return fmt.Errorf("functions must belong to an app; use --app")
+		}
+		fmt.Printf("Detected app: %s\n", appName)
+	}
+
+	appPath := filepath.Join(appsDir, appName+".yaml")
+	bundle, err := loadOrNewBundle(appPath, appName)
+	if err != nil {
+		return err
+	}
+	if bundle.Shell == nil {
+		bundle.Shell = &apps.Shell{}
+	}
+	if bundle.Shell.Functions == nil {
+		bundle.Shell.Functions = make(map[string]apps.ConditionalValues)
+	}
+	values, replaced := setFunctionVariant(bundle.Shell.Functions[name], variant)
+	if replaced {
+		fmt.Printf("Overwriting existing function '%s'%s\n", name, describeVariant(variant))
+	}
+	bundle.Shell.Functions[name] = values
+
+	if err := os.MkdirAll(filepath.Dir(appPath), 0755); err != nil {
+		return fmt.Errorf("creating apps directory: %w", err)
+	}
+	if err := bundle.Save(appPath); err != nil {
+		return fmt.Errorf("saving app bundle: %w", err)
+	}
+
+	fmt.Printf("✓ Added function '%s'%s to app '%s'\n", name, describeVariant(variant), appName)
+	return nil
+}
+
+func runShellFnList(cmd *cobra.Command, args []string) error {
+	appsDir := filepath.Join(platform.ConfigDir(), "apps")
+	bundles, err := loadAppBundles(appsDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Functions by app:")
+	found := false
+	for _, ref := range bundles {
+		if ref.bundle.Shell == nil || len(ref.bundle.Shell.Functions) == 0 {
+			continue
+		}
+		found = true
+		fmt.Printf("\n  %s:\n", ref.bundle.Name)
+		functions := ref.bundle.Shell.Functions
+		names := make([]string, 0, len(functions))
+		for name := range functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, v := range functions[name] {
+				lines := strings.Split(strings.TrimRight(v.Value, "\n"), "\n")
+				summary := lines[0]
+				if len(lines) > 1 {
+					summary += fmt.Sprintf(" … (%d lines)", len(lines))
+				}
+				fmt.Printf("    %s%s: %s\n", name, describeVariant(v), summary)
+			}
+		}
+	}
+	if !found {
+		fmt.Println("\n  (none)")
+	}
+	return nil
+}
+
+func runShellFnEdit(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	selector, err := shellFnVariantFromFlags()
+	if err != nil {
+		return err
+	}
+
+	ref, err := findAppWith(filepath.Join(platform.ConfigDir(), "apps"), shellFnApp, "function", name, func(s *apps.Shell) bool {
+		_, ok := s.Functions[name]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+
+	values := ref.bundle.Shell.Functions[name]
+	index := -1
+	for i, v := range values {
+		if v.When == selector.When && v.Shell == selector.Shell {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("function '%s' in app '%s' has no body%s; add one with 'gdf shell fn add'", name, ref.bundle.Name, describeVariant(selector))
+	}
+
+	edited, err := editText(name, values[index].Value, shellFnExtension(selector.Shell))
+	if err != nil {
+		return err
+	}
+	if edited == values[index].Value {
+		fmt.Println("No changes.")
+		return nil
+	}
+	if strings.TrimSpace(edited) == "" {
+		return fmt.Errorf("function body is empty; nothing saved (use 'gdf shell fn remove' to delete it)")
+	}
+	selector.Value = edited
+	if err := checkFunctionBody(name, selector); err != nil {
+		kept, keepErr := keepEditedText(name, edited, shellFnExtension(selector.Shell))
+		if keepErr == nil {
+			return fmt.Errorf("%w\nnot saved; your edit is in %s", err, kept)
+		}
+		return fmt.Errorf("%w\nnot saved", err)
+	}
+
+	values[index].Value = edited
+	if err := ref.bundle.Save(ref.path); err != nil {
+		return fmt.Errorf("saving app '%s': %w", ref.bundle.Name, err)
+	}
+	fmt.Printf("✓ Updated function '%s'%s in app '%s'\n", name, describeVariant(selector), ref.bundle.Name)
+	return nil
+}
+
+func runShellFnRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	ref, err := findAppWith(filepath.Join(platform.ConfigDir(), "apps"), shellFnApp, "function", name, func(s *apps.Shell) bool {
+		_, ok := s.Functions[name]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	delete(ref.bundle.Shell.Functions, name)
+	if err := ref.bundle.Save(ref.path); err != nil {
+		return fmt.Errorf("saving app '%s': %w", ref.bundle.Name, err)
+	}
+	fmt.Printf("✓ Removed function '%s' from app '%s'\n", name, ref.bundle.Name)
+	return nil
+}
+
+// shellFnVariantFromFlags validates --when and --shell.
+func shellFnVariantFromFlags() (apps.ConditionalValue, error) {
+	v := apps.ConditionalValue{When: shellFnWhen, Shell: shellFnShell}
+	if v.Shell != "" && shell.ParseShellType(v.Shell).String() != v.Shell {
+		return v, fmt.Errorf("invalid --shell %q: expected bash, zsh or fish", v.Shell)
+	}
+	if v.When != "" {
+		if _, err := config.EvaluateCondition(v.When, platform.Detect()); err != nil {
+			return v, fmt.Errorf("invalid --when condition: %w", err)
+		}
+	}
+	return v, nil
+}
+
+// checkFunctionBody syntax-checks a body in the shells that will use it:
+// its own shell, or bash and zsh for POSIX bodies. Shells that are not
+// installed are skipped.
+func checkFunctionBody(name string, v apps.ConditionalValue) error {
+	shells := []shell.ShellType{shell.Bash, shell.Zsh}
+	if v.Shell != "" {
+		shells = []shell.ShellType{shell.ParseShellType(v.Shell)}
+	}
+	for _, st := range shells {
+		if err := shell.ValidateFunctionBody(st, name, v.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFunctionVariant stores v in place of the body with the same condition
+// and shell. New conditional or per-shell bodies go ahead of the plain
+// fallback body, since the first matching body wins.
+func setFunctionVariant(existing apps.ConditionalValues, v apps.ConditionalValue) (apps.ConditionalValues, bool) {
+	out := make(apps.ConditionalValues, 0, len(existing)+1)
+	replaced := false
+	for _, e := range existing {
+		if e.When == v.When && e.Shell == v.Shell {
+			out = append(out, v)
+			replaced = true
+			continue
+		}
+		out = append(out, e)
+	}
+	if replaced {
+		return out, true
+	}
+	if v.When != "" || v.Shell != "" {
+		for i, e := range out {
+			if e.When == "" && e.Shell == "" {
+				return append(out[:i], append(apps.ConditionalValues{v}, out[i:]...)...), false
+			}
+		}
+	}
+	return append(out, v), false
+}
+
+// describeVariant renders the shell and condition of a value for messages.
+func describeVariant(v apps.ConditionalValue) string {
+	var parts []string
+	if v.Shell != "" {
+		parts = append(parts, "shell: "+v.Shell)
+	}
+	if v.When != "" {
+		parts = append(parts, "when: "+v.When)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+func shellFnExtension(shellName string) string {
+	if shellName == "" {
+		return ".sh"
+	}
+	return "." + shellName
+}
+
+// errNotInApps is returned by findAppWith when no app defines the item.
+var errNotInApps = errors.New("not found")
+
+// appRef is an app bundle loaded from the apps directory.
+type appRef struct {
+	path   string
+	bundle *apps.Bundle
+}
+
+// loadAppBundles loads every app bundle in appsDir, sorted by file name.
+// Bundles that fail to load are skipped.
+func loadAppBundles(appsDir string) ([]appRef, error) {
+	entries, err := os.ReadDir(appsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading apps directory: %w", err)
+	}
+	var refs []appRef
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		path := filepath.Join(appsDir, entry.Name())
+		bundle, err := apps.Load(path)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, appRef{path: path, bundle: bundle})
+	}
+	return refs, nil
+}
+
+// findAppWith returns the app whose shell section has the named item. With
+// appName set only that app is searched; otherwise the item must be defined
+// by exactly one app.
+func findAppWith(appsDir, appName, kind, name string, has func(*apps.Shell) bool) (appRef, error) {
+	refs, err := loadAppBundles(appsDir)
+	if err != nil {
+		return appRef{}, err
+	}
+	var matches []appRef
+	for _, ref := range refs {
+		if appName != "" && ref.bundle.Name != appName {
+			continue
+		}
+		if ref.bundle.Shell != nil && has(ref.bundle.Shell) {
+			matches = append(matches, ref)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		if appName != "" {
+			return appRef{}, fmt.Errorf("%s '%s' %w in app '%s'", kind, name, errNotInApps, appName)
+		}
+		return appRef{}, fmt.Errorf("%s '%s' %w in any app bundle", kind, name, errNotInApps)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, ref := range matches {
+			names = append(names, ref.bundle.Name)
+		}
+		return appRef{}, fmt.Errorf("%s '%s' is defined in apps %s; use --app", kind, name, strings.Join(names, ", "))
+	}
+}
+
+// loadOrNewBundle loads the app at appPath, or returns a new bundle named
+// appName when it does not exist yet.
+func loadOrNewBundle(appPath, appName string) (*apps.Bundle, error) {
+	if _, err := os.Stat(appPath); os.IsNotExist(err) {
+		return &apps.Bundle{
+			Name:        appName,
+			Description: fmt.Sprintf("App bundle for %s", appName),
+		}, nil
+	}
+	bundle, err := apps.Load(appPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading app bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+// editText opens initial in $VISUAL or $EDITOR (default vi) and returns the
+// saved text without trailing newlines.
+func editText(name, initial, ext string) (string, error) {
+	f, err := os.CreateTemp("", "gdf-"+name+"-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("creating edit file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if initial != "" {
+		initial += "\n"
+	}
+	if _, err := f.WriteString(initial); err != nil {
+		f.Close()
+		return "", fmt.Errorf("writing edit file: %w", err)
+	}
+	f.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Run through sh so editors configured with arguments ("code --wait") work.
+	c := exec.Command("sh", "-c", editor+` "$1"`, "gdf-edit", f.Name())
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("running editor %q: %w", editor, err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("reading edit file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// keepEditedText saves rejected editor text so it is not lost.
+func keepEditedText(name, text, ext string) (string, error) {
+	f, err := os.CreateTemp("", "gdf-"+name+"-rejected-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// setupShellFnTest creates a GDF repo in a temporary HOME and returns its
+// directory.
+func setupShellFnTest(t *testing.T) string {
+	t.Helper()
+	homeDir := filepath.Join(t.TempDir(), "home")
+	gdfDir := filepath.Join(homeDir, ".gdf")
+
+	t.Setenv("HOME", homeDir)
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	configureGitUserGlobal(t, homeDir)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { shellFnApp, shellFnWhen, shellFnShell = "", "", "" })
+	return gdfDir
+}
+
+// fakeEditor points $EDITOR at a script that replaces the edited file with
+// content.
+func fakeEditor(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "content")
+	if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	editor := filepath.Join(dir, "editor")
+	script := "#!/bin/sh\ncat '" + src + "' > \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+}
+
+func TestShellFnAdd(t *testing.T) {
+	gdfDir := setupShellFnTest(t)
+
+	shellFnApp = "coreutils"
+	if err := runShellFnAdd(nil, []string{"mkcd", `mkdir -p "$1" && cd "$1"`}); err != nil {
+		t.Fatalf("runShellFnAdd() error = %v", err)
+	}
+
+	fakeEditor(t, "local dir\ndir=$(mktemp -d)\ncd \"$dir\"\n")
+	if err := runShellFnAdd(nil, []string{"tmpd"}); err != nil {
+		t.Fatalf("runShellFnAdd() with editor error = %v", err)
+	}
+
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "coreutils.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bundle.Shell.Functions["mkcd"].String(); got != `mkdir -p "$1" && cd "$1"` {
+		t.Errorf("function mkcd = %q", got)
+	}
+	if got := bundle.Shell.Functions["tmpd"].String(); got != "local dir\ndir=$(mktemp -d)\ncd \"$dir\"" {
+		t.Errorf("function tmpd = %q, want editor content without trailing newline", got)
+	}
+}
+
+func TestShellFnAdd_Errors(t *testing.T) {
+	gdfDir := setupShellFnTest(t)
+
+	tests := []struct {
+		name  string
+		app   string
+		shell string
+		args  []string
+	}{
+		{name: "no app", args: []string{"mkcd", "mkdir -p $1"}},
+		{name: "invalid name", app: "coreutils", args: []string{"mk cd", "true"}},
+		{name: "invalid shell", app: "coreutils", shell: "tcsh", args: []string{"mkcd", "true"}},
+		{name: "empty body", app: "coreutils", args: []string{"mkcd", "  "}},
+	}
+	if _, err := exec.LookPath("bash"); err == nil {
+		tests = append(tests, struct {
+			name  string
+			app   string
+			shell string
+			args  []string
+		}{name: "unparsable body", app: "coreutils", shell: "bash", args: []string{"mkcd", "if true; then echo"}})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shellFnApp, shellFnShell = tt.app, tt.shell
+			if err := runShellFnAdd(nil, tt.args); err == nil {
+				t.Error("runShellFnAdd() error = nil, want error")
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(gdfDir, "apps", "coreutils.yaml")); err == nil {
+		t.Error("coreutils.yaml was written for a rejected function")
+	}
+}
+
+func TestShellFnAdd_ShellVariants(t *testing.T) {
+	gdfDir := setupShellFnTest(t)
+
+	shellFnApp = "coreutils"
+	if err := runShellFnAdd(nil, []string{"mkcd", `mkdir -p "$1" && cd "$1"`}); err != nil {
+		t.Fatal(err)
+	}
+	shellFnShell = "fish"
+	if err := runShellFnAdd(nil, []string{"mkcd", "mkdir -p $argv[1]; and cd $argv[1]"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "coreutils.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := apps.ConditionalValues{
+		{Value: "mkdir -p $argv[1]; and cd $argv[1]", Shell: "fish"},
+		{Value: `mkdir -p "$1" && cd "$1"`},
+	}
+	if got := bundle.Shell.Functions["mkcd"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("function mkcd = %+v, want %+v", got, want)
+	}
+}
+
+func TestShellFnEdit(t *testing.T) {
+	gdfDir := setupShellFnTest(t)
+
+	shellFnApp = "coreutils"
+	if err := runShellFnAdd(nil, []string{"mkcd", "mkdir -p $1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	shellFnApp = ""
+	fakeEditor(t, "mkdir -p \"$1\"\ncd \"$1\"\n")
+	if err := runShellFnEdit(nil, []string{"mkcd"}); err != nil {
+		t.Fatalf("runShellFnEdit() error = %v", err)
+	}
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "coreutils.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bundle.Shell.Functions["mkcd"].String(); got != "mkdir -p \"$1\"\ncd \"$1\"" {
+		t.Errorf("function mkcd = %q", got)
+	}
+
+	shellFnShell = "fish"
+	if err := runShellFnEdit(nil, []string{"mkcd"}); err == nil || !strings.Contains(err.Error(), "no body") {
+		t.Errorf("runShellFnEdit() of missing fish body error = %v, want no body error", err)
+	}
+	shellFnShell = ""
+	if err := runShellFnEdit(nil, []string{"nope"}); err == nil {
+		t.Error("runShellFnEdit() of unknown function error = nil, want error")
+	}
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		return
+	}
+	fakeEditor(t, "case $1 in\n")
+	if err := runShellFnEdit(nil, []string{"mkcd"}); err == nil || !strings.Contains(err.Error(), "not saved") {
+		t.Errorf("runShellFnEdit() with unparsable body error = %v, want not saved error", err)
+	}
+	bundle, err = apps.Load(filepath.Join(gdfDir, "apps", "coreutils.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bundle.Shell.Functions["mkcd"].String(); got != "mkdir -p \"$1\"\ncd \"$1\"" {
+		t.Errorf("function mkcd = %q after rejected edit, want previous body", got)
+	}
+}
+
+func TestShellFnRemove(t *testing.T) {
+	gdfDir := setupShellFnTest(t)
+
+	for _, app := range []string{"coreutils", "extras"} {
+		shellFnApp = app
+		if err := runShellFnAdd(nil, []string{"mkcd", "mkdir -p $1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shellFnApp = ""
+	if err := runShellFnRemove(nil, []string{"mkcd"}); err == nil || !strings.Contains(err.Error(), "use --app") {
+		t.Errorf("runShellFnRemove() of ambiguous function error = %v, want use --app", err)
+	}
+
+	shellFnApp = "extras"
+	if err := runShellFnRemove(nil, []string{"mkcd"}); err != nil {
+		t.Fatalf("runShellFnRemove() error = %v", err)
+	}
+	for app, want := range map[string]bool{"coreutils": true, "extras": false} {
+		bundle, err := apps.Load(filepath.Join(gdfDir, "apps", app+".yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := bundle.Shell.Functions["mkcd"]; ok != want {
+			t.Errorf("%s has mkcd = %v, want %v", app, ok, want)
+		}
+	}
+}
+
+func TestSetFunctionVariant(t *testing.T) {
+	plain := apps.ConditionalValue{Value: "a"}
+	mac := apps.ConditionalValue{Value: "b", When: "os == 'macos'"}
+	fish := apps.ConditionalValue{Value: "c", Shell: "fish"}
+
+	tests := []struct {
+		name         string
+		existing     apps.ConditionalValues
+		v            apps.ConditionalValue
+		want         apps.ConditionalValues
+		wantReplaced bool
+	}{
+		{name: "new", v: plain, want: apps.ConditionalValues{plain}},
+		{name: "variant before fallback", existing: apps.ConditionalValues{plain}, v: fish, want: apps.ConditionalValues{fish, plain}},
+		{name: "variant after variants", existing: apps.ConditionalValues{mac, plain}, v: fish, want: apps.ConditionalValues{mac, fish, plain}},
+		{name: "fallback last", existing: apps.ConditionalValues{mac}, v: plain, want: apps.ConditionalValues{mac, plain}},
+		{
+			name:         "replace",
+			existing:     apps.ConditionalValues{fish, plain},
+			v:            apps.ConditionalValue{Value: "d", Shell: "fish"},
+			want:         apps.ConditionalValues{{Value: "d", Shell: "fish"}, plain},
+			wantReplaced: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, replaced := setFunctionVariant(tt.existing, tt.v)
+			if !reflect.DeepEqual(got, tt.want) || replaced != tt.wantReplaced {
+				t.Errorf("setFunctionVariant() = %+v, %v, want %+v, %v", got, replaced, tt.want, tt.wantReplaced)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var shellProfileShell string
+
+var shellProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Measure the startup cost of each app's init snippets",
+	Long: `Time an interactive shell startup and the generated init script, and list
+the cost of every app init snippet, slowest first.
+
+Snippets that take more than 100ms are candidates for 'lazy:' in the app's
+shell.init, which defers them until one of the listed commands is first run.
+Per-snippet timing needs EPOCHREALTIME (bash 5+ or zsh).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runShellProfile(platform.ConfigDir(), cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	shellCmd.AddCommand(shellProfileCmd)
+	shellProfileCmd.Flags().StringVar(&shellProfileShell, "shell", "", "Shell to profile: bash or zsh (default: current shell)")
+}
+
+func runShellProfile(gdfDir string, w io.Writer) error {
+	name := shellProfileShell
+	if name == "" {
+		name = platform.DetectShell()
+	}
+	shellType := shell.ParseShellType(name)
+	if shellType != shell.Bash && shellType != shell.Zsh {
+		return fmt.Errorf("cannot profile shell %q: expected bash or zsh", name)
+	}
+	initPath := filepath.Join(gdfDir, "generated", shellType.InitFileName())
+	if _, err := os.Stat(initPath); err != nil {
+		return fmt.Errorf("%s not found; run 'gdf apply' first", initPath)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shellStartupTimeout)
+	defer cancel()
+	profile, err := shell.ProfileStartup(ctx, shellType, initPath)
+	if err != nil {
+		if errors.Is(err, shell.ErrShellUnavailable) {
+			return fmt.Errorf("%s is not installed", shellType)
+		}
+		return err
+	}
+
+	fmt.Fprintf(w, "Interactive %s startup: %s (%s alone: %s)\n", shellType, roundDuration(profile.Total), shellType.InitFileName(), roundDuration(profile.Init))
+	if len(profile.Segments) == 0 {
+		fmt.Fprintf(w, "Per-snippet timing is unavailable: %s has no EPOCHREALTIME.\n", shellType)
+		return nil
+	}
+
+	var snippets []shell.StartupSegment
+	for _, seg := range profile.Segments {
+		if seg.App != "" {
+			snippets = append(snippets, seg)
+		}
+	}
+	if len(snippets) == 0 {
+		fmt.Fprintln(w, "No app init snippets.")
+		return nil
+	}
+	sort.SliceStable(snippets, func(i, j int) bool {
+		return snippets[i].Duration > snippets[j].Duration
+	})
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tAPP\tSNIPPET\tNOTE")
+	slow := 0
+	for _, seg := range snippets {
+		note := ""
+		switch {
+		case seg.Failed():
+			note = fmt.Sprintf("failed (exit status %d)", seg.Status)
+		case seg.Lazy:
+			note = "lazy"
+		case seg.Duration > shellSegmentSlowThreshold:
+			note = "slow"
+			slow++
+		}
+		_, snippetName, _ := strings.Cut(seg.Name, ":")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", roundDuration(seg.Duration), seg.App, snippetName, note)
+	}
+	tw.Flush()
+
+	if slow > 0 {
+		fmt.Fprintf(w, "\n%d snippet(s) took more than %s. Add 'lazy: [<command>...]' to defer them until first use.\n", slow, shellSegmentSlowThreshold)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellProfile_ListsSnippetsSlowestFirst(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	gdfDir := filepath.Join(home, ".gdf")
+	initPath := filepath.Join(gdfDir, "generated", "init.bash")
+	if err := os.MkdirAll(filepath.Dir(initPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "# Init\n# fast:env\ntrue\n# slow:env\nsleep 0.2\n# nvm:load\n# lazy: nvm\nnvm() { :; }\n"
+	if err := os.WriteFile(initPath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old := shellProfileShell
+	shellProfileShell = "bash"
+	defer func() { shellProfileShell = old }()
+
+	var out bytes.Buffer
+	if err := runShellProfile(gdfDir, &out); err != nil {
+		t.Fatalf("runShellProfile() error = %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "Interactive bash startup:") {
+		t.Fatalf("missing startup summary:\n%s", got)
+	}
+	if strings.Contains(got, "Per-snippet timing is unavailable") {
+		t.Skip("bash has no EPOCHREALTIME; per-snippet timing unavailable")
+	}
+	slow, fast := strings.Index(got, "slow "), strings.Index(got, "fast ")
+	if slow < 0 || fast < 0 || slow > fast {
+		t.Errorf("expected slow:env listed before fast:env:\n%s", got)
+	}
+	if !strings.Contains(got, "lazy") || !strings.Contains(got, "Add 'lazy:") {
+		t.Errorf("expected lazy note and hint:\n%s", got)
+	}
+}
+
+func TestShellProfile_RequiresGeneratedInit(t *testing.T) {
+	old := shellProfileShell
+	shellProfileShell = "bash"
+	defer func() { shellProfileShell = old }()
+
+	err := runShellProfile(t.TempDir(), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "gdf apply") {
+		t.Fatalf("runShellProfile() error = %v, want hint to run gdf apply", err)
+	}
+}
@@ -2,8 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/shell"
 )
 
 func TestRunShellCompletion(t *testing.T) {
@@ -50,3 +54,37 @@ func TestRunShellCompletion(t *testing.T) {
 		}
 	})
 }
+
+func TestConfiguredShells(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	tests := []struct {
+		name            string
+		cfg             *config.Config
+		wantShells      []shell.ShellType
+		wantUnsupported []string
+	}{
+		{name: "nil config uses detected shell", cfg: nil, wantShells: []shell.ShellType{shell.Zsh}},
+		{
+			name:       "configured list keeps order and drops duplicates",
+			cfg:        &config.Config{ShellIntegration: &config.ShellIntegrationConfig{Shells: []string{"bash", "fish", "bash"}}},
+			wantShells: []shell.ShellType{shell.Bash, shell.Fish},
+		},
+		{
+			name:            "unsupported names are reported",
+			cfg:             &config.Config{ShellIntegration: &config.ShellIntegrationConfig{Shells: []string{"zsh", "tcsh"}}},
+			wantShells:      []shell.ShellType{shell.Zsh},
+			wantUnsupported: []string{"tcsh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shells, unsupported := configuredShells(tt.cfg)
+			if !reflect.DeepEqual(shells, tt.wantShells) {
+				t.Errorf("shells = %v, want %v", shells, tt.wantShells)
+			}
+			if !reflect.DeepEqual(unsupported, tt.wantUnsupported) {
+				t.Errorf("unsupported = %v, want %v", unsupported, tt.wantUnsupported)
+			}
+		})
+	}
+}
@@ -9,15 +9,22 @@ import (
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 	"github.com/spf13/cobra"
 )
 
 var trackCmd = &cobra.Command{
-	Use:   "track <path>",
-	Short: "Track an existing dotfile",
-	Long: `Move an existing file to the GDF repository and replace it with a symlink.
-Automatically detects the app name or uses --app if provided.`,
-	Args: cobra.ExactArgs(1),
+	Use:   "track <path>...",
+	Short: "Track existing dotfiles",
+	Long: `Move existing files to the GDF repository and replace them with symlinks.
+Automatically detects the app name or uses --app if provided.
+
+Paths may be glob patterns; quote them so GDF expands '~' and wildcards itself.
+Files matching patterns in ~/.gdf/.gdfignore are skipped. When more than one
+file is selected, the whole batch is previewed and confirmed once.`,
+	Example: `  gdf app track ~/.gitconfig -a git
+  gdf app track '~/.config/alacritty/*.toml' ~/.tmux.conf`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: runTrack,
 }
 
@@ -30,6 +37,8 @@ type trackFileOptions struct {
 	Secret      bool
 	Interactive bool
 	Audit       *decisionAudit
+	// Bundles defers app bundle saves to the caller when set.
+	Bundles *trackBundleSet
 }
 
 type trackFileResult struct {
@@ -37,28 +46,120 @@ type trackFileResult struct {
 	Reason  string
 }
 
+// trackBundleSet caches app bundles touched by a track batch so each bundle
+// is loaded and saved once.
+type trackBundleSet struct {
+	appsDir string
+	bundles map[string]*apps.Bundle
+	order   []string
+}
+
+func newTrackBundleSet(appsDir string) *trackBundleSet {
+	return &trackBundleSet{appsDir: appsDir, bundles: make(map[string]*apps.Bundle)}
+}
+
+// Get returns the cached bundle for appName, loading it or creating a new one.
+func (s *trackBundleSet) Get(appName string) (*apps.Bundle, error) {
+	if bundle, ok := s.bundles[appName]; ok {
+		return bundle, nil
+	}
+
+	appPath := filepath.Join(s.appsDir, appName+".yaml")
+	var bundle *apps.Bundle
+	if _, err := os.Stat(appPath); os.IsNotExist(err) {
+		bundle = &apps.Bundle{
+			Name:        appName,
+			Description: fmt.Sprintf("App bundle for %s", appName),
+		}
+	} else {
+		bundle, err = apps.Load(appPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading app bundle: %w", err)
+		}
+	}
+
+	s.bundles[appName] = bundle
+	s.order = append(s.order, appName)
+	return bundle, nil
+}
+
+// Save writes every cached bundle back to the apps directory.
+func (s *trackBundleSet) Save() error {
+	for _, name := range s.order {
+		if err := s.bundles[name].Save(filepath.Join(s.appsDir, name+".yaml")); err != nil {
+			return fmt.Errorf("saving app bundle %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func init() {
 	appCmd.AddCommand(trackCmd)
-	trackCmd.Flags().StringVarP(&targetApp, "app", "a", "", "App bundle to add this file to")
-	trackCmd.Flags().BoolVar(&secretFlag, "secret", false, "Mark file as secret (add to .gitignore)")
+	trackCmd.Flags().StringVarP(&targetApp, "app", "a", "", "App bundle to add these files to")
+	trackCmd.Flags().BoolVar(&secretFlag, "secret", false, "Mark files as secret (add to .gitignore)")
 	trackCmd.Flags().BoolVar(&trackInteractive, "interactive", false, "Preview and resolve path/target conflicts interactively")
 }
 
 func runTrack(cmd *cobra.Command, args []string) error {
-	audit := newDecisionAudit("gdf app track", false)
-	result, err := trackFile(args[0], trackFileOptions{
-		AppName:     targetApp,
-		Secret:      secretFlag,
-		Interactive: trackInteractive,
-		Audit:       audit,
-	})
+	gdfDir := platform.ConfigDir()
+	home := platform.Detect().Home
+
+	ignore, err := loadGdfIgnore(gdfDir)
+	if err != nil {
+		return err
+	}
+	paths, skipped, err := expandTrackPaths(args, gdfDir, home, ignore)
 	if err != nil {
 		return err
 	}
-	if result.Skipped {
-		fmt.Printf("Skipped tracking %s (%s)\n", args[0], result.Reason)
+	for _, skip := range skipped {
+		fmt.Printf("Skipped %s (%s)\n", skip.Path, skip.Reason)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to track")
 	}
-	if logPath, err := audit.Save(platform.ConfigDir()); err != nil {
+
+	if len(paths) > 1 {
+		printTrackBatchPreview(paths, targetApp)
+		ok, err := confirmPromptDefaultYes(fmt.Sprintf("Track %d files? [Y/n]: ", len(paths)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	audit := newDecisionAudit("gdf app track", false)
+	bundles := newTrackBundleSet(filepath.Join(gdfDir, "apps"))
+	var trackErr error
+	for _, path := range paths {
+		result, err := trackFile(path, trackFileOptions{
+			AppName:     targetApp,
+			Secret:      secretFlag,
+			Interactive: trackInteractive,
+			Audit:       audit,
+			Bundles:     bundles,
+		})
+		if err != nil {
+			trackErr = err
+			break
+		}
+		if result.Skipped {
+			fmt.Printf("Skipped tracking %s (%s)\n", path, result.Reason)
+		}
+	}
+
+	// Files moved before a failure are already symlinked, so their bundle
+	// entries must be persisted even when the batch stops early.
+	if err := bundles.Save(); err != nil {
+		return err
+	}
+	if trackErr != nil {
+		return trackErr
+	}
+	if logPath, err := audit.Save(gdfDir); err != nil {
 		return err
 	} else if logPath != "" {
 		fmt.Printf("Logged conflict decisions: %s\n", logPath)
@@ -66,6 +167,89 @@ func runTrack(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+type trackSkip struct {
+	Path   string
+	Reason string
+}
+
+// expandTrackPaths expands globs in args and filters out ignored, managed,
+// and directory matches. Literal paths are passed through for trackFile to
+// validate so missing files still produce a clear error.
+func expandTrackPaths(args []string, gdfDir, home string, ignore *util.IgnoreMatcher) ([]string, []trackSkip, error) {
+	var paths []string
+	var skipped []trackSkip
+	seen := make(map[string]bool)
+	managedRoot := filepath.Join(gdfDir, "dotfiles") + string(filepath.Separator)
+
+	for _, arg := range args {
+		expanded := platform.ExpandPath(arg)
+		isGlob := strings.ContainsAny(expanded, "*?[")
+
+		matches := []string{expanded}
+		if isGlob {
+			globbed, err := filepath.Glob(expanded)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+			if len(globbed) == 0 {
+				return nil, nil, fmt.Errorf("no files match %s", arg)
+			}
+			matches = globbed
+		}
+
+		for _, match := range matches {
+			if seen[match] {
+				continue
+			}
+			seen[match] = true
+
+			if isGdfIgnored(ignore, home, match) {
+				skipped = append(skipped, trackSkip{Path: match, Reason: "matched " + gdfIgnoreFileName})
+				continue
+			}
+			if isGlob {
+				info, err := os.Lstat(match)
+				if err != nil {
+					continue
+				}
+				if info.IsDir() {
+					continue
+				}
+				if info.Mode()&os.ModeSymlink != 0 {
+					if dest, err := resolveLinkDestination(match); err == nil && strings.HasPrefix(dest, managedRoot) {
+						skipped = append(skipped, trackSkip{Path: match, Reason: "already managed"})
+						continue
+					}
+				}
+			}
+			paths = append(paths, match)
+		}
+	}
+	return paths, skipped, nil
+}
+
+func printTrackBatchPreview(paths []string, appName string) {
+	fmt.Printf("Tracking plan (%d files):\n", len(paths))
+	for _, path := range paths {
+		app := appName
+		if app == "" {
+			app = apps.DetectAppFromPath(path)
+		}
+		fmt.Printf("  - %s -> app=%s\n", path, app)
+	}
+}
+
+func resolveLinkDestination(path string) (string, error) {
+	dest, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(path), dest)
+	}
+	return filepath.Clean(dest), nil
+}
+
 func trackFile(path string, opts trackFileOptions) (*trackFileResult, error) {
 	expandedPath := platform.ExpandPath(path)
 
@@ -91,7 +275,6 @@ func trackFile(path string, opts trackFileOptions) (*trackFileResult, error) {
 	// 3. Prepare config paths
 	gdfDir := platform.ConfigDir()
 	dotfilesDir := filepath.Join(gdfDir, "dotfiles")
-	appPath := filepath.Join(gdfDir, "apps", appName+".yaml")
 
 	// 4. Determine destination in repo
 	relPath := filepath.Base(expandedPath)
@@ -154,17 +337,13 @@ func trackFile(path string, opts trackFileOptions) (*trackFileResult, error) {
 	}
 
 	// 8. Update App Bundle
-	var bundle *apps.Bundle
-	if _, err := os.Stat(appPath); os.IsNotExist(err) {
-		bundle = &apps.Bundle{
-			Name:        appName,
-			Description: fmt.Sprintf("App bundle for %s", appName),
-		}
-	} else {
-		bundle, err = apps.Load(appPath)
-		if err != nil {
-			return nil, fmt.Errorf("loading app bundle: %w", err)
-		}
+	bundles := opts.Bundles
+	if bundles == nil {
+		bundles = newTrackBundleSet(filepath.Join(gdfDir, "apps"))
+	}
+	bundle, err := bundles.Get(appName)
+	if err != nil {
+		return nil, err
 	}
 
 	home := platform.Detect().Home
@@ -196,8 +375,10 @@ func trackFile(path string, opts trackFileOptions) (*trackFileResult, error) {
 		})
 	}
 
-	if err := bundle.Save(appPath); err != nil {
-		return nil, fmt.Errorf("saving app bundle: %w", err)
+	if opts.Bundles == nil {
+		if err := bundles.Save(); err != nil {
+			return nil, err
+		}
 	}
 
 	fmt.Printf("✓ Tracked %s in app '%s'\n", path, appName)
@@ -224,3 +224,69 @@ func TestTrack_InteractiveConflictNonInteractiveStop(t *testing.T) {
 		t.Fatalf("expected exitCodeError, got %T", err)
 	}
 }
+
+func TestTrack_GlobBatchRespectsGdfIgnore(t *testing.T) {
+	home := t.TempDir()
+	gdfDir := filepath.Join(home, ".gdf")
+	t.Setenv("HOME", home)
+	configureGitUserGlobal(t, home)
+	if err := createNewRepo(gdfDir); err != nil {
+		t.Fatal(err)
+	}
+
+	configDir := filepath.Join(home, ".config", "alacritty")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alacritty.toml", "themes.toml", "alacritty.lock"} {
+		if err := os.WriteFile(filepath.Join(configDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldYes := globalYes
+	globalYes = true
+	targetApp = "alacritty"
+	defer func() {
+		globalYes = oldYes
+		targetApp = ""
+	}()
+
+	if err := runTrack(nil, []string{"~/.config/alacritty/*"}); err != nil {
+		t.Fatalf("runTrack() error = %v", err)
+	}
+
+	bundle, err := apps.Load(filepath.Join(gdfDir, "apps", "alacritty.yaml"))
+	if err != nil {
+		t.Fatalf("loading bundle: %v", err)
+	}
+	if len(bundle.Dotfiles) != 2 {
+		t.Fatalf("dotfiles count = %d, want 2: %#v", len(bundle.Dotfiles), bundle.Dotfiles)
+	}
+	for _, dot := range bundle.Dotfiles {
+		if strings.HasSuffix(dot.Source, ".lock") {
+			t.Fatalf("ignored lock file was tracked: %#v", dot)
+		}
+	}
+
+	lockInfo, err := os.Lstat(filepath.Join(configDir, "alacritty.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockInfo.Mode()&os.ModeSymlink != 0 {
+		t.Fatal("ignored file should not be replaced with a symlink")
+	}
+
+	// Re-running the same glob skips already-managed symlinks.
+	if err := runTrack(nil, []string{"~/.config/alacritty/*.toml"}); err == nil {
+		t.Fatal("expected error when every match is already managed")
+	}
+}
+
+func TestExpandTrackPaths_NoMatches(t *testing.T) {
+	home := t.TempDir()
+	_, _, err := expandTrackPaths([]string{filepath.Join(home, "*.toml")}, filepath.Join(home, ".gdf"), home, nil)
+	if err == nil {
+		t.Fatal("expected error for glob without matches")
+	}
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/engine"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [apps...]",
+	Short: "Upgrade the packages of managed apps",
+	Long: `Upgrade the installed packages of the given apps, or of all apps in the
+applied profiles (and their dependencies), without a system-wide upgrade.
+
+Each app's package is upgraded with the manager it is installed with. apt
+package lists are refreshed first unless --dry-run is set. Upgrades are
+recorded in the operation log.`,
+	Example: `  gdf upgrade --dry-run
+  gdf upgrade
+  gdf upgrade git ripgrep`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runUpgrade,
+}
+
+var (
+	upgradeDryRun bool
+	upgradeJSON   bool
+)
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "List available upgrades without installing them")
+	upgradeCmd.Flags().BoolVar(&upgradeJSON, "json", false, "Output available upgrades as JSON (requires --dry-run)")
+}
+
+// packageUpgrade is an app package with a newer version available.
+type packageUpgrade struct {
+	App       string `json:"app"`
+	Manager   string `json:"manager"`
+	Package   string `json:"package"`
+	Current   string `json:"current"`
+	Available string `json:"available"`
+}
+
+// upgradeGroup holds the installed packages of one manager.
+type upgradeGroup struct {
+	Name    string
+	Manager packages.Manager
+	// Apps maps each package name to the app that installs it.
+	Apps     map[string]string
+	Packages []string
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	if upgradeJSON && !upgradeDryRun {
+		return fmt.Errorf("--json is currently only supported with --dry-run")
+	}
+
+	gdfDir := platform.ConfigDir()
+	plat := platform.Detect()
+	cfg, err := config.LoadConfig(filepath.Join(gdfDir, "config.yaml"))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	bundles, err := loadUpgradeBundles(gdfDir, args)
+	if err != nil {
+		return err
+	}
+	groups := groupInstalledPackages(bundles, plat, cfg)
+
+	var upgrades []packageUpgrade
+	for _, g := range groups {
+		if refresher, ok := g.Manager.(packages.Refresher); ok && !upgradeDryRun {
+			if !upgradeJSON {
+				fmt.Printf("Refreshing %s package index...\n", g.Name)
+			}
+			if err := refresher.Refresh(); err != nil {
+				return err
+			}
+		}
+		outdated, err := g.Manager.Outdated(g.Packages)
+		if err != nil {
+			return err
+		}
+		for _, o := range outdated {
+			upgrades = append(upgrades, packageUpgrade{
+				App:       g.Apps[o.Name],
+				Manager:   g.Name,
+				Package:   o.Name,
+				Current:   o.Current,
+				Available: o.Available,
+			})
+		}
+	}
+
+	if upgradeJSON {
+		if upgrades == nil {
+			upgrades = []packageUpgrade{}
+		}
+		data, err := json.MarshalIndent(upgrades, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(upgrades) == 0 {
+		fmt.Println("✓ All managed packages are up to date")
+		return nil
+	}
+	fmt.Println("Upgradable packages:")
+	for _, u := range upgrades {
+		fmt.Printf("   %s: %s %s → %s (via %s)\n", u.App, u.Package, u.Current, u.Available, u.Manager)
+	}
+	if upgradeDryRun {
+		fmt.Printf("\nDry run: %d package(s) would be upgraded\n", len(upgrades))
+		return nil
+	}
+
+	logger := engine.NewLogger(false)
+	var errs []error
+	upgraded := 0
+	for _, g := range groups {
+		var names []string
+		for _, u := range upgrades {
+			if u.Manager == g.Name {
+				names = append(names, u.Package)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		failures := g.Manager.Upgrade(names)
+		for _, u := range upgrades {
+			if u.Manager != g.Name {
+				continue
+			}
+			if err, failed := failures[u.Package]; failed {
+				fmt.Printf("   ✗ %s\n", u.Package)
+				errs = append(errs, fmt.Errorf("upgrading package %s: %w", u.Package, err))
+				continue
+			}
+			fmt.Printf("   ✓ %s %s\n", u.Package, u.Available)
+			logger.Log("package_upgrade", u.Package, map[string]string{
+				"manager": u.Manager,
+				"app":     u.App,
+				"from":    u.Current,
+				"to":      u.Available,
+			})
+			upgraded++
+		}
+	}
+
+	if logPath, err := logger.Save(gdfDir); err != nil {
+		fmt.Printf("! Warning: failed to save upgrade operation log: %v\n", err)
+	} else if logPath != "" {
+		fmt.Printf("Logged upgrade operations: %s\n", logPath)
+	}
+	if upgraded > 0 {
+		if lock, err := packages.LoadLock(filepath.Join(gdfDir, packages.LockFileName)); err == nil && len(lock.Entries(packages.LockPlatformKey(plat))) > 0 {
+			fmt.Printf("! %s now records older versions; run 'gdf lock refresh' to update it\n", packages.LockFileName)
+		}
+	}
+	fmt.Printf("✓ Upgraded %d package(s)\n", upgraded)
+	return errors.Join(errs...)
+}
+
+// loadUpgradeBundles loads the named apps, or the apps of the applied
+// profiles and their dependencies when no names are given.
+func loadUpgradeBundles(gdfDir string, names []string) ([]*apps.Bundle, error) {
+	appsDir := filepath.Join(gdfDir, "apps")
+	if len(names) > 0 {
+		bundles := make([]*apps.Bundle, 0, len(names))
+		for _, name := range names {
+			appPath := filepath.Join(appsDir, name+".yaml")
+			if _, err := os.Stat(appPath); os.IsNotExist(err) {
+				return nil, fmt.Errorf("app '%s' not found", name)
+			}
+			bundle, err := apps.Load(appPath)
+			if err != nil {
+				return nil, fmt.Errorf("loading app '%s': %w", name, err)
+			}
+			bundles = append(bundles, bundle)
+		}
+		return bundles, nil
+	}
+
+	st, err := state.LoadFromDir(gdfDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading state: %w", err)
+	}
+	var queue []string
+	for _, profile := range st.AppliedProfiles {
+		queue = append(queue, profile.Apps...)
+	}
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("no applied profiles; run 'gdf apply' first or name the apps to upgrade")
+	}
+
+	var bundles []*apps.Bundle
+	seen := make(map[string]bool)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		bundle, err := apps.Load(filepath.Join(appsDir, name+".yaml"))
+		if err != nil {
+			fmt.Printf("! Warning: skipping app '%s': %v\n", name, err)
+			continue
+		}
+		bundles = append(bundles, bundle)
+		queue = append(queue, bundle.Dependencies...)
+	}
+	return bundles, nil
+}
+
+// groupInstalledPackages groups the installed packages of bundles by the
+// manager they are installed with. Apps without a package, with a custom
+// install script, or whose package is not installed are left out.
+func groupInstalledPackages(bundles []*apps.Bundle, plat *platform.Platform, cfg *config.Config) []*upgradeGroup {
+	var groups []*upgradeGroup
+	byName := make(map[string]*upgradeGroup)
+	for _, bundle := range bundles {
+		if bundle.Package == nil {
+			continue
+		}
+		plan := resolvePackageManagerPlan(bundle.Package, plat, cfg)
+		if plan == nil || plan.Selected.Name == "custom" || plan.Selected.Name == "none" {
+			continue
+		}
+		for _, probe := range plan.Probes {
+			installed, err := packages.IsPackageInstalled(probe.Manager, probe.Package, probe.PackageName)
+			if err != nil || !installed {
+				continue
+			}
+			g, ok := byName[probe.Name]
+			if !ok {
+				g = &upgradeGroup{Name: probe.Name, Manager: probe.Manager, Apps: make(map[string]string)}
+				byName[probe.Name] = g
+				groups = append(groups, g)
+			}
+			if _, dup := g.Apps[probe.PackageName]; !dup {
+				g.Packages = append(g.Packages, probe.PackageName)
+				g.Apps[probe.PackageName] = bundle.Name
+			}
+			break
+		}
+	}
+	return groups
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/engine"
+	"github.com/rztaylor/GoDotFiles/internal/packages"
+	"github.com/rztaylor/GoDotFiles/internal/state"
+)
+
+func setupUpgradeTest(t *testing.T, dryRun bool) (string, *MockPackageManager) {
+	t.Helper()
+	_, gdfDir := setupApplyPackageInstallTest(t, "pkg-app", "git")
+
+	oldDryRun, oldJSON := upgradeDryRun, upgradeJSON
+	upgradeDryRun, upgradeJSON = dryRun, false
+	t.Cleanup(func() { upgradeDryRun, upgradeJSON = oldDryRun, oldJSON })
+
+	st, err := state.LoadFromDir(gdfDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.AddProfile("default", []string{"pkg-app"})
+	if err := st.Save(gdfDir + "/state.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := &MockPackageManager{
+		mgrName:   "apt",
+		installed: []string{"git"},
+		versions:  map[string]string{"git": "2.43.0"},
+		available: map[string]string{"git": "2.44.0"},
+	}
+	packages.Override = mgr
+	t.Cleanup(func() { packages.Override = nil })
+	return gdfDir, mgr
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Run("dry run lists upgrades without installing", func(t *testing.T) {
+		_, mgr := setupUpgradeTest(t, true)
+		if err := runUpgrade(nil, nil); err != nil {
+			t.Fatalf("runUpgrade() error = %v", err)
+		}
+		if len(mgr.upgraded) != 0 {
+			t.Fatalf("upgraded = %v, want none", mgr.upgraded)
+		}
+	})
+
+	t.Run("upgrades applied apps and logs operations", func(t *testing.T) {
+		gdfDir, mgr := setupUpgradeTest(t, false)
+		if err := runUpgrade(nil, nil); err != nil {
+			t.Fatalf("runUpgrade() error = %v", err)
+		}
+		if !reflect.DeepEqual(mgr.upgraded, []string{"git"}) {
+			t.Fatalf("upgraded = %v, want [git]", mgr.upgraded)
+		}
+
+		_, ops, err := engine.LatestOperationLog(gdfDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ops) != 1 || ops[0].Type != "package_upgrade" || ops[0].Target != "git" {
+			t.Fatalf("operations = %+v, want one package_upgrade of git", ops)
+		}
+		want := map[string]string{"manager": "apt", "app": "pkg-app", "from": "2.43.0", "to": "2.44.0"}
+		if !reflect.DeepEqual(ops[0].Details, want) {
+			t.Errorf("details = %v, want %v", ops[0].Details, want)
+		}
+	})
+
+	t.Run("skips up to date packages", func(t *testing.T) {
+		_, mgr := setupUpgradeTest(t, false)
+		mgr.available["git"] = "2.43.0"
+		if err := runUpgrade(nil, []string{"pkg-app"}); err != nil {
+			t.Fatalf("runUpgrade() error = %v", err)
+		}
+		if len(mgr.upgraded) != 0 {
+			t.Fatalf("upgraded = %v, want none", mgr.upgraded)
+		}
+	})
+
+	t.Run("invalid config is reported", func(t *testing.T) {
+		gdfDir, mgr := setupUpgradeTest(t, false)
+		cfg := "kind: Config/v1\npackage_manager:\n  managers:\n    vscode:\n      list: code --list-extensions\n"
+		if err := os.WriteFile(filepath.Join(gdfDir, "config.yaml"), []byte(cfg), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := runUpgrade(nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "loading config") {
+			t.Fatalf("runUpgrade() error = %v, want config error", err)
+		}
+		if len(mgr.upgraded) != 0 {
+			t.Fatalf("upgraded = %v, want none", mgr.upgraded)
+		}
+	})
+
+	t.Run("unknown app", func(t *testing.T) {
+		_, _ = setupUpgradeTest(t, true)
+		err := runUpgrade(nil, []string{"missing"})
+		if err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("runUpgrade() error = %v, want not found", err)
+		}
+	})
+}
@@ -2,13 +2,18 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/rztaylor/GoDotFiles/internal/schema"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 )
 
 // Config represents the global GDF configuration (~/.gdf/config.yaml).
@@ -74,7 +79,7 @@ type ConflictResolution struct {
 	// Aliases strategy: last_wins, error, or prompt (default: last_wins).
 	Aliases string `yaml:"aliases,omitempty"`
 
-	// Dotfiles strategy: error, backup_and_replace, or prompt (default: error).
+	// Dotfiles strategy: error, backup_and_replace, replace, merge, or prompt (default: error).
 	Dotfiles string `yaml:"dotfiles,omitempty"`
 }
 
@@ -98,6 +103,35 @@ func (c *ConflictResolution) DotfilesDefault() string {
 type PackageManagerConfig struct {
 	// Prefer specifies which package manager to prefer per platform.
 	Prefer *PackageManagerPrefer `yaml:"prefer,omitempty"`
+
+	// Managers declares user-defined package managers by name. Apps use
+	// them through package.managers.
+	Managers map[string]*ManagerCommands `yaml:"managers,omitempty"`
+}
+
+// ManagerTemplateData is the data the command templates of a user-defined
+// package manager are executed with.
+type ManagerTemplateData struct {
+	// Package is the shell-quoted package name.
+	Package string
+}
+
+// ManagerCommands are the shell command templates of a user-defined
+// package manager. {{.Package}} expands to the shell-quoted package name.
+type ManagerCommands struct {
+	// Install installs a package (required).
+	Install string `yaml:"install"`
+
+	// Uninstall removes a package.
+	Uninstall string `yaml:"uninstall,omitempty"`
+
+	// IsInstalled exits 0 when the package is installed.
+	IsInstalled string `yaml:"is_installed,omitempty"`
+
+	// List prints installed packages, one per line as "name version" or
+	// "name@version" (the version is optional). It is used to detect
+	// installed packages when IsInstalled is not set, and to read versions.
+	List string `yaml:"list,omitempty"`
 }
 
 // PackageManagerPrefer maps platforms to preferred package managers.
@@ -126,6 +160,17 @@ type HistoryConfig struct {
 type ShellIntegrationConfig struct {
 	// AutoReloadEnabled enables prompt-hook based reload checks for generated init scripts.
 	AutoReloadEnabled *bool `yaml:"auto_reload_enabled,omitempty"`
+
+	// Shells lists the shells to generate init scripts for (bash, zsh, fish).
+	Shells []string `yaml:"shells,omitempty"`
+
+	// RCFiles overrides the RC files the source line is injected into, per
+	// shell (for example bash: [~/.bashrc, ~/.bash_profile]).
+	RCFiles map[string][]string `yaml:"rc_files,omitempty"`
+
+	// LoginEnv lists where app env vars are also exported for login sessions
+	// and GUI apps: profile, systemd, launchd.
+	LoginEnv []string `yaml:"login_env,omitempty"`
 }
 
 // UIConfig controls interactive CLI presentation defaults.
@@ -172,6 +217,36 @@ func (s *ShellIntegrationConfig) AutoReloadEnabledDefault() bool {
 	return *s.AutoReloadEnabled
 }
 
+// ShellsDefault returns the shells to generate init scripts for. When none
+// are configured it is the detected shell, or bash if that is unknown.
+func (s *ShellIntegrationConfig) ShellsDefault(detected string) []string {
+	if s != nil && len(s.Shells) > 0 {
+		return s.Shells
+	}
+	if detected == "" || detected == "unknown" {
+		return []string{"bash"}
+	}
+	return []string{detected}
+}
+
+// RCFilesDefault returns the configured RC files for shell. Nil means the
+// shell's default RC file.
+func (s *ShellIntegrationConfig) RCFilesDefault(shell string) []string {
+	if s == nil {
+		return nil
+	}
+	return s.RCFiles[shell]
+}
+
+// LoginEnvDefault returns the configured login environment targets. None
+// are enabled by default.
+func (s *ShellIntegrationConfig) LoginEnvDefault() []string {
+	if s == nil {
+		return nil
+	}
+	return s.LoginEnv
+}
+
 // ColorDefault returns the effective UI color mode.
 func (u *UIConfig) ColorDefault() string {
 	if u == nil || u.Color == "" {
@@ -216,6 +291,10 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("validating config version: %w", err)
 	}
 
+	if err := cfg.PackageManager.validateManagers(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -244,3 +323,55 @@ func (c *Config) Save(path string) error {
 
 	return nil
 }
+
+// validateManagers checks the user-defined package managers.
+func (p *PackageManagerConfig) validateManagers() error {
+	if p == nil {
+		return nil
+	}
+	for _, name := range util.SortedKeys(p.Managers) {
+		field := "package_manager.managers." + name
+		if !managerNameRegex.MatchString(name) {
+			return fmt.Errorf("%s: name must be lowercase alphanumeric with hyphens", field)
+		}
+		if reservedManagerNames[name] {
+			return fmt.Errorf("%s: %q is a built-in package manager name", field, name)
+		}
+		cmds := p.Managers[name]
+		if cmds == nil || strings.TrimSpace(cmds.Install) == "" {
+			return fmt.Errorf("%s: install is required", field)
+		}
+		if cmds.IsInstalled == "" && cmds.List == "" {
+			return fmt.Errorf("%s: is_installed or list is required", field)
+		}
+		for _, tmpl := range []struct{ key, text string }{
+			{"install", cmds.Install},
+			{"uninstall", cmds.Uninstall},
+			{"is_installed", cmds.IsInstalled},
+			{"list", cmds.List},
+		} {
+			t, err := template.New(tmpl.key).Parse(tmpl.text)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", field, tmpl.key, err)
+			}
+			// Executing catches unknown fields such as {{.Name}}.
+			if err := t.Execute(io.Discard, ManagerTemplateData{Package: "'pkg'"}); err != nil {
+				return fmt.Errorf("%s.%s: %w", field, tmpl.key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// managerNameRegex matches user-defined package manager names.
+var managerNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// reservedManagerNames are the built-in package managers and the names
+// apps use for custom scripts and package-less installs; user-defined
+// managers cannot shadow them.
+var reservedManagerNames = map[string]bool{
+	"brew": true, "apt": true, "dnf": true, "pacman": true,
+	"flatpak": true, "snap": true, "nix": true, "release": true,
+	"cargo": true, "go": true, "pipx": true, "uv": true, "npm": true,
+	"custom": true, "none": true,
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -62,6 +63,67 @@ shell: bash
 			wantAliases:  "last_wins",
 			wantDotfiles: "error",
 		},
+		{
+			name: "user-defined package manager",
+			yaml: `
+kind: Config/v1
+package_manager:
+  managers:
+    vscode:
+      install: code --install-extension {{.Package}}
+      uninstall: code --uninstall-extension {{.Package}}
+      list: code --list-extensions --show-versions
+`,
+			wantAliases:  "last_wins",
+			wantDotfiles: "error",
+		},
+		{
+			name: "user-defined package manager without install",
+			yaml: `
+kind: Config/v1
+package_manager:
+  managers:
+    vscode:
+      list: code --list-extensions --show-versions
+`,
+			wantErr: true,
+		},
+		{
+			name: "user-defined package manager with invalid template",
+			yaml: `
+kind: Config/v1
+package_manager:
+  managers:
+    krew:
+      install: kubectl krew install {{.Package}
+      is_installed: kubectl krew list | grep -qx {{.Package}}
+`,
+			wantErr: true,
+		},
+		{
+			name: "user-defined package manager shadowing a built-in",
+			yaml: `
+kind: Config/v1
+package_manager:
+  managers:
+    npm:
+      install: pnpm add -g {{.Package}}
+      list: pnpm list -g
+`,
+			wantErr: true,
+		},
+		{
+			name: "user-defined package manager with unknown template field",
+			yaml: `
+kind: Config/v1
+package_manager:
+  managers:
+    krew:
+      install: kubectl krew install {{.Name}}
+      is_installed: kubectl krew list | grep -qx {{.Package}}
+`,
+			wantErr: true,
+		},
 		{
 			name: "invalid yaml",
 			yaml: `kind: Config/v1
@@ -263,6 +325,41 @@ func TestShellIntegrationConfig_AutoReloadEnabledDefault(t *testing.T) {
 	})
 }
 
+func TestShellIntegrationConfig_ShellsDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        *ShellIntegrationConfig
+		detected string
+		want     []string
+	}{
+		{name: "nil uses detected", s: nil, detected: "zsh", want: []string{"zsh"}},
+		{name: "empty uses detected", s: &ShellIntegrationConfig{}, detected: "fish", want: []string{"fish"}},
+		{name: "unknown falls back to bash", s: nil, detected: "unknown", want: []string{"bash"}},
+		{name: "configured list", s: &ShellIntegrationConfig{Shells: []string{"zsh", "bash"}}, detected: "fish", want: []string{"zsh", "bash"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.ShellsDefault(tt.detected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ShellsDefault(%q) = %v, want %v", tt.detected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellIntegrationConfig_RCFilesDefault(t *testing.T) {
+	var nilConfig *ShellIntegrationConfig
+	if got := nilConfig.RCFilesDefault("bash"); got != nil {
+		t.Errorf("nil RCFilesDefault() = %v, want nil", got)
+	}
+	s := &ShellIntegrationConfig{RCFiles: map[string][]string{"bash": {"~/.bashrc", "~/.bash_profile"}}}
+	if got := s.RCFilesDefault("bash"); !reflect.DeepEqual(got, []string{"~/.bashrc", "~/.bash_profile"}) {
+		t.Errorf("RCFilesDefault(bash) = %v", got)
+	}
+	if got := s.RCFilesDefault("zsh"); got != nil {
+		t.Errorf("RCFilesDefault(zsh) = %v, want nil", got)
+	}
+}
+
 func TestDefaultShell(t *testing.T) {
 	tests := []struct {
 		name string
@@ -312,6 +409,8 @@ func TestDefaultConfigYAML_IncludesAllSections(t *testing.T) {
 		"check_interval: 24h",
 		"shell_integration:",
 		"auto_reload_enabled: false",
+		"shells: [bash]",
+		"login_env: []",
 		"ui:",
 		"color: auto",
 		"color_section_headings: true",
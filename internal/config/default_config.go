@@ -26,7 +26,7 @@ git:
   remote: ""
   branch: main
 
-shell: %s
+shell: %[1]s
 
 conflict_resolution:
   aliases: last_wins
@@ -51,6 +51,8 @@ updates:
 
 shell_integration:
   auto_reload_enabled: false
+  shells: [%[1]s]
+  login_env: []
 
 ui:
   color: auto
@@ -179,3 +179,49 @@ func (h *HistoryManager) enforceQuota(protectedPath string) error {
 
 	return nil
 }
+
+// mergeBasePath returns where the last linked source content for target is kept.
+func (h *HistoryManager) mergeBasePath(target string) string {
+	sum := sha256.Sum256([]byte(target))
+	return filepath.Join(h.Dir, "merge-base", hex.EncodeToString(sum[:]))
+}
+
+// RecordMergeBase stores the content of source as the merge base for target.
+// Non-regular sources (such as directories) clear any previous base.
+func (h *HistoryManager) RecordMergeBase(target, source string) error {
+	basePath := h.mergeBasePath(target)
+	info, err := os.Stat(source)
+	if err != nil || !info.Mode().IsRegular() {
+		if rmErr := os.Remove(basePath); rmErr != nil && !os.IsNotExist(rmErr) {
+			return fmt.Errorf("clearing merge base: %w", rmErr)
+		}
+		return nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("reading merge base source: %w", err)
+	}
+	if existing, err := os.ReadFile(basePath); err == nil && string(existing) == string(data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(basePath), 0755); err != nil {
+		return fmt.Errorf("create merge base directory: %w", err)
+	}
+	if err := os.WriteFile(basePath, data, 0600); err != nil {
+		return fmt.Errorf("writing merge base: %w", err)
+	}
+	return nil
+}
+
+// MergeBase returns the recorded merge base for target, if any.
+func (h *HistoryManager) MergeBase(target string) (string, bool, error) {
+	data, err := os.ReadFile(h.mergeBasePath(target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("reading merge base: %w", err)
+	}
+	return string(data), true, nil
+}
@@ -7,16 +7,62 @@ import (
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
 	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 )
 
 // Linker handles the creation and management of symlinks for dotfiles.
 type Linker struct {
 	// ConflictStrategy determines how to handle existing files at the target location.
-	// Options: "backup_and_replace", "replace", "error", "prompt" (prompt not implemented here, assumed resolved upstream)
+	// Options: "backup_and_replace", "replace", "error", "merge", "prompt".
 	ConflictStrategy string
 
 	history           *HistoryManager
+	prompter          ConflictPrompter
 	conflictSnapshots map[string]*Snapshot
+	conflictOutcomes  map[string]*ConflictOutcome
+}
+
+// Conflict choices returned by a ConflictPrompter.
+const (
+	ConflictKeep    = "keep"
+	ConflictReplace = "replace"
+	ConflictMerge   = "merge"
+)
+
+// Conflict outcome actions reported by ConsumeConflictOutcome.
+const (
+	ConflictActionKept          = "kept"
+	ConflictActionReplaced      = "replaced"
+	ConflictActionBackedUp      = "backed_up"
+	ConflictActionMerged        = "merged"
+	ConflictActionMergeConflict = "merge_conflict"
+)
+
+// ConflictInfo describes an existing target that blocks a link.
+type ConflictInfo struct {
+	Target string
+	Source string
+	// Mergeable is true when both target and source are regular files.
+	Mergeable bool
+	// HasBase is true when a merge base from a previous link is available.
+	HasBase bool
+}
+
+// ConflictPrompter asks the user how to resolve a conflict. It returns one of
+// ConflictKeep, ConflictReplace or ConflictMerge.
+type ConflictPrompter func(info ConflictInfo) (string, error)
+
+// ConflictOutcome records how a conflicting target was resolved.
+type ConflictOutcome struct {
+	Action string
+	// MergeFile holds the conflict-marked result when Action is merge_conflict.
+	MergeFile string
+	Conflicts int
+	// SourceUpdated is true when a clean merge rewrote the repo source.
+	SourceUpdated bool
+	// Warning explains a fallback, e.g. a merge that could not run and
+	// backed up the target instead.
+	Warning string
 }
 
 // NewLinker creates a new Linker with the given conflict strategy.
@@ -24,6 +70,7 @@ func NewLinker(strategy string) *Linker {
 	return &Linker{
 		ConflictStrategy:  strategy,
 		conflictSnapshots: make(map[string]*Snapshot),
+		conflictOutcomes:  make(map[string]*ConflictOutcome),
 	}
 }
 
@@ -39,6 +86,19 @@ func (l *Linker) ConsumeConflictSnapshot(target string) *Snapshot {
 	return s
 }
 
+// SetConflictPrompter configures the callback used by the "prompt" strategy.
+func (l *Linker) SetConflictPrompter(prompter ConflictPrompter) {
+	l.prompter = prompter
+}
+
+// ConsumeConflictOutcome returns and clears the conflict outcome for target.
+// It returns nil when linking target did not hit a conflict.
+func (l *Linker) ConsumeConflictOutcome(target string) *ConflictOutcome {
+	o := l.conflictOutcomes[target]
+	delete(l.conflictOutcomes, target)
+	return o
+}
+
 // Link processes a single dotfile, creating a symlink from target to source.
 // source: path relative to repo root (e.g. "git/.gitconfig")
 // target: absolute path or path relative to home (e.g. "~/.gitconfig")
@@ -75,18 +135,21 @@ func (l *Linker) Link(dotfile apps.Dotfile, gdfDir string) error {
 			}
 			if linkDest == sourcePath {
 				// Already linked correctly
-				return nil
+				return l.recordMergeBase(targetPath, sourcePath)
 			}
 		}
 
 		// 2. Handle conflict
-		snapshot, err := l.handleConflict(targetPath)
+		snapshot, link, err := l.handleConflict(targetPath, sourcePath)
 		if err != nil {
 			return err
 		}
 		if snapshot != nil {
 			l.conflictSnapshots[targetPath] = snapshot
 		}
+		if !link {
+			return nil
+		}
 	} else if !os.IsNotExist(err) {
 		return fmt.Errorf("checking target: %w", err)
 	}
@@ -96,6 +159,18 @@ func (l *Linker) Link(dotfile apps.Dotfile, gdfDir string) error {
 		return fmt.Errorf("creating symlink: %w", err)
 	}
 
+	return l.recordMergeBase(targetPath, sourcePath)
+}
+
+// recordMergeBase remembers the linked source content so a later "merge"
+// conflict can tell which side changed.
+func (l *Linker) recordMergeBase(targetPath, sourcePath string) error {
+	if l.history == nil {
+		return nil
+	}
+	if err := l.history.RecordMergeBase(targetPath, sourcePath); err != nil {
+		return fmt.Errorf("recording merge base for %s: %w", targetPath, err)
+	}
 	return nil
 }
 
@@ -155,59 +230,188 @@ func resolveSymlinkDestination(targetPath string) (string, error) {
 	return filepath.Clean(filepath.Join(filepath.Dir(targetPath), dest)), nil
 }
 
-func (l *Linker) handleConflict(path string) (*Snapshot, error) {
-	var snapshot *Snapshot
-
+// handleConflict resolves an existing target according to the strategy. It
+// returns false when the target was left in place and must not be linked.
+func (l *Linker) handleConflict(path, sourcePath string) (*Snapshot, bool, error) {
 	switch l.ConflictStrategy {
 	case "error":
-		return nil, fmt.Errorf("target already exists: %s", path)
+		return nil, false, fmt.Errorf("target already exists: %s", path)
 	case "replace", "force":
 		s, err := l.captureSnapshot(path)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		snapshot = s
 		if err := os.RemoveAll(path); err != nil {
-			return nil, fmt.Errorf("removing existing target: %w", err)
+			return nil, false, fmt.Errorf("removing existing target: %w", err)
 		}
+		l.conflictOutcomes[path] = &ConflictOutcome{Action: ConflictActionReplaced}
+		return s, true, nil
 	case "backup_and_replace":
-		s, err := l.captureSnapshot(path)
+		s, err := l.backupAndReplace(path)
+		if err != nil {
+			return nil, false, err
+		}
+		return s, true, nil
+	case "merge":
+		return l.mergeConflict(path, sourcePath)
+	case "prompt":
+		return l.promptConflict(path, sourcePath)
+	default:
+		// Default to error for safety
+		return nil, false, fmt.Errorf("unknown conflict strategy '%s', defaulting to error: file exists %s", l.ConflictStrategy, path)
+	}
+}
+
+func (l *Linker) backupAndReplace(path string) (*Snapshot, error) {
+	// Directories are not snapshotted; the .gdf.bak rename keeps them.
+	var snapshot *Snapshot
+	if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+		snapshot, err = l.captureSnapshot(path)
 		if err != nil {
 			return nil, err
 		}
-		snapshot = s
+	}
 
-		// Cycle backups: .bak -> .bak.1 -> .bak.2 -> .bak.3 (keep last 3)
-		const maxBackups = 3
-		for i := maxBackups - 1; i >= 0; i-- {
-			var oldPath, newPath string
-			if i == 0 {
-				oldPath = path + ".gdf.bak"
+	// Cycle backups: .bak -> .bak.1 -> .bak.2 -> .bak.3 (keep last 3)
+	const maxBackups = 3
+	for i := maxBackups - 1; i >= 0; i-- {
+		var oldPath, newPath string
+		if i == 0 {
+			oldPath = path + ".gdf.bak"
+		} else {
+			oldPath = fmt.Sprintf("%s.gdf.bak.%d", path, i)
+		}
+		newPath = fmt.Sprintf("%s.gdf.bak.%d", path, i+1)
+
+		if _, err := os.Stat(oldPath); err == nil {
+			if i == maxBackups-1 {
+				// Remove oldest backup
+				_ = os.Remove(oldPath)
 			} else {
-				oldPath = fmt.Sprintf("%s.gdf.bak.%d", path, i)
-			}
-			newPath = fmt.Sprintf("%s.gdf.bak.%d", path, i+1)
-
-			if _, err := os.Stat(oldPath); err == nil {
-				if i == maxBackups-1 {
-					// Remove oldest backup
-					_ = os.Remove(oldPath)
-				} else {
-					// Rename to next number
-					_ = os.Rename(oldPath, newPath)
-				}
+				// Rename to next number
+				_ = os.Rename(oldPath, newPath)
 			}
 		}
-		// Move current file to .bak
-		backupPath := path + ".gdf.bak"
-		if err := os.Rename(path, backupPath); err != nil {
-			return nil, fmt.Errorf("backing up existing target: %w", err)
+	}
+	// Move current file to .bak
+	backupPath := path + ".gdf.bak"
+	if err := os.Rename(path, backupPath); err != nil {
+		return nil, fmt.Errorf("backing up existing target: %w", err)
+	}
+	l.conflictOutcomes[path] = &ConflictOutcome{Action: ConflictActionBackedUp}
+	return snapshot, nil
+}
+
+func (l *Linker) promptConflict(path, sourcePath string) (*Snapshot, bool, error) {
+	if l.prompter == nil {
+		return nil, false, fmt.Errorf("target already exists: %s (prompt strategy requires interactive input)", path)
+	}
+	info := ConflictInfo{
+		Target:    path,
+		Source:    sourcePath,
+		Mergeable: isRegularFile(path) && isRegularFile(sourcePath),
+	}
+	if l.history != nil {
+		if _, ok, err := l.history.MergeBase(path); err == nil {
+			info.HasBase = ok
+		}
+	}
+
+	choice, err := l.prompter(info)
+	if err != nil {
+		return nil, false, err
+	}
+	switch choice {
+	case ConflictKeep:
+		l.conflictOutcomes[path] = &ConflictOutcome{Action: ConflictActionKept}
+		return nil, false, nil
+	case ConflictReplace:
+		s, err := l.backupAndReplace(path)
+		if err != nil {
+			return nil, false, err
 		}
+		return s, true, nil
+	case ConflictMerge:
+		return l.mergeConflict(path, sourcePath)
 	default:
-		// Default to error for safety
-		return nil, fmt.Errorf("unknown conflict strategy '%s', defaulting to error: file exists %s", l.ConflictStrategy, path)
+		return nil, false, fmt.Errorf("unknown conflict choice %q for %s", choice, path)
 	}
-	return snapshot, nil
+}
+
+// mergeConflict three-way merges the target into the repo source using the
+// content recorded at the previous link as the base. A clean merge updates
+// the source and replaces the target with the link; otherwise the merged
+// result with conflict markers is written next to the target, which is kept.
+// When either side is not a regular file (a symlink or directory) there is
+// nothing to merge, so the target is backed up and replaced with a warning.
+func (l *Linker) mergeConflict(path, sourcePath string) (*Snapshot, bool, error) {
+	if !isRegularFile(path) || !isRegularFile(sourcePath) {
+		s, err := l.backupAndReplace(path)
+		if err != nil {
+			return nil, false, err
+		}
+		l.conflictOutcomes[path].Warning = "cannot merge: target and source must both be regular files"
+		return s, true, nil
+	}
+	ours, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading target for merge: %w", err)
+	}
+	theirs, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading source for merge: %w", err)
+	}
+
+	base, hasBase := "", false
+	if l.history != nil {
+		base, hasBase, err = l.history.MergeBase(path)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if !hasBase {
+		base = CommonBase(string(ours), string(theirs))
+	}
+
+	result := MergeThreeWay(base, string(ours), string(theirs), "target", "source")
+	if result.Conflicts > 0 {
+		mergePath := path + ".gdf.merge"
+		if err := util.WriteFileAtomic(mergePath, []byte(result.Content), 0644); err != nil {
+			return nil, false, fmt.Errorf("writing merge result: %w", err)
+		}
+		l.conflictOutcomes[path] = &ConflictOutcome{
+			Action:    ConflictActionMergeConflict,
+			MergeFile: mergePath,
+			Conflicts: result.Conflicts,
+		}
+		return nil, false, nil
+	}
+
+	outcome := &ConflictOutcome{Action: ConflictActionMerged}
+	if result.Content != string(theirs) {
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(sourcePath); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := util.WriteFileAtomic(sourcePath, []byte(result.Content), mode); err != nil {
+			return nil, false, fmt.Errorf("writing merged source: %w", err)
+		}
+		outcome.SourceUpdated = true
+	}
+	snapshot, err := l.captureSnapshot(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, false, fmt.Errorf("removing merged target: %w", err)
+	}
+	l.conflictOutcomes[path] = outcome
+	return snapshot, true, nil
+}
+
+func isRegularFile(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.Mode().IsRegular()
 }
 
 func (l *Linker) captureSnapshot(path string) (*Snapshot, error) {
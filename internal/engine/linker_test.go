@@ -293,3 +293,241 @@ func TestLinker_UnlinkManaged_SkipsUnmanagedSymlink(t *testing.T) {
 func prettify(format string, args ...interface{}) error {
 	return filepath.ErrBadPattern // just a dummy error type for simplicity in test helper
 }
+
+func TestLinker_MergeStrategy(t *testing.T) {
+	tests := []struct {
+		name       string
+		base       string
+		source     string
+		target     string
+		wantAction string
+		wantSource string
+		wantLinked bool
+	}{
+		{
+			name:       "clean merge updates source and links",
+			base:       "a\nb\nc\n",
+			source:     "a\nb\nc\nd\n",
+			target:     "A\nb\nc\n",
+			wantAction: ConflictActionMerged,
+			wantSource: "A\nb\nc\nd\n",
+			wantLinked: true,
+		},
+		{
+			name:       "conflicting edits keep target",
+			base:       "a\nb\nc\n",
+			source:     "a\nsource\nc\n",
+			target:     "a\ntarget\nc\n",
+			wantAction: ConflictActionMergeConflict,
+			wantSource: "a\nsource\nc\n",
+			wantLinked: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			gdfDir := filepath.Join(tmpDir, ".gdf")
+			homeDir := filepath.Join(tmpDir, "home")
+			t.Setenv("HOME", homeDir)
+			if err := os.MkdirAll(filepath.Join(gdfDir, "dotfiles", "app"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(homeDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			sourceFile := filepath.Join(gdfDir, "dotfiles", "app", "config")
+			target := filepath.Join(homeDir, ".config")
+			dotfile := apps.Dotfile{Source: "app/config", Target: "~/.config"}
+
+			l := NewLinker("merge")
+			l.SetHistoryManager(NewHistoryManager(gdfDir, 512))
+
+			// Link once to record the merge base, then diverge both sides.
+			if err := os.WriteFile(sourceFile, []byte(tt.base), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := l.Link(dotfile, gdfDir); err != nil {
+				t.Fatalf("initial Link() error = %v", err)
+			}
+			if err := os.Remove(target); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(target, []byte(tt.target), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(sourceFile, []byte(tt.source), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := l.Link(dotfile, gdfDir); err != nil {
+				t.Fatalf("Link() error = %v", err)
+			}
+			outcome := l.ConsumeConflictOutcome(target)
+			if outcome == nil || outcome.Action != tt.wantAction {
+				t.Fatalf("outcome = %#v, want action %q", outcome, tt.wantAction)
+			}
+			got, err := os.ReadFile(sourceFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.wantSource {
+				t.Errorf("source = %q, want %q", string(got), tt.wantSource)
+			}
+			info, err := os.Lstat(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if linked := info.Mode()&os.ModeSymlink != 0; linked != tt.wantLinked {
+				t.Errorf("target linked = %v, want %v", linked, tt.wantLinked)
+			}
+			if !tt.wantLinked {
+				if _, err := os.Stat(outcome.MergeFile); err != nil {
+					t.Errorf("expected merge file %s: %v", outcome.MergeFile, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLinker_PromptStrategy(t *testing.T) {
+	tests := []struct {
+		choice     string
+		wantAction string
+		wantLinked bool
+	}{
+		{ConflictKeep, ConflictActionKept, false},
+		{ConflictReplace, ConflictActionBackedUp, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.choice, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			gdfDir := filepath.Join(tmpDir, ".gdf")
+			homeDir := filepath.Join(tmpDir, "home")
+			t.Setenv("HOME", homeDir)
+			if err := os.MkdirAll(filepath.Join(gdfDir, "dotfiles", "app"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(homeDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(gdfDir, "dotfiles", "app", "config"), []byte("source"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			target := filepath.Join(homeDir, ".config")
+			if err := os.WriteFile(target, []byte("target"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			l := NewLinker("prompt")
+			var asked ConflictInfo
+			l.SetConflictPrompter(func(info ConflictInfo) (string, error) {
+				asked = info
+				return tt.choice, nil
+			})
+			if err := l.Link(apps.Dotfile{Source: "app/config", Target: "~/.config"}, gdfDir); err != nil {
+				t.Fatalf("Link() error = %v", err)
+			}
+			if asked.Target != target || !asked.Mergeable {
+				t.Errorf("prompter info = %#v", asked)
+			}
+			outcome := l.ConsumeConflictOutcome(target)
+			if outcome == nil || outcome.Action != tt.wantAction {
+				t.Fatalf("outcome = %#v, want action %q", outcome, tt.wantAction)
+			}
+			info, err := os.Lstat(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if linked := info.Mode()&os.ModeSymlink != 0; linked != tt.wantLinked {
+				t.Errorf("target linked = %v, want %v", linked, tt.wantLinked)
+			}
+		})
+	}
+}
+
+func TestLinker_PromptStrategyWithoutPrompter(t *testing.T) {
+	tmpDir := t.TempDir()
+	gdfDir := filepath.Join(tmpDir, ".gdf")
+	homeDir := filepath.Join(tmpDir, "home")
+	t.Setenv("HOME", homeDir)
+	if err := os.MkdirAll(filepath.Join(gdfDir, "dotfiles", "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gdfDir, "dotfiles", "app", "config"), []byte("source"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".config"), []byte("target"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLinker("prompt")
+	if err := l.Link(apps.Dotfile{Source: "app/config", Target: "~/.config"}, gdfDir); err == nil {
+		t.Fatal("expected error when prompt strategy has no prompter")
+	}
+}
+
+func TestLinker_MergeStrategyFallsBackToBackup(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func(t *testing.T, target, other string)
+	}{
+		{
+			name: "directory target",
+			create: func(t *testing.T, target, _ string) {
+				if err := os.MkdirAll(filepath.Join(target, "nested"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "symlink target",
+			create: func(t *testing.T, target, other string) {
+				if err := os.WriteFile(other, []byte("other"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(other, target); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			gdfDir := filepath.Join(tmpDir, ".gdf")
+			homeDir := filepath.Join(tmpDir, "home")
+			t.Setenv("HOME", homeDir)
+			if err := os.MkdirAll(filepath.Join(gdfDir, "dotfiles", "app"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(homeDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(gdfDir, "dotfiles", "app", "config"), []byte("source"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			target := filepath.Join(homeDir, ".config")
+			tt.create(t, target, filepath.Join(tmpDir, "other"))
+
+			l := NewLinker("merge")
+			l.SetHistoryManager(NewHistoryManager(gdfDir, 512))
+			if err := l.Link(apps.Dotfile{Source: "app/config", Target: "~/.config"}, gdfDir); err != nil {
+				t.Fatalf("Link() error = %v", err)
+			}
+			outcome := l.ConsumeConflictOutcome(target)
+			if outcome == nil || outcome.Action != ConflictActionBackedUp || outcome.Warning == "" {
+				t.Fatalf("outcome = %#v, want backed_up with a warning", outcome)
+			}
+			if _, err := os.Lstat(target + ".gdf.bak"); err != nil {
+				t.Errorf("expected backup of the original target: %v", err)
+			}
+			info, err := os.Lstat(target)
+			if err != nil || info.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("target is not linked: %v", err)
+			}
+		})
+	}
+}
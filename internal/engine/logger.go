@@ -10,7 +10,7 @@ import (
 
 // Operation represents a single operation performed during apply.
 type Operation struct {
-	Type      string            `json:"type"`      // "link", "package_install", "hook_run", "shell_generate"
+	Type      string            `json:"type"`      // "link", "package_install", "hook_run", "shell_generate", "login_env"
 	Timestamp time.Time         `json:"timestamp"` // When the operation was performed
 	Target    string            `json:"target"`    // What was affected (e.g., symlink path, package name)
 	Details   map[string]string `json:"details"`   // Additional context
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+// MergeResult is the outcome of a three-way text merge.
+type MergeResult struct {
+	Content   string
+	Conflicts int
+}
+
+// mergeChange replaces base lines [start, end) with lines.
+type mergeChange struct {
+	start int
+	end   int
+	lines []string
+}
+
+// MergeThreeWay merges ours and theirs, which both derive from base.
+// Regions changed on only one side (or identically on both) are merged
+// cleanly; regions changed differently on both sides are emitted with
+// conflict markers labelled oursLabel and theirsLabel.
+func MergeThreeWay(base, ours, theirs, oursLabel, theirsLabel string) MergeResult {
+	baseLines := util.SplitLinesKeepEnds(base)
+	oursChanges := mergeChanges(baseLines, util.SplitLinesKeepEnds(ours))
+	theirsChanges := mergeChanges(baseLines, util.SplitLinesKeepEnds(theirs))
+
+	var out strings.Builder
+	conflicts := 0
+	pos := 0
+	oi, ti := 0, 0
+	for oi < len(oursChanges) || ti < len(theirsChanges) {
+		// Start a cluster at the earliest pending change and grow it while
+		// the next change on either side touches it.
+		start, end := -1, -1
+		var clusterOurs, clusterTheirs []mergeChange
+		take := func(c mergeChange, fromOurs bool) {
+			if start < 0 || c.start < start {
+				start = c.start
+			}
+			if c.end > end {
+				end = c.end
+			}
+			if fromOurs {
+				clusterOurs = append(clusterOurs, c)
+			} else {
+				clusterTheirs = append(clusterTheirs, c)
+			}
+		}
+		if ti >= len(theirsChanges) || (oi < len(oursChanges) && oursChanges[oi].start <= theirsChanges[ti].start) {
+			take(oursChanges[oi], true)
+			oi++
+		} else {
+			take(theirsChanges[ti], false)
+			ti++
+		}
+		for {
+			if oi < len(oursChanges) && oursChanges[oi].start <= end {
+				take(oursChanges[oi], true)
+				oi++
+				continue
+			}
+			if ti < len(theirsChanges) && theirsChanges[ti].start <= end {
+				take(theirsChanges[ti], false)
+				ti++
+				continue
+			}
+			break
+		}
+
+		out.WriteString(strings.Join(baseLines[pos:start], ""))
+		oursText := applyMergeChanges(baseLines, start, end, clusterOurs)
+		theirsText := applyMergeChanges(baseLines, start, end, clusterTheirs)
+		switch {
+		case len(clusterTheirs) == 0, oursText == theirsText:
+			out.WriteString(oursText)
+		case len(clusterOurs) == 0:
+			out.WriteString(theirsText)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + oursLabel + "\n")
+			out.WriteString(withTrailingNewline(oursText))
+			out.WriteString("=======\n")
+			out.WriteString(withTrailingNewline(theirsText))
+			out.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+		pos = end
+	}
+	out.WriteString(strings.Join(baseLines[pos:], ""))
+
+	return MergeResult{Content: out.String(), Conflicts: conflicts}
+}
+
+// CommonBase approximates a merge base for two versions without history by
+// keeping only the lines they share, in order.
+func CommonBase(a, b string) string {
+	aLines := util.SplitLinesKeepEnds(a)
+	matcher := difflib.NewMatcher(aLines, util.SplitLinesKeepEnds(b))
+	var out strings.Builder
+	for _, m := range matcher.GetMatchingBlocks() {
+		out.WriteString(strings.Join(aLines[m.A:m.A+m.Size], ""))
+	}
+	return out.String()
+}
+
+func mergeChanges(base, other []string) []mergeChange {
+	matcher := difflib.NewMatcher(base, other)
+	var changes []mergeChange
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		changes = append(changes, mergeChange{start: op.I1, end: op.I2, lines: other[op.J1:op.J2]})
+	}
+	return changes
+}
+
+// applyMergeChanges renders base[start:end] with the given side's changes applied.
+func applyMergeChanges(base []string, start, end int, changes []mergeChange) string {
+	var out strings.Builder
+	pos := start
+	for _, c := range changes {
+		out.WriteString(strings.Join(base[pos:c.start], ""))
+		out.WriteString(strings.Join(c.lines, ""))
+		pos = c.end
+	}
+	out.WriteString(strings.Join(base[pos:end], ""))
+	return out.String()
+}
+
+func withTrailingNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,65 @@
+package engine
+
+import "testing"
+
+func TestMergeThreeWay(t *testing.T) {
+	base := "a\nb\nc\nd\n"
+
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "only ours changed",
+			ours:   "a\nB\nc\nd\n",
+			theirs: base,
+			want:   "a\nB\nc\nd\n",
+		},
+		{
+			name:   "only theirs changed",
+			ours:   base,
+			theirs: "a\nb\nc\nd\ne\n",
+			want:   "a\nb\nc\nd\ne\n",
+		},
+		{
+			name:   "disjoint changes on both sides",
+			ours:   "A\nb\nc\nd\n",
+			theirs: "a\nb\nc\nD\n",
+			want:   "A\nb\nc\nD\n",
+		},
+		{
+			name:   "identical change on both sides",
+			ours:   "a\nX\nc\nd\n",
+			theirs: "a\nX\nc\nd\n",
+			want:   "a\nX\nc\nd\n",
+		},
+		{
+			name:      "overlapping change conflicts",
+			ours:      "a\nours\nc\nd\n",
+			theirs:    "a\ntheirs\nc\nd\n",
+			want:      "a\n<<<<<<< target\nours\n=======\ntheirs\n>>>>>>> source\nc\nd\n",
+			conflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeThreeWay(base, tt.ours, tt.theirs, "target", "source")
+			if got.Content != tt.want {
+				t.Errorf("Content = %q, want %q", got.Content, tt.want)
+			}
+			if got.Conflicts != tt.conflicts {
+				t.Errorf("Conflicts = %d, want %d", got.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestCommonBase(t *testing.T) {
+	got := CommonBase("a\nx\nb\n", "a\nb\ny\n")
+	if got != "a\nb\n" {
+		t.Fatalf("CommonBase() = %q, want %q", got, "a\nb\n")
+	}
+}
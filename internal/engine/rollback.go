@@ -146,11 +146,42 @@ func RollbackOperations(gdfDir string, ops []Operation, selector func(target str
 			} else {
 				result.Removed++
 			}
+		case "login_env", "rc_inject":
+			restored, err := rollbackGeneratedFile(op)
+			if err != nil {
+				result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", op.Target, err))
+				continue
+			}
+			if restored {
+				result.Restored++
+			} else {
+				result.Removed++
+			}
 		}
 	}
 	return result
 }
 
+// rollbackGeneratedFile undoes a write or removal of a file GDF generated
+// outside a symlink: the snapshot is restored when one was taken, otherwise
+// the file did not exist before and is removed.
+func rollbackGeneratedFile(op Operation) (bool, error) {
+	if op.Details == nil || op.Details["snapshot_path"] == "" {
+		if err := os.Remove(op.Target); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		return false, nil
+	}
+	candidate := &SnapshotCandidate{
+		Target:       op.Target,
+		SnapshotPath: op.Details["snapshot_path"],
+		SnapshotKind: op.Details["snapshot_kind"],
+		SnapshotMode: op.Details["snapshot_mode"],
+		LinkTarget:   op.Details["snapshot_link_target"],
+	}
+	return true, restoreSnapshot(op.Target, candidate)
+}
+
 func rollbackLink(gdfDir string, op Operation, selector func(target string, candidates []SnapshotCandidate) (*SnapshotCandidate, error)) error {
 	if op.Details == nil {
 		return removeSymlinkIfManaged(op.Target, "")
@@ -128,3 +128,42 @@ func TestFindSnapshotCandidates(t *testing.T) {
 		t.Fatalf("expected newest snapshot first, got %s", candidates[0].SnapshotPath)
 	}
 }
+
+func TestRollbackOperationsLoginEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	created := filepath.Join(tmpDir, "environment.d", "60-gdf.conf")
+	replaced := filepath.Join(tmpDir, "env.sh")
+	for _, path := range []string{created, replaced} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshotPath := filepath.Join(tmpDir, "snap")
+	if err := os.WriteFile(snapshotPath, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := []Operation{
+		{Type: "login_env", Target: created, Details: map[string]string{"target": "systemd", "action": "write"}},
+		{Type: "login_env", Target: replaced, Details: map[string]string{
+			"target":        "profile",
+			"action":        "write",
+			"snapshot_path": snapshotPath,
+			"snapshot_kind": "file",
+			"snapshot_mode": "0644",
+		}},
+	}
+	res := RollbackOperations(tmpDir, ops, nil)
+	if len(res.Failed) > 0 || res.Restored != 1 || res.Removed != 1 {
+		t.Fatalf("RollbackOperations() = %#v, want 1 restored and 1 removed", res)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("created file still exists: %v", err)
+	}
+	if data, _ := os.ReadFile(replaced); string(data) != "old" {
+		t.Errorf("replaced file = %q, want snapshot content", data)
+	}
+}
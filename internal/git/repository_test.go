@@ -108,6 +108,37 @@ func TestRepository_AddCommit(t *testing.T) {
 	}
 }
 
+func TestRepository_CommitPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	repo, err := Init(tmpDir)
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	configureGitUser(t, tmpDir)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	if err := repo.Add("a.txt", "b.txt"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if err := repo.CommitPaths("commit a only", "a.txt"); err != nil {
+		t.Fatalf("CommitPaths() error = %v", err)
+	}
+
+	status, err := repo.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status != "A  b.txt\n" {
+		t.Errorf("Status() = %q, want only b.txt staged", status)
+	}
+}
+
 func TestRepository_HasChanges(t *testing.T) {
 	tmpDir := t.TempDir()
 
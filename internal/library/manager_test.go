@@ -192,7 +192,7 @@ func TestFDRecipeShellCompatibility(t *testing.T) {
 	if recipe.Shell == nil {
 		t.Fatalf("recipe %q missing shell config", "fd")
 	}
-	if recipe.Shell.Aliases["fd"] != "" {
+	if recipe.Shell.Aliases["fd"].String() != "" {
 		t.Fatalf("recipe %q should not define an unconditional fd alias", "fd")
 	}
 	if !hasInitSnippet(recipe, "fd-find-compat-alias") {
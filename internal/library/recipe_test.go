@@ -71,7 +71,7 @@ func TestRecipe_ToBundle(t *testing.T) {
 		Name:        "git",
 		Description: "Version control",
 		Package: &apps.Package{
-			Brew: "git",
+			Brew: apps.BrewFormula("git"),
 		},
 	}
 
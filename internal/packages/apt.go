@@ -2,6 +2,7 @@ package packages
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -12,12 +13,24 @@ import (
 type Apt struct {
 	// execCommand allows mocking in tests
 	execCommand func(string, ...string) *exec.Cmd
+
+	// keyringDir, sourcesDir and osRelease locate the system files read and
+	// written by repository setup; tests point them at temporary paths.
+	keyringDir string
+	sourcesDir string
+	osRelease  string
+
+	// allowDowngrades lets a pinned install replace a newer installed version.
+	allowDowngrades bool
 }
 
 // NewApt creates a new Apt package manager.
 func NewApt() *Apt {
 	return &Apt{
 		execCommand: exec.Command,
+		keyringDir:  aptKeyringDir,
+		sourcesDir:  aptSourcesDir,
+		osRelease:   osReleasePath,
 	}
 }
 
@@ -33,7 +46,7 @@ func (a *Apt) Install(pkg string) error {
 	}
 
 	// Use sudo apt-get install -y
-	cmd := execCmd("sudo", "apt-get", "install", "-y", pkg)
+	cmd := execCmd("sudo", a.installArgs([]string{pkg})...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to install %s via apt: %w\nOutput: %s", pkg, err, string(output))
@@ -42,6 +55,43 @@ func (a *Apt) Install(pkg string) error {
 	return nil
 }
 
+// InstallBatch installs packages with a single apt-get install call.
+func (a *Apt) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, func(names []string) error {
+		execCmd := a.execCommand
+		if execCmd == nil {
+			execCmd = exec.Command
+		}
+		return execCmd("sudo", a.installArgs(names)...).Run()
+	}, a.Install)
+}
+
+// SetAllowDowngrades lets pinned installs (name=version) downgrade an
+// installed package. Without it apt refuses a pinned version that is older
+// than the installed one.
+func (a *Apt) SetAllowDowngrades(allow bool) {
+	a.allowDowngrades = allow
+}
+
+// installArgs returns the apt-get arguments that install pkgs.
+func (a *Apt) installArgs(pkgs []string) []string {
+	args := []string{"apt-get", "install", "-y"}
+	if a.allowDowngrades {
+		for _, pkg := range pkgs {
+			if strings.Contains(pkg, "=") {
+				args = append(args, "--allow-downgrades")
+				break
+			}
+		}
+	}
+	return append(args, pkgs...)
+}
+
+// PinVersion returns the apt-get argument that installs version of pkg.
+func (a *Apt) PinVersion(pkg, version string) string {
+	return pkg + "=" + version
+}
+
 // Uninstall removes a package using apt-get.
 func (a *Apt) Uninstall(pkg string) error {
 	if pkg == "" {
@@ -64,6 +114,17 @@ func (a *Apt) Uninstall(pkg string) error {
 
 // InstallWithRepo installs a package with optional repository and key setup.
 func (a *Apt) InstallWithRepo(aptPkg *apps.AptPackage) error {
+	if err := a.AddRepo(aptPkg); err != nil {
+		return err
+	}
+	return a.Install(aptPkg.Name)
+}
+
+// AddRepo sets up the repository and key of aptPkg, if any. The key is
+// stored in its own keyring and the repository is written as a deb822
+// .sources file that trusts only that keyring; package lists are refreshed
+// only when either file changed.
+func (a *Apt) AddRepo(aptPkg *apps.AptPackage) error {
 	if aptPkg == nil {
 		return fmt.Errorf("apt package configuration cannot be nil")
 	}
@@ -77,33 +138,45 @@ func (a *Apt) InstallWithRepo(aptPkg *apps.AptPackage) error {
 		execCmd = exec.Command
 	}
 
-	// Add GPG key if specified
-	if aptPkg.Key != "" {
-		cmd := execCmd("sh", "-c", fmt.Sprintf("curl -fsSL %s | sudo apt-key add -", aptPkg.Key))
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("failed to add GPG key: %w\nOutput: %s", err, string(output))
-		}
-	}
-
-	// Add repository if specified
-	if aptPkg.Repo != "" {
+	switch {
+	case strings.HasPrefix(aptPkg.Repo, "ppa:"):
+		// add-apt-repository fetches PPA keys from Launchpad itself.
 		cmd := execCmd("sudo", "add-apt-repository", "-y", aptPkg.Repo)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("failed to add repository: %w\nOutput: %s", err, string(output))
 		}
-
-		// Update package lists after adding repo
-		cmd = execCmd("sudo", "apt-get", "update")
-		output, err = cmd.CombinedOutput()
+		if err := a.Refresh(); err != nil {
+			return err
+		}
+	case aptPkg.Repo != "":
+		changed, err := a.setupRepo(aptPkg)
 		if err != nil {
-			return fmt.Errorf("failed to update package lists: %w\nOutput: %s", err, string(output))
+			return err
+		}
+		if changed {
+			if err := a.Refresh(); err != nil {
+				return err
+			}
 		}
+	case aptPkg.Key != "":
+		return fmt.Errorf("apt key %s needs a repo: keys are trusted only for their repository", aptPkg.Key)
 	}
+	return nil
+}
 
-	// Install the package
-	return a.Install(aptPkg.Name)
+// Refresh updates the package lists.
+func (a *Apt) Refresh() error {
+	execCmd := a.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	cmd := execCmd("sudo", "apt-get", "update")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to update package lists: %w\nOutput: %s", err, string(output))
+	}
+	return nil
 }
 
 // IsInstalled checks if a package is installed via apt.
@@ -136,6 +209,117 @@ func (a *Apt) IsInstalled(pkg string) (bool, error) {
 	return false, nil
 }
 
+// InstalledVersion returns the installed version of a package, or "" if it
+// is not installed.
+func (a *Apt) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := a.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("dpkg-query", "-W", "-f=${Status}\t${Version}", pkg).Output()
+	if err != nil {
+		// dpkg-query exits 1 for packages it does not know
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read installed version of %s: %w", pkg, err)
+	}
+	status, version, _ := strings.Cut(string(output), "\t")
+	// Removed packages keep their config files with status "deinstall ok config-files".
+	if !strings.HasSuffix(status, " installed") {
+		return "", nil
+	}
+	return strings.TrimSpace(version), nil
+}
+
+// Outdated returns the installed packages whose apt candidate version is
+// newer than the installed one, compared with dpkg --compare-versions. A
+// package installed at a newer version than the candidate (from another
+// repository or a local .deb) is not outdated. Candidates come from the
+// package lists of the last Refresh.
+func (a *Apt) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	execCmd := a.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	cmd := execCmd("apt-cache", append([]string{"policy"}, pkgs...)...)
+	// The policy labels are translated in other locales.
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for apt upgrades: %w", err)
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range parseAptPolicy(string(output)) {
+		err := execCmd("dpkg", "--compare-versions", pkg.Available, "gt", pkg.Current).Run()
+		if err == nil {
+			outdated = append(outdated, pkg)
+			continue
+		}
+		// dpkg --compare-versions exits 1 when the relation is false.
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("failed to compare apt versions of %s: %w", pkg.Name, err)
+		}
+	}
+	return outdated, nil
+}
+
+// parseAptPolicy reads the Installed and Candidate versions of each package
+// in apt-cache policy output and returns those where they differ.
+func parseAptPolicy(output string) []OutdatedPackage {
+	var outdated []OutdatedPackage
+	var current OutdatedPackage
+	flush := func() {
+		if current.Name != "" && current.Current != "" && current.Current != "(none)" &&
+			current.Available != "" && current.Available != "(none)" && current.Current != current.Available {
+			outdated = append(outdated, current)
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case line != "" && !strings.HasPrefix(line, " ") && strings.HasSuffix(line, ":"):
+			flush()
+			current = OutdatedPackage{Name: strings.TrimSuffix(line, ":")}
+		case strings.HasPrefix(trimmed, "Installed:"):
+			current.Current = strings.TrimSpace(strings.TrimPrefix(trimmed, "Installed:"))
+		case strings.HasPrefix(trimmed, "Candidate:"):
+			current.Available = strings.TrimSpace(strings.TrimPrefix(trimmed, "Candidate:"))
+		}
+	}
+	flush()
+	return outdated
+}
+
+// Upgrade upgrades installed packages with apt-get install --only-upgrade,
+// which never installs a package that is missing.
+func (a *Apt) Upgrade(pkgs []string) map[string]error {
+	execCmd := a.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	upgrade := func(names []string) error {
+		args := append([]string{"apt-get", "install", "-y", "--only-upgrade"}, names...)
+		output, err := execCmd("sudo", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via apt: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+		}
+		return nil
+	}
+	return runBatch(pkgs, upgrade, func(name string) error { return upgrade([]string{name}) })
+}
+
 // Name returns the package manager name.
 func (a *Apt) Name() string {
 	return "apt"
@@ -0,0 +1,354 @@
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// System locations used for apt repository setup.
+const (
+	aptKeyringDir = "/etc/apt/keyrings"
+	aptSourcesDir = "/etc/apt/sources.list.d"
+	osReleasePath = "/etc/os-release"
+)
+
+// aptSourceHeader starts every .sources file gdf writes.
+const aptSourceHeader = "# Generated by gdf - DO NOT EDIT MANUALLY\n"
+
+// aptSource is one deb822 source stanza.
+type aptSource struct {
+	URIs          string
+	Suite         string
+	Components    []string
+	Architectures []string
+	SignedBy      string
+}
+
+// deb822 renders the source as the contents of a .sources file.
+func (s aptSource) deb822() string {
+	var out strings.Builder
+	out.WriteString(aptSourceHeader)
+	out.WriteString("Types: deb\n")
+	fmt.Fprintf(&out, "URIs: %s\n", s.URIs)
+	fmt.Fprintf(&out, "Suites: %s\n", s.Suite)
+	// Flat repositories ("./") have no components.
+	if !strings.HasSuffix(s.Suite, "/") && len(s.Components) > 0 {
+		fmt.Fprintf(&out, "Components: %s\n", strings.Join(s.Components, " "))
+	}
+	if len(s.Architectures) > 0 {
+		fmt.Fprintf(&out, "Architectures: %s\n", strings.Join(s.Architectures, " "))
+	}
+	if s.SignedBy != "" {
+		fmt.Fprintf(&out, "Signed-By: %s\n", s.SignedBy)
+	}
+	return out.String()
+}
+
+// parseAptRepo parses a repo as either a URL or a one-line
+// "deb [arch=...] URL suite component..." entry. Options other than arch
+// are dropped: the key is always bound with Signed-By.
+func parseAptRepo(repo string) (aptSource, error) {
+	fields := strings.Fields(repo)
+	if len(fields) == 0 {
+		return aptSource{}, fmt.Errorf("apt repo is empty")
+	}
+	if fields[0] != "deb" {
+		if len(fields) != 1 || !strings.Contains(fields[0], "://") {
+			return aptSource{}, fmt.Errorf("unsupported apt repo %q: expected a URL, a one-line 'deb URL suite component' entry, or ppa:", repo)
+		}
+		return aptSource{URIs: fields[0]}, nil
+	}
+
+	var src aptSource
+	fields = fields[1:]
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "[") {
+		var options []string
+		for len(fields) > 0 {
+			field := fields[0]
+			fields = fields[1:]
+			options = append(options, strings.Trim(field, "[]"))
+			if strings.HasSuffix(field, "]") {
+				break
+			}
+		}
+		for _, opt := range options {
+			if arch, ok := strings.CutPrefix(opt, "arch="); ok {
+				src.Architectures = strings.Split(arch, ",")
+			}
+		}
+	}
+	if len(fields) < 2 {
+		return aptSource{}, fmt.Errorf("unsupported apt repo %q: a 'deb' entry needs a URL and a suite", repo)
+	}
+	src.URIs, src.Suite, src.Components = fields[0], fields[1], fields[2:]
+	return src, nil
+}
+
+// setupRepo writes the keyring and .sources file for aptPkg and reports
+// whether either changed.
+func (a *Apt) setupRepo(aptPkg *apps.AptPackage) (bool, error) {
+	src, err := parseAptRepo(aptPkg.Repo)
+	if err != nil {
+		return false, err
+	}
+	if src.Suite == "" {
+		src.Suite = aptPkg.Suite
+	}
+	if src.Suite == "" {
+		codename, err := a.releaseCodename()
+		if err != nil {
+			return false, err
+		}
+		src.Suite = codename
+	}
+	if len(src.Components) == 0 {
+		src.Components = aptPkg.Components
+	}
+	if len(src.Components) == 0 {
+		src.Components = []string{"main"}
+	}
+
+	keyringDir := a.keyringDir
+	if keyringDir == "" {
+		keyringDir = aptKeyringDir
+	}
+	sourcesDir := a.sourcesDir
+	if sourcesDir == "" {
+		sourcesDir = aptSourcesDir
+	}
+
+	// apt refuses two sources for the same URIs with different Signed-By,
+	// so every app using a repository must share its files.
+	existing, signedBy, err := existingAptSource(sourcesDir, src.URIs)
+	if err != nil {
+		return false, err
+	}
+	name := aptPkg.Keyring
+	switch {
+	case name == "" && existing != "":
+		name = existing
+	case name == "":
+		name = aptRepoName(src.URIs)
+	case existing != "" && existing != name:
+		return false, fmt.Errorf("apt repository %s is already configured in %s.sources; set keyring: %s to share it", src.URIs, existing, existing)
+	}
+
+	changed := false
+	if aptPkg.Key != "" {
+		key, err := a.fetchKey(aptPkg.Key, aptPkg.Fingerprint)
+		if err != nil {
+			return false, err
+		}
+		src.SignedBy = filepath.Join(keyringDir, name+".gpg")
+		keyChanged, err := a.writeSystemFile(src.SignedBy, key)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || keyChanged
+	} else {
+		// An app without a key must not strip the key another app bound
+		// to the shared source.
+		src.SignedBy = signedBy
+	}
+
+	sourcesChanged, err := a.writeSystemFile(filepath.Join(sourcesDir, name+".sources"), []byte(src.deb822()))
+	if err != nil {
+		return false, err
+	}
+	return changed || sourcesChanged, nil
+}
+
+// aptRepoName names the keyring and sources files of a repository: its host
+// and a hash of the URI, so apps installing from one repository share them.
+func aptRepoName(uris string) string {
+	uri := strings.TrimRight(uris, "/")
+	host := uri
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(host))
+	sum := sha256.Sum256([]byte(uri))
+	return host + "-" + hex.EncodeToString(sum[:4])
+}
+
+// existingAptSource returns the name and Signed-By keyring of a .sources
+// file gdf wrote for uris in dir, or "" if there is none.
+func existingAptSource(dir, uris string) (name, signedBy string, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sources"))
+	if err != nil {
+		return "", "", err
+	}
+	want := strings.TrimRight(uris, "/")
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil || !bytes.HasPrefix(data, []byte(aptSourceHeader)) {
+			continue
+		}
+		matched := false
+		signedBy := ""
+		for _, line := range strings.Split(string(data), "\n") {
+			if uri, ok := strings.CutPrefix(line, "URIs: "); ok && strings.TrimRight(uri, "/") == want {
+				matched = true
+			}
+			if keyring, ok := strings.CutPrefix(line, "Signed-By: "); ok {
+				signedBy = keyring
+			}
+		}
+		if matched {
+			return strings.TrimSuffix(filepath.Base(path), ".sources"), signedBy, nil
+		}
+	}
+	return "", "", nil
+}
+
+// fetchKey downloads a repository key, dearmors it if needed, and checks
+// it against fingerprint when one is pinned.
+func (a *Apt) fetchKey(url, fingerprint string) ([]byte, error) {
+	execCmd := a.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gdf-repo-key-")
+	if err != nil {
+		return nil, fmt.Errorf("creating key download directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	keyPath := filepath.Join(tmpDir, "key")
+	if output, err := execCmd("curl", "-fsSL", "-o", keyPath, url).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to download GPG key %s: %w\nOutput: %s", url, err, string(output))
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading GPG key %s: %w", url, err)
+	}
+
+	if bytes.Contains(key, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		binPath := keyPath + ".gpg"
+		if output, err := execCmd("gpg", "--batch", "--yes", "--dearmor", "-o", binPath, keyPath).CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to dearmor GPG key %s: %w\nOutput: %s", url, err, string(output))
+		}
+		if key, err = os.ReadFile(binPath); err != nil {
+			return nil, fmt.Errorf("reading GPG key %s: %w", url, err)
+		}
+		keyPath = binPath
+	}
+
+	if fingerprint != "" {
+		output, err := execCmd("gpg", "--batch", "--show-keys", "--with-colons", "--with-fingerprint", keyPath).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fingerprint of GPG key %s: %w", url, err)
+		}
+		if err := checkKeyFingerprints(output, fingerprint); err != nil {
+			return nil, fmt.Errorf("GPG key %s: %w", url, err)
+		}
+	}
+	return key, nil
+}
+
+// checkKeyFingerprints checks that every primary key in gpg --with-colons
+// output has the pinned fingerprint, so a key file cannot smuggle in
+// additional trusted keys.
+func checkKeyFingerprints(colons []byte, want string) error {
+	want = strings.ToUpper(strings.ReplaceAll(want, " ", ""))
+	var primaries []string
+	afterPub := false
+	for _, line := range strings.Split(string(colons), "\n") {
+		fields := strings.Split(line, ":")
+		switch fields[0] {
+		case "pub":
+			afterPub = true
+		case "fpr":
+			if afterPub && len(fields) > 9 {
+				primaries = append(primaries, strings.ToUpper(fields[9]))
+			}
+			afterPub = false
+		}
+	}
+	if len(primaries) == 0 {
+		return fmt.Errorf("no keys found")
+	}
+	for _, got := range primaries {
+		if got != want {
+			return fmt.Errorf("fingerprint %s does not match pinned fingerprint %s", got, want)
+		}
+	}
+	return nil
+}
+
+// releaseCodename returns the release codename from os-release, preferring
+// UBUNTU_CODENAME so Ubuntu derivatives get Ubuntu repositories.
+func (a *Apt) releaseCodename() (string, error) {
+	path := a.osRelease
+	if path == "" {
+		path = osReleasePath
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s for the apt suite: %w (set suite in the apt package)", path, err)
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok {
+			values[key] = strings.Trim(value, `"'`)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	for _, key := range []string{"UBUNTU_CODENAME", "VERSION_CODENAME"} {
+		if values[key] != "" {
+			return values[key], nil
+		}
+	}
+	return "", fmt.Errorf("no release codename in %s (set suite in the apt package)", path)
+}
+
+// writeSystemFile installs content at path with sudo unless the file
+// already has that content, and reports whether it was written.
+func (a *Apt) writeSystemFile(path string, content []byte) (bool, error) {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+
+	execCmd := a.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	tmp, err := os.CreateTemp("", "gdf-apt-*")
+	if err != nil {
+		return false, fmt.Errorf("creating temporary file for %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("writing temporary file for %s: %w", path, err)
+	}
+	tmp.Close()
+
+	if output, err := execCmd("sudo", "install", "-D", "-m", "0644", tmp.Name(), path).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w\nOutput: %s", path, err, string(output))
+	}
+	return true, nil
+}
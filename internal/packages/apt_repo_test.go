@@ -0,0 +1,365 @@
+package packages
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func TestParseAptRepo(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		want    aptSource
+		wantErr bool
+	}{
+		{
+			name: "url",
+			repo: "https://packages.microsoft.com/repos/azure-cli/",
+			want: aptSource{URIs: "https://packages.microsoft.com/repos/azure-cli/"},
+		},
+		{
+			name: "one-line entry",
+			repo: "deb [arch=amd64,arm64 signed-by=/usr/share/keyrings/x.gpg] https://download.docker.com/linux/ubuntu jammy stable",
+			want: aptSource{
+				URIs:          "https://download.docker.com/linux/ubuntu",
+				Suite:         "jammy",
+				Components:    []string{"stable"},
+				Architectures: []string{"amd64", "arm64"},
+			},
+		},
+		{
+			name: "flat repository",
+			repo: "deb https://pkgs.k8s.io/core:/stable:/v1.30/deb/ /",
+			want: aptSource{URIs: "https://pkgs.k8s.io/core:/stable:/v1.30/deb/", Suite: "/", Components: []string{}},
+		},
+		{name: "no suite", repo: "deb https://example.com/apt", wantErr: true},
+		{name: "not a url", repo: "example.com/apt", wantErr: true},
+		{name: "empty", repo: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAptRepo(tt.repo)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAptRepo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAptRepo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAptSource_Deb822(t *testing.T) {
+	src := aptSource{
+		URIs:          "https://download.docker.com/linux/ubuntu",
+		Suite:         "jammy",
+		Components:    []string{"stable"},
+		Architectures: []string{"amd64"},
+		SignedBy:      "/etc/apt/keyrings/docker.gpg",
+	}
+	want := `# Generated by gdf - DO NOT EDIT MANUALLY
+Types: deb
+URIs: https://download.docker.com/linux/ubuntu
+Suites: jammy
+Components: stable
+Architectures: amd64
+Signed-By: /etc/apt/keyrings/docker.gpg
+`
+	if got := src.deb822(); got != want {
+		t.Errorf("deb822() =\n%s\nwant\n%s", got, want)
+	}
+
+	flat := aptSource{URIs: "https://example.com/deb/", Suite: "/", Components: []string{"main"}}
+	if got := flat.deb822(); strings.Contains(got, "Components:") {
+		t.Errorf("deb822() of flat repository has components:\n%s", got)
+	}
+}
+
+func TestCheckKeyFingerprints(t *testing.T) {
+	const fpr = "9DC858229FC7DD38854AE2D88D81803C0EBFCD88"
+	colons := "pub:-:4096:1:8D81803C0EBFCD88:1487788586:::-:::scESA::::::23::0:\n" +
+		"fpr:::::::::" + fpr + ":\n" +
+		"sub:-:4096:1:7EA0A9C3F273FCD8:1487792064::::::s::::::23:\n" +
+		"fpr:::::::::D3306A018370199E527AE7997EA0A9C3F273FCD8:\n"
+
+	if err := checkKeyFingerprints([]byte(colons), "9DC8 5822 9FC7 DD38 854A  E2D8 8D81 803C 0EBF CD88"); err != nil {
+		t.Errorf("checkKeyFingerprints() error = %v", err)
+	}
+	if err := checkKeyFingerprints([]byte(colons), strings.Repeat("A", 40)); err == nil {
+		t.Error("checkKeyFingerprints() with wrong fingerprint error = nil, want error")
+	}
+	extra := colons + "pub:-:255:22:AAAA:1::::::scESC::::::ed25519:::0:\nfpr:::::::::" + strings.Repeat("B", 40) + ":\n"
+	if err := checkKeyFingerprints([]byte(extra), fpr); err == nil {
+		t.Error("checkKeyFingerprints() with an extra key error = nil, want error")
+	}
+	if err := checkKeyFingerprints(nil, fpr); err == nil {
+		t.Error("checkKeyFingerprints() with no keys error = nil, want error")
+	}
+}
+
+// fakeAptSystem mocks the commands used by apt repository setup: curl copies
+// keyFile, sudo runs its command unprivileged (apt-get is a no-op), and gpg
+// --show-keys prints colons.
+func fakeAptSystem(t *testing.T, keyFile, colons string, calls *[]string) *Apt {
+	t.Helper()
+	dir := t.TempDir()
+	osRelease := filepath.Join(dir, "os-release")
+	if err := os.WriteFile(osRelease, []byte("ID=ubuntu\nVERSION_CODENAME=noble\nUBUNTU_CODENAME=noble\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &Apt{
+		keyringDir: filepath.Join(dir, "keyrings"),
+		sourcesDir: filepath.Join(dir, "sources.list.d"),
+		osRelease:  osRelease,
+		execCommand: func(name string, args ...string) *exec.Cmd {
+			*calls = append(*calls, strings.Join(append([]string{name}, args...), " "))
+			switch {
+			case name == "curl":
+				return exec.Command("cp", keyFile, args[len(args)-2])
+			case name == "gpg":
+				return exec.Command("printf", "%s", colons)
+			case name == "sudo" && args[0] == "install":
+				return exec.Command(args[0], args[1:]...)
+			default:
+				return exec.Command("true")
+			}
+		},
+	}
+}
+
+func TestApt_InstallWithRepo_SignedBy(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.gpg")
+	if err := os.WriteFile(keyFile, []byte("binary key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	const fpr = "BC528686B50D79E339D3721CEB3E94ADBE1229CF"
+	colons := "pub:-:2048:1:EB3E94ADBE1229CF:1:::-:::scSC::::::23::0:\nfpr:::::::::" + fpr + ":\n"
+
+	var calls []string
+	a := fakeAptSystem(t, keyFile, colons, &calls)
+	pkg := &apps.AptPackage{
+		Name:        "azure-cli",
+		Repo:        "https://packages.microsoft.com/repos/azure-cli/",
+		Key:         "https://packages.microsoft.com/keys/microsoft.asc",
+		Fingerprint: fpr,
+		Keyring:     "microsoft",
+	}
+	if err := a.InstallWithRepo(pkg); err != nil {
+		t.Fatalf("InstallWithRepo() error = %v", err)
+	}
+
+	key, err := os.ReadFile(filepath.Join(a.keyringDir, "microsoft.gpg"))
+	if err != nil || string(key) != "binary key" {
+		t.Errorf("keyring = %q, %v, want downloaded key", key, err)
+	}
+	sources, err := os.ReadFile(filepath.Join(a.sourcesDir, "microsoft.sources"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"URIs: https://packages.microsoft.com/repos/azure-cli/\n",
+		"Suites: noble\n",
+		"Components: main\n",
+		"Signed-By: " + filepath.Join(a.keyringDir, "microsoft.gpg") + "\n",
+	} {
+		if !strings.Contains(string(sources), want) {
+			t.Errorf("sources file missing %q:\n%s", want, sources)
+		}
+	}
+	if !containsCall(calls, "sudo apt-get update") {
+		t.Errorf("calls = %v, want apt-get update after adding the repo", calls)
+	}
+	if containsCall(calls, "apt-key add") {
+		t.Errorf("calls = %v, want no apt-key", calls)
+	}
+
+	// Unchanged sources do not refresh package lists again.
+	calls = nil
+	if err := a.InstallWithRepo(pkg); err != nil {
+		t.Fatalf("InstallWithRepo() second run error = %v", err)
+	}
+	if containsCall(calls, "sudo apt-get update") {
+		t.Errorf("calls = %v, want no apt-get update when sources are unchanged", calls)
+	}
+	if !containsCall(calls, "sudo apt-get install -y azure-cli") {
+		t.Errorf("calls = %v, want package install", calls)
+	}
+}
+
+func TestApt_InstallWithRepo_SharedRepository(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.gpg")
+	if err := os.WriteFile(keyFile, []byte("binary key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	a := fakeAptSystem(t, keyFile, "", &calls)
+	docker := func(name string) *apps.AptPackage {
+		return &apps.AptPackage{
+			Name: name,
+			Repo: "https://download.docker.com/linux/ubuntu",
+			Key:  "https://download.docker.com/linux/ubuntu/gpg",
+		}
+	}
+
+	for _, name := range []string{"docker-ce", "docker-ce-cli"} {
+		if err := a.InstallWithRepo(docker(name)); err != nil {
+			t.Fatalf("InstallWithRepo(%s) error = %v", name, err)
+		}
+	}
+	sources, _ := filepath.Glob(filepath.Join(a.sourcesDir, "*.sources"))
+	keyrings, _ := filepath.Glob(filepath.Join(a.keyringDir, "*.gpg"))
+	if len(sources) != 1 || len(keyrings) != 1 {
+		t.Fatalf("sources = %v, keyrings = %v, want one of each for a shared repository", sources, keyrings)
+	}
+	if want := "download.docker.com-"; !strings.HasPrefix(filepath.Base(sources[0]), want) {
+		t.Errorf("sources file = %s, want name starting with %s", sources[0], want)
+	}
+
+	// A differently named keyring for the same repository is rejected.
+	pkg := docker("containerd.io")
+	pkg.Keyring = "containerd"
+	if err := a.InstallWithRepo(pkg); err == nil || !strings.Contains(err.Error(), "already configured") {
+		t.Errorf("InstallWithRepo() error = %v, want conflict with the existing source", err)
+	}
+}
+
+func TestApt_InstallWithRepo_SharedRepositoryKeepsKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.gpg")
+	if err := os.WriteFile(keyFile, []byte("binary key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	a := fakeAptSystem(t, keyFile, "", &calls)
+	keyed := &apps.AptPackage{Name: "docker-ce", Repo: "https://download.docker.com/linux/ubuntu", Key: "https://download.docker.com/linux/ubuntu/gpg"}
+	unkeyed := &apps.AptPackage{Name: "docker-compose-plugin", Repo: "https://download.docker.com/linux/ubuntu"}
+
+	for _, pkg := range []*apps.AptPackage{keyed, unkeyed} {
+		if err := a.InstallWithRepo(pkg); err != nil {
+			t.Fatalf("InstallWithRepo(%s) error = %v", pkg.Name, err)
+		}
+	}
+	sources, _ := filepath.Glob(filepath.Join(a.sourcesDir, "*.sources"))
+	if len(sources) != 1 {
+		t.Fatalf("sources = %v, want one shared file", sources)
+	}
+	data, err := os.ReadFile(sources[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := filepath.Join(a.keyringDir, strings.TrimSuffix(filepath.Base(sources[0]), ".sources")+".gpg")
+	if !strings.Contains(string(data), "Signed-By: "+keyring+"\n") {
+		t.Errorf("shared sources lost the Signed-By of the keyed app:\n%s", data)
+	}
+}
+
+func TestApt_InstallWithRepo_ReusesExistingSource(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.gpg")
+	if err := os.WriteFile(keyFile, []byte("binary key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	a := fakeAptSystem(t, keyFile, "", &calls)
+	// Written by an earlier gdf version, named after the package.
+	existing := aptSourceHeader + "Types: deb\nURIs: https://download.docker.com/linux/ubuntu/\nSuites: noble\n"
+	if err := os.MkdirAll(a.sourcesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(a.sourcesDir, "docker-ce.sources"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := &apps.AptPackage{Name: "docker-ce-cli", Repo: "https://download.docker.com/linux/ubuntu", Key: "https://download.docker.com/linux/ubuntu/gpg"}
+	if err := a.InstallWithRepo(pkg); err != nil {
+		t.Fatalf("InstallWithRepo() error = %v", err)
+	}
+	sources, _ := filepath.Glob(filepath.Join(a.sourcesDir, "*.sources"))
+	if len(sources) != 1 || filepath.Base(sources[0]) != "docker-ce.sources" {
+		t.Errorf("sources = %v, want the existing docker-ce.sources reused", sources)
+	}
+	if _, err := os.Stat(filepath.Join(a.keyringDir, "docker-ce.gpg")); err != nil {
+		t.Errorf("keyring not written under the reused name: %v", err)
+	}
+}
+
+func TestApt_InstallWithRepo_Errors(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.gpg")
+	if err := os.WriteFile(keyFile, []byte("binary key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	colons := "pub:-:2048:1:X:1:::-:::scSC::::::23::0:\nfpr:::::::::" + strings.Repeat("A", 40) + ":\n"
+
+	tests := []struct {
+		name string
+		pkg  *apps.AptPackage
+	}{
+		{
+			name: "fingerprint mismatch",
+			pkg: &apps.AptPackage{
+				Name:        "tool",
+				Repo:        "https://example.com/apt",
+				Key:         "https://example.com/key.gpg",
+				Fingerprint: strings.Repeat("B", 40),
+			},
+		},
+		{name: "key without repo", pkg: &apps.AptPackage{Name: "tool", Key: "https://example.com/key.gpg"}},
+		{name: "invalid repo", pkg: &apps.AptPackage{Name: "tool", Repo: "example.com/apt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			a := fakeAptSystem(t, keyFile, colons, &calls)
+			if err := a.InstallWithRepo(tt.pkg); err == nil {
+				t.Fatal("InstallWithRepo() error = nil, want error")
+			}
+			if containsCall(calls, "apt-get install") {
+				t.Errorf("calls = %v, want no install after a failed repo setup", calls)
+			}
+			if written, _ := filepath.Glob(filepath.Join(a.sourcesDir, "*.sources")); len(written) > 0 {
+				t.Errorf("sources files %v written after a failed repo setup", written)
+			}
+		})
+	}
+}
+
+func TestApt_ReleaseCodename(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "debian", content: "ID=debian\nVERSION_CODENAME=bookworm\n", want: "bookworm"},
+		{name: "ubuntu derivative", content: "ID=linuxmint\nVERSION_CODENAME=wilma\nUBUNTU_CODENAME=\"noble\"\n", want: "noble"},
+		{name: "missing", content: "ID=debian\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "os-release")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := (&Apt{osRelease: path}).releaseCodename()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("releaseCodename() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("releaseCodename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func containsCall(calls []string, substr string) bool {
+	for _, c := range calls {
+		if strings.Contains(c, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package packages
+
+import (
+	"fmt"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// BatchItem is one package of a batched install.
+type BatchItem struct {
+	// Name is the package name for the manager.
+	Name string
+	// Package is the app's package configuration, used for installs that
+	// need more than the name (apt repositories, brew taps and casks).
+	Package *apps.Package
+	// Version pins the package to an exact version when the manager is a
+	// VersionPinner; other managers install their current version.
+	Version string
+}
+
+// InstallPackages installs items with mgr and returns the error of each
+// package name that failed. Apt repositories are set up first; brew
+// packages with a tap, cask or install args are installed one at a time;
+// all other packages share a single InstallBatch call.
+func InstallPackages(mgr Manager, items []BatchItem) map[string]error {
+	errs := make(map[string]error)
+	var plain []string
+	names := make(map[string]string, len(items))
+	for _, item := range items {
+		if m, ok := mgr.(*Apt); ok && item.Package != nil && item.Package.Apt != nil {
+			if err := m.AddRepo(item.Package.Apt); err != nil {
+				errs[item.Name] = err
+				continue
+			}
+		}
+		if !batchable(mgr, item.Package) {
+			if err := InstallPackage(mgr, item.Package, item.Name); err != nil {
+				errs[item.Name] = err
+			}
+			continue
+		}
+		arg := item.Name
+		if pinner, ok := mgr.(VersionPinner); ok && item.Version != "" {
+			arg = pinner.PinVersion(item.Name, item.Version)
+		}
+		names[arg] = item.Name
+		plain = append(plain, arg)
+	}
+
+	switch len(plain) {
+	case 0:
+	case 1:
+		if err := mgr.Install(plain[0]); err != nil {
+			errs[names[plain[0]]] = err
+		}
+	default:
+		for arg, err := range mgr.InstallBatch(plain) {
+			errs[names[arg]] = err
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// batchable reports whether pkg can be installed by name alone.
+func batchable(mgr Manager, pkg *apps.Package) bool {
+	if pkg == nil {
+		return true
+	}
+	switch mgr.(type) {
+	case *Brew:
+		if pkg.Brew != nil {
+			return pkg.Brew.Tap == "" && !pkg.Brew.Cask && len(pkg.Brew.Args) == 0
+		}
+	case *Flatpak:
+		if pkg.Flatpak != nil {
+			return (pkg.Flatpak.Remote == "" || pkg.Flatpak.Remote == defaultFlatpakRemote) && pkg.Flatpak.RemoteURL == ""
+		}
+	case *Snap:
+		if pkg.Snap != nil {
+			return !pkg.Snap.Classic && pkg.Snap.Channel == ""
+		}
+	case *Release:
+		return pkg.Release == nil
+	}
+	return true
+}
+
+// runBatch runs batch for pkgs. A failed batch does not say which package
+// broke it, so single is then run for each package on its own to find the
+// ones that fail.
+func runBatch(pkgs []string, batch func([]string) error, single func(string) error) map[string]error {
+	errs := make(map[string]error)
+	var names []string
+	for _, pkg := range pkgs {
+		if pkg == "" {
+			errs[pkg] = fmt.Errorf("package name cannot be empty")
+			continue
+		}
+		names = append(names, pkg)
+	}
+
+	if len(names) > 0 && batch(names) != nil {
+		for _, name := range names {
+			if err := single(name); err != nil {
+				errs[name] = err
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// runEach runs single for each of pkgs, for managers that take one package
+// per invocation.
+func runEach(pkgs []string, single func(string) error) map[string]error {
+	errs := make(map[string]error)
+	for _, pkg := range pkgs {
+		if err := single(pkg); err != nil {
+			errs[pkg] = err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
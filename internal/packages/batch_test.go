@@ -0,0 +1,149 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// fakeInstaller mocks package installs: any command naming a package in
+// broken fails, everything else succeeds.
+func fakeInstaller(broken []string, calls *[]string) func(string, ...string) *exec.Cmd {
+	return func(name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, strings.Join(append([]string{name}, args...), " "))
+		for _, arg := range args {
+			for _, b := range broken {
+				if arg == b {
+					return exec.Command("false")
+				}
+			}
+		}
+		return exec.Command("true")
+	}
+}
+
+func TestInstallBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		pkgs      []string
+		broken    []string
+		wantCalls []string
+		wantErrs  []string
+	}{
+		{
+			name:      "single call",
+			pkgs:      []string{"git", "curl", "jq"},
+			wantCalls: []string{"sudo apt-get install -y git curl jq"},
+		},
+		{
+			name:   "failed batch retries each package",
+			pkgs:   []string{"git", "nope", "jq"},
+			broken: []string{"nope"},
+			wantCalls: []string{
+				"sudo apt-get install -y git nope jq",
+				"sudo apt-get install -y git",
+				"sudo apt-get install -y nope",
+				"sudo apt-get install -y jq",
+			},
+			wantErrs: []string{"nope"},
+		},
+		{
+			name:      "empty name",
+			pkgs:      []string{"git", ""},
+			wantCalls: []string{"sudo apt-get install -y git"},
+			wantErrs:  []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			a := &Apt{execCommand: fakeInstaller(tt.broken, &calls)}
+			errs := a.InstallBatch(tt.pkgs)
+
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+			var gotErrs []string
+			for name := range errs {
+				gotErrs = append(gotErrs, name)
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("InstallBatch() errors for %q, want %q", gotErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestInstallPackages(t *testing.T) {
+	var calls []string
+	b := &Brew{execCommand: func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		if len(args) > 0 && args[0] == "tap" {
+			return exec.Command("printf", "homebrew/core\n")
+		}
+		return exec.Command("true")
+	}}
+
+	errs := InstallPackages(b, []BatchItem{
+		{Name: "git", Package: &apps.Package{Brew: apps.BrewFormula("git")}},
+		{Name: "firefox", Package: &apps.Package{Brew: &apps.BrewPackage{Name: "firefox", Cask: true}}},
+		{Name: "ripgrep", Package: &apps.Package{Brew: apps.BrewFormula("ripgrep")}},
+	})
+	if errs != nil {
+		t.Fatalf("InstallPackages() errors = %v", errs)
+	}
+	want := []string{
+		"brew install --cask firefox",
+		"brew install git ripgrep",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	// A single plain package is installed with Install.
+	m := &mockManager{name: "apt"}
+	if errs := InstallPackages(m, []BatchItem{{Name: "git"}}); errs != nil {
+		t.Fatalf("InstallPackages() errors = %v", errs)
+	}
+	if m.installCalls != 1 {
+		t.Errorf("installCalls = %d, want 1", m.installCalls)
+	}
+}
+
+func TestInstallPackages_PinnedVersions(t *testing.T) {
+	var calls []string
+	a := &Apt{execCommand: fakeInstaller(nil, &calls)}
+	errs := InstallPackages(a, []BatchItem{
+		{Name: "git", Version: "1:2.43.0-1ubuntu7"},
+		{Name: "jq"},
+	})
+	if errs != nil {
+		t.Fatalf("InstallPackages() errors = %v", errs)
+	}
+	want := []string{"sudo apt-get install -y git=1:2.43.0-1ubuntu7 jq"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	// Downgrades are only allowed when requested.
+	calls = nil
+	a.SetAllowDowngrades(true)
+	if errs := InstallPackages(a, []BatchItem{{Name: "git", Version: "1:2.43.0-1ubuntu7"}, {Name: "jq"}}); errs != nil {
+		t.Fatalf("InstallPackages() errors = %v", errs)
+	}
+	want = []string{"sudo apt-get install -y --allow-downgrades git=1:2.43.0-1ubuntu7 jq"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	// Errors are reported under the package name, not the pinned argument.
+	calls = nil
+	a = &Apt{execCommand: fakeInstaller([]string{"git=1.0"}, &calls)}
+	errs = InstallPackages(a, []BatchItem{{Name: "git", Version: "1.0"}})
+	if _, ok := errs["git"]; !ok || len(errs) != 1 {
+		t.Errorf("InstallPackages() errors = %v, want an error for git", errs)
+	}
+}
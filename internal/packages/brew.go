@@ -1,8 +1,12 @@
 package packages
 
 import (
+	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
 )
 
 // Brew implements the Manager interface for Homebrew/Linuxbrew.
@@ -38,6 +42,18 @@ func (b *Brew) Install(pkg string) error {
 	return nil
 }
 
+// InstallBatch installs packages with a single brew install call.
+func (b *Brew) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, func(names []string) error {
+		execCmd := b.execCommand
+		if execCmd == nil {
+			execCmd = exec.Command
+		}
+		args := append([]string{"install"}, names...)
+		return execCmd("brew", args...).Run()
+	}, b.Install)
+}
+
 // Uninstall removes a package using brew.
 func (b *Brew) Uninstall(pkg string) error {
 	if pkg == "" {
@@ -83,6 +99,278 @@ func (b *Brew) IsInstalled(pkg string) (bool, error) {
 	return true, nil
 }
 
+// InstallPackage installs a formula or cask, adding its tap first and
+// passing any extra install args.
+func (b *Brew) InstallPackage(bp *apps.BrewPackage) error {
+	if bp == nil {
+		return fmt.Errorf("brew package configuration cannot be nil")
+	}
+	if bp.Name == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	if bp.Tap != "" {
+		if err := b.tap(bp.Tap, bp.TapURL); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"install"}
+	if bp.Cask {
+		args = append(args, "--cask")
+	}
+	args = append(args, bp.Args...)
+	args = append(args, bp.Name)
+	cmd := execCmd("brew", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via brew: %w\nOutput: %s", bp.Name, err, string(output))
+	}
+
+	return nil
+}
+
+// IsPackageInstalled checks if a formula or cask is installed. Casks are
+// checked with --cask so a formula of the same name does not match.
+func (b *Brew) IsPackageInstalled(bp *apps.BrewPackage) (bool, error) {
+	if bp == nil {
+		return false, fmt.Errorf("brew package configuration cannot be nil")
+	}
+	if !bp.Cask {
+		return b.IsInstalled(bp.Name)
+	}
+	if bp.Name == "" {
+		return false, fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	cmd := execCmd("brew", "list", "--cask", bp.Name)
+	err := cmd.Run()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check if cask %s is installed: %w", bp.Name, err)
+	}
+
+	return true, nil
+}
+
+// InstalledVersion returns the installed version of a formula, or "" if it
+// is not installed.
+func (b *Brew) InstalledVersion(pkg string) (string, error) {
+	return b.PackageVersion(apps.BrewFormula(pkg))
+}
+
+// PackageVersion returns the installed version of a formula or cask, or ""
+// if it is not installed. When several versions are kept, the last one
+// listed is reported.
+func (b *Brew) PackageVersion(bp *apps.BrewPackage) (string, error) {
+	if bp == nil {
+		return "", fmt.Errorf("brew package configuration cannot be nil")
+	}
+	if bp.Name == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	args := []string{"list", "--versions"}
+	if bp.Cask {
+		args = append(args, "--cask")
+	}
+	output, err := execCmd("brew", append(args, bp.Name)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read installed version of %s: %w", bp.Name, err)
+	}
+	// Output is "name 1.0 1.1".
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return "", nil
+	}
+	return fields[len(fields)-1], nil
+}
+
+// brewOutdatedEntry is a formula or cask in brew outdated --json=v2 output.
+// Casks report installed_versions as a string in older brew releases.
+type brewOutdatedEntry struct {
+	Name              string          `json:"name"`
+	InstalledVersions json.RawMessage `json:"installed_versions"`
+	CurrentVersion    string          `json:"current_version"`
+}
+
+func (e brewOutdatedEntry) installed() string {
+	var versions []string
+	if err := json.Unmarshal(e.InstalledVersions, &versions); err == nil {
+		if len(versions) == 0 {
+			return ""
+		}
+		return versions[len(versions)-1]
+	}
+	var version string
+	_ = json.Unmarshal(e.InstalledVersions, &version)
+	return version
+}
+
+// Outdated returns the installed formulae and casks among pkgs with a newer
+// version available, using brew outdated.
+func (b *Brew) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("brew", append([]string{"outdated", "--json=v2"}, pkgs...)...).Output()
+	if err != nil {
+		// brew outdated exits 1 when named packages are outdated.
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || exitErr.ExitCode() != 1 || len(output) == 0 {
+			return nil, fmt.Errorf("failed to check for brew upgrades: %w", err)
+		}
+	}
+
+	var result struct {
+		Formulae []brewOutdatedEntry `json:"formulae"`
+		Casks    []brewOutdatedEntry `json:"casks"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("parsing brew outdated output: %w", err)
+	}
+
+	var outdated []OutdatedPackage
+	for _, entry := range append(result.Formulae, result.Casks...) {
+		// Tapped packages are requested as user/repo/name.
+		name := entry.Name
+		for _, pkg := range pkgs {
+			if strings.HasSuffix(pkg, "/"+entry.Name) {
+				name = pkg
+			}
+		}
+		outdated = append(outdated, OutdatedPackage{Name: name, Current: entry.installed(), Available: entry.CurrentVersion})
+	}
+	return outdated, nil
+}
+
+// Upgrade upgrades installed formulae and casks with brew upgrade.
+func (b *Brew) Upgrade(pkgs []string) map[string]error {
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	upgrade := func(names []string) error {
+		output, err := execCmd("brew", append([]string{"upgrade"}, names...)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via brew: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+		}
+		return nil
+	}
+	return runBatch(pkgs, upgrade, func(name string) error { return upgrade([]string{name}) })
+}
+
+// tap adds a third-party tap unless it is already tapped. url is the clone
+// URL of a tap that is not on GitHub, or empty.
+func (b *Brew) tap(name, url string) error {
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("brew", "tap").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list brew taps: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), name) {
+			return nil
+		}
+	}
+
+	args := []string{"tap", name}
+	if url != "" {
+		args = append(args, url)
+	}
+	cmd := execCmd("brew", args...)
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to tap %s: %w\nOutput: %s", name, err, string(output))
+	}
+	return nil
+}
+
+// brewInfoEntry is a formula or cask in brew info --json=v2 output.
+type brewInfoEntry struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	Tap   string `json:"tap"`
+}
+
+// PackageTaps returns the tap that provides each of pkgs, looked up with
+// brew info. Packages brew does not know are left out.
+func (b *Brew) PackageTaps(pkgs []*apps.BrewPackage) (map[*apps.BrewPackage]string, error) {
+	execCmd := b.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	taps := make(map[*apps.BrewPackage]string)
+	for _, cask := range []bool{false, true} {
+		byName := make(map[string]*apps.BrewPackage)
+		args := []string{"info", "--json=v2", "--formula"}
+		if cask {
+			args[2] = "--cask"
+		}
+		for _, pkg := range pkgs {
+			if pkg.Cask == cask {
+				byName[pkg.Name] = pkg
+				args = append(args, pkg.Name)
+			}
+		}
+		if len(byName) == 0 {
+			continue
+		}
+		output, err := execCmd("brew", args...).Output()
+		if err != nil {
+			return taps, fmt.Errorf("failed to look up brew taps: %w", err)
+		}
+		var result struct {
+			Formulae []brewInfoEntry `json:"formulae"`
+			Casks    []brewInfoEntry `json:"casks"`
+		}
+		if err := json.Unmarshal(output, &result); err != nil {
+			return taps, fmt.Errorf("parsing brew info output: %w", err)
+		}
+		for _, entry := range append(result.Formulae, result.Casks...) {
+			name := entry.Name
+			if entry.Token != "" {
+				name = entry.Token
+			}
+			if pkg := byName[name]; pkg != nil && entry.Tap != "" {
+				taps[pkg] = entry.Tap
+			}
+		}
+	}
+	return taps, nil
+}
+
 // Name returns the package manager name.
 func (b *Brew) Name() string {
 	return "brew"
@@ -1,7 +1,12 @@
 package packages
 
 import (
+	"os/exec"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
 )
 
 func TestBrew_Name(t *testing.T) {
@@ -69,3 +74,126 @@ func TestBrew_IsAvailable(t *testing.T) {
 	// Just test that it doesn't crash
 	_ = b.IsAvailable()
 }
+
+// fakeBrew records brew commands. `brew tap` lists taps, `brew list --cask`
+// succeeds only for installedCasks, and everything else succeeds.
+func fakeBrew(taps string, installedCasks []string, calls *[]string) *Brew {
+	return &Brew{
+		execCommand: func(name string, args ...string) *exec.Cmd {
+			*calls = append(*calls, strings.Join(append([]string{name}, args...), " "))
+			switch {
+			case len(args) == 1 && args[0] == "tap":
+				return exec.Command("printf", "%s", taps)
+			case len(args) == 3 && args[0] == "list" && args[1] == "--cask":
+				for _, c := range installedCasks {
+					if c == args[2] {
+						return exec.Command("true")
+					}
+				}
+				return exec.Command("false")
+			default:
+				return exec.Command("true")
+			}
+		},
+	}
+}
+
+func TestBrew_InstallPackage(t *testing.T) {
+	tests := []struct {
+		name      string
+		pkg       *apps.BrewPackage
+		taps      string
+		wantCalls []string
+	}{
+		{
+			name:      "formula",
+			pkg:       apps.BrewFormula("ripgrep"),
+			wantCalls: []string{"brew install ripgrep"},
+		},
+		{
+			name:      "cask",
+			pkg:       &apps.BrewPackage{Name: "iterm2", Cask: true},
+			wantCalls: []string{"brew install --cask iterm2"},
+		},
+		{
+			name:      "new tap and args",
+			pkg:       &apps.BrewPackage{Name: "hashicorp/tap/terraform", Tap: "hashicorp/tap", Args: []string{"--HEAD"}},
+			taps:      "homebrew/core\n",
+			wantCalls: []string{"brew tap", "brew tap hashicorp/tap", "brew install --HEAD hashicorp/tap/terraform"},
+		},
+		{
+			name:      "tap with URL",
+			pkg:       &apps.BrewPackage{Name: "widget", Tap: "acme/tools", TapURL: "https://git.example.com/acme/homebrew-tools"},
+			taps:      "homebrew/core\n",
+			wantCalls: []string{"brew tap", "brew tap acme/tools https://git.example.com/acme/homebrew-tools", "brew install widget"},
+		},
+		{
+			name:      "existing tap",
+			pkg:       &apps.BrewPackage{Name: "font-fira-code", Cask: true, Tap: "homebrew/cask-fonts"},
+			taps:      "homebrew/cask-fonts\nhomebrew/core\n",
+			wantCalls: []string{"brew tap", "brew install --cask font-fira-code"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			if err := fakeBrew(tt.taps, nil, &calls).InstallPackage(tt.pkg); err != nil {
+				t.Fatalf("InstallPackage() error = %v", err)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	if err := NewBrew().InstallPackage(&apps.BrewPackage{}); err == nil {
+		t.Error("InstallPackage() with empty name error = nil, want error")
+	}
+}
+
+func TestBrew_IsPackageInstalled_Cask(t *testing.T) {
+	var calls []string
+	b := fakeBrew("", []string{"iterm2"}, &calls)
+
+	installed, err := b.IsPackageInstalled(&apps.BrewPackage{Name: "iterm2", Cask: true})
+	if err != nil || !installed {
+		t.Errorf("IsPackageInstalled(iterm2) = %v, %v, want true", installed, err)
+	}
+	installed, err = b.IsPackageInstalled(&apps.BrewPackage{Name: "docker", Cask: true})
+	if err != nil || installed {
+		t.Errorf("IsPackageInstalled(docker) = %v, %v, want false", installed, err)
+	}
+	if calls[0] != "brew list --cask iterm2" {
+		t.Errorf("calls = %q, want brew list --cask", calls)
+	}
+}
+
+func TestBrew_PackageTaps(t *testing.T) {
+	font := &apps.BrewPackage{Name: "font-fira-code", Cask: true}
+	widget := apps.BrewFormula("widget")
+	ripgrep := apps.BrewFormula("ripgrep")
+	var calls []string
+	b := &Brew{execCommand: func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		if args[2] == "--cask" {
+			return exec.Command("printf", "%s", `{"formulae":[],"casks":[{"token":"font-fira-code","tap":"homebrew/cask-fonts"}]}`)
+		}
+		return exec.Command("printf", "%s", `{"formulae":[{"name":"widget","tap":"acme/tools"},{"name":"ripgrep","tap":"homebrew/core"}],"casks":[]}`)
+	}}
+
+	got, err := b.PackageTaps([]*apps.BrewPackage{font, widget, ripgrep})
+	if err != nil {
+		t.Fatalf("PackageTaps() error = %v", err)
+	}
+	want := map[*apps.BrewPackage]string{font: "homebrew/cask-fonts", widget: "acme/tools", ripgrep: "homebrew/core"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PackageTaps() = %v, want %v", got, want)
+	}
+	wantCalls := []string{
+		"brew info --json=v2 --formula widget ripgrep",
+		"brew info --json=v2 --cask font-fira-code",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", calls, wantCalls)
+	}
+}
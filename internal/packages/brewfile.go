@@ -0,0 +1,391 @@
+package packages
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// BrewfileTap is a tap line of a Brewfile.
+type BrewfileTap struct {
+	Name string
+	// URL is the clone URL of a tap that is not on GitHub, if given.
+	URL string
+}
+
+// Brewfile is the subset of a `brew bundle` Brewfile that GDF understands:
+// taps, formulae and casks.
+type Brewfile struct {
+	Taps     []BrewfileTap
+	Packages []*apps.BrewPackage
+	// Skipped lists lines for other entry types (mas, vscode, whalebrew)
+	// and options that have no BrewPackage equivalent.
+	Skipped []string
+}
+
+// ParseBrewfile parses the tap, brew and cask lines of a Brewfile. Brewfile
+// args are written without dashes (args: ["HEAD"], args: { appdir: "~/Apps" })
+// and are converted to brew install flags (--HEAD, --appdir=~/Apps). A
+// formula named "user/repo/name" gets the tap "user/repo", with the URL of
+// its tap line if there is one. Use AttachTaps for packages with plain names.
+func ParseBrewfile(r io.Reader) (*Brewfile, error) {
+	bf := &Brewfile{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, rest, _ := strings.Cut(line, " ")
+		switch kind {
+		case "tap", "brew", "cask":
+		default:
+			bf.Skipped = append(bf.Skipped, line)
+			continue
+		}
+
+		p := &brewfileParser{s: strings.TrimSpace(rest)}
+		name, err := p.parseString()
+		if err != nil {
+			return nil, fmt.Errorf("Brewfile line %d: %w", lineNo, err)
+		}
+		var url string
+		options := map[string]brewfileValue{}
+		var optionOrder []string
+		for p.skipSpace(); p.peek() == ','; p.skipSpace() {
+			p.pos++
+			p.skipSpace()
+			if p.peek() == '"' || p.peek() == '\'' {
+				if url, err = p.parseString(); err != nil {
+					return nil, fmt.Errorf("Brewfile line %d: %w", lineNo, err)
+				}
+				continue
+			}
+			key, value, err := p.parseOption()
+			if err != nil {
+				return nil, fmt.Errorf("Brewfile line %d: %w", lineNo, err)
+			}
+			options[key] = value
+			optionOrder = append(optionOrder, key)
+		}
+		if p.pos < len(p.s) && !strings.HasPrefix(p.s[p.pos:], "#") {
+			return nil, fmt.Errorf("Brewfile line %d: unexpected %q", lineNo, p.s[p.pos:])
+		}
+
+		if kind == "tap" {
+			bf.Taps = append(bf.Taps, BrewfileTap{Name: name, URL: url})
+			continue
+		}
+
+		pkg := &apps.BrewPackage{Name: name, Cask: kind == "cask"}
+		if parts := strings.Split(name, "/"); len(parts) == 3 {
+			pkg.Tap = parts[0] + "/" + parts[1]
+		}
+		for _, key := range optionOrder {
+			if key != "args" {
+				bf.Skipped = append(bf.Skipped, fmt.Sprintf("%s %q: option %s", kind, name, key))
+				continue
+			}
+			args, err := options[key].installArgs()
+			if err != nil {
+				return nil, fmt.Errorf("Brewfile line %d: %w", lineNo, err)
+			}
+			pkg.Args = args
+		}
+		bf.Packages = append(bf.Packages, pkg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading Brewfile: %w", err)
+	}
+	bf.AttachTaps(nil)
+	return bf, nil
+}
+
+// AttachTaps sets the tap of each package that comes from one of the
+// Brewfile's tap lines, so installing the package adds the tap first.
+// tapOf returns the tap that provides a package with a plain name (see
+// Brew.PackageTaps); it may be nil. AttachTaps returns the declared taps
+// that no package uses.
+func (bf *Brewfile) AttachTaps(tapOf map[*apps.BrewPackage]string) []BrewfileTap {
+	declared := make(map[string]BrewfileTap, len(bf.Taps))
+	for _, tap := range bf.Taps {
+		declared[strings.ToLower(tap.Name)] = tap
+	}
+	used := make(map[string]bool)
+	for _, pkg := range bf.Packages {
+		tap := pkg.Tap
+		if tap == "" {
+			tap = tapOf[pkg]
+		}
+		decl, ok := declared[strings.ToLower(tap)]
+		if !ok {
+			continue
+		}
+		pkg.Tap = decl.Name
+		pkg.TapURL = decl.URL
+		used[strings.ToLower(decl.Name)] = true
+	}
+
+	var unused []BrewfileTap
+	for _, tap := range bf.Taps {
+		if !used[strings.ToLower(tap.Name)] {
+			unused = append(unused, tap)
+		}
+	}
+	return unused
+}
+
+// FormatBrewfile renders packages as a Brewfile: taps first (with their
+// clone URL when set), then formulae, then casks, each sorted by name.
+func FormatBrewfile(pkgs []*apps.BrewPackage) string {
+	taps := map[string]string{}
+	var formulae, casks []*apps.BrewPackage
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Name == "" {
+			continue
+		}
+		if pkg.Tap != "" && taps[pkg.Tap] == "" {
+			taps[pkg.Tap] = pkg.TapURL
+		}
+		if pkg.Cask {
+			casks = append(casks, pkg)
+		} else {
+			formulae = append(formulae, pkg)
+		}
+	}
+
+	var out strings.Builder
+	tapNames := make([]string, 0, len(taps))
+	for tap := range taps {
+		tapNames = append(tapNames, tap)
+	}
+	sort.Strings(tapNames)
+	for _, tap := range tapNames {
+		if url := taps[tap]; url != "" {
+			fmt.Fprintf(&out, "tap %s, %s\n", strconv.Quote(tap), strconv.Quote(url))
+			continue
+		}
+		fmt.Fprintf(&out, "tap %s\n", strconv.Quote(tap))
+	}
+	for _, group := range [][]*apps.BrewPackage{formulae, casks} {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+		for _, pkg := range group {
+			out.WriteString(formatBrewfileEntry(pkg))
+		}
+	}
+	return out.String()
+}
+
+// formatBrewfileEntry renders one brew or cask line. Formula args are a
+// list; cask args are a hash, as brew bundle expects.
+func formatBrewfileEntry(pkg *apps.BrewPackage) string {
+	kind := "brew"
+	if pkg.Cask {
+		kind = "cask"
+	}
+	line := fmt.Sprintf("%s %s", kind, strconv.Quote(pkg.Name))
+	if len(pkg.Args) == 0 {
+		return line + "\n"
+	}
+
+	parts := make([]string, 0, len(pkg.Args))
+	for _, arg := range pkg.Args {
+		flag := strings.TrimLeft(arg, "-")
+		if !pkg.Cask {
+			parts = append(parts, strconv.Quote(flag))
+			continue
+		}
+		key, value, hasValue := strings.Cut(flag, "=")
+		key = strings.ReplaceAll(key, "-", "_")
+		if hasValue {
+			parts = append(parts, fmt.Sprintf("%s: %s", key, strconv.Quote(value)))
+		} else {
+			parts = append(parts, key+": true")
+		}
+	}
+	if pkg.Cask {
+		return fmt.Sprintf("%s, args: { %s }\n", line, strings.Join(parts, ", "))
+	}
+	return fmt.Sprintf("%s, args: [%s]\n", line, strings.Join(parts, ", "))
+}
+
+// brewfileValue is a parsed Brewfile option value: a string, bool, list
+// or hash (as ordered key/value pairs).
+type brewfileValue struct {
+	str   string
+	list  []brewfileValue
+	hash  []brewfileOption
+	kind  byte // 's' string, 'b' bool, 'l' list, 'h' hash
+	truth bool
+}
+
+type brewfileOption struct {
+	key   string
+	value brewfileValue
+}
+
+// installArgs converts Brewfile args to brew install flags.
+func (v brewfileValue) installArgs() ([]string, error) {
+	var args []string
+	switch v.kind {
+	case 'l':
+		for _, item := range v.list {
+			if item.kind != 's' {
+				return nil, fmt.Errorf("args list entries must be strings")
+			}
+			args = append(args, "--"+item.str)
+		}
+	case 'h':
+		for _, opt := range v.hash {
+			flag := "--" + strings.ReplaceAll(opt.key, "_", "-")
+			switch opt.value.kind {
+			case 's':
+				args = append(args, flag+"="+opt.value.str)
+			case 'b':
+				if opt.value.truth {
+					args = append(args, flag)
+				}
+			default:
+				return nil, fmt.Errorf("args hash values must be strings or booleans")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("args must be a list or hash")
+	}
+	return args, nil
+}
+
+// brewfileParser reads the Ruby literals used on Brewfile lines.
+type brewfileParser struct {
+	s   string
+	pos int
+}
+
+func (p *brewfileParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *brewfileParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *brewfileParser) parseString() (string, error) {
+	p.skipSpace()
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		return "", fmt.Errorf("expected a quoted string at %q", p.s[p.pos:])
+	}
+	p.pos++
+	var out strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return out.String(), nil
+		case c == '\\' && p.pos < len(p.s):
+			out.WriteByte(p.s[p.pos])
+			p.pos++
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+func (p *brewfileParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.s) && (unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos])) || p.s[p.pos] == '_') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// parseOption reads `key: value`.
+func (p *brewfileParser) parseOption() (string, brewfileValue, error) {
+	p.skipSpace()
+	key := p.parseIdent()
+	if key == "" || p.peek() != ':' {
+		return "", brewfileValue{}, fmt.Errorf("expected an option (key: value) at %q", p.s[p.pos:])
+	}
+	p.pos++
+	value, err := p.parseValue()
+	return key, value, err
+}
+
+func (p *brewfileParser) parseValue() (brewfileValue, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		s, err := p.parseString()
+		return brewfileValue{kind: 's', str: s}, err
+	case c == ':':
+		// Ruby symbol, e.g. restart_service: :changed
+		p.pos++
+		return brewfileValue{kind: 's', str: p.parseIdent()}, nil
+	case c == '[':
+		p.pos++
+		v := brewfileValue{kind: 'l'}
+		for {
+			p.skipSpace()
+			if p.peek() == ']' {
+				p.pos++
+				return v, nil
+			}
+			item, err := p.parseValue()
+			if err != nil {
+				return v, err
+			}
+			v.list = append(v.list, item)
+			p.skipSpace()
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != ']' {
+				return v, fmt.Errorf("expected ',' or ']' at %q", p.s[p.pos:])
+			}
+		}
+	case c == '{':
+		p.pos++
+		v := brewfileValue{kind: 'h'}
+		for {
+			p.skipSpace()
+			if p.peek() == '}' {
+				p.pos++
+				return v, nil
+			}
+			key, value, err := p.parseOption()
+			if err != nil {
+				return v, err
+			}
+			v.hash = append(v.hash, brewfileOption{key: key, value: value})
+			p.skipSpace()
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != '}' {
+				return v, fmt.Errorf("expected ',' or '}' at %q", p.s[p.pos:])
+			}
+		}
+	default:
+		switch word := p.parseIdent(); word {
+		case "true", "false":
+			return brewfileValue{kind: 'b', truth: word == "true"}, nil
+		case "":
+			return brewfileValue{}, fmt.Errorf("unexpected %q", p.s[p.pos:])
+		default:
+			return brewfileValue{kind: 's', str: word}, nil
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package packages
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func TestParseBrewfile(t *testing.T) {
+	input := `# Brewfile
+tap "homebrew/bundle"
+tap "acme/tools", "https://git.example.com/acme/homebrew-tools"
+brew "ripgrep"
+brew "neovim", args: ["HEAD"]
+brew "hashicorp/tap/terraform"
+brew "acme/tools/widget"
+brew 'mysql', restart_service: :changed
+cask "iterm2"
+cask "firefox", args: { appdir: "~/Applications", no_quarantine: true }
+mas "Xcode", id: 497799835
+vscode "golang.go"
+`
+	bf, err := ParseBrewfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseBrewfile() error = %v", err)
+	}
+
+	wantTaps := []BrewfileTap{
+		{Name: "homebrew/bundle"},
+		{Name: "acme/tools", URL: "https://git.example.com/acme/homebrew-tools"},
+	}
+	if !reflect.DeepEqual(bf.Taps, wantTaps) {
+		t.Errorf("Taps = %+v, want %+v", bf.Taps, wantTaps)
+	}
+	wantPackages := []*apps.BrewPackage{
+		{Name: "ripgrep"},
+		{Name: "neovim", Args: []string{"--HEAD"}},
+		{Name: "hashicorp/tap/terraform", Tap: "hashicorp/tap"},
+		{Name: "acme/tools/widget", Tap: "acme/tools", TapURL: "https://git.example.com/acme/homebrew-tools"},
+		{Name: "mysql"},
+		{Name: "iterm2", Cask: true},
+		{Name: "firefox", Cask: true, Args: []string{"--appdir=~/Applications", "--no-quarantine"}},
+	}
+	if !reflect.DeepEqual(bf.Packages, wantPackages) {
+		t.Errorf("Packages =\n%+v\nwant\n%+v", bf.Packages, wantPackages)
+	}
+	wantSkipped := []string{
+		`brew "mysql": option restart_service`,
+		`mas "Xcode", id: 497799835`,
+		`vscode "golang.go"`,
+	}
+	if !reflect.DeepEqual(bf.Skipped, wantSkipped) {
+		t.Errorf("Skipped = %q, want %q", bf.Skipped, wantSkipped)
+	}
+}
+
+func TestBrewfile_AttachTaps(t *testing.T) {
+	input := `tap "homebrew/cask-fonts"
+tap "acme/tools", "https://git.example.com/acme/homebrew-tools"
+tap "unused/tap"
+cask "font-fira-code"
+brew "widget"
+brew "ripgrep"
+`
+	bf, err := ParseBrewfile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseBrewfile() error = %v", err)
+	}
+	font, widget, ripgrep := bf.Packages[0], bf.Packages[1], bf.Packages[2]
+	unused := bf.AttachTaps(map[*apps.BrewPackage]string{
+		font:    "homebrew/cask-fonts",
+		widget:  "acme/tools",
+		ripgrep: "homebrew/core",
+	})
+
+	want := []*apps.BrewPackage{
+		{Name: "font-fira-code", Cask: true, Tap: "homebrew/cask-fonts"},
+		{Name: "widget", Tap: "acme/tools", TapURL: "https://git.example.com/acme/homebrew-tools"},
+		{Name: "ripgrep"},
+	}
+	if !reflect.DeepEqual(bf.Packages, want) {
+		t.Errorf("Packages =\n%+v\nwant\n%+v", bf.Packages, want)
+	}
+	if wantUnused := []BrewfileTap{{Name: "unused/tap"}}; !reflect.DeepEqual(unused, wantUnused) {
+		t.Errorf("AttachTaps() = %+v, want %+v", unused, wantUnused)
+	}
+}
+
+func TestParseBrewfile_Errors(t *testing.T) {
+	for _, input := range []string{
+		`brew ripgrep`,
+		`brew "ripgrep`,
+		`brew "neovim", args: ["HEAD"`,
+		`cask "firefox", args: "appdir"`,
+		`brew "neovim" extra`,
+	} {
+		if _, err := ParseBrewfile(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseBrewfile(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestFormatBrewfile(t *testing.T) {
+	pkgs := []*apps.BrewPackage{
+		{Name: "iterm2", Cask: true},
+		{Name: "ripgrep"},
+		{Name: "hashicorp/tap/terraform", Tap: "hashicorp/tap"},
+		{Name: "firefox", Cask: true, Args: []string{"--appdir=~/Applications", "--no-quarantine"}},
+		{Name: "neovim", Args: []string{"--HEAD"}},
+		{Name: "widget", Tap: "acme/tools", TapURL: "https://git.example.com/acme/homebrew-tools"},
+	}
+	want := `tap "acme/tools", "https://git.example.com/acme/homebrew-tools"
+tap "hashicorp/tap"
+brew "hashicorp/tap/terraform"
+brew "neovim", args: ["HEAD"]
+brew "ripgrep"
+brew "widget"
+cask "firefox", args: { appdir: "~/Applications", no_quarantine: true }
+cask "iterm2"
+`
+	got := FormatBrewfile(pkgs)
+	if got != want {
+		t.Errorf("FormatBrewfile() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The output parses back to the same packages once plain names are
+	// attached to their taps.
+	bf, err := ParseBrewfile(strings.NewReader(got))
+	if err != nil {
+		t.Fatalf("ParseBrewfile(FormatBrewfile()) error = %v", err)
+	}
+	tapOf := map[*apps.BrewPackage]string{}
+	for _, pkg := range bf.Packages {
+		if pkg.Name == "widget" {
+			tapOf[pkg] = "acme/tools"
+		}
+	}
+	bf.AttachTaps(tapOf)
+	if len(bf.Packages) != len(pkgs) {
+		t.Fatalf("round trip has %d packages, want %d", len(bf.Packages), len(pkgs))
+	}
+	for _, pkg := range bf.Packages {
+		found := false
+		for _, orig := range pkgs {
+			if reflect.DeepEqual(pkg, orig) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("round trip package %+v not in input", pkg)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package packages
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Cargo implements the Manager interface for Rust crates installed with
+// cargo install.
+type Cargo struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewCargo creates a new Cargo package manager.
+func NewCargo() *Cargo {
+	return &Cargo{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs a crate using cargo install.
+func (c *Cargo) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	return c.install([]string{pkg})
+}
+
+// InstallBatch installs crates with a single cargo install call.
+func (c *Cargo) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, c.install, c.Install)
+}
+
+func (c *Cargo) install(names []string) error {
+	execCmd := c.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("cargo", append([]string{"install"}, names...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via cargo: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+	}
+	return nil
+}
+
+// Uninstall removes a crate using cargo uninstall.
+func (c *Cargo) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := c.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("cargo", "uninstall", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via cargo: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if a crate is installed via cargo.
+func (c *Cargo) IsInstalled(pkg string) (bool, error) {
+	version, err := c.InstalledVersion(pkg)
+	return version != "", err
+}
+
+// InstalledVersion returns the installed version of a crate, or "" if it is
+// not installed.
+func (c *Cargo) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+	installed, err := c.list()
+	if err != nil {
+		return "", err
+	}
+	return installed[pkg], nil
+}
+
+// list returns the versions of installed crates from cargo install --list,
+// which prints each crate as "name v1.2.3:" followed by its binaries.
+func (c *Cargo) list() (map[string]string, error) {
+	execCmd := c.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("cargo", "install", "--list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cargo installs: %w", err)
+	}
+
+	installed := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		// Binaries are indented under their crate.
+		if line == "" || strings.HasPrefix(line, " ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		installed[fields[0]] = strings.TrimPrefix(strings.TrimSuffix(fields[1], ":"), "v")
+	}
+	return installed, nil
+}
+
+// Outdated returns the installed crates whose latest crates.io version
+// differs from the installed one.
+func (c *Cargo) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	installed, err := c.list()
+	if err != nil {
+		return nil, err
+	}
+
+	execCmd := c.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		current := installed[pkg]
+		if current == "" {
+			continue
+		}
+		// cargo search prints the best match first: name = "1.2.3"    # description
+		output, err := execCmd("cargo", "search", "--limit", "1", pkg).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for cargo upgrades of %s: %w", pkg, err)
+		}
+		line, _, _ := strings.Cut(string(output), "\n")
+		name, rest, ok := strings.Cut(line, " = ")
+		if !ok || strings.TrimSpace(name) != pkg {
+			continue
+		}
+		latest, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(rest), `"`), `"`)
+		if latest != "" && latest != current {
+			outdated = append(outdated, OutdatedPackage{Name: pkg, Current: current, Available: latest})
+		}
+	}
+	return outdated, nil
+}
+
+// Upgrade upgrades installed crates; cargo install replaces a crate when a
+// newer version is available.
+func (c *Cargo) Upgrade(pkgs []string) map[string]error {
+	return c.InstallBatch(pkgs)
+}
+
+// PinVersion returns the cargo install argument that installs version of pkg.
+func (c *Cargo) PinVersion(pkg, version string) string {
+	return pkg + "@" + version
+}
+
+// Name returns the package manager name.
+func (c *Cargo) Name() string {
+	return "cargo"
+}
+
+// IsAvailable checks if cargo is available on the system.
+func (c *Cargo) IsAvailable() bool {
+	_, err := lookPath("cargo")
+	return err == nil
+}
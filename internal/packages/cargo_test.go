@@ -0,0 +1,65 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestCargo_InstalledVersion(t *testing.T) {
+	c := &Cargo{execCommand: func(string, ...string) *exec.Cmd {
+		return scripted("bat v0.24.0:\n    bat\nripgrep v14.1.0:\n    rg\n", 0)
+	}}
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"ripgrep", "14.1.0"},
+		{"rg", ""},
+		{"fd-find", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pkg, func(t *testing.T) {
+			got, err := c.InstalledVersion(tt.pkg)
+			if err != nil {
+				t.Fatalf("InstalledVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("InstalledVersion(%q) = %q, want %q", tt.pkg, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.IsInstalled(""); err == nil {
+		t.Error("IsInstalled(\"\") should return error for empty package name")
+	}
+}
+
+func TestCargo_Outdated(t *testing.T) {
+	c := &Cargo{execCommand: func(name string, args ...string) *exec.Cmd {
+		if args[0] == "search" {
+			latest := map[string]string{"ripgrep": "14.1.1", "bat": "0.24.0"}[args[len(args)-1]]
+			return scripted(args[len(args)-1]+` = "`+latest+`"    # description`+"\n", 0)
+		}
+		return scripted("bat v0.24.0:\n    bat\nripgrep v14.1.0:\n    rg\n", 0)
+	}}
+	got, err := c.Outdated([]string{"ripgrep", "bat", "fd-find"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "ripgrep", Current: "14.1.0", Available: "14.1.1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCargo_InstallBatch(t *testing.T) {
+	var calls []string
+	c := &Cargo{execCommand: fakeInstaller(nil, &calls)}
+	if errs := c.InstallBatch([]string{"ripgrep", c.PinVersion("bat", "0.24.0")}); errs != nil {
+		t.Fatalf("InstallBatch() errors = %v", errs)
+	}
+	if want := []string{"cargo install ripgrep bat@0.24.0"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,202 @@
+package packages
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/rztaylor/GoDotFiles/internal/config"
+)
+
+// CommandManager implements the Manager interface for a user-defined
+// package manager declared in config.yaml as shell command templates.
+type CommandManager struct {
+	name string
+	cmds *config.ManagerCommands
+
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewCommandManager creates a package manager that runs the command
+// templates of cmds.
+func NewCommandManager(name string, cmds *config.ManagerCommands) *CommandManager {
+	return &CommandManager{
+		name:        name,
+		cmds:        cmds,
+		execCommand: exec.Command,
+	}
+}
+
+// command renders the template text for pkg as an sh command.
+func (c *CommandManager) command(key, text, pkg string) (*exec.Cmd, error) {
+	if text == "" {
+		return nil, fmt.Errorf("package manager %s does not define %s", c.name, key)
+	}
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s command of package manager %s: %w", key, c.name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, config.ManagerTemplateData{Package: shellQuote(pkg)}); err != nil {
+		return nil, fmt.Errorf("rendering %s command of package manager %s: %w", key, c.name, err)
+	}
+
+	execCmd := c.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	return execCmd("sh", "-c", buf.String()), nil
+}
+
+// shellQuote quotes s as a single sh word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Install installs a package with the install command.
+func (c *CommandManager) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	cmd, err := c.command("install", c.cmds.Install, pkg)
+	if err != nil {
+		return err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via %s: %w\nOutput: %s", pkg, c.name, err, string(output))
+	}
+	return nil
+}
+
+// InstallBatch installs packages one at a time: the install command takes
+// a single package.
+func (c *CommandManager) InstallBatch(pkgs []string) map[string]error {
+	return runEach(pkgs, c.Install)
+}
+
+// Uninstall removes a package with the uninstall command.
+func (c *CommandManager) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	cmd, err := c.command("uninstall", c.cmds.Uninstall, pkg)
+	if err != nil {
+		return err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via %s: %w\nOutput: %s", pkg, c.name, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled runs the is_installed command, or looks for the package in
+// the list output when there is none.
+func (c *CommandManager) IsInstalled(pkg string) (bool, error) {
+	if pkg == "" {
+		return false, fmt.Errorf("package name cannot be empty")
+	}
+	if c.cmds.IsInstalled == "" {
+		installed, err := c.list(pkg)
+		if err != nil {
+			return false, err
+		}
+		_, ok := installed[pkg]
+		return ok, nil
+	}
+
+	cmd, err := c.command("is_installed", c.cmds.IsInstalled, pkg)
+	if err != nil {
+		return false, err
+	}
+	if err := cmd.Run(); err != nil {
+		// Any non-zero exit means not installed, like grep -q.
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check if %s is installed: %w", pkg, err)
+	}
+	return true, nil
+}
+
+// InstalledVersion returns the version the list command prints for a
+// package. It is "" when the package is not installed, or when the manager
+// has no list command or the list shows no version.
+func (c *CommandManager) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+	if c.cmds.List == "" {
+		return "", nil
+	}
+	installed, err := c.list(pkg)
+	if err != nil {
+		return "", err
+	}
+	return installed[pkg], nil
+}
+
+// list runs the list command and maps each package to its version. Lines
+// are "name version" or "name@version"; names match case-insensitively.
+func (c *CommandManager) list(pkg string) (map[string]string, error) {
+	cmd, err := c.command("list", c.cmds.List, pkg)
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s packages: %w", c.name, err)
+	}
+
+	installed := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		name, version := fields[0], ""
+		if len(fields) > 1 {
+			version = fields[1]
+		} else if i := strings.LastIndex(name, "@"); i > 0 {
+			name, version = name[:i], name[i+1:]
+		}
+		if strings.EqualFold(name, pkg) {
+			name = pkg
+		}
+		installed[name] = version
+	}
+	return installed, nil
+}
+
+// Outdated returns nil: user-defined managers do not report available
+// versions.
+func (c *CommandManager) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	return nil, nil
+}
+
+// Upgrade reports an error for each package: user-defined managers have no
+// upgrade command.
+func (c *CommandManager) Upgrade(pkgs []string) map[string]error {
+	return runEach(pkgs, func(pkg string) error {
+		return fmt.Errorf("package manager %s does not support upgrades", c.name)
+	})
+}
+
+// Name returns the package manager name.
+func (c *CommandManager) Name() string {
+	return c.name
+}
+
+// IsAvailable checks if the program the install command runs is available.
+func (c *CommandManager) IsAvailable() bool {
+	fields := strings.Fields(c.cmds.Install)
+	if len(fields) == 0 {
+		return false
+	}
+	_, err := lookPath(fields[0])
+	return err == nil
+}
@@ -0,0 +1,101 @@
+package packages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/config"
+)
+
+// fileManager is a user-defined manager that records installed packages in
+// a file, one "name version" line each.
+func fileManager(t *testing.T, withIsInstalled bool) *CommandManager {
+	t.Helper()
+	db := filepath.Join(t.TempDir(), "installed")
+	if err := os.WriteFile(db, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmds := &config.ManagerCommands{
+		Install:   `echo {{.Package}} 1.0 >> ` + db,
+		Uninstall: `grep -vx {{.Package}}' 1.0' ` + db + ` > ` + db + `.new; mv ` + db + `.new ` + db,
+		List:      `cat ` + db,
+	}
+	if withIsInstalled {
+		cmds.IsInstalled = `grep -q "^"{{.Package}}" " ` + db
+	}
+	return NewCommandManager("files", cmds)
+}
+
+func TestCommandManager(t *testing.T) {
+	for _, withIsInstalled := range []bool{true, false} {
+		name := "list"
+		if withIsInstalled {
+			name = "is_installed"
+		}
+		t.Run(name, func(t *testing.T) {
+			m := fileManager(t, withIsInstalled)
+			if m.Name() != "files" {
+				t.Errorf("Name() = %q, want files", m.Name())
+			}
+
+			if installed, err := m.IsInstalled("ms-python.python"); err != nil || installed {
+				t.Fatalf("IsInstalled() before install = %v, %v, want false", installed, err)
+			}
+			if err := m.Install("ms-python.python"); err != nil {
+				t.Fatalf("Install() error = %v", err)
+			}
+			if installed, err := m.IsInstalled("ms-python.python"); err != nil || !installed {
+				t.Fatalf("IsInstalled() after install = %v, %v, want true", installed, err)
+			}
+			if version, err := m.InstalledVersion("ms-python.python"); err != nil || version != "1.0" {
+				t.Errorf("InstalledVersion() = %q, %v, want 1.0", version, err)
+			}
+
+			if err := m.Uninstall("ms-python.python"); err != nil {
+				t.Fatalf("Uninstall() error = %v", err)
+			}
+			if installed, err := m.IsInstalled("ms-python.python"); err != nil || installed {
+				t.Errorf("IsInstalled() after uninstall = %v, %v, want false", installed, err)
+			}
+		})
+	}
+}
+
+func TestCommandManager_QuotesPackage(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	m := NewCommandManager("echo", &config.ManagerCommands{Install: `printf '%s' {{.Package}} > ` + out})
+	if err := m.Install(`x; touch pwned 'quoted'`); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `x; touch pwned 'quoted'` {
+		t.Errorf("package expanded to %q", data)
+	}
+}
+
+func TestCommandManager_ListVersions(t *testing.T) {
+	m := NewCommandManager("vscode", &config.ManagerCommands{
+		Install: "true",
+		List:    `printf 'MS-Python.Python@2024.8.1\nesbenp.prettier-vscode@10.4.0\nredhat.vscode-yaml\n'`,
+	})
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"ms-python.python", "2024.8.1"},
+		{"esbenp.prettier-vscode", "10.4.0"},
+		{"golang.go", ""},
+	}
+	for _, tt := range tests {
+		if got, err := m.InstalledVersion(tt.pkg); err != nil || got != tt.want {
+			t.Errorf("InstalledVersion(%q) = %q, %v, want %q", tt.pkg, got, err, tt.want)
+		}
+	}
+	if installed, err := m.IsInstalled("redhat.vscode-yaml"); err != nil || !installed {
+		t.Errorf("IsInstalled(redhat.vscode-yaml) = %v, %v, want true", installed, err)
+	}
+}
@@ -2,14 +2,20 @@ package packages
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
 )
 
+// maxLoggedOutput caps the stdout and stderr kept in the audit log for one
+// script run; longer output keeps its end, where errors usually are.
+const maxLoggedOutput = 64 * 1024
+
 // Custom executes custom installation scripts with user confirmation.
 type Custom struct {
 	// promptFunc allows mocking user prompts in tests
@@ -18,8 +24,17 @@ type Custom struct {
 	// execCommand allows mocking command execution in tests
 	execCommand func(string, ...string) *exec.Cmd
 
-	// logFunc allows mocking operation logging in tests
-	logFunc func(operation string) error
+	// logFunc records script runs for audit; nil disables logging.
+	logFunc func(run ScriptRun) error
+}
+
+// ScriptRun is a custom script execution recorded for audit.
+type ScriptRun struct {
+	Script   string
+	Sudo     bool
+	ExitCode int
+	Stdout   string
+	Stderr   string
 }
 
 // NewCustom creates a new Custom script executor.
@@ -27,11 +42,59 @@ func NewCustom() *Custom {
 	return &Custom{
 		promptFunc:  promptUser,
 		execCommand: exec.Command,
-		logFunc:     logOperation,
 	}
 }
 
-// Execute runs a custom installation script with user confirmation.
+// SetLogFunc records each script run, with its output, by calling fn.
+// Callers set it when security.log_scripts is on; nil disables logging.
+func (c *Custom) SetLogFunc(fn func(run ScriptRun) error) {
+	c.logFunc = fn
+}
+
+// Created reports whether the creates path of a custom install exists. It
+// runs no commands.
+func (c *Custom) Created(customInstall *apps.CustomInstall) (bool, error) {
+	if customInstall == nil {
+		return false, fmt.Errorf("custom install configuration cannot be nil")
+	}
+	if customInstall.Creates == "" {
+		return false, nil
+	}
+	if _, err := os.Stat(platform.ExpandPath(customInstall.Creates)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("checking %s: %w", customInstall.Creates, err)
+	}
+	return true, nil
+}
+
+// IsInstalled reports whether a custom install has already run: its creates
+// path exists or its check command exits 0. Without either it reports false.
+func (c *Custom) IsInstalled(customInstall *apps.CustomInstall) (bool, error) {
+	created, err := c.Created(customInstall)
+	if err != nil || created {
+		return created, err
+	}
+
+	if customInstall.Check == "" {
+		return false, nil
+	}
+	execCmd := c.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	if err := execCmd("sh", "-c", customInstall.Check).Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to run custom install check: %w", err)
+	}
+	return true, nil
+}
+
+// Execute runs a custom installation script with user confirmation. The
+// script is skipped when its check or creates path shows it already ran.
 func (c *Custom) Execute(customInstall *apps.CustomInstall) error {
 	if customInstall == nil {
 		return fmt.Errorf("custom install configuration cannot be nil")
@@ -41,6 +104,14 @@ func (c *Custom) Execute(customInstall *apps.CustomInstall) error {
 		return fmt.Errorf("custom install script cannot be empty")
 	}
 
+	installed, err := c.IsInstalled(customInstall)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return nil
+	}
+
 	// Build prompt message
 	message := c.buildPromptMessage(customInstall.Script, customInstall.Sudo)
 
@@ -61,11 +132,6 @@ func (c *Custom) Execute(customInstall *apps.CustomInstall) error {
 		}
 	}
 
-	// Log the operation for audit
-	if c.logFunc != nil {
-		_ = c.logFunc(fmt.Sprintf("custom script: %s", customInstall.Script))
-	}
-
 	// Execute the script
 	execCmd := c.execCommand
 	if execCmd == nil {
@@ -79,14 +145,50 @@ func (c *Custom) Execute(customInstall *apps.CustomInstall) error {
 		cmd = execCmd("sh", "-c", customInstall.Script)
 	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to execute custom script: %w\nOutput: %s", err, string(output))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	// Log the run for audit, whether or not it succeeded
+	if c.logFunc != nil {
+		run := ScriptRun{
+			Script:   customInstall.Script,
+			Sudo:     customInstall.Sudo,
+			ExitCode: cmd.ProcessState.ExitCode(),
+			Stdout:   tail(stdout.String(), maxLoggedOutput),
+			Stderr:   tail(stderr.String(), maxLoggedOutput),
+		}
+		if err := c.logFunc(run); err != nil {
+			return fmt.Errorf("logging custom script execution: %w", err)
+		}
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("failed to execute custom script: %w\nOutput: %s%s", runErr, stdout.String(), stderr.String())
+	}
+
+	if customInstall.Check != "" || customInstall.Creates != "" {
+		installed, err := c.IsInstalled(customInstall)
+		if err != nil {
+			return err
+		}
+		if !installed {
+			return fmt.Errorf("custom script finished but its check or creates path still reports the app as not installed")
+		}
 	}
 
 	return nil
 }
 
+// tail returns the last n bytes of s.
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
+
 // buildPromptMessage creates the confirmation prompt message.
 func (c *Custom) buildPromptMessage(script string, sudo bool) string {
 	var msg strings.Builder
@@ -118,11 +220,3 @@ func promptUser(message string) (bool, error) {
 	response = strings.ToLower(strings.TrimSpace(response))
 	return response == "y" || response == "yes", nil
 }
-
-// logOperation logs a custom script operation for audit purposes.
-func logOperation(operation string) error {
-	// TODO: Implement proper operation logging to .gdf/.operations/
-	// For now, just print to stdout
-	fmt.Printf("[AUDIT] %s\n", operation)
-	return nil
-}
@@ -1,7 +1,11 @@
 package packages
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
@@ -147,7 +151,7 @@ func TestCustom_ExecuteLogging(t *testing.T) {
 		execCommand: func(cmd string, args ...string) *exec.Cmd {
 			return exec.Command("echo", "mock")
 		},
-		logFunc: func(operation string) error {
+		logFunc: func(run ScriptRun) error {
 			logged = true
 			return nil
 		},
@@ -160,6 +164,95 @@ func TestCustom_ExecuteLogging(t *testing.T) {
 	}
 }
 
+func TestCustom_IsInstalled(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "tool")
+	if err := os.WriteFile(existing, []byte("bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		customInstall *apps.CustomInstall
+		want          bool
+	}{
+		{name: "no check", customInstall: &apps.CustomInstall{Script: "true"}, want: false},
+		{name: "check passes", customInstall: &apps.CustomInstall{Script: "true", Check: "exit 0"}, want: true},
+		{name: "check fails", customInstall: &apps.CustomInstall{Script: "true", Check: "exit 1"}, want: false},
+		{name: "creates exists", customInstall: &apps.CustomInstall{Script: "true", Creates: existing}, want: true},
+		{name: "creates missing", customInstall: &apps.CustomInstall{Script: "true", Creates: filepath.Join(dir, "missing")}, want: false},
+		{name: "creates missing, check passes", customInstall: &apps.CustomInstall{Script: "true", Creates: filepath.Join(dir, "missing"), Check: "exit 0"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Custom{execCommand: exec.Command}
+			got, err := c.IsInstalled(tt.customInstall)
+			if err != nil {
+				t.Fatalf("IsInstalled() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsInstalled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustom_ExecuteSkipsWhenInstalled(t *testing.T) {
+	c := &Custom{
+		promptFunc: func(string) (bool, error) {
+			t.Fatal("Execute() should not prompt when the check passes")
+			return false, nil
+		},
+		execCommand: func(name string, args ...string) *exec.Cmd {
+			if args[len(args)-1] != "exit 0" {
+				t.Fatalf("Execute() ran %q, want only the check", args)
+			}
+			return exec.Command(name, args...)
+		},
+		logFunc: func(ScriptRun) error {
+			t.Fatal("Execute() should not log a skipped script")
+			return nil
+		},
+	}
+	if err := c.Execute(&apps.CustomInstall{Script: "exit 1", Check: "exit 0"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+}
+
+func TestCustom_ExecuteCheckStillFails(t *testing.T) {
+	c := &Custom{
+		promptFunc:  func(string) (bool, error) { return true, nil },
+		execCommand: exec.Command,
+	}
+	err := c.Execute(&apps.CustomInstall{Script: "true", Check: "exit 1"})
+	if err == nil || !strings.Contains(err.Error(), "still reports the app as not installed") {
+		t.Errorf("Execute() error = %v, want check failure", err)
+	}
+}
+
+func TestCustom_ExecuteRecordsRun(t *testing.T) {
+	var runs []ScriptRun
+	c := &Custom{
+		promptFunc:  func(string) (bool, error) { return true, nil },
+		execCommand: exec.Command,
+	}
+	c.SetLogFunc(func(run ScriptRun) error {
+		runs = append(runs, run)
+		return nil
+	})
+
+	script := "echo installed; echo warning >&2; exit 3"
+	if err := c.Execute(&apps.CustomInstall{Script: script}); err == nil {
+		t.Fatal("Execute() should return error for failing script")
+	}
+
+	want := []ScriptRun{{Script: script, ExitCode: 3, Stdout: "installed\n", Stderr: "warning\n"}}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("logged runs = %+v, want %+v", runs, want)
+	}
+}
+
 // Helper functions
 func boolPtr(b bool) *bool {
 	return &b
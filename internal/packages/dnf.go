@@ -40,6 +40,18 @@ func (d *Dnf) Install(pkg string) error {
 	return nil
 }
 
+// InstallBatch installs packages with a single dnf install call.
+func (d *Dnf) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, func(names []string) error {
+		execCmd := d.execCommand
+		if execCmd == nil {
+			execCmd = exec.Command
+		}
+		args := append([]string{"dnf", "install", "-y"}, names...)
+		return execCmd("sudo", args...).Run()
+	}, d.Install)
+}
+
 // Uninstall removes a package using dnf.
 func (d *Dnf) Uninstall(pkg string) error {
 	if pkg == "" {
@@ -86,6 +98,98 @@ func (d *Dnf) IsInstalled(pkg string) (bool, error) {
 	return strings.Contains(outputStr, pkg), nil
 }
 
+// InstalledVersion returns the installed version-release of a package, or
+// "" if it is not installed.
+func (d *Dnf) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := d.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("rpm", "-q", "--qf", "%{VERSION}-%{RELEASE}\n", pkg).Output()
+	if err != nil {
+		// rpm -q exits 1 when the package is not installed
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read installed version of %s: %w", pkg, err)
+	}
+	// Multilib packages print one line per architecture.
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return version, nil
+}
+
+// Outdated returns the installed packages with an update available, using
+// dnf check-update.
+func (d *Dnf) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	execCmd := d.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("dnf", append([]string{"check-update", "-q"}, pkgs...)...).Output()
+	if err == nil {
+		// Exit code 0 means no updates
+		return nil, nil
+	}
+	// Exit code 100 means updates are available
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 100 {
+		return nil, fmt.Errorf("failed to check for dnf upgrades: %w", err)
+	}
+
+	var outdated []OutdatedPackage
+	for _, line := range strings.Split(string(output), "\n") {
+		// Obsoleted packages are listed after the updates.
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		name := fields[0]
+		if i := strings.LastIndex(name, "."); i > 0 {
+			name = name[:i]
+		}
+		current, err := d.InstalledVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		outdated = append(outdated, OutdatedPackage{Name: name, Current: current, Available: fields[1]})
+	}
+	return outdated, nil
+}
+
+// Upgrade upgrades installed packages with dnf upgrade.
+func (d *Dnf) Upgrade(pkgs []string) map[string]error {
+	execCmd := d.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	upgrade := func(names []string) error {
+		args := append([]string{"dnf", "upgrade", "-y"}, names...)
+		output, err := execCmd("sudo", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via dnf: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+		}
+		return nil
+	}
+	return runBatch(pkgs, upgrade, func(name string) error { return upgrade([]string{name}) })
+}
+
+// PinVersion returns the dnf argument that installs version of pkg.
+func (d *Dnf) PinVersion(pkg, version string) string {
+	return pkg + "-" + version
+}
+
 // Name returns the package manager name.
 func (d *Dnf) Name() string {
 	return "dnf"
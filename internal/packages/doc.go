@@ -3,11 +3,20 @@
 // # Responsibility
 //
 // This package handles:
-//   - Installing/uninstalling packages via brew, apt, dnf
+//   - Installing/uninstalling packages via brew, apt, dnf, flatpak, snap, nix
+//     and the user-level language managers cargo, go install, pipx, uv and npm
+//   - User-defined package managers declared as command templates in config.yaml
+//   - Installing checksum-verified binaries from upstream release archives
+//   - Installing many packages with one manager call (InstallPackages)
+//   - Reading installed versions, installing pinned versions, and the
+//     gdf.lock file (Lock)
+//   - Listing outdated packages and upgrading only the given packages
 //   - Checking if packages are installed
 //   - Running custom install scripts with user confirmation
 //   - Handling OS-specific package names
-//   - Repository and GPG key management for apt
+//   - Homebrew taps and casks, and Brewfile import/export
+//   - Repository and GPG key management for apt (per-repository keyrings,
+//     deb822 sources with Signed-By, optional key fingerprint pinning)
 //
 // # Key Types
 //
@@ -15,8 +24,13 @@
 //   - Brew: Homebrew/Linuxbrew implementation
 //   - Apt: Debian/Ubuntu apt implementation
 //   - Dnf: Fedora/RHEL dnf implementation
+//   - Flatpak, Snap, Nix: Cross-distribution package manager implementations
+//   - Release: Checksum-verified binaries from upstream release archives
+//   - CommandManager: User-defined package manager from config.yaml command templates
+//   - Cargo, GoInstall, Pipx, Uv, Npm: Language package manager implementations
 //   - Custom: Custom script-based installation with security controls
 //   - Installer: Coordinator for selecting and using package managers
+//   - Lock: Installed package versions per platform, stored in gdf.lock
 //
 // # Usage
 //
@@ -31,7 +45,7 @@
 //
 //	installer := packages.NewInstaller()
 //	pkg := &apps.Package{
-//	    Brew: "kubectl",
+//	    Brew: apps.BrewFormula("kubectl"),
 //	    Apt: &apps.AptPackage{Name: "kubectl"},
 //	}
 //	if err := installer.Install(pkg, platform); err != nil {
@@ -44,5 +58,6 @@
 //   - Always prompts before execution (cannot be bypassed)
 //   - Displays script source for review
 //   - Clearly indicates when sudo is required
-//   - Logs all executions for audit trail
+//   - Skips scripts whose check command passes or creates path exists
+//   - Logs executions and their output for audit when security.log_scripts is on
 package packages
@@ -0,0 +1,235 @@
+package packages
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+const (
+	// defaultFlatpakRemote is the remote applications are installed from
+	// unless they name another.
+	defaultFlatpakRemote = "flathub"
+
+	// flathubRemoteURL is used to add the flathub remote when it is missing.
+	flathubRemoteURL = "https://dl.flathub.org/repo/flathub.flatpakrepo"
+)
+
+// Flatpak implements the Manager interface for Flatpak applications,
+// installed per user.
+type Flatpak struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewFlatpak creates a new Flatpak package manager.
+func NewFlatpak() *Flatpak {
+	return &Flatpak{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs an application from flathub.
+func (f *Flatpak) Install(pkg string) error {
+	return f.InstallPackage(&apps.FlatpakPackage{ID: pkg})
+}
+
+// InstallPackage installs an application from its remote, adding the remote
+// first if it is missing.
+func (f *Flatpak) InstallPackage(fp *apps.FlatpakPackage) error {
+	if fp == nil {
+		return fmt.Errorf("flatpak package configuration cannot be nil")
+	}
+	if fp.ID == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	remote := fp.Remote
+	if remote == "" {
+		remote = defaultFlatpakRemote
+	}
+	if err := f.addRemote(remote, fp.RemoteURL); err != nil {
+		return err
+	}
+	return f.install(remote, []string{fp.ID})
+}
+
+// InstallBatch installs applications from flathub with a single flatpak
+// install call.
+func (f *Flatpak) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, func(names []string) error {
+		if err := f.addRemote(defaultFlatpakRemote, ""); err != nil {
+			return err
+		}
+		return f.install(defaultFlatpakRemote, names)
+	}, f.Install)
+}
+
+func (f *Flatpak) install(remote string, ids []string) error {
+	execCmd := f.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	args := append([]string{"install", "--user", "-y", "--noninteractive", remote}, ids...)
+	output, err := execCmd("flatpak", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via flatpak: %w\nOutput: %s", strings.Join(ids, " "), err, string(output))
+	}
+	return nil
+}
+
+// addRemote adds a per-user remote unless it already exists. url defaults
+// to the Flathub URL for flathub; other remotes must already exist when url
+// is empty.
+func (f *Flatpak) addRemote(remote, url string) error {
+	if url == "" {
+		if remote != defaultFlatpakRemote {
+			return nil
+		}
+		url = flathubRemoteURL
+	}
+
+	execCmd := f.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("flatpak", "remote-add", "--user", "--if-not-exists", remote, url).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add flatpak remote %s: %w\nOutput: %s", remote, err, string(output))
+	}
+	return nil
+}
+
+// Uninstall removes an application using flatpak uninstall.
+func (f *Flatpak) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := f.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("flatpak", "uninstall", "-y", "--noninteractive", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via flatpak: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if an application is installed, per user or
+// system-wide.
+func (f *Flatpak) IsInstalled(pkg string) (bool, error) {
+	if pkg == "" {
+		return false, fmt.Errorf("package name cannot be empty")
+	}
+	installed, err := f.list("list", "--app")
+	if err != nil {
+		return false, err
+	}
+	_, ok := installed[pkg]
+	return ok, nil
+}
+
+// InstalledVersion returns the installed version of an application, or ""
+// if it is not installed. Applications without a version report their
+// branch.
+func (f *Flatpak) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+	installed, err := f.list("list", "--app")
+	if err != nil {
+		return "", err
+	}
+	return installed[pkg], nil
+}
+
+// list runs a flatpak listing command and maps each application ID to its
+// version (or branch when it has none).
+func (f *Flatpak) list(args ...string) (map[string]string, error) {
+	execCmd := f.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	args = append(args, "--columns=application,version,branch")
+	output, err := execCmd("flatpak", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flatpak applications: %w", err)
+	}
+
+	versions := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 || fields[0] == "" {
+			continue
+		}
+		version := fields[1]
+		if version == "" {
+			version = fields[2]
+		}
+		versions[fields[0]] = version
+	}
+	return versions, nil
+}
+
+// Outdated returns the installed applications with an update on their
+// remote.
+func (f *Flatpak) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	installed, err := f.list("list", "--app")
+	if err != nil {
+		return nil, err
+	}
+	updates, err := f.list("remote-ls", "--updates", "--app")
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		current, ok := installed[pkg]
+		if !ok {
+			continue
+		}
+		if available, ok := updates[pkg]; ok {
+			outdated = append(outdated, OutdatedPackage{Name: pkg, Current: current, Available: available})
+		}
+	}
+	return outdated, nil
+}
+
+// Upgrade updates installed applications with flatpak update.
+func (f *Flatpak) Upgrade(pkgs []string) map[string]error {
+	execCmd := f.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	update := func(ids []string) error {
+		args := append([]string{"update", "-y", "--noninteractive"}, ids...)
+		output, err := execCmd("flatpak", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via flatpak: %w\nOutput: %s", strings.Join(ids, " "), err, string(output))
+		}
+		return nil
+	}
+	return runBatch(pkgs, update, func(id string) error { return update([]string{id}) })
+}
+
+// Name returns the package manager name.
+func (f *Flatpak) Name() string {
+	return "flatpak"
+}
+
+// IsAvailable checks if flatpak is available on the system.
+func (f *Flatpak) IsAvailable() bool {
+	_, err := lookPath("flatpak")
+	return err == nil
+}
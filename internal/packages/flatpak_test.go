@@ -0,0 +1,98 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func TestFlatpak_InstallPackage(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  *apps.FlatpakPackage
+		want []string
+	}{
+		{
+			name: "flathub remote is added",
+			pkg:  &apps.FlatpakPackage{ID: "org.mozilla.firefox"},
+			want: []string{
+				"flatpak remote-add --user --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo",
+				"flatpak install --user -y --noninteractive flathub org.mozilla.firefox",
+			},
+		},
+		{
+			name: "custom remote with url",
+			pkg:  &apps.FlatpakPackage{ID: "org.gnome.Builder", Remote: "gnome-nightly", RemoteURL: "https://nightly.gnome.org/gnome-nightly.flatpakrepo"},
+			want: []string{
+				"flatpak remote-add --user --if-not-exists gnome-nightly https://nightly.gnome.org/gnome-nightly.flatpakrepo",
+				"flatpak install --user -y --noninteractive gnome-nightly org.gnome.Builder",
+			},
+		},
+		{
+			name: "existing custom remote",
+			pkg:  &apps.FlatpakPackage{ID: "org.example.App", Remote: "example"},
+			want: []string{"flatpak install --user -y --noninteractive example org.example.App"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			f := &Flatpak{execCommand: fakeInstaller(nil, &calls)}
+			if err := f.InstallPackage(tt.pkg); err != nil {
+				t.Fatalf("InstallPackage() error = %v", err)
+			}
+			if !reflect.DeepEqual(calls, tt.want) {
+				t.Errorf("calls = %q, want %q", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatpak_Outdated(t *testing.T) {
+	f := &Flatpak{execCommand: func(name string, args ...string) *exec.Cmd {
+		if args[0] == "remote-ls" {
+			return scripted("org.mozilla.firefox\t125.0\tstable\n", 0)
+		}
+		return scripted("org.mozilla.firefox\t124.0\tstable\ncom.spotify.Client\t\tstable\n", 0)
+	}}
+
+	version, err := f.InstalledVersion("com.spotify.Client")
+	if err != nil || version != "stable" {
+		t.Errorf("InstalledVersion() = %q, %v, want the branch", version, err)
+	}
+	got, err := f.Outdated([]string{"org.mozilla.firefox", "com.spotify.Client", "org.gimp.GIMP"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "org.mozilla.firefox", Current: "124.0", Available: "125.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInstallPackages_FlatpakAndSnap(t *testing.T) {
+	var calls []string
+	record := func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		return exec.Command("true")
+	}
+
+	errs := InstallPackages(&Snap{execCommand: record}, []BatchItem{
+		{Name: "code", Package: &apps.Package{Snap: &apps.SnapPackage{Name: "code", Classic: true}}},
+		{Name: "spotify", Package: &apps.Package{Snap: &apps.SnapPackage{Name: "spotify"}}},
+		{Name: "vlc", Package: &apps.Package{Snap: &apps.SnapPackage{Name: "vlc"}}},
+	})
+	if errs != nil {
+		t.Fatalf("InstallPackages() errors = %v", errs)
+	}
+	want := []string{
+		"sudo snap install --classic code",
+		"sudo snap install spotify vlc",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,237 @@
+package packages
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// majorVersionRegex matches the major version suffix of a module path.
+var majorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// GoInstall implements the Manager interface for Go programs installed with
+// go install. Packages are package paths, optionally with an @version
+// (default: @latest).
+type GoInstall struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewGoInstall creates a new GoInstall package manager.
+func NewGoInstall() *GoInstall {
+	return &GoInstall{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs a Go program using go install.
+func (g *GoInstall) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := g.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	target := pkg
+	if !strings.Contains(target, "@") {
+		target += "@latest"
+	}
+	output, err := execCmd("go", "install", target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via go: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// InstallBatch installs Go programs one at a time: go install only accepts
+// several versioned packages from the same module.
+func (g *GoInstall) InstallBatch(pkgs []string) map[string]error {
+	return runEach(pkgs, g.Install)
+}
+
+// Uninstall removes the installed binary of a Go program.
+func (g *GoInstall) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	bin, err := g.binaryPath(pkg)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(bin); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to uninstall %s: %w", pkg, err)
+	}
+	return nil
+}
+
+// IsInstalled checks if the binary of a Go program is installed.
+func (g *GoInstall) IsInstalled(pkg string) (bool, error) {
+	if pkg == "" {
+		return false, fmt.Errorf("package name cannot be empty")
+	}
+	bin, err := g.binaryPath(pkg)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(bin); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// InstalledVersion returns the module version the installed binary was
+// built from, or "" if it is not installed.
+func (g *GoInstall) InstalledVersion(pkg string) (string, error) {
+	_, version, err := g.installedModule(pkg)
+	return version, err
+}
+
+// installedModule reads the module path and version embedded in the
+// installed binary of pkg.
+func (g *GoInstall) installedModule(pkg string) (string, string, error) {
+	installed, err := g.IsInstalled(pkg)
+	if err != nil || !installed {
+		return "", "", err
+	}
+	bin, err := g.binaryPath(pkg)
+	if err != nil {
+		return "", "", err
+	}
+
+	execCmd := g.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("go", "version", "-m", bin).Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read build info of %s: %w", bin, err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" {
+			return fields[1], fields[2], nil
+		}
+	}
+	return "", "", nil
+}
+
+// Outdated returns the installed Go programs whose module has a newer
+// version than the one they were built from. Packages pinned to a version
+// (path@v1.2.3) are never outdated.
+func (g *GoInstall) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	execCmd := g.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		if goPinned(pkg) {
+			continue
+		}
+		module, current, err := g.installedModule(pkg)
+		if err != nil {
+			return nil, err
+		}
+		// Binaries built from a local checkout report "(devel)".
+		if module == "" || current == "(devel)" {
+			continue
+		}
+		output, err := execCmd("go", "list", "-m", "-f", "{{.Version}}", module+"@latest").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for go upgrades of %s: %w", pkg, err)
+		}
+		if latest := strings.TrimSpace(string(output)); latest != "" && latest != current {
+			outdated = append(outdated, OutdatedPackage{Name: pkg, Current: current, Available: latest})
+		}
+	}
+	return outdated, nil
+}
+
+// Upgrade reinstalls Go programs at their latest version. Packages pinned
+// to a version are left as they are.
+func (g *GoInstall) Upgrade(pkgs []string) map[string]error {
+	return runEach(pkgs, func(pkg string) error {
+		if pkg == "" {
+			return fmt.Errorf("package name cannot be empty")
+		}
+		if goPinned(pkg) {
+			return nil
+		}
+		return g.Install(goPackagePath(pkg) + "@latest")
+	})
+}
+
+// goPinned reports whether pkg selects a version other than @latest.
+func goPinned(pkg string) bool {
+	_, version, ok := strings.Cut(pkg, "@")
+	return ok && version != "latest"
+}
+
+// PinVersion returns the go install argument that installs version of pkg.
+func (g *GoInstall) PinVersion(pkg, version string) string {
+	return goPackagePath(pkg) + "@" + version
+}
+
+// Name returns the package manager name.
+func (g *GoInstall) Name() string {
+	return "go"
+}
+
+// IsAvailable checks if go is available on the system.
+func (g *GoInstall) IsAvailable() bool {
+	_, err := lookPath("go")
+	return err == nil
+}
+
+// binaryPath returns where go install puts the binary of pkg: GOBIN, or the
+// bin directory of the first GOPATH entry.
+func (g *GoInstall) binaryPath(pkg string) (string, error) {
+	execCmd := g.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("go", "env", "GOBIN", "GOPATH").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read go environment: %w", err)
+	}
+	gobin, gopath, _ := strings.Cut(string(output), "\n")
+	dir := strings.TrimSpace(gobin)
+	if dir == "" {
+		paths := filepath.SplitList(strings.TrimSpace(gopath))
+		if len(paths) == 0 || paths[0] == "" {
+			return "", fmt.Errorf("neither GOBIN nor GOPATH is set")
+		}
+		dir = filepath.Join(paths[0], "bin")
+	}
+	return filepath.Join(dir, goBinaryName(pkg)), nil
+}
+
+// goPackagePath strips the version from pkg.
+func goPackagePath(pkg string) string {
+	pkgPath, _, _ := strings.Cut(pkg, "@")
+	return pkgPath
+}
+
+// goBinaryName returns the name go install gives the binary of pkg: the
+// last path element, skipping a major version suffix such as /v4.
+func goBinaryName(pkg string) string {
+	pkgPath := goPackagePath(pkg)
+	name := path.Base(pkgPath)
+	if majorVersionRegex.MatchString(name) && path.Dir(pkgPath) != "." {
+		name = path.Base(path.Dir(pkgPath))
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+package packages
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGoBinaryName(t *testing.T) {
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"golang.org/x/tools/gopls", "gopls"},
+		{"golang.org/x/tools/gopls@v0.15.0", "gopls"},
+		{"github.com/mikefarah/yq/v4", "yq"},
+		{"github.com/mikefarah/yq/v4@latest", "yq"},
+	}
+	for _, tt := range tests {
+		if got := goBinaryName(tt.pkg); got != tt.want {
+			t.Errorf("goBinaryName(%q) = %q, want %q", tt.pkg, got, tt.want)
+		}
+	}
+}
+
+// fakeGo mocks the go command with binaries in gobin and modules at the
+// given latest versions.
+func fakeGo(gobin string, latest map[string]string, calls *[]string) func(string, ...string) *exec.Cmd {
+	return func(name string, args ...string) *exec.Cmd {
+		*calls = append(*calls, strings.Join(append([]string{name}, args...), " "))
+		switch args[0] {
+		case "env":
+			return scripted(gobin+"\n\n", 0)
+		case "version":
+			return scripted(args[2]+": go1.24.0\n\tpath\tgithub.com/mikefarah/yq/v4\n\tmod\tgithub.com/mikefarah/yq/v4\tv4.44.1\th1:abc=\n", 0)
+		case "list":
+			module, _, _ := strings.Cut(args[len(args)-1], "@")
+			return scripted(latest[module]+"\n", 0)
+		}
+		return exec.Command("true")
+	}
+}
+
+func TestGoInstall(t *testing.T) {
+	gobin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(gobin, "yq"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	g := &GoInstall{execCommand: fakeGo(gobin, map[string]string{"github.com/mikefarah/yq/v4": "v4.45.0"}, &calls)}
+
+	if installed, err := g.IsInstalled("golang.org/x/tools/gopls"); err != nil || installed {
+		t.Errorf("IsInstalled(gopls) = %v, %v, want false", installed, err)
+	}
+	version, err := g.InstalledVersion("github.com/mikefarah/yq/v4")
+	if err != nil || version != "v4.44.1" {
+		t.Errorf("InstalledVersion(yq) = %q, %v, want v4.44.1", version, err)
+	}
+
+	outdated, err := g.Outdated([]string{"github.com/mikefarah/yq/v4", "golang.org/x/tools/gopls"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "github.com/mikefarah/yq/v4", Current: "v4.44.1", Available: "v4.45.0"}}
+	if !reflect.DeepEqual(outdated, want) {
+		t.Errorf("Outdated() = %+v, want %+v", outdated, want)
+	}
+
+	// A package pinned to a version is neither outdated nor upgraded.
+	calls = nil
+	pinned := "github.com/mikefarah/yq/v4@v4.44.1"
+	if outdated, err := g.Outdated([]string{pinned}); err != nil || outdated != nil {
+		t.Errorf("Outdated(pinned) = %+v, %v, want none", outdated, err)
+	}
+	if errs := g.Upgrade([]string{pinned}); errs != nil {
+		t.Errorf("Upgrade(pinned) errors = %v", errs)
+	}
+	if len(calls) != 0 {
+		t.Errorf("calls for pinned package = %q, want none", calls)
+	}
+
+	calls = nil
+	if errs := g.InstallBatch([]string{"golang.org/x/tools/gopls", g.PinVersion("github.com/mikefarah/yq/v4@latest", "v4.44.1")}); errs != nil {
+		t.Fatalf("InstallBatch() errors = %v", errs)
+	}
+	wantCalls := []string{
+		"go install golang.org/x/tools/gopls@latest",
+		"go install github.com/mikefarah/yq/v4@v4.44.1",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", calls, wantCalls)
+	}
+
+	if err := g.Uninstall("github.com/mikefarah/yq/v4"); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(gobin, "yq")); !os.IsNotExist(err) {
+		t.Errorf("binary still exists after Uninstall(): %v", err)
+	}
+}
@@ -44,7 +44,7 @@ func (i *Installer) SelectManager(pkg *apps.Package, p *platform.Platform) Manag
 	// Platform-based selection
 	switch {
 	case p.IsMacOS():
-		if pkg.Brew != "" {
+		if pkg.Brew != nil {
 			return NewBrew()
 		}
 	case p.IsDebian() || (p.IsWSL() && p.IsDebian()):
@@ -80,7 +80,7 @@ func (i *Installer) getPreferredManager(pkg *apps.Package, p *platform.Platform)
 
 	switch preferred {
 	case "brew":
-		if pkg.Brew != "" {
+		if pkg.Brew != nil {
 			return NewBrew()
 		}
 	case "apt":
@@ -91,6 +91,42 @@ func (i *Installer) getPreferredManager(pkg *apps.Package, p *platform.Platform)
 		if pkg.Dnf != "" {
 			return NewDnf()
 		}
+	case "cargo":
+		if pkg.Cargo != "" {
+			return NewCargo()
+		}
+	case "go":
+		if pkg.Go != "" {
+			return NewGoInstall()
+		}
+	case "pipx":
+		if pkg.Pipx != "" {
+			return NewPipx()
+		}
+	case "npm":
+		if pkg.Npm != "" {
+			return NewNpm()
+		}
+	case "uv":
+		if pkg.Uv != "" {
+			return NewUv()
+		}
+	case "flatpak":
+		if pkg.Flatpak != nil {
+			return NewFlatpak()
+		}
+	case "snap":
+		if pkg.Snap != nil {
+			return NewSnap()
+		}
+	case "nix":
+		if pkg.Nix != "" {
+			return NewNix()
+		}
+	case "release":
+		if pkg.Release != nil {
+			return NewRelease()
+		}
 	}
 
 	return nil
@@ -106,23 +142,14 @@ func (i *Installer) Install(pkg *apps.Package, p *platform.Platform) error {
 	mgr := i.managerFor(pkg, p)
 
 	if mgr.Name() != "none" {
-		// Check if already installed
+		// Get package name for the selected manager
 		if pkgName := i.getPackageName(pkg, mgr.Name()); pkgName != "" {
-			installed, err := mgr.IsInstalled(pkgName)
+			// Check if already installed
+			installed, err := IsPackageInstalled(mgr, pkg, pkgName)
 			if err == nil && installed {
 				return nil
 			}
-		}
-		// For apt with repo configuration, use special method
-		if mgr.Name() == "apt" && pkg.Apt != nil && (pkg.Apt.Repo != "" || pkg.Apt.Key != "") {
-			aptMgr := mgr.(*Apt)
-			return aptMgr.InstallWithRepo(pkg.Apt)
-		}
-
-		// Get package name for the selected manager
-		pkgName := i.getPackageName(pkg, mgr.Name())
-		if pkgName != "" {
-			return mgr.Install(pkgName)
+			return InstallPackage(mgr, pkg, pkgName)
 		}
 	}
 
@@ -143,7 +170,10 @@ func (i *Installer) IsInstalled(pkg *apps.Package, p *platform.Platform) (bool,
 
 	mgr := i.managerFor(pkg, p)
 	if mgr.Name() == "none" {
-		// Can't check if custom script installed something
+		// Custom scripts are checked with their check command or creates path
+		if pkg.Custom != nil {
+			return i.custom.IsInstalled(pkg.Custom)
+		}
 		return false, nil
 	}
 
@@ -152,20 +182,79 @@ func (i *Installer) IsInstalled(pkg *apps.Package, p *platform.Platform) (bool,
 		return false, nil
 	}
 
-	return mgr.IsInstalled(pkgName)
+	return IsPackageInstalled(mgr, pkg, pkgName)
+}
+
+// InstallPackage installs name with mgr. Managers with richer package
+// configuration use it: apt sets up the repository, brew adds the tap and
+// installs casks, flatpak adds the remote, snap sets confinement and
+// channel, and release downloads and verifies the archive.
+func InstallPackage(mgr Manager, pkg *apps.Package, name string) error {
+	if pkg != nil {
+		switch m := mgr.(type) {
+		case *Apt:
+			if pkg.Apt != nil && (pkg.Apt.Repo != "" || pkg.Apt.Key != "") {
+				return m.InstallWithRepo(pkg.Apt)
+			}
+		case *Brew:
+			if pkg.Brew != nil {
+				return m.InstallPackage(pkg.Brew)
+			}
+		case *Flatpak:
+			if pkg.Flatpak != nil {
+				return m.InstallPackage(pkg.Flatpak)
+			}
+		case *Snap:
+			if pkg.Snap != nil {
+				return m.InstallPackage(pkg.Snap)
+			}
+		case *Release:
+			if pkg.Release != nil {
+				return m.InstallPackage(pkg.Release)
+			}
+		}
+	}
+	return mgr.Install(name)
+}
+
+// IsPackageInstalled checks whether name is installed via mgr, checking
+// brew casks as casks. A release binary is only installed at the
+// configured version, so changing the version reinstalls it.
+func IsPackageInstalled(mgr Manager, pkg *apps.Package, name string) (bool, error) {
+	if m, ok := mgr.(*Brew); ok && pkg != nil && pkg.Brew != nil {
+		return m.IsPackageInstalled(pkg.Brew)
+	}
+	if m, ok := mgr.(*Release); ok && pkg != nil && pkg.Release != nil {
+		return m.IsPackageInstalled(pkg.Release)
+	}
+	return mgr.IsInstalled(name)
+}
+
+// InstalledPackageVersion returns the installed version of name via mgr,
+// reading brew casks as casks. It returns "" if the package is not installed.
+func InstalledPackageVersion(mgr Manager, pkg *apps.Package, name string) (string, error) {
+	if m, ok := mgr.(*Brew); ok && pkg != nil && pkg.Brew != nil {
+		return m.PackageVersion(pkg.Brew)
+	}
+	return mgr.InstalledVersion(name)
 }
 
 // getPackageName returns the package name for the specified manager.
 func (i *Installer) getPackageName(pkg *apps.Package, mgrName string) string {
 	switch mgrName {
 	case "brew":
-		return pkg.Brew
+		if pkg.Brew != nil {
+			return pkg.Brew.Name
+		}
 	case "apt":
 		if pkg.Apt != nil {
 			return pkg.Apt.Name
 		}
 	case "dnf":
 		return pkg.Dnf
+	default:
+		name, _ := pkg.ResolveName(mgrName)
+		return name
 	}
 	return ""
 }
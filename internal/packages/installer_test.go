@@ -27,6 +27,33 @@ func (m *mockManager) Install(pkg string) error {
 	return m.installErr
 }
 
+func (m *mockManager) InstallBatch(pkgs []string) map[string]error {
+	m.installCalls++
+	if m.installErr == nil {
+		return nil
+	}
+	errs := make(map[string]error)
+	for _, pkg := range pkgs {
+		errs[pkg] = m.installErr
+	}
+	return errs
+}
+
+func (m *mockManager) InstalledVersion(pkg string) (string, error) {
+	if !m.installed {
+		return "", m.isInstalledErr
+	}
+	return "1.0.0", nil
+}
+
+func (m *mockManager) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	return nil, nil
+}
+
+func (m *mockManager) Upgrade(pkgs []string) map[string]error {
+	return nil
+}
+
 func (m *mockManager) Uninstall(pkg string) error {
 	m.uninstallCalls++
 	return m.uninstallErr
@@ -50,7 +77,7 @@ func TestInstaller_SelectManager(t *testing.T) {
 		{
 			name: "macOS uses brew",
 			pkg: &apps.Package{
-				Brew: "kubectl",
+				Brew: apps.BrewFormula("kubectl"),
 			},
 			platform: &platform.Platform{
 				OS: "macos",
@@ -85,7 +112,7 @@ func TestInstaller_SelectManager(t *testing.T) {
 		{
 			name: "prefer override - use brew on linux",
 			pkg: &apps.Package{
-				Brew: "kubectl",
+				Brew: apps.BrewFormula("kubectl"),
 				Apt:  &apps.AptPackage{Name: "kubectl"},
 				Prefer: &apps.Prefer{
 					Linux: "brew",
@@ -113,7 +140,7 @@ func TestInstaller_SelectManager(t *testing.T) {
 		{
 			name: "no matching package manager",
 			pkg: &apps.Package{
-				Brew: "only-on-mac",
+				Brew: apps.BrewFormula("only-on-mac"),
 			},
 			platform: &platform.Platform{
 				OS:     "linux",
@@ -165,7 +192,7 @@ func TestInstaller_Install_SkipsAlreadyInstalled(t *testing.T) {
 		},
 	}
 
-	err := installer.Install(&apps.Package{Brew: "ripgrep"}, &platform.Platform{OS: "macos"})
+	err := installer.Install(&apps.Package{Brew: apps.BrewFormula("ripgrep")}, &platform.Platform{OS: "macos"})
 	if err != nil {
 		t.Fatalf("Install() unexpected error: %v", err)
 	}
@@ -183,7 +210,7 @@ func TestInstaller_Install_InstallsWhenCheckFails(t *testing.T) {
 		},
 	}
 
-	err := installer.Install(&apps.Package{Brew: "ripgrep"}, &platform.Platform{OS: "macos"})
+	err := installer.Install(&apps.Package{Brew: apps.BrewFormula("ripgrep")}, &platform.Platform{OS: "macos"})
 	if err != nil {
 		t.Fatalf("Install() unexpected error: %v", err)
 	}
@@ -0,0 +1,92 @@
+package packages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/schema"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the name of the lock file in the repository root.
+const LockFileName = "gdf.lock"
+
+const lockHeader = "# Generated by gdf lock refresh - DO NOT EDIT MANUALLY\n"
+
+// Lock records the installed package version of each app, per platform.
+type Lock struct {
+	schema.TypeMeta `yaml:",inline"`
+
+	// Platforms maps a platform key (see LockPlatformKey) to the locked
+	// package of each app on that platform.
+	Platforms map[string]map[string]LockEntry `yaml:"platforms"`
+}
+
+// LockEntry is the locked package of one app.
+type LockEntry struct {
+	Manager string `yaml:"manager"`
+	Package string `yaml:"package"`
+	Version string `yaml:"version"`
+}
+
+// LockPlatformKey returns the lock file key for p: the OS, the distribution
+// and its release on Linux and WSL, and the architecture
+// ("macos/arm64", "linux/ubuntu/noble/amd64", "linux/arch/amd64"). Package
+// versions differ between releases and architectures, so each gets its own
+// entries.
+func LockPlatformKey(p *platform.Platform) string {
+	parts := []string{p.OS}
+	for _, part := range []string{p.Distro, p.Release, p.Arch} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// LoadLock reads a lock file. A missing file is an empty lock.
+func LoadLock(path string) (*Lock, error) {
+	lock := &Lock{Platforms: map[string]map[string]LockEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("reading lock file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parsing lock file %s: %w", filepath.Base(path), err)
+	}
+	if err := lock.ValidateKind("Lock"); err != nil {
+		return nil, fmt.Errorf("validating lock file version: %w", err)
+	}
+	if lock.Platforms == nil {
+		lock.Platforms = map[string]map[string]LockEntry{}
+	}
+	return lock, nil
+}
+
+// Save writes the lock file.
+func (l *Lock) Save(path string) error {
+	l.Kind = "Lock/v1"
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("marshaling lock file: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, append([]byte(lockHeader), data...), 0644); err != nil {
+		return fmt.Errorf("writing lock file: %w", err)
+	}
+	return nil
+}
+
+// Entries returns the locked packages for a platform key, or nil.
+func (l *Lock) Entries(platformKey string) map[string]LockEntry {
+	if l == nil {
+		return nil
+	}
+	return l.Platforms[platformKey]
+}
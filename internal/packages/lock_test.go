@@ -0,0 +1,119 @@
+package packages
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+func TestLock_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+
+	lock, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("LoadLock() of missing file error = %v", err)
+	}
+	if len(lock.Platforms) != 0 {
+		t.Fatalf("LoadLock() of missing file = %+v, want empty", lock)
+	}
+
+	lock.Platforms["linux/ubuntu"] = map[string]LockEntry{
+		"git": {Manager: "apt", Package: "git", Version: "1:2.43.0-1ubuntu7"},
+	}
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("LoadLock() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Platforms, lock.Platforms) || got.Kind != "Lock/v1" {
+		t.Errorf("LoadLock() = %+v, want %+v", got, lock)
+	}
+	if entries := got.Entries("macos"); entries != nil {
+		t.Errorf("Entries(macos) = %v, want nil", entries)
+	}
+
+	if err := os.WriteFile(path, []byte("kind: Config/v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLock(path); err == nil {
+		t.Error("LoadLock() with wrong kind error = nil, want error")
+	}
+}
+
+func TestLockPlatformKey(t *testing.T) {
+	tests := []struct {
+		plat *platform.Platform
+		want string
+	}{
+		{&platform.Platform{OS: "macos", Arch: "arm64"}, "macos/arm64"},
+		{&platform.Platform{OS: "linux", Distro: "ubuntu", Release: "noble", Arch: "amd64"}, "linux/ubuntu/noble/amd64"},
+		{&platform.Platform{OS: "wsl", Distro: "debian", Release: "bookworm", Arch: "amd64"}, "wsl/debian/bookworm/amd64"},
+		{&platform.Platform{OS: "linux", Distro: "arch", Arch: "arm64"}, "linux/arch/arm64"},
+	}
+	for _, tt := range tests {
+		if got := LockPlatformKey(tt.plat); got != tt.want {
+			t.Errorf("LockPlatformKey(%+v) = %q, want %q", tt.plat, got, tt.want)
+		}
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	printing := func(output string, code int) func(string, ...string) *exec.Cmd {
+		return func(string, ...string) *exec.Cmd {
+			if code != 0 {
+				return exec.Command("sh", "-c", "exit 1")
+			}
+			return exec.Command("printf", "%s", output)
+		}
+	}
+	tests := []struct {
+		name string
+		mgr  Manager
+		pkg  *apps.Package
+		want string
+	}{
+		{
+			name: "apt installed",
+			mgr:  &Apt{execCommand: printing("install ok installed\t1:2.43.0-1ubuntu7", 0)},
+			want: "1:2.43.0-1ubuntu7",
+		},
+		{
+			name: "apt config files only",
+			mgr:  &Apt{execCommand: printing("deinstall ok config-files\t2.43.0", 0)},
+			want: "",
+		},
+		{name: "apt unknown", mgr: &Apt{execCommand: printing("", 1)}, want: ""},
+		{
+			name: "dnf multilib",
+			mgr:  &Dnf{execCommand: printing("2.43.0-1.fc40\n2.43.0-1.fc40\n", 0)},
+			want: "2.43.0-1.fc40",
+		},
+		{name: "dnf not installed", mgr: &Dnf{execCommand: printing("package git is not installed\n", 1)}, want: ""},
+		{name: "brew formula", mgr: &Brew{execCommand: printing("git 2.43.0 2.44.0\n", 0)}, want: "2.44.0"},
+		{
+			name: "brew cask",
+			mgr:  &Brew{execCommand: printing("firefox 124.0\n", 0)},
+			pkg:  &apps.Package{Brew: &apps.BrewPackage{Name: "firefox", Cask: true}},
+			want: "124.0",
+		},
+		{name: "brew not installed", mgr: &Brew{execCommand: printing("", 1)}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InstalledPackageVersion(tt.mgr, tt.pkg, "git")
+			if err != nil {
+				t.Fatalf("InstalledPackageVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("InstalledPackageVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,16 +14,53 @@ type Manager interface {
 	// Install installs a package.
 	Install(pkg string) error
 
+	// InstallBatch installs several packages with one manager invocation
+	// and returns the error of each package that failed to install.
+	InstallBatch(pkgs []string) map[string]error
+
 	// Uninstall removes a package.
 	Uninstall(pkg string) error
 
 	// IsInstalled checks if a package is installed.
 	IsInstalled(pkg string) (bool, error)
 
+	// InstalledVersion returns the installed version of a package, or ""
+	// if it is not installed.
+	InstalledVersion(pkg string) (string, error)
+
+	// Outdated returns the packages among pkgs that are installed and have
+	// a newer version available.
+	Outdated(pkgs []string) ([]OutdatedPackage, error)
+
+	// Upgrade upgrades installed packages to their latest version and
+	// returns the error of each package that failed to upgrade.
+	Upgrade(pkgs []string) map[string]error
+
 	// Name returns the package manager name.
 	Name() string
 }
 
+// OutdatedPackage is an installed package with a newer version available.
+type OutdatedPackage struct {
+	Name      string `json:"name"`
+	Current   string `json:"current"`
+	Available string `json:"available"`
+}
+
+// Refresher is implemented by managers whose view of available versions
+// comes from a local index that must be refreshed first.
+type Refresher interface {
+	// Refresh updates the package index.
+	Refresh() error
+}
+
+// VersionPinner is implemented by managers that can install an exact
+// package version.
+type VersionPinner interface {
+	// PinVersion returns the install argument that selects version of pkg.
+	PinVersion(pkg, version string) string
+}
+
 // NoOpManager is a package manager that does nothing.
 // Used when no package manager is available or during graceful degradation.
 type NoOpManager struct{}
@@ -33,6 +70,11 @@ func (m *NoOpManager) Install(pkg string) error {
 	return nil
 }
 
+// InstallBatch returns nil (no-op).
+func (m *NoOpManager) InstallBatch(pkgs []string) map[string]error {
+	return nil
+}
+
 // Uninstall returns nil (no-op).
 func (m *NoOpManager) Uninstall(pkg string) error {
 	return nil
@@ -43,6 +85,21 @@ func (m *NoOpManager) IsInstalled(pkg string) (bool, error) {
 	return false, nil
 }
 
+// InstalledVersion always returns "" (no-op).
+func (m *NoOpManager) InstalledVersion(pkg string) (string, error) {
+	return "", nil
+}
+
+// Outdated returns nil (no-op).
+func (m *NoOpManager) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	return nil, nil
+}
+
+// Upgrade returns nil (no-op).
+func (m *NoOpManager) Upgrade(pkgs []string) map[string]error {
+	return nil
+}
+
 // Name returns "none".
 func (m *NoOpManager) Name() string {
 	return "none"
@@ -76,6 +76,14 @@ func TestForPlatform(t *testing.T) {
 			},
 			wantManager: "none",
 		},
+		{
+			name: "Arch Linux with nix returns noop (nix is opt-in)",
+			platform: &platform.Platform{
+				OS:     "linux",
+				Distro: "arch",
+			},
+			wantManager: "none",
+		},
 	}
 
 	// Mock lookPath to return success for the expected manager
@@ -90,10 +98,11 @@ func TestForPlatform(t *testing.T) {
 					"brew":    "brew",
 					"apt-get": "apt",
 					"dnf":     "dnf",
+					"nix":     "nix",
 				}
 
 				expected, ok := checkMap[file]
-				if ok && expected == tt.wantManager {
+				if ok && (expected == tt.wantManager || expected == "nix") {
 					return "/usr/bin/" + file, nil
 				}
 				return "", exec.ErrNotFound
@@ -0,0 +1,268 @@
+package packages
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// nixStorePathRegex matches a store path and captures its name-version.
+var nixStorePathRegex = regexp.MustCompile(`^/nix/store/[0-9a-z]{32}-(.+)$`)
+
+// Nix implements the Manager interface for packages installed into the
+// user's default profile with nix profile. Packages are flake installables
+// such as "nixpkgs#ripgrep"; a bare attribute is looked up in nixpkgs.
+type Nix struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewNix creates a new Nix package manager.
+func NewNix() *Nix {
+	return &Nix{
+		execCommand: exec.Command,
+	}
+}
+
+// nixInstallable returns pkg as a flake installable.
+func nixInstallable(pkg string) string {
+	if strings.Contains(pkg, "#") {
+		return pkg
+	}
+	return "nixpkgs#" + pkg
+}
+
+// nixAttribute returns the attribute part of an installable.
+func nixAttribute(pkg string) string {
+	_, attr, found := strings.Cut(pkg, "#")
+	if !found {
+		return pkg
+	}
+	return attr
+}
+
+// Install installs a package using nix profile install.
+func (n *Nix) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	return n.install([]string{pkg})
+}
+
+// InstallBatch installs packages with a single nix profile install call.
+func (n *Nix) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, n.install, n.Install)
+}
+
+func (n *Nix) install(pkgs []string) error {
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	args := []string{"profile", "install"}
+	for _, pkg := range pkgs {
+		args = append(args, nixInstallable(pkg))
+	}
+	output, err := execCmd("nix", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via nix: %w\nOutput: %s", strings.Join(pkgs, " "), err, string(output))
+	}
+	return nil
+}
+
+// Uninstall removes a package from the profile.
+func (n *Nix) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	elem, err := n.find(pkg)
+	if err != nil {
+		return err
+	}
+	if elem == nil {
+		return nil
+	}
+
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("nix", "profile", "remove", elem.selector).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via nix: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if a package is installed in the profile.
+func (n *Nix) IsInstalled(pkg string) (bool, error) {
+	if pkg == "" {
+		return false, fmt.Errorf("package name cannot be empty")
+	}
+	elem, err := n.find(pkg)
+	return elem != nil, err
+}
+
+// InstalledVersion returns the version of the installed package, taken from
+// its store path, or "" if it is not installed.
+func (n *Nix) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+	elem, err := n.find(pkg)
+	if err != nil || elem == nil {
+		return "", err
+	}
+	return elem.version(), nil
+}
+
+// nixElement is a package in the profile.
+type nixElement struct {
+	// selector identifies the element to nix profile remove and upgrade:
+	// its name, or its attribute path on Nix versions before 2.20.
+	selector   string
+	AttrPath   string   `json:"attrPath"`
+	StorePaths []string `json:"storePaths"`
+}
+
+// version returns the version suffix of the element's first store path.
+func (e *nixElement) version() string {
+	if len(e.StorePaths) == 0 {
+		return ""
+	}
+	m := nixStorePathRegex.FindStringSubmatch(e.StorePaths[0])
+	if m == nil {
+		return ""
+	}
+	// Versions start at the first dash followed by a digit: ripgrep-14.1.0.
+	name := m[1]
+	for i := 0; i < len(name)-1; i++ {
+		if name[i] == '-' && name[i+1] >= '0' && name[i+1] <= '9' {
+			return name[i+1:]
+		}
+	}
+	return ""
+}
+
+// find returns the profile element installed from pkg, or nil.
+func (n *Nix) find(pkg string) (*nixElement, error) {
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("nix", "profile", "list", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nix profile: %w", err)
+	}
+	elements, err := parseNixProfile(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// nixpkgs#ripgrep is installed as legacyPackages.<system>.ripgrep.
+	attr := nixAttribute(pkg)
+	for _, elem := range elements {
+		if elem.AttrPath == attr || strings.HasSuffix(elem.AttrPath, "."+attr) {
+			return elem, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseNixProfile parses nix profile list --json. Nix 2.20 and later key
+// elements by name; older versions list them in an array.
+func parseNixProfile(output []byte) ([]*nixElement, error) {
+	var profile struct {
+		Elements json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal(output, &profile); err != nil {
+		return nil, fmt.Errorf("parsing nix profile list output: %w", err)
+	}
+
+	var elements []*nixElement
+	var named map[string]*nixElement
+	if err := json.Unmarshal(profile.Elements, &named); err == nil {
+		for name, elem := range named {
+			elem.selector = name
+			elements = append(elements, elem)
+		}
+		return elements, nil
+	}
+	if err := json.Unmarshal(profile.Elements, &elements); err != nil {
+		return nil, fmt.Errorf("parsing nix profile list output: %w", err)
+	}
+	for _, elem := range elements {
+		elem.selector = elem.AttrPath
+	}
+	return elements, nil
+}
+
+// Outdated returns the installed packages whose flake now provides a
+// different version.
+func (n *Nix) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		elem, err := n.find(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if elem == nil {
+			continue
+		}
+		output, err := execCmd("nix", "eval", "--raw", nixInstallable(pkg)+".version").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for nix upgrades of %s: %w", pkg, err)
+		}
+		current, latest := elem.version(), strings.TrimSpace(string(output))
+		if latest != "" && latest != current {
+			outdated = append(outdated, OutdatedPackage{Name: pkg, Current: current, Available: latest})
+		}
+	}
+	return outdated, nil
+}
+
+// Upgrade upgrades installed packages with nix profile upgrade.
+func (n *Nix) Upgrade(pkgs []string) map[string]error {
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	return runEach(pkgs, func(pkg string) error {
+		if pkg == "" {
+			return fmt.Errorf("package name cannot be empty")
+		}
+		elem, err := n.find(pkg)
+		if err != nil {
+			return err
+		}
+		if elem == nil {
+			return fmt.Errorf("%s is not installed in the nix profile", pkg)
+		}
+		output, err := execCmd("nix", "profile", "upgrade", elem.selector).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via nix: %w\nOutput: %s", pkg, err, string(output))
+		}
+		return nil
+	})
+}
+
+// Name returns the package manager name.
+func (n *Nix) Name() string {
+	return "nix"
+}
+
+// IsAvailable checks if nix is available on the system.
+func (n *Nix) IsAvailable() bool {
+	_, err := lookPath("nix")
+	return err == nil
+}
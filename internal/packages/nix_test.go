@@ -0,0 +1,76 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	// nixProfileNamed is nix profile list --json from Nix 2.20 and later.
+	nixProfileNamed = `{"version": 3, "elements": {"ripgrep": {"active": true, "attrPath": "legacyPackages.x86_64-linux.ripgrep",
+		"originalUrl": "flake:nixpkgs", "storePaths": ["/nix/store/0123456789abcdfghijklmnpqrsvwxyz-ripgrep-14.1.0"]}}}`
+
+	// nixProfileList is nix profile list --json from older Nix versions.
+	nixProfileList = `{"version": 2, "elements": [{"active": true, "attrPath": "legacyPackages.x86_64-linux.ripgrep",
+		"originalUrl": "flake:nixpkgs", "storePaths": ["/nix/store/0123456789abcdfghijklmnpqrsvwxyz-ripgrep-14.1.0"]}]}`
+)
+
+func TestNix_InstalledVersion(t *testing.T) {
+	for name, profile := range map[string]string{"named elements": nixProfileNamed, "element list": nixProfileList} {
+		t.Run(name, func(t *testing.T) {
+			n := &Nix{execCommand: func(string, ...string) *exec.Cmd { return scripted(profile, 0) }}
+			for _, pkg := range []string{"ripgrep", "nixpkgs#ripgrep"} {
+				version, err := n.InstalledVersion(pkg)
+				if err != nil || version != "14.1.0" {
+					t.Errorf("InstalledVersion(%q) = %q, %v, want 14.1.0", pkg, version, err)
+				}
+			}
+			if installed, err := n.IsInstalled("nixpkgs#fd"); err != nil || installed {
+				t.Errorf("IsInstalled(fd) = %v, %v, want false", installed, err)
+			}
+		})
+	}
+}
+
+func TestNix_OutdatedUpgrade(t *testing.T) {
+	var calls []string
+	n := &Nix{execCommand: func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+		switch args[0] {
+		case "eval":
+			return scripted("14.1.1", 0)
+		case "profile":
+			if args[1] == "list" {
+				return scripted(nixProfileNamed, 0)
+			}
+		}
+		return exec.Command("true")
+	}}
+
+	got, err := n.Outdated([]string{"ripgrep", "fd"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "ripgrep", Current: "14.1.0", Available: "14.1.1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+
+	calls = nil
+	if errs := n.Upgrade([]string{"ripgrep"}); errs != nil {
+		t.Fatalf("Upgrade() errors = %v", errs)
+	}
+	if want := "nix profile upgrade ripgrep"; calls[len(calls)-1] != want {
+		t.Errorf("calls = %q, want last %q", calls, want)
+	}
+
+	calls = nil
+	if errs := n.InstallBatch([]string{"fd", "github:helix-editor/helix#helix"}); errs != nil {
+		t.Fatalf("InstallBatch() errors = %v", errs)
+	}
+	if want := []string{"nix profile install nixpkgs#fd github:helix-editor/helix#helix"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,175 @@
+package packages
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Npm implements the Manager interface for global npm packages.
+type Npm struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewNpm creates a new Npm package manager.
+func NewNpm() *Npm {
+	return &Npm{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs a package using npm install -g.
+func (n *Npm) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	return n.install([]string{pkg})
+}
+
+// InstallBatch installs packages with a single npm install -g call.
+func (n *Npm) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, n.install, n.Install)
+}
+
+func (n *Npm) install(names []string) error {
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("npm", append([]string{"install", "-g"}, names...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via npm: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+	}
+	return nil
+}
+
+// Uninstall removes a package using npm uninstall -g.
+func (n *Npm) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("npm", "uninstall", "-g", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via npm: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if a package is installed globally via npm.
+func (n *Npm) IsInstalled(pkg string) (bool, error) {
+	version, err := n.InstalledVersion(pkg)
+	return version != "", err
+}
+
+// InstalledVersion returns the installed version of a global package, or ""
+// if it is not installed.
+func (n *Npm) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("npm", "ls", "-g", "--depth=0", "--json", pkg).Output()
+	if err != nil {
+		// npm ls exits 1 when the package is not installed.
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return "", fmt.Errorf("failed to check if %s is installed: %w", pkg, err)
+		}
+	}
+
+	var result struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if len(output) > 0 {
+		if err := json.Unmarshal(output, &result); err != nil {
+			return "", fmt.Errorf("parsing npm ls output: %w", err)
+		}
+	}
+	return result.Dependencies[pkg].Version, nil
+}
+
+// Outdated returns the installed global packages with a newer version on
+// the registry.
+func (n *Npm) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	execCmd := n.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("npm", append([]string{"outdated", "-g", "--json"}, pkgs...)...).Output()
+	if err != nil {
+		// npm outdated exits 1 when packages are outdated.
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || exitErr.ExitCode() != 1 || len(output) == 0 {
+			return nil, fmt.Errorf("failed to check for npm upgrades: %w", err)
+		}
+	}
+
+	var result map[string]struct {
+		Current string `json:"current"`
+		Latest  string `json:"latest"`
+	}
+	if len(output) > 0 {
+		if err := json.Unmarshal(output, &result); err != nil {
+			return nil, fmt.Errorf("parsing npm outdated output: %w", err)
+		}
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		entry, ok := result[pkg]
+		// Packages that are not installed have no current version.
+		if !ok || entry.Current == "" || entry.Current == entry.Latest {
+			continue
+		}
+		outdated = append(outdated, OutdatedPackage{Name: pkg, Current: entry.Current, Available: entry.Latest})
+	}
+	return outdated, nil
+}
+
+// Upgrade installs the latest version of global packages.
+func (n *Npm) Upgrade(pkgs []string) map[string]error {
+	latest := func(name string) string { return name + "@latest" }
+	return runBatch(pkgs, func(names []string) error {
+		args := make([]string, len(names))
+		for i, name := range names {
+			args[i] = latest(name)
+		}
+		return n.install(args)
+	}, func(name string) error { return n.install([]string{latest(name)}) })
+}
+
+// PinVersion returns the npm install argument that installs version of pkg.
+func (n *Npm) PinVersion(pkg, version string) string {
+	return pkg + "@" + version
+}
+
+// Name returns the package manager name.
+func (n *Npm) Name() string {
+	return "npm"
+}
+
+// IsAvailable checks if npm is available on the system.
+func (n *Npm) IsAvailable() bool {
+	_, err := lookPath("npm")
+	return err == nil
+}
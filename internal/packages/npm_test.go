@@ -0,0 +1,54 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestNpm(t *testing.T) {
+	n := &Npm{execCommand: func(name string, args ...string) *exec.Cmd {
+		switch args[0] {
+		case "ls":
+			if args[len(args)-1] == "typescript" {
+				return scripted(`{"dependencies": {"typescript": {"version": "5.4.2"}}}`, 0)
+			}
+			return scripted(`{}`, 1)
+		case "outdated":
+			return scripted(`{"typescript": {"current": "5.4.2", "wanted": "5.4.5", "latest": "5.5.0"}}`, 1)
+		}
+		return exec.Command("true")
+	}}
+
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"typescript", "5.4.2"},
+		{"@biomejs/biome", ""},
+	}
+	for _, tt := range tests {
+		got, err := n.InstalledVersion(tt.pkg)
+		if err != nil || got != tt.want {
+			t.Errorf("InstalledVersion(%q) = %q, %v, want %q", tt.pkg, got, err, tt.want)
+		}
+	}
+
+	got, err := n.Outdated([]string{"typescript", "@biomejs/biome"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "typescript", Current: "5.4.2", Available: "5.5.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+
+	var calls []string
+	n = &Npm{execCommand: fakeInstaller(nil, &calls)}
+	if errs := n.Upgrade([]string{"typescript", "@biomejs/biome"}); errs != nil {
+		t.Fatalf("Upgrade() errors = %v", errs)
+	}
+	if want := []string{"npm install -g typescript@latest @biomejs/biome@latest"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,178 @@
+package packages
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Pipx implements the Manager interface for Python applications installed
+// into isolated environments with pipx.
+type Pipx struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewPipx creates a new Pipx package manager.
+func NewPipx() *Pipx {
+	return &Pipx{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs a package using pipx install.
+func (p *Pipx) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	return p.install([]string{pkg})
+}
+
+// InstallBatch installs packages with a single pipx install call.
+func (p *Pipx) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, p.install, p.Install)
+}
+
+func (p *Pipx) install(names []string) error {
+	execCmd := p.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("pipx", append([]string{"install"}, names...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via pipx: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+	}
+	return nil
+}
+
+// Uninstall removes a package using pipx uninstall.
+func (p *Pipx) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := p.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("pipx", "uninstall", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via pipx: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if a package is installed via pipx.
+func (p *Pipx) IsInstalled(pkg string) (bool, error) {
+	version, err := p.InstalledVersion(pkg)
+	return version != "", err
+}
+
+// InstalledVersion returns the installed version of a package, or "" if it
+// is not installed.
+func (p *Pipx) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := p.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("pipx", "list", "--json").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list pipx installs: %w", err)
+	}
+
+	var result struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Package        string `json:"package"`
+					PackageVersion string `json:"package_version"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return "", fmt.Errorf("parsing pipx list output: %w", err)
+	}
+	for _, venv := range result.Venvs {
+		if strings.EqualFold(venv.Metadata.MainPackage.Package, pkg) {
+			return venv.Metadata.MainPackage.PackageVersion, nil
+		}
+	}
+	return "", nil
+}
+
+// Outdated returns the installed packages with a newer version on the
+// package index, asking pip inside each package's environment.
+func (p *Pipx) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	execCmd := p.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		installed, err := p.IsInstalled(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if !installed {
+			continue
+		}
+		output, err := execCmd("pipx", "runpip", pkg, "list", "--outdated", "--format", "json").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for pipx upgrades of %s: %w", pkg, err)
+		}
+		var entries []struct {
+			Name          string `json:"name"`
+			Version       string `json:"version"`
+			LatestVersion string `json:"latest_version"`
+		}
+		if err := json.Unmarshal(output, &entries); err != nil {
+			return nil, fmt.Errorf("parsing pip list output: %w", err)
+		}
+		// The environment also lists outdated dependencies.
+		for _, entry := range entries {
+			if strings.EqualFold(entry.Name, pkg) {
+				outdated = append(outdated, OutdatedPackage{Name: pkg, Current: entry.Version, Available: entry.LatestVersion})
+			}
+		}
+	}
+	return outdated, nil
+}
+
+// Upgrade upgrades installed packages with pipx upgrade.
+func (p *Pipx) Upgrade(pkgs []string) map[string]error {
+	execCmd := p.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	return runEach(pkgs, func(pkg string) error {
+		if pkg == "" {
+			return fmt.Errorf("package name cannot be empty")
+		}
+		output, err := execCmd("pipx", "upgrade", pkg).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via pipx: %w\nOutput: %s", pkg, err, string(output))
+		}
+		return nil
+	})
+}
+
+// Name returns the package manager name.
+func (p *Pipx) Name() string {
+	return "pipx"
+}
+
+// IsAvailable checks if pipx is available on the system.
+func (p *Pipx) IsAvailable() bool {
+	_, err := lookPath("pipx")
+	return err == nil
+}
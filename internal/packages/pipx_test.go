@@ -0,0 +1,45 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+const pipxListJSON = `{"venvs": {"httpie": {"metadata": {"main_package": {"package": "httpie", "package_version": "3.2.2"}}}}}`
+
+func TestPipx(t *testing.T) {
+	p := &Pipx{execCommand: func(name string, args ...string) *exec.Cmd {
+		if args[0] == "runpip" {
+			return scripted(`[{"name": "certifi", "version": "2024.2.2", "latest_version": "2024.7.4"},`+
+				`{"name": "httpie", "version": "3.2.2", "latest_version": "3.2.3"}]`, 0)
+		}
+		return scripted(pipxListJSON, 0)
+	}}
+
+	version, err := p.InstalledVersion("httpie")
+	if err != nil || version != "3.2.2" {
+		t.Errorf("InstalledVersion(httpie) = %q, %v, want 3.2.2", version, err)
+	}
+	if installed, err := p.IsInstalled("black"); err != nil || installed {
+		t.Errorf("IsInstalled(black) = %v, %v, want false", installed, err)
+	}
+
+	got, err := p.Outdated([]string{"httpie", "black"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "httpie", Current: "3.2.2", Available: "3.2.3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+
+	var calls []string
+	p = &Pipx{execCommand: fakeInstaller(nil, &calls)}
+	if errs := p.Upgrade([]string{"httpie", "black"}); errs != nil {
+		t.Fatalf("Upgrade() errors = %v", errs)
+	}
+	if want := []string{"pipx upgrade httpie", "pipx upgrade black"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,382 @@
+package packages
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+// ReleaseManifestName is the file in the GDF data directory that records
+// the versions of binaries installed from release archives.
+const ReleaseManifestName = "releases.yaml"
+
+// Release implements the Manager interface for prebuilt binaries installed
+// from upstream release archives into ~/.local/bin. Packages are the
+// installed command names; installing needs the app's package.release.
+type Release struct {
+	// binDir is where binaries are installed (default: ~/.local/bin).
+	binDir string
+
+	// manifestPath records installed versions (default:
+	// ~/.local/share/gdf/releases.yaml).
+	manifestPath string
+
+	// goos and goarch select the download (default: the running platform).
+	goos, goarch string
+
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// releaseRecord is an installed release binary in the manifest.
+type releaseRecord struct {
+	Version string `yaml:"version"`
+	URL     string `yaml:"url"`
+	SHA256  string `yaml:"sha256"`
+}
+
+// NewRelease creates a new Release package manager.
+func NewRelease() *Release {
+	home := os.Getenv("HOME")
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return &Release{
+		binDir:       filepath.Join(home, ".local", "bin"),
+		manifestPath: filepath.Join(dataHome, "gdf", ReleaseManifestName),
+		goos:         runtime.GOOS,
+		goarch:       runtime.GOARCH,
+		execCommand:  exec.Command,
+	}
+}
+
+// Install fails: release binaries are installed with InstallPackage.
+func (r *Release) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	return fmt.Errorf("installing %s from a release needs the app's package.release configuration", pkg)
+}
+
+// InstallBatch fails for each package: release binaries are installed with
+// InstallPackage.
+func (r *Release) InstallBatch(pkgs []string) map[string]error {
+	return runEach(pkgs, r.Install)
+}
+
+// InstallPackage downloads the release for this platform, verifies its
+// checksum, extracts the binary and installs it into the bin directory.
+func (r *Release) InstallPackage(rel *apps.ReleaseInstall) error {
+	if rel == nil {
+		return fmt.Errorf("release configuration cannot be nil")
+	}
+	name := rel.InstalledName()
+	platformKey := r.goos + "/" + r.goarch
+	want := strings.ToLower(rel.SHA256[platformKey])
+	if want == "" {
+		return fmt.Errorf("release of %s has no sha256 checksum for %s", name, platformKey)
+	}
+
+	// A binary without a manifest record was installed by something else
+	// (a package manager, make install, the user) and is left alone.
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(r.binDir, name)
+	if _, managed := manifest[name]; !managed {
+		if _, err := os.Lstat(target); err == nil {
+			return fmt.Errorf("%s already exists and was not installed by gdf; remove or rename it to install the %s release", target, name)
+		}
+	}
+
+	downloadURL, err := r.render("url", rel.URL, rel)
+	if err != nil {
+		return err
+	}
+	binaryPattern, err := r.render("binary", rel.Binary, rel)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gdf-release-")
+	if err != nil {
+		return fmt.Errorf("creating download directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	execCmd := r.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	archivePath := filepath.Join(tmpDir, "download")
+	if output, err := execCmd("curl", "-fsSL", "-o", archivePath, downloadURL).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to download %s: %w\nOutput: %s", downloadURL, err, string(output))
+	}
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return fmt.Errorf("reading download of %s: %w", name, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got sha256 %s, want %s", downloadURL, got, want)
+	}
+
+	binary, err := r.extract(archivePath, data, downloadURL, binaryPattern)
+	if err != nil {
+		return fmt.Errorf("extracting %s from %s: %w", binaryPattern, downloadURL, err)
+	}
+
+	if err := os.MkdirAll(r.binDir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", r.binDir, err)
+	}
+	if err := util.WriteFileAtomic(target, binary, 0755); err != nil {
+		return fmt.Errorf("installing %s: %w", name, err)
+	}
+
+	manifest[name] = releaseRecord{Version: rel.Version, URL: downloadURL, SHA256: want}
+	return r.saveManifest(manifest)
+}
+
+// render expands a URL or binary template for this platform.
+func (r *Release) render(key, text string, rel *apps.ReleaseInstall) (string, error) {
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing release %s template: %w", key, err)
+	}
+	replace := func(v string) string {
+		if r, ok := rel.Replacements[v]; ok {
+			return r
+		}
+		return v
+	}
+	var buf bytes.Buffer
+	data := struct{ OS, Arch, Version string }{replace(r.goos), replace(r.goarch), rel.Version}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering release %s template: %w", key, err)
+	}
+	return buf.String(), nil
+}
+
+// extract returns the binary matching pattern from the download, choosing
+// the archive format from the file name in the URL. Downloads that are not
+// archives are the binary itself.
+func (r *Release) extract(archivePath string, data []byte, downloadURL, pattern string) ([]byte, error) {
+	fileName := downloadURL
+	if u, err := url.Parse(downloadURL); err == nil {
+		fileName = u.Path
+	}
+	fileName = strings.ToLower(path.Base(fileName))
+
+	switch {
+	case strings.HasSuffix(fileName, ".tar.gz"), strings.HasSuffix(fileName, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return extractTar(gz, pattern)
+	case strings.HasSuffix(fileName, ".tar.bz2"), strings.HasSuffix(fileName, ".tbz2"):
+		return extractTar(bzip2.NewReader(bytes.NewReader(data)), pattern)
+	case strings.HasSuffix(fileName, ".tar.xz"), strings.HasSuffix(fileName, ".txz"):
+		// The standard library has no xz reader.
+		execCmd := r.execCommand
+		if execCmd == nil {
+			execCmd = exec.Command
+		}
+		tarData, err := execCmd("xz", "-dc", archivePath).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress with xz: %w", err)
+		}
+		return extractTar(bytes.NewReader(tarData), pattern)
+	case strings.HasSuffix(fileName, ".tar"):
+		return extractTar(bytes.NewReader(data), pattern)
+	case strings.HasSuffix(fileName, ".zip"):
+		return extractZip(data, pattern)
+	case strings.HasSuffix(fileName, ".gz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(gz)
+	default:
+		return data, nil
+	}
+}
+
+// matchesBinary reports whether an archive entry is the binary: patterns
+// without "/" match the base name at any depth, others the whole path.
+func matchesBinary(entry, pattern string) bool {
+	entry = strings.TrimPrefix(entry, "./")
+	if !strings.Contains(pattern, "/") {
+		entry = path.Base(entry)
+	}
+	ok, err := path.Match(pattern, entry)
+	return err == nil && ok
+}
+
+// extractTar returns the first regular file in a tar stream that matches
+// pattern.
+func extractTar(r io.Reader, pattern string) ([]byte, error) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no file in the archive matches %q", pattern)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && matchesBinary(hdr.Name, pattern) {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// extractZip returns the first file in a zip archive that matches pattern.
+func extractZip(data []byte, pattern string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !matchesBinary(f.Name, pattern) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("no file in the archive matches %q", pattern)
+}
+
+// Uninstall removes a binary installed from a release and its manifest
+// record. A binary without a record is left alone.
+func (r *Release) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+	// Only binaries gdf recorded are removed; anything else in the bin
+	// directory was installed by something else.
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return err
+	}
+	if _, ok := manifest[pkg]; !ok {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(r.binDir, pkg)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to uninstall %s: %w", pkg, err)
+	}
+	delete(manifest, pkg)
+	return r.saveManifest(manifest)
+}
+
+// IsInstalled checks if a binary was installed from a release and is still
+// in the bin directory.
+func (r *Release) IsInstalled(pkg string) (bool, error) {
+	version, err := r.InstalledVersion(pkg)
+	return version != "", err
+}
+
+// IsPackageInstalled checks if the configured version of a release is
+// installed, so changing the version reinstalls it.
+func (r *Release) IsPackageInstalled(rel *apps.ReleaseInstall) (bool, error) {
+	if rel == nil {
+		return false, fmt.Errorf("release configuration cannot be nil")
+	}
+	version, err := r.InstalledVersion(rel.InstalledName())
+	return version != "" && version == rel.Version, err
+}
+
+// InstalledVersion returns the release version of an installed binary, or
+// "" if it is not installed.
+func (r *Release) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+	if _, err := os.Stat(filepath.Join(r.binDir, pkg)); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	manifest, err := r.loadManifest()
+	if err != nil {
+		return "", err
+	}
+	return manifest[pkg].Version, nil
+}
+
+// Outdated returns nil: release versions are pinned in the app bundle.
+func (r *Release) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	return nil, nil
+}
+
+// Upgrade reports an error for each package: release binaries are upgraded
+// by changing package.release.version and applying.
+func (r *Release) Upgrade(pkgs []string) map[string]error {
+	return runEach(pkgs, func(pkg string) error {
+		return fmt.Errorf("upgrade %s by changing its package.release.version and running gdf apply", pkg)
+	})
+}
+
+// Name returns the package manager name.
+func (r *Release) Name() string {
+	return "release"
+}
+
+// IsAvailable checks if curl is available to download releases.
+func (r *Release) IsAvailable() bool {
+	_, err := lookPath("curl")
+	return err == nil
+}
+
+func (r *Release) loadManifest() (map[string]releaseRecord, error) {
+	manifest := make(map[string]releaseRecord)
+	data, err := os.ReadFile(r.manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return nil, fmt.Errorf("reading release manifest: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing release manifest %s: %w", r.manifestPath, err)
+	}
+	return manifest, nil
+}
+
+func (r *Release) saveManifest(manifest map[string]releaseRecord) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("marshaling release manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.manifestPath), 0755); err != nil {
+		return fmt.Errorf("creating release manifest directory: %w", err)
+	}
+	return util.WriteFileAtomic(r.manifestPath, data, 0644)
+}
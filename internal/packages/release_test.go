@@ -0,0 +1,219 @@
+package packages
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// releaseArchive writes a tar.gz holding files and returns its path and
+// checksum.
+func releaseArchive(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "release.tar.gz")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return archive, hex.EncodeToString(sum[:])
+}
+
+func TestRelease_InstallPackage(t *testing.T) {
+	archive, sum := releaseArchive(t, map[string]string{
+		"k9s_Linux_amd64/README.md": "docs",
+		"k9s_Linux_amd64/k9s":       "#!/bin/sh\necho k9s\n",
+	})
+	dir := t.TempDir()
+	var urls []string
+	r := &Release{
+		binDir:       filepath.Join(dir, "bin"),
+		manifestPath: filepath.Join(dir, "gdf", ReleaseManifestName),
+		goos:         "linux",
+		goarch:       "amd64",
+		execCommand: func(name string, args ...string) *exec.Cmd {
+			// curl -fsSL -o <file> <url>
+			urls = append(urls, args[len(args)-1])
+			return exec.Command("cp", archive, args[2])
+		},
+	}
+	rel := &apps.ReleaseInstall{
+		URL:          "https://github.com/derailed/k9s/releases/download/v{{.Version}}/k9s_{{.OS}}_{{.Arch}}.tar.gz",
+		Version:      "0.32.5",
+		Binary:       "k9s",
+		SHA256:       map[string]string{"linux/amd64": sum},
+		Replacements: map[string]string{"linux": "Linux"},
+	}
+
+	if installed, err := r.IsPackageInstalled(rel); err != nil || installed {
+		t.Fatalf("IsPackageInstalled() before install = %v, %v, want false", installed, err)
+	}
+	if err := r.InstallPackage(rel); err != nil {
+		t.Fatalf("InstallPackage() error = %v", err)
+	}
+	if want := "https://github.com/derailed/k9s/releases/download/v0.32.5/k9s_Linux_amd64.tar.gz"; len(urls) != 1 || urls[0] != want {
+		t.Errorf("downloaded %q, want %q", urls, want)
+	}
+
+	info, err := os.Stat(filepath.Join(r.binDir, "k9s"))
+	if err != nil {
+		t.Fatalf("binary not installed: %v", err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("binary mode = %v, want 0755", info.Mode().Perm())
+	}
+	if version, err := r.InstalledVersion("k9s"); err != nil || version != "0.32.5" {
+		t.Errorf("InstalledVersion() = %q, %v, want 0.32.5", version, err)
+	}
+	if installed, err := r.IsPackageInstalled(rel); err != nil || !installed {
+		t.Errorf("IsPackageInstalled() = %v, %v, want true", installed, err)
+	}
+
+	// A new version in the bundle is not installed yet.
+	bumped := *rel
+	bumped.Version = "0.33.0"
+	if installed, err := r.IsPackageInstalled(&bumped); err != nil || installed {
+		t.Errorf("IsPackageInstalled() after version change = %v, %v, want false", installed, err)
+	}
+
+	if err := r.Uninstall("k9s"); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if installed, err := r.IsInstalled("k9s"); err != nil || installed {
+		t.Errorf("IsInstalled() after uninstall = %v, %v, want false", installed, err)
+	}
+}
+
+func TestRelease_InstallPackageErrors(t *testing.T) {
+	archive, sum := releaseArchive(t, map[string]string{"tool": "binary"})
+	tests := []struct {
+		name    string
+		rel     *apps.ReleaseInstall
+		wantErr string
+	}{
+		{
+			name:    "no checksum for platform",
+			rel:     &apps.ReleaseInstall{URL: "https://example.com/tool.tar.gz", Version: "1.0.0", Binary: "tool", SHA256: map[string]string{"darwin/arm64": sum}},
+			wantErr: "no sha256 checksum for linux/amd64",
+		},
+		{
+			name:    "checksum mismatch",
+			rel:     &apps.ReleaseInstall{URL: "https://example.com/tool.tar.gz", Version: "1.0.0", Binary: "tool", SHA256: map[string]string{"linux/amd64": strings.Repeat("0", 64)}},
+			wantErr: "checksum mismatch",
+		},
+		{
+			name:    "binary missing from archive",
+			rel:     &apps.ReleaseInstall{URL: "https://example.com/tool.tar.gz", Version: "1.0.0", Binary: "other", SHA256: map[string]string{"linux/amd64": sum}},
+			wantErr: `no file in the archive matches "other"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r := &Release{
+				binDir:       filepath.Join(dir, "bin"),
+				manifestPath: filepath.Join(dir, ReleaseManifestName),
+				goos:         "linux",
+				goarch:       "amd64",
+				execCommand: func(name string, args ...string) *exec.Cmd {
+					return exec.Command("cp", archive, args[2])
+				},
+			}
+			err := r.InstallPackage(tt.rel)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("InstallPackage() error = %v, want %q", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(r.binDir, tt.rel.InstalledName())); !os.IsNotExist(err) {
+				t.Errorf("binary should not be installed, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRelease_InstallPackageRefusesUnmanagedBinary(t *testing.T) {
+	archive, sum := releaseArchive(t, map[string]string{"tool": "binary"})
+	dir := t.TempDir()
+	downloaded := false
+	r := &Release{
+		binDir:       filepath.Join(dir, "bin"),
+		manifestPath: filepath.Join(dir, ReleaseManifestName),
+		goos:         "linux",
+		goarch:       "amd64",
+		execCommand: func(name string, args ...string) *exec.Cmd {
+			downloaded = true
+			return exec.Command("cp", archive, args[2])
+		},
+	}
+	existing := filepath.Join(r.binDir, "tool")
+	if err := os.MkdirAll(r.binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("from make install"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	rel := &apps.ReleaseInstall{URL: "https://example.com/tool.tar.gz", Version: "1.0.0", Binary: "tool", SHA256: map[string]string{"linux/amd64": sum}}
+	err := r.InstallPackage(rel)
+	if err == nil || !strings.Contains(err.Error(), "was not installed by gdf") {
+		t.Fatalf("InstallPackage() error = %v, want refusal", err)
+	}
+	if downloaded {
+		t.Error("release should not be downloaded when the target is unmanaged")
+	}
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "from make install" {
+		t.Errorf("existing binary = %q, %v, want it left unchanged", data, err)
+	}
+
+	if err := r.Uninstall("tool"); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("Uninstall() removed a binary gdf did not install: %v", err)
+	}
+}
+
+func TestMatchesBinary(t *testing.T) {
+	tests := []struct {
+		entry, pattern string
+		want           bool
+	}{
+		{"k9s", "k9s", true},
+		{"./dist/k9s", "k9s", true},
+		{"dist/k9s.md", "k9s", false},
+		{"gh_2.50.0_linux_amd64/bin/gh", "gh_*/bin/gh", true},
+		{"gh_2.50.0_linux_amd64/share/gh", "gh_*/bin/gh", false},
+		{"tool-linux-amd64", "tool-*", true},
+	}
+	for _, tt := range tests {
+		if got := matchesBinary(tt.entry, tt.pattern); got != tt.want {
+			t.Errorf("matchesBinary(%q, %q) = %v, want %v", tt.entry, tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package packages
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+// Snap implements the Manager interface for snaps.
+type Snap struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewSnap creates a new Snap package manager.
+func NewSnap() *Snap {
+	return &Snap{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs a strictly confined snap from the stable channel.
+func (s *Snap) Install(pkg string) error {
+	return s.InstallPackage(&apps.SnapPackage{Name: pkg})
+}
+
+// InstallPackage installs a snap with its confinement and channel.
+func (s *Snap) InstallPackage(sp *apps.SnapPackage) error {
+	if sp == nil {
+		return fmt.Errorf("snap package configuration cannot be nil")
+	}
+	if sp.Name == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	var args []string
+	if sp.Classic {
+		args = append(args, "--classic")
+	}
+	if sp.Channel != "" {
+		args = append(args, "--channel="+sp.Channel)
+	}
+	return s.install(append(args, sp.Name))
+}
+
+// InstallBatch installs strictly confined snaps with a single snap install
+// call.
+func (s *Snap) InstallBatch(pkgs []string) map[string]error {
+	return runBatch(pkgs, s.install, s.Install)
+}
+
+func (s *Snap) install(args []string) error {
+	execCmd := s.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("sudo", append([]string{"snap", "install"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via snap: %w\nOutput: %s", strings.Join(args, " "), err, string(output))
+	}
+	return nil
+}
+
+// Uninstall removes a snap using snap remove.
+func (s *Snap) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := s.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("sudo", "snap", "remove", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via snap: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if a snap is installed.
+func (s *Snap) IsInstalled(pkg string) (bool, error) {
+	version, err := s.InstalledVersion(pkg)
+	return version != "", err
+}
+
+// InstalledVersion returns the installed version of a snap, or "" if it is
+// not installed.
+func (s *Snap) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := s.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("snap", "list", pkg).Output()
+	if err != nil {
+		// snap list exits 1 when the snap is not installed
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to check if %s is installed: %w", pkg, err)
+	}
+	return parseSnapTable(string(output))[pkg], nil
+}
+
+// parseSnapTable maps the snap names of a snap list or snap refresh --list
+// table to their version column.
+func parseSnapTable(output string) map[string]string {
+	versions := make(map[string]string)
+	lines := strings.Split(output, "\n")
+	if len(lines) > 0 {
+		// Skip the "Name  Version  Rev ..." header.
+		lines = lines[1:]
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	return versions
+}
+
+// Outdated returns the installed snaps with a refresh available on their
+// channel.
+func (s *Snap) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+
+	execCmd := s.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("snap", "refresh", "--list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for snap upgrades: %w", err)
+	}
+	// With nothing to refresh, snap prints "All snaps up to date." instead
+	// of a table.
+	updates := parseSnapTable(string(output))
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		available, ok := updates[pkg]
+		if !ok {
+			continue
+		}
+		current, err := s.InstalledVersion(pkg)
+		if err != nil {
+			return nil, err
+		}
+		outdated = append(outdated, OutdatedPackage{Name: pkg, Current: current, Available: available})
+	}
+	return outdated, nil
+}
+
+// Upgrade refreshes installed snaps with snap refresh.
+func (s *Snap) Upgrade(pkgs []string) map[string]error {
+	execCmd := s.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	refresh := func(names []string) error {
+		output, err := execCmd("sudo", append([]string{"snap", "refresh"}, names...)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via snap: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+		}
+		return nil
+	}
+	return runBatch(pkgs, refresh, func(name string) error { return refresh([]string{name}) })
+}
+
+// Name returns the package manager name.
+func (s *Snap) Name() string {
+	return "snap"
+}
+
+// IsAvailable checks if snap is available on the system.
+func (s *Snap) IsAvailable() bool {
+	_, err := lookPath("snap")
+	return err == nil
+}
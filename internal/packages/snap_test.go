@@ -0,0 +1,52 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func TestSnap_InstallPackage(t *testing.T) {
+	var calls []string
+	s := &Snap{execCommand: fakeInstaller(nil, &calls)}
+	if err := s.InstallPackage(&apps.SnapPackage{Name: "go", Classic: true, Channel: "1.22/stable"}); err != nil {
+		t.Fatalf("InstallPackage() error = %v", err)
+	}
+	if want := []string{"sudo snap install --classic --channel=1.22/stable go"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+	if err := s.Install(""); err == nil {
+		t.Error("Install(\"\") should return error for empty package name")
+	}
+}
+
+func TestSnap_Outdated(t *testing.T) {
+	s := &Snap{execCommand: func(name string, args ...string) *exec.Cmd {
+		if args[0] == "refresh" {
+			return scripted("Name     Version  Rev   Size  Publisher  Notes\nspotify  1.2.31   77    177MB spotify    -\n", 0)
+		}
+		if args[len(args)-1] == "spotify" {
+			return scripted("Name     Version  Rev  Tracking       Publisher  Notes\nspotify  1.2.26   75   latest/stable  spotify    -\n", 0)
+		}
+		return scripted("error: no matching snaps installed\n", 1)
+	}}
+
+	if installed, err := s.IsInstalled("vlc"); err != nil || installed {
+		t.Errorf("IsInstalled(vlc) = %v, %v, want false", installed, err)
+	}
+	got, err := s.Outdated([]string{"spotify", "vlc"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "spotify", Current: "1.2.26", Available: "1.2.31"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+
+	s.execCommand = func(string, ...string) *exec.Cmd { return scripted("All snaps up to date.\n", 0) }
+	if got, err := s.Outdated([]string{"spotify"}); err != nil || got != nil {
+		t.Errorf("Outdated() up to date = %+v, %v, want none", got, err)
+	}
+}
@@ -0,0 +1,136 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// scripted returns a mock command that prints output and exits with code.
+func scripted(output string, code int) *exec.Cmd {
+	return exec.Command("sh", "-c", `printf '%s' "$1"; exit "$2"`, "sh", output, strconv.Itoa(code))
+}
+
+func TestParseAptPolicy(t *testing.T) {
+	output := `git:
+  Installed: 1:2.43.0-1ubuntu7
+  Candidate: 1:2.43.0-1ubuntu7.1
+  Version table:
+     1:2.43.0-1ubuntu7.1 500
+        500 http://archive.ubuntu.com/ubuntu noble-updates/main amd64 Packages
+ *** 1:2.43.0-1ubuntu7 100
+        100 /var/lib/dpkg/status
+jq:
+  Installed: 1.7.1-3build1
+  Candidate: 1.7.1-3build1
+  Version table:
+tree:
+  Installed: (none)
+  Candidate: 2.1.1-2
+`
+	want := []OutdatedPackage{{Name: "git", Current: "1:2.43.0-1ubuntu7", Available: "1:2.43.0-1ubuntu7.1"}}
+	if got := parseAptPolicy(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAptPolicy() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOutdated(t *testing.T) {
+	tests := []struct {
+		name string
+		mgr  Manager
+		pkgs []string
+		want []OutdatedPackage
+	}{
+		{
+			name: "brew formulae and casks",
+			mgr: &Brew{execCommand: func(string, ...string) *exec.Cmd {
+				return scripted(`{"formulae":[{"name":"terraform","installed_versions":["1.5.0"],"current_version":"1.5.7"}],`+
+					`"casks":[{"name":"firefox","installed_versions":"123.0","current_version":"124.0"}]}`, 1)
+			}},
+			pkgs: []string{"hashicorp/tap/terraform", "firefox", "git"},
+			want: []OutdatedPackage{
+				{Name: "hashicorp/tap/terraform", Current: "1.5.0", Available: "1.5.7"},
+				{Name: "firefox", Current: "123.0", Available: "124.0"},
+			},
+		},
+		{
+			name: "apt candidates newer than installed",
+			mgr: &Apt{execCommand: func(name string, args ...string) *exec.Cmd {
+				if name == "dpkg" {
+					// Only git's candidate is newer; code is installed from a newer local .deb.
+					if args[1] == "1:2.43.0-1ubuntu7.1" {
+						return scripted("", 0)
+					}
+					return scripted("", 1)
+				}
+				return scripted("git:\n  Installed: 1:2.43.0-1ubuntu7\n  Candidate: 1:2.43.0-1ubuntu7.1\n"+
+					"code:\n  Installed: 1.90.0-1717531825\n  Candidate: 1.89.1-1715060508\n", 0)
+			}},
+			pkgs: []string{"git", "code"},
+			want: []OutdatedPackage{{Name: "git", Current: "1:2.43.0-1ubuntu7", Available: "1:2.43.0-1ubuntu7.1"}},
+		},
+		{
+			name: "dnf updates",
+			mgr: &Dnf{execCommand: func(name string, args ...string) *exec.Cmd {
+				if name == "rpm" {
+					return scripted("2.43.0-1.fc40\n", 0)
+				}
+				return scripted("\ngit.x86_64    2.44.0-1.fc40    updates\n", 100)
+			}},
+			pkgs: []string{"git", "jq"},
+			want: []OutdatedPackage{{Name: "git", Current: "2.43.0-1.fc40", Available: "2.44.0-1.fc40"}},
+		},
+		{
+			name: "dnf up to date",
+			mgr:  &Dnf{execCommand: func(string, ...string) *exec.Cmd { return scripted("", 0) }},
+			pkgs: []string{"git"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.mgr.Outdated(tt.pkgs)
+			if err != nil {
+				t.Fatalf("Outdated() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Outdated() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		name string
+		mgr  func(*[]string) Manager
+		want string
+	}{
+		{
+			name: "apt only upgrades installed packages",
+			mgr:  func(calls *[]string) Manager { return &Apt{execCommand: fakeInstaller(nil, calls)} },
+			want: "sudo apt-get install -y --only-upgrade git jq",
+		},
+		{
+			name: "dnf",
+			mgr:  func(calls *[]string) Manager { return &Dnf{execCommand: fakeInstaller(nil, calls)} },
+			want: "sudo dnf upgrade -y git jq",
+		},
+		{
+			name: "brew",
+			mgr:  func(calls *[]string) Manager { return &Brew{execCommand: fakeInstaller(nil, calls)} },
+			want: "brew upgrade git jq",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			if errs := tt.mgr(&calls).Upgrade([]string{"git", "jq"}); errs != nil {
+				t.Fatalf("Upgrade() errors = %v", errs)
+			}
+			if !reflect.DeepEqual(calls, []string{tt.want}) {
+				t.Errorf("calls = %q, want %q", calls, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package packages
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Uv implements the Manager interface for Python tools installed with
+// uv tool install.
+type Uv struct {
+	// execCommand allows mocking in tests
+	execCommand func(string, ...string) *exec.Cmd
+}
+
+// NewUv creates a new Uv package manager.
+func NewUv() *Uv {
+	return &Uv{
+		execCommand: exec.Command,
+	}
+}
+
+// Install installs a tool using uv tool install.
+func (u *Uv) Install(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := u.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("uv", "tool", "install", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install %s via uv: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// InstallBatch installs tools one at a time: uv tool install takes a single
+// package.
+func (u *Uv) InstallBatch(pkgs []string) map[string]error {
+	return runEach(pkgs, u.Install)
+}
+
+// Uninstall removes a tool using uv tool uninstall.
+func (u *Uv) Uninstall(pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("package name cannot be empty")
+	}
+
+	execCmd := u.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("uv", "tool", "uninstall", pkg).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to uninstall %s via uv: %w\nOutput: %s", pkg, err, string(output))
+	}
+	return nil
+}
+
+// IsInstalled checks if a tool is installed via uv.
+func (u *Uv) IsInstalled(pkg string) (bool, error) {
+	version, err := u.InstalledVersion(pkg)
+	return version != "", err
+}
+
+// InstalledVersion returns the installed version of a tool, or "" if it is
+// not installed.
+func (u *Uv) InstalledVersion(pkg string) (string, error) {
+	if pkg == "" {
+		return "", fmt.Errorf("package name cannot be empty")
+	}
+	tools, err := u.list()
+	if err != nil {
+		return "", err
+	}
+	return tools[pkg].version, nil
+}
+
+// uvTool is an installed tool as listed by uv tool list.
+type uvTool struct {
+	version string
+	latest  string
+}
+
+// list parses uv tool list, which prints each tool as "name v1.2.3"
+// followed by its executables as "- name". With --outdated, tools end with
+// "[latest: 1.2.4]".
+func (u *Uv) list(args ...string) (map[string]uvTool, error) {
+	execCmd := u.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+
+	output, err := execCmd("uv", append([]string{"tool", "list"}, args...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uv tools: %w", err)
+	}
+
+	tools := make(map[string]uvTool)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] == "-" {
+			continue
+		}
+		tool := uvTool{version: strings.TrimPrefix(fields[1], "v")}
+		if _, latest, ok := strings.Cut(line, "[latest: "); ok {
+			tool.latest = strings.TrimPrefix(strings.TrimSuffix(strings.TrimSpace(latest), "]"), "v")
+		}
+		tools[fields[0]] = tool
+	}
+	return tools, nil
+}
+
+// Outdated returns the installed tools with a newer version available.
+func (u *Uv) Outdated(pkgs []string) ([]OutdatedPackage, error) {
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	tools, err := u.list("--outdated")
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range pkgs {
+		tool, ok := tools[pkg]
+		if !ok || tool.latest == "" || tool.latest == tool.version {
+			continue
+		}
+		outdated = append(outdated, OutdatedPackage{Name: pkg, Current: tool.version, Available: tool.latest})
+	}
+	return outdated, nil
+}
+
+// Upgrade upgrades installed tools with uv tool upgrade.
+func (u *Uv) Upgrade(pkgs []string) map[string]error {
+	execCmd := u.execCommand
+	if execCmd == nil {
+		execCmd = exec.Command
+	}
+	upgrade := func(names []string) error {
+		output, err := execCmd("uv", append([]string{"tool", "upgrade"}, names...)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to upgrade %s via uv: %w\nOutput: %s", strings.Join(names, " "), err, string(output))
+		}
+		return nil
+	}
+	return runBatch(pkgs, upgrade, func(name string) error { return upgrade([]string{name}) })
+}
+
+// PinVersion returns the uv tool install argument that installs version of
+// pkg.
+func (u *Uv) PinVersion(pkg, version string) string {
+	return pkg + "==" + version
+}
+
+// Name returns the package manager name.
+func (u *Uv) Name() string {
+	return "uv"
+}
+
+// IsAvailable checks if uv is available on the system.
+func (u *Uv) IsAvailable() bool {
+	_, err := lookPath("uv")
+	return err == nil
+}
@@ -0,0 +1,42 @@
+package packages
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestUv(t *testing.T) {
+	u := &Uv{execCommand: func(name string, args ...string) *exec.Cmd {
+		if args[len(args)-1] == "--outdated" {
+			return scripted("ruff v0.4.1 [latest: 0.5.0]\n- ruff\n", 0)
+		}
+		return scripted("black v24.4.0\n- black\n- blackd\nruff v0.4.1\n- ruff\n", 0)
+	}}
+
+	version, err := u.InstalledVersion("ruff")
+	if err != nil || version != "0.4.1" {
+		t.Errorf("InstalledVersion(ruff) = %q, %v, want 0.4.1", version, err)
+	}
+	if installed, err := u.IsInstalled("blackd"); err != nil || installed {
+		t.Errorf("IsInstalled(blackd) = %v, %v, want false", installed, err)
+	}
+
+	got, err := u.Outdated([]string{"ruff", "black"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "ruff", Current: "0.4.1", Available: "0.5.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() = %+v, want %+v", got, want)
+	}
+
+	var calls []string
+	u = &Uv{execCommand: fakeInstaller(nil, &calls)}
+	if errs := u.InstallBatch([]string{"ruff", u.PinVersion("black", "24.4.0")}); errs != nil {
+		t.Fatalf("InstallBatch() errors = %v", errs)
+	}
+	if want := []string{"uv tool install ruff", "uv tool install black==24.4.0"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
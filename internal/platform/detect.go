@@ -14,6 +14,11 @@ type Platform struct {
 	// Distro is the Linux distribution (empty on macOS): ubuntu, fedora, arch, etc.
 	Distro string
 
+	// Release is the distribution release: the codename where the distro has
+	// one (noble, bookworm), otherwise its version (40). Empty on macOS and
+	// rolling distributions.
+	Release string
+
 	// Hostname is the machine hostname.
 	Hostname string
 
@@ -51,7 +56,7 @@ func Detect() *Platform {
 		} else {
 			p.OS = "linux"
 		}
-		p.Distro = detectDistro()
+		p.Distro, p.Release = detectDistro()
 	default:
 		p.OS = runtime.GOOS
 	}
@@ -70,26 +75,26 @@ func isWSL() bool {
 	return strings.Contains(version, "microsoft") || strings.Contains(version, "wsl")
 }
 
-// detectDistro detects the Linux distribution.
-func detectDistro() string {
+// detectDistro detects the Linux distribution and its release.
+func detectDistro() (string, string) {
 	// Try /etc/os-release first (most modern distros)
 	data, err := os.ReadFile("/etc/os-release")
 	if err == nil {
-		return parseOSRelease(string(data))
+		return parseOSRelease(string(data)), parseOSReleaseVersion(string(data))
 	}
 
 	// Fallback: check for specific files
 	if _, err := os.Stat("/etc/debian_version"); err == nil {
-		return "debian"
+		return "debian", ""
 	}
 	if _, err := os.Stat("/etc/fedora-release"); err == nil {
-		return "fedora"
+		return "fedora", ""
 	}
 	if _, err := os.Stat("/etc/arch-release"); err == nil {
-		return "arch"
+		return "arch", ""
 	}
 
-	return ""
+	return "", ""
 }
 
 // DetectShell returns the user's current shell (bash, zsh, or unknown).
@@ -115,11 +120,23 @@ func DetectShell() string {
 
 // parseOSRelease parses /etc/os-release and returns the distro ID.
 func parseOSRelease(content string) string {
+	return strings.ToLower(osReleaseField(content, "ID"))
+}
+
+// parseOSReleaseVersion returns the release codename from /etc/os-release,
+// or the version ID when the distro has no codename.
+func parseOSReleaseVersion(content string) string {
+	if codename := osReleaseField(content, "VERSION_CODENAME"); codename != "" {
+		return strings.ToLower(codename)
+	}
+	return osReleaseField(content, "VERSION_ID")
+}
+
+// osReleaseField returns the unquoted value of key in os-release content.
+func osReleaseField(content, key string) string {
 	for _, line := range strings.Split(content, "\n") {
-		if strings.HasPrefix(line, "ID=") {
-			id := strings.TrimPrefix(line, "ID=")
-			id = strings.Trim(id, `"'`)
-			return strings.ToLower(id)
+		if value, ok := strings.CutPrefix(line, key+"="); ok {
+			return strings.Trim(value, `"'`)
 		}
 	}
 	return ""
@@ -4,13 +4,13 @@
 //
 // This package handles:
 //   - Detecting the current OS (macOS, Linux, WSL)
-//   - Detecting Linux distributions (Ubuntu, Fedora, Arch)
+//   - Detecting Linux distributions (Ubuntu, Fedora, Arch) and their release
 //   - Path normalization (expanding ~, XDG directories)
 //   - Hostname and architecture detection
 //
 // # Key Types
 //
-//   - Platform: Contains OS, distro, hostname, arch info
+//   - Platform: Contains OS, distro, release, hostname, arch info
 //   - PathExpander: Handles path expansion and normalization
 //
 // # Usage
@@ -88,6 +88,25 @@ ID="custom"
 	}
 }
 
+func TestParseOSReleaseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"ubuntu codename", "ID=ubuntu\nVERSION_ID=\"24.04\"\nVERSION_CODENAME=noble\n", "noble"},
+		{"fedora version", "ID=fedora\nVERSION_ID=40\n", "40"},
+		{"rolling", "ID=arch\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOSReleaseVersion(tt.content); got != tt.want {
+				t.Errorf("parseOSReleaseVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestPlatform_Is(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,36 @@
+package shell
+
+import (
+	"fmt"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// ResolveValue returns the first value for shellType whose condition matches
+// plat. Entries limited to another shell are skipped. It reports false when
+// no entry applies, so the alias or function is not defined here.
+func ResolveValue(values apps.ConditionalValues, shellType ShellType, plat *platform.Platform) (string, bool, error) {
+	for i, v := range values {
+		if v.Shell != "" && v.Shell != shellType.String() {
+			continue
+		}
+		ok, err := conditionMatches(v.When, plat)
+		if err != nil {
+			return "", false, fmt.Errorf("[%d]: %w", i, err)
+		}
+		if ok {
+			return v.Value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// conditionMatches evaluates when, treating an empty condition as true.
+func conditionMatches(when string, plat *platform.Platform) (bool, error) {
+	if when == "" {
+		return true, nil
+	}
+	return config.EvaluateCondition(when, plat)
+}
@@ -0,0 +1,115 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+func TestGenerator_ConditionalShellEntries(t *testing.T) {
+	bundles := []*apps.Bundle{
+		{
+			Name: "clipboard",
+			Shell: &apps.Shell{
+				Aliases: map[string]apps.ConditionalValues{
+					"copy": {
+						{Value: "pbcopy", When: "os == 'macos'"},
+						{Value: "xclip -selection clipboard", When: "os == 'linux'"},
+					},
+					"paste": {{Value: "pbpaste", When: "os == 'macos'"}},
+				},
+				Functions: map[string]apps.ConditionalValues{
+					"flushdns": {{Value: "sudo killall -HUP mDNSResponder", When: "os == 'macos'"}},
+					"clip":     apps.Value("copy < \"$1\""),
+				},
+				Env: map[string]apps.EnvVar{
+					"CLIPBOARD_TOOL": {Value: "pbcopy", When: "os == 'macos'"},
+				},
+			},
+		},
+		{
+			Name: "base",
+			Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+				"CLIPBOARD_TOOL": {Value: "none"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		os      string
+		want    []string
+		notWant []string
+	}{
+		{
+			os:      "linux",
+			want:    []string{"alias copy='xclip -selection clipboard'", "clip() {", "export CLIPBOARD_TOOL=\"none\""},
+			notWant: []string{"paste", "flushdns", "pbcopy"},
+		},
+		{
+			os:      "macos",
+			want:    []string{"alias copy='pbcopy'", "alias paste='pbpaste'", "flushdns() {", "export CLIPBOARD_TOOL=\"none\""},
+			notWant: []string{"xclip"},
+		},
+	}
+
+	g := NewGenerator()
+	for _, tt := range tests {
+		t.Run(tt.os, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "init.sh")
+			opts := GenerateOptions{Platform: &platform.Platform{OS: tt.os}}
+			if err := g.GenerateWithOptions(bundles, Bash, outputPath, nil, opts); err != nil {
+				t.Fatalf("GenerateWithOptions() error = %v", err)
+			}
+			data, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := string(data)
+			for _, want := range tt.want {
+				if !strings.Contains(content, want) {
+					t.Errorf("script missing %q\n%s", want, content)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(content, notWant) {
+					t.Errorf("script contains %q\n%s", notWant, content)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveValue_Shell(t *testing.T) {
+	values := apps.ConditionalValues{
+		{Value: "fish body", Shell: "fish"},
+		{Value: "zsh mac body", Shell: "zsh", When: "os == 'macos'"},
+		{Value: "posix body"},
+	}
+	tests := []struct {
+		shell ShellType
+		os    string
+		want  string
+	}{
+		{shell: Fish, os: "linux", want: "fish body"},
+		{shell: Zsh, os: "macos", want: "zsh mac body"},
+		{shell: Zsh, os: "linux", want: "posix body"},
+		{shell: Bash, os: "macos", want: "posix body"},
+	}
+	for _, tt := range tests {
+		got, ok, err := ResolveValue(values, tt.shell, &platform.Platform{OS: tt.os})
+		if err != nil || !ok || got != tt.want {
+			t.Errorf("ResolveValue(%s, %s) = %q, %v, %v, want %q", tt.shell, tt.os, got, ok, err, tt.want)
+		}
+	}
+}
+
+func TestResolveValue_InvalidCondition(t *testing.T) {
+	_, _, err := ResolveValue(apps.ConditionalValues{{Value: "x", When: "os =="}}, Bash, &platform.Platform{OS: "linux"})
+	if err == nil {
+		t.Fatal("ResolveValue() error = nil, want condition error")
+	}
+}
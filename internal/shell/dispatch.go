@@ -0,0 +1,32 @@
+package shell
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+// DispatcherFileName is the POSIX init script that older rc files source.
+const DispatcherFileName = "init.sh"
+
+// WriteDispatcher writes init.sh into dir. It sources init.zsh or init.bash
+// from the same directory depending on the running shell, so rc files that
+// still source init.sh keep working.
+func WriteDispatcher(dir string) error {
+	zshInit := QuoteLiteral(Bash, filepath.Join(dir, Zsh.InitFileName()))
+	bashInit := QuoteLiteral(Bash, filepath.Join(dir, Bash.InitFileName()))
+	content := fmt.Sprintf(`#!/bin/sh
+# Generated by gdf - DO NOT EDIT MANUALLY
+# Sources the init script for the running shell.
+if [ -n "${ZSH_VERSION-}" ]; then
+  if [ -f %[1]s ]; then . %[1]s; fi
+elif [ -n "${BASH_VERSION-}" ]; then
+  if [ -f %[2]s ]; then . %[2]s; fi
+fi
+`, zshInit, bashInit)
+	if err := util.WriteFileAtomic(filepath.Join(dir, DispatcherFileName), []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", DispatcherFileName, err)
+	}
+	return nil
+}
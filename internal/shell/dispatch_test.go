@@ -0,0 +1,40 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteDispatcher(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "init.bash"), []byte("printf bash\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "init.zsh"), []byte("printf zsh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDispatcher(dir); err != nil {
+		t.Fatalf("WriteDispatcher() error = %v", err)
+	}
+
+	out, err := exec.Command(bash, "-c", `. "$1"`, "test", filepath.Join(dir, DispatcherFileName)).Output()
+	if err != nil {
+		t.Fatalf("sourcing dispatcher: %v", err)
+	}
+	if string(out) != "bash" {
+		t.Errorf("dispatcher under bash printed %q, want %q", out, "bash")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "init.bash")); err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Command(bash, "-c", `. "$1"`, "test", filepath.Join(dir, DispatcherFileName)).Run(); err != nil {
+		t.Errorf("dispatcher failed with init.bash missing: %v", err)
+	}
+}
@@ -6,22 +6,44 @@
 //   - Generating combined alias files from all active apps
 //   - Generating function definitions
 //   - Setting up environment variables
-//   - Emitting app startup/init snippets
+//   - Merging PATH entries from all apps in bundle order
+//   - Quoting values and validating alias, function and variable names per shell
+//   - Emitting app startup/init snippets, optionally lazy-loaded
+//   - Splitting output into per-app fragments behind a hash-checking loader
 //   - Loading shell completions
 //   - Optional event-based auto-reload hooks on prompt
+//   - Syntax-checking generated scripts with the target shell (`bash -n`, `zsh -n`, `fish -n`)
+//   - Timing interactive startup per section and init snippet
+//   - Exporting env vars for login sessions and GUI apps (profile, systemd, launchd)
 //   - Auto-injecting source line into shell RC files
-//   - Supporting bash and zsh shells
+//   - Supporting bash, zsh and fish shells
 //
 // # Key Types
 //
 //   - Generator: Main shell script generator that combines shell config from bundles
-//   - Injector: Handles auto-injection of source line into .bashrc/.zshrc
-//   - ShellType: Enum for supported shells (Bash, Zsh, Unknown)
+//   - Injector: Handles auto-injection of source line into .bashrc/.zshrc/config.fish
+//   - ShellType: Enum for supported shells (Bash, Zsh, Fish, Unknown)
+//   - InitValidationError: Generated script rejected by the shell's syntax check
+//   - StartupProfile: Startup timings from ProfileStartup
 //
 // # Output
 //
-// Generates ~/.gdf/generated/init.sh which is sourced by the user's shell.
-// This file is automatically regenerated during 'gdf apply'.
+// Generates ~/.gdf/generated/init.<shell> (init.bash, init.zsh, init.fish) for
+// each configured shell; the rc file of each shell sources its own script.
+// These files are regenerated during 'gdf apply'. A candidate that fails the
+// shell's syntax check is saved as init.<shell>.rejected and the previous
+// script is kept. WriteDispatcher writes init.sh, which sources init.zsh or
+// init.bash for rc files that still source init.sh.
+//
+// With GenerateOptions.Split, each app's aliases, functions, init snippets
+// and completions go to ~/.gdf/generated/shell/<app>.<shell>, and PATH, env
+// vars and global aliases to _gdf.<shell>. init.<shell> becomes a loader that
+// sources a fragment only if this shell has not already sourced the same
+// content, so auto-reload re-runs just the fragments that changed. Zsh
+// fragments are compiled with zcompile when zsh is installed.
+//
+// Fish gets PATH, aliases, env vars and fish-specific init snippets. Function
+// bodies and common init snippets are POSIX shell and are left out.
 //
 // # Usage
 //
@@ -29,16 +51,29 @@
 //
 //	generator := shell.NewGenerator()
 //	shellType := shell.ParseShellType(platform.DetectShell())
-//	err := generator.Generate(bundles, shellType, "~/.gdf/generated/init.sh")
+//	err := generator.Generate(bundles, shellType, "~/.gdf/generated/"+shellType.InitFileName(), nil)
+//
+// Apply writes per-app fragments to ~/.gdf/generated/shell and makes
+// init.<shell> a loader that sources them:
+//
+//	opts := shell.GenerateOptions{Split: true}
+//	err := generator.GenerateWithOptions(bundles, shellType, initPath, globalAliases, opts)
 //
 // Auto-inject source line during init:
 //
 //	injector := shell.NewInjector()
 //	shellType := shell.ParseShellType(platform.DetectShell())
-//	err := injector.InjectSourceLine(shellType)
+//	injector.SetSnapshotFunc(func(path string) error {
+//		_, err := history.Capture(path)
+//		return err
+//	})
+//	injected, err := injector.InjectInto(shellType, rcPath)
 //
 // # Security
 //
-// The injector creates a backup (.gdf.backup) before modifying RC files
-// and prevents duplicate injection by checking if the source line already exists.
+// The injector hands RC files to the caller's snapshot function before
+// modifying them and prevents duplicate injection by checking if the source
+// line already exists. CheckRCChain reports startup files that never reach the
+// source line: only a `source` or `.` command whose argument is one of the RC
+// files counts as reaching it.
 package shell
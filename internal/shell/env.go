@@ -0,0 +1,157 @@
+package shell
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+// EnvExport is an environment variable resolved for one shell and platform.
+type EnvExport struct {
+	Name   string
+	App    string
+	Value  string
+	Expand bool
+}
+
+// envRefPattern matches $NAME and ${NAME...} references.
+var envRefPattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// ResolveEnv picks each variable's value for shellType and plat and orders
+// the exports so a variable is set before any value that references it.
+// When several bundles define a variable, the last one that applies wins. A
+// definition applies unless its `when` is false or it has only conditional
+// values and none of them match.
+func ResolveEnv(bundles []*apps.Bundle, shellType ShellType, plat *platform.Platform) ([]EnvExport, error) {
+	vars := make(map[string]EnvExport)
+	for _, bundle := range bundles {
+		if bundle.Shell == nil {
+			continue
+		}
+		for _, name := range util.SortedKeys(bundle.Shell.Env) {
+			env := bundle.Shell.Env[name]
+			value, ok, err := envValue(env, shellType, plat)
+			if err != nil {
+				return nil, fmt.Errorf("app %s: env %s: %w", bundle.Name, name, err)
+			}
+			if !ok {
+				continue
+			}
+			vars[name] = EnvExport{Name: name, App: bundle.Name, Value: value, Expand: env.ExpandDefault()}
+		}
+	}
+	return orderEnv(vars)
+}
+
+// envValue returns the value of env for shellType: nothing when its `when`
+// is false, else the first matching conditional value, else the
+// shell-specific value, else Value. Conditional values limited to another
+// shell are skipped.
+func envValue(env apps.EnvVar, shellType ShellType, plat *platform.Platform) (string, bool, error) {
+	if ok, err := conditionMatches(env.When, plat); err != nil || !ok {
+		return "", false, err
+	}
+	for i, cv := range env.Values {
+		if cv.Shell != "" && cv.Shell != shellType.String() {
+			continue
+		}
+		ok, err := conditionMatches(cv.When, plat)
+		if err != nil {
+			return "", false, fmt.Errorf("values[%d]: %w", i, err)
+		}
+		if ok {
+			return cv.Value, true, nil
+		}
+	}
+	switch {
+	case shellType == Bash && env.Bash != "":
+		return env.Bash, true, nil
+	case shellType == Zsh && env.Zsh != "":
+		return env.Zsh, true, nil
+	case shellType == Fish && env.Fish != "":
+		return env.Fish, true, nil
+	}
+	if len(env.Values) > 0 && env.Value == "" {
+		return "", false, nil
+	}
+	return env.Value, true, nil
+}
+
+// envRefs returns the variables in vars that the value of v references.
+// Literal values and self references (PATH="$PATH:...") add no edges.
+func envRefs(v EnvExport, vars map[string]EnvExport) []string {
+	if !v.Expand {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var refs []string
+	for _, m := range envRefPattern.FindAllStringSubmatch(v.Value, -1) {
+		name := m[1] + m[2]
+		if name == v.Name || seen[name] {
+			continue
+		}
+		if _, ok := vars[name]; ok {
+			seen[name] = true
+			refs = append(refs, name)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// orderEnv sorts variables topologically, breaking ties by name so output
+// stays deterministic. A reference cycle is an error.
+func orderEnv(vars map[string]EnvExport) ([]EnvExport, error) {
+	deps := make(map[string][]string, len(vars))
+	for name, v := range vars {
+		deps[name] = envRefs(v, vars)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	stateOf := make(map[string]int, len(vars))
+	out := make([]EnvExport, 0, len(vars))
+	var stack []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch stateOf[name] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range stack {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, stack[start:]...), name)
+			return fmt.Errorf("environment variable cycle: %s", strings.Join(cycle, " -> "))
+		}
+		stateOf[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		stateOf[name] = done
+		out = append(out, vars[name])
+		return nil
+	}
+	for _, name := range util.SortedKeys(vars) {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
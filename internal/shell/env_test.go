@@ -0,0 +1,149 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+func envNames(exports []EnvExport) []string {
+	names := make([]string, 0, len(exports))
+	for _, e := range exports {
+		names = append(names, e.Name+"="+e.Value)
+	}
+	return names
+}
+
+func TestResolveEnv(t *testing.T) {
+	noExpand := false
+	linux := &platform.Platform{OS: "linux"}
+	tests := []struct {
+		name    string
+		bundles []*apps.Bundle
+		shell   ShellType
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "references across bundles are ordered first",
+			bundles: []*apps.Bundle{
+				{Name: "go-tools", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"GOBIN": {Value: "$GOPATH/bin"},
+				}}},
+				{Name: "go", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"GOPATH":      {Value: "${GO_ROOT_DIR}/go"},
+					"GO_ROOT_DIR": {Value: "$HOME"},
+				}}},
+			},
+			shell: Bash,
+			want:  []string{"GO_ROOT_DIR=$HOME", "GOPATH=${GO_ROOT_DIR}/go", "GOBIN=$GOPATH/bin"},
+		},
+		{
+			name: "self and literal references add no edges",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"A_LIST": {Value: "$A_LIST:$Z_VAR", Expand: &noExpand},
+					"Z_VAR":  {Value: "$Z_VAR:x"},
+				}}},
+			},
+			shell: Bash,
+			want:  []string{"A_LIST=$A_LIST:$Z_VAR", "Z_VAR=$Z_VAR:x"},
+		},
+		{
+			name: "cycle is an error",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"A": {Value: "$B"},
+					"B": {Value: "${C}"},
+					"C": {Value: "$A"},
+				}}},
+			},
+			shell:   Bash,
+			wantErr: "A -> B -> C -> A",
+		},
+		{
+			name: "per-shell value",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"HISTFILE": {Value: "~/.history", Zsh: "~/.zsh_history"},
+				}}},
+			},
+			shell: Zsh,
+			want:  []string{"HISTFILE=~/.zsh_history"},
+		},
+		{
+			name: "fish value",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"HISTFILE": {Value: "~/.history", Zsh: "~/.zsh_history", Fish: "~/.local/share/fish/fish_history"},
+				}}},
+			},
+			shell: Fish,
+			want:  []string{"HISTFILE=~/.local/share/fish/fish_history"},
+		},
+		{
+			name: "conditional values take the first match",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"BROWSER": {Value: "firefox", Bash: "lynx", Values: []apps.ConditionalValue{
+						{Value: "open", When: "os == 'macos'"},
+						{Value: "xdg-open", When: "os == 'linux'"},
+					}},
+					"OPENER": {Values: []apps.ConditionalValue{{Value: "open", When: "os == 'macos'"}}},
+				}}},
+			},
+			shell: Bash,
+			want:  []string{"BROWSER=xdg-open"},
+		},
+		{
+			name: "conditional values for another shell are skipped",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"EDITOR": {Value: "vi", Values: []apps.ConditionalValue{{Value: "hx", Shell: "fish"}}},
+					"PAGER":  {Values: []apps.ConditionalValue{{Value: "less", Shell: "fish"}}},
+				}}},
+			},
+			shell: Bash,
+			want:  []string{"EDITOR=vi"},
+		},
+		{
+			name: "later bundle wins",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{"EDITOR": {Value: "nano"}}}},
+				{Name: "b", Shell: &apps.Shell{Env: map[string]apps.EnvVar{"EDITOR": {Value: "vim"}}}},
+			},
+			shell: Bash,
+			want:  []string{"EDITOR=vim"},
+		},
+		{
+			name: "invalid condition",
+			bundles: []*apps.Bundle{
+				{Name: "a", Shell: &apps.Shell{Env: map[string]apps.EnvVar{
+					"X": {Values: []apps.ConditionalValue{{Value: "1", When: "os =="}}},
+				}}},
+			},
+			shell:   Bash,
+			wantErr: "app a: env X: values[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveEnv(tt.bundles, tt.shell, linux)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveEnv() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveEnv() error = %v", err)
+			}
+			if strings.Join(envNames(got), " ") != strings.Join(tt.want, " ") {
+				t.Errorf("ResolveEnv() = %v, want %v", envNames(got), tt.want)
+			}
+		})
+	}
+}
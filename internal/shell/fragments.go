@@ -0,0 +1,215 @@
+package shell
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// FragmentDirName is the directory, next to the init scripts, that holds the
+// per-app fragments written by split generation.
+const FragmentDirName = "shell"
+
+// coreFragment holds PATH, env vars and global aliases, which are resolved
+// across apps. App names cannot start with "_", so it never collides.
+const coreFragment = "_gdf"
+
+// fragmentVarPrefix names the shell variables that record the content hash
+// of each fragment sourced in the current shell.
+const fragmentVarPrefix = "__GDF_FRAG_"
+
+type fragment struct {
+	name    string
+	path    string
+	content []byte
+}
+
+// hash returns a short content hash used by the loader to skip fragments
+// that have not changed since they were sourced.
+func (f fragment) hash() string {
+	sum := sha256.Sum256(f.content)
+	return hex.EncodeToString(sum[:6])
+}
+
+// generateSplit writes the core fragment and one fragment per app with shell
+// content, then a loader at outputPath. Every changed file is syntax-checked
+// before any is replaced; fragments of removed apps are deleted.
+func (g *Generator) generateSplit(bundles []*apps.Bundle, shellType ShellType, outputPath string, globalAliases map[string]string, pathDirs []PathDir, envExports []EnvExport, plat *platform.Platform, opts GenerateOptions) error {
+	fragmentDir := filepath.Join(filepath.Dir(outputPath), FragmentDirName)
+	if err := os.MkdirAll(fragmentDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	fragmentPath := func(name string) string {
+		return filepath.Join(fragmentDir, name+"."+shellType.String())
+	}
+
+	var fragments []fragment
+
+	// Global aliases that no app overrides go in the core fragment; app
+	// fragments are sourced after it, so app aliases still win.
+	globals := make(map[string]string)
+	for name, cmd := range globalAliases {
+		globals[name] = cmd
+	}
+	for _, bundle := range bundles {
+		if bundle.Shell == nil {
+			continue
+		}
+		for name := range bundle.Shell.Aliases {
+			delete(globals, name)
+		}
+	}
+	globalAliasLines, err := g.generateAliases(nil, globals, shellType, plat)
+	if err != nil {
+		return err
+	}
+	var core strings.Builder
+	writeSection(&core, "Environment variables", g.generateEnvVars(envExports, shellType))
+	writeSection(&core, "PATH", g.generatePath(pathDirs, shellType))
+	writeSection(&core, "Aliases", globalAliasLines)
+	if core.Len() > 0 {
+		fragments = append(fragments, fragment{name: coreFragment, path: fragmentPath(coreFragment), content: []byte(g.generateHeader(shellType) + core.String())})
+	}
+
+	for _, bundle := range bundles {
+		if bundle.Shell == nil {
+			continue
+		}
+		one := []*apps.Bundle{bundle}
+		var body strings.Builder
+		aliases, err := g.generateAliases(one, nil, shellType, plat)
+		if err != nil {
+			return err
+		}
+		writeSection(&body, "Aliases", aliases)
+		functions, err := g.generateFunctions(one, shellType, plat)
+		if err != nil {
+			return err
+		}
+		writeSection(&body, "Functions", functions)
+		writeSection(&body, "Init", g.generateInit(one, shellType))
+		if !opts.DisableCompletionCommands {
+			writeSection(&body, "Completions", g.generateCompletions(one, shellType))
+		}
+		if body.Len() == 0 {
+			continue
+		}
+		fragments = append(fragments, fragment{name: bundle.Name, path: fragmentPath(bundle.Name), content: []byte(g.generateHeader(shellType) + body.String())})
+	}
+
+	loader := fragment{path: outputPath, content: []byte(g.generateLoader(shellType, fragments, opts))}
+
+	// Check every changed file before replacing any of them.
+	candidates := make(map[string]string)
+	discard := func() {
+		for _, candidate := range candidates {
+			_ = os.Remove(candidate)
+		}
+	}
+	for _, f := range append(fragments, loader) {
+		if current, err := os.ReadFile(f.path); err == nil && bytes.Equal(current, f.content) {
+			continue
+		}
+		candidate, err := writeCandidate(shellType, f.path, f.content)
+		if err != nil {
+			discard()
+			return err
+		}
+		candidates[f.path] = candidate
+	}
+
+	for _, f := range fragments {
+		candidate, ok := candidates[f.path]
+		if !ok {
+			continue
+		}
+		if err := os.Rename(candidate, f.path); err != nil {
+			discard()
+			return fmt.Errorf("failed to write shell fragment: %w", err)
+		}
+		delete(candidates, f.path)
+		_ = os.Remove(f.path + ".rejected")
+		if shellType == Zsh {
+			compileZsh(f.path)
+		}
+	}
+	if candidate, ok := candidates[loader.path]; ok {
+		if err := os.Rename(candidate, loader.path); err != nil {
+			_ = os.Remove(candidate)
+			return fmt.Errorf("failed to write shell script: %w", err)
+		}
+		_ = os.Remove(loader.path + ".rejected")
+	}
+
+	return removeStaleFragments(fragmentDir, shellType, fragments)
+}
+
+// generateLoader renders the init script of a split generation. Each
+// fragment is sourced unless this shell already sourced the same content,
+// so re-sourcing the loader (as auto-reload does) only re-runs fragments
+// that changed.
+func (g *Generator) generateLoader(shellType ShellType, fragments []fragment, opts GenerateOptions) string {
+	var body strings.Builder
+	for _, f := range fragments {
+		variable := fragmentVarPrefix + shellIdentifier(f.name)
+		path := QuoteLiteral(shellType, f.path)
+		fmt.Fprintf(&body, "# fragment: %s\n", f.name)
+		if shellType == Fish {
+			fmt.Fprintf(&body, "if test \"$%s\" != %s\n  source %s\n  set -g %s %s\nend\n", variable, f.hash(), path, variable, f.hash())
+			continue
+		}
+		fmt.Fprintf(&body, "if [ \"${%s-}\" != %s ]; then\n  . %s\n  %s=%s\nfi\n", variable, f.hash(), path, variable, f.hash())
+	}
+
+	var script strings.Builder
+	script.WriteString(g.generateHeader(shellType))
+	writeSection(&script, "Fragments", body.String())
+	if opts.EnableAutoReload {
+		writeSection(&script, "Auto-reload", g.generateAutoReload(shellType))
+	}
+	return script.String()
+}
+
+// compileZsh runs zcompile on path so zsh sources the compiled path.zwc.
+// Without zsh, or if compiling fails, a stale .zwc is removed instead; zsh
+// ignores a .zwc older than its source, so this only saves disk space.
+func compileZsh(path string) {
+	if bin, err := exec.LookPath("zsh"); err == nil {
+		if exec.Command(bin, "-f", "-c", `zcompile -- "$1"`, "gdf-zcompile", path).Run() == nil {
+			return
+		}
+	}
+	_ = os.Remove(path + ".zwc")
+}
+
+// removeStaleFragments deletes fragments for shellType that were not part
+// of this generation, along with their compiled .zwc files.
+func removeStaleFragments(dir string, shellType ShellType, keep []fragment) error {
+	wanted := make(map[string]bool, len(keep))
+	for _, f := range keep {
+		wanted[filepath.Base(f.path)] = true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading shell fragments: %w", err)
+	}
+	suffix := "." + shellType.String()
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".zwc")
+		if entry.IsDir() || !strings.HasSuffix(name, suffix) || wanted[name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing stale shell fragment: %w", err)
+		}
+	}
+	return nil
+}
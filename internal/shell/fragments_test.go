@@ -0,0 +1,167 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func splitBundles(greeting string) []*apps.Bundle {
+	return []*apps.Bundle{
+		{
+			Name: "alpha",
+			Shell: &apps.Shell{
+				Aliases: map[string]apps.ConditionalValues{"a": apps.Value("echo alpha")},
+				Env:     map[string]apps.EnvVar{"ALPHA_HOME": {Value: "/opt/alpha"}},
+				Init:    []apps.InitSnippet{{Name: "count", Common: "ALPHA_LOADS=$((${ALPHA_LOADS:-0} + 1))"}},
+			},
+		},
+		{
+			Name: "beta",
+			Shell: &apps.Shell{
+				Init: []apps.InitSnippet{{Name: "count", Common: "BETA_LOADS=$((${BETA_LOADS:-0} + 1)); BETA_GREETING=" + QuoteLiteral(Bash, greeting)}},
+			},
+		},
+		{Name: "no-shell"},
+	}
+}
+
+func TestGenerator_SplitWritesFragmentsAndLoader(t *testing.T) {
+	dir := t.TempDir()
+	initPath := filepath.Join(dir, "init.bash")
+	g := NewGenerator()
+	opts := GenerateOptions{Split: true}
+	if err := g.GenerateWithOptions(splitBundles("hi"), Bash, initPath, map[string]string{"a": "echo global", "g": "echo global"}, opts); err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+
+	fragmentDir := filepath.Join(dir, FragmentDirName)
+	core, err := os.ReadFile(filepath.Join(fragmentDir, "_gdf.bash"))
+	if err != nil {
+		t.Fatalf("reading core fragment: %v", err)
+	}
+	for _, want := range []string{`export ALPHA_HOME="/opt/alpha"`, "alias g='echo global'"} {
+		if !strings.Contains(string(core), want) {
+			t.Errorf("core fragment missing %q:\n%s", want, core)
+		}
+	}
+	if strings.Contains(string(core), "alias a=") {
+		t.Errorf("core fragment contains global alias overridden by an app:\n%s", core)
+	}
+	alpha, err := os.ReadFile(filepath.Join(fragmentDir, "alpha.bash"))
+	if err != nil {
+		t.Fatalf("reading alpha fragment: %v", err)
+	}
+	for _, want := range []string{"alias a='echo alpha'", "# alpha:count"} {
+		if !strings.Contains(string(alpha), want) {
+			t.Errorf("alpha fragment missing %q:\n%s", want, alpha)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(fragmentDir, "no-shell.bash")); !os.IsNotExist(err) {
+		t.Errorf("app without shell content got a fragment: %v", err)
+	}
+
+	loader, err := os.ReadFile(initPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# fragment: _gdf", "# fragment: beta", `if [ "${__GDF_FRAG_alpha-}" != `, ". '" + filepath.Join(fragmentDir, "beta.bash") + "'"} {
+		if !strings.Contains(string(loader), want) {
+			t.Errorf("loader missing %q:\n%s", want, loader)
+		}
+	}
+
+	// Unchanged fragments are not rewritten; removed apps lose theirs.
+	old := time.Now().Add(-time.Hour)
+	alphaPath := filepath.Join(fragmentDir, "alpha.bash")
+	if err := os.Chtimes(alphaPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateWithOptions(splitBundles("hi")[:1], Bash, initPath, nil, opts); err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+	if info, err := os.Stat(alphaPath); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("unchanged alpha fragment was rewritten: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(fragmentDir, "beta.bash")); !os.IsNotExist(err) {
+		t.Errorf("stale beta fragment not removed: %v", err)
+	}
+}
+
+func TestGenerator_SplitRejectsInvalidFragment(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	dir := t.TempDir()
+	initPath := filepath.Join(dir, "init.bash")
+	g := NewGenerator()
+	opts := GenerateOptions{Split: true}
+	if err := g.GenerateWithOptions(splitBundles("hi"), Bash, initPath, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(initPath)
+
+	bundles := splitBundles("bye")
+	bundles[1].Shell.Init[0].Common = "if true; then"
+	err := g.GenerateWithOptions(bundles, Bash, initPath, nil, opts)
+	verr, ok := err.(*InitValidationError)
+	if !ok {
+		t.Fatalf("GenerateWithOptions() error = %v, want *InitValidationError", err)
+	}
+	if verr.Context != "beta:count" || !strings.HasSuffix(verr.RejectedPath, "beta.bash.rejected") {
+		t.Errorf("validation error = %+v", verr)
+	}
+	after, _ := os.ReadFile(initPath)
+	if string(after) != string(before) {
+		t.Error("loader replaced although a fragment was rejected")
+	}
+	if beta, _ := os.ReadFile(filepath.Join(dir, FragmentDirName, "beta.bash")); !strings.Contains(string(beta), "'hi'") {
+		t.Errorf("previous beta fragment not kept:\n%s", beta)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, FragmentDirName, "*.new")); len(matches) > 0 {
+		t.Errorf("candidate files left behind: %v", matches)
+	}
+}
+
+// TestGenerator_SplitReloadSkipsUnchangedFragments sources the loader, swaps
+// in a generation where only beta changed, and sources it again, as the
+// auto-reload hook does.
+func TestGenerator_SplitReloadSkipsUnchangedFragments(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	base := t.TempDir()
+	dir := filepath.Join(base, "generated")
+	initPath := filepath.Join(dir, "init.bash")
+	g := NewGenerator()
+	opts := GenerateOptions{Split: true}
+
+	generation := func(greeting, copyTo string) {
+		t.Helper()
+		if err := g.GenerateWithOptions(splitBundles(greeting), Bash, initPath, nil, opts); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("cp", "-R", dir, copyTo).CombinedOutput(); err != nil {
+			t.Fatalf("cp: %v\n%s", err, out)
+		}
+	}
+	generation("bye", filepath.Join(base, "second"))
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	generation("hi", filepath.Join(base, "first"))
+
+	script := `. "$1/init.bash"; cp -R "$2/." "$1/"; . "$1/init.bash"; echo "$ALPHA_LOADS $BETA_LOADS $BETA_GREETING"`
+	out, err := exec.Command("bash", "-c", script, "test", dir, filepath.Join(base, "second")).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash: %v\n%s", err, out)
+	}
+	if got := strings.TrimSpace(string(out)); got != "1 2 bye" {
+		t.Errorf("after reload got %q, want alpha loaded once and beta twice (\"1 2 bye\")", got)
+	}
+}
@@ -1,6 +1,7 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
 	"github.com/rztaylor/GoDotFiles/internal/util"
 )
 
@@ -21,6 +23,12 @@ type GenerateOptions struct {
 	// DisableCompletionCommands skips inline completion command generation.
 	// This is used when completion artifacts are generated as managed files during apply.
 	DisableCompletionCommands bool
+	// Platform evaluates `when` conditions. Defaults to the detected platform.
+	Platform *platform.Platform
+	// Split writes each app's aliases, functions, init snippets and
+	// completions to its own fragment in a "shell" directory next to the
+	// output, and makes the output a loader that sources them.
+	Split bool
 }
 
 // NewGenerator creates a new shell generator.
@@ -40,6 +48,27 @@ func (g *Generator) GenerateWithOptions(bundles []*apps.Bundle, shellType ShellT
 		return fmt.Errorf("cannot generate script for unknown shell type")
 	}
 
+	if err := ValidateNames(bundles, globalAliases, shellType); err != nil {
+		return err
+	}
+
+	plat := opts.Platform
+	if plat == nil {
+		plat = platform.Detect()
+	}
+	pathDirs, err := ResolvePath(bundles, plat)
+	if err != nil {
+		return err
+	}
+	envExports, err := ResolveEnv(bundles, shellType, plat)
+	if err != nil {
+		return err
+	}
+
+	if opts.Split {
+		return g.generateSplit(bundles, shellType, outputPath, globalAliases, pathDirs, envExports, plat, opts)
+	}
+
 	// Build script content
 	var script strings.Builder
 
@@ -47,48 +76,35 @@ func (g *Generator) GenerateWithOptions(bundles []*apps.Bundle, shellType ShellT
 	script.WriteString(g.generateHeader(shellType))
 
 	// Aliases
-	aliases := g.generateAliases(bundles, globalAliases)
-	if aliases != "" {
-		script.WriteString("\n# Aliases\n")
-		script.WriteString(aliases)
+	aliases, err := g.generateAliases(bundles, globalAliases, shellType, plat)
+	if err != nil {
+		return err
 	}
+	writeSection(&script, "Aliases", aliases)
 
 	// Environment variables
-	envVars := g.generateEnvVars(bundles)
-	if envVars != "" {
-		script.WriteString("\n# Environment variables\n")
-		script.WriteString(envVars)
-	}
+	writeSection(&script, "Environment variables", g.generateEnvVars(envExports, shellType))
+
+	// PATH, after the env vars its entries may reference
+	writeSection(&script, "PATH", g.generatePath(pathDirs, shellType))
 
 	// Functions
-	functions := g.generateFunctions(bundles)
-	if functions != "" {
-		script.WriteString("\n# Functions\n")
-		script.WriteString(functions)
+	functions, err := g.generateFunctions(bundles, shellType, plat)
+	if err != nil {
+		return err
 	}
+	writeSection(&script, "Functions", functions)
 
 	// Init
-	initSnippets := g.generateInit(bundles, shellType)
-	if initSnippets != "" {
-		script.WriteString("\n# Init\n")
-		script.WriteString(initSnippets)
-	}
+	writeSection(&script, "Init", g.generateInit(bundles, shellType))
 
 	if !opts.DisableCompletionCommands {
 		// Completions
-		completions := g.generateCompletions(bundles, shellType)
-		if completions != "" {
-			script.WriteString("\n# Completions\n")
-			script.WriteString(completions)
-		}
+		writeSection(&script, "Completions", g.generateCompletions(bundles, shellType))
 	}
 
 	if opts.EnableAutoReload {
-		autoReload := g.generateAutoReload(shellType)
-		if autoReload != "" {
-			script.WriteString("\n# Auto-reload\n")
-			script.WriteString(autoReload)
-		}
+		writeSection(&script, "Auto-reload", g.generateAutoReload(shellType))
 	}
 
 	// Ensure output directory exists
@@ -97,14 +113,54 @@ func (g *Generator) GenerateWithOptions(bundles []*apps.Bundle, shellType ShellT
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Write to file
-	if err := util.WriteFileAtomic(outputPath, []byte(script.String()), 0644); err != nil {
+	// Write a candidate first so a script that does not parse never replaces
+	// the working init file.
+	candidatePath, err := writeCandidate(shellType, outputPath, []byte(script.String()))
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(candidatePath, outputPath); err != nil {
+		_ = os.Remove(candidatePath)
 		return fmt.Errorf("failed to write shell script: %w", err)
 	}
+	_ = os.Remove(outputPath + ".rejected")
 
 	return nil
 }
 
+// writeSection appends a "# <name>" section to script when body is not empty.
+func writeSection(script *strings.Builder, name, body string) {
+	if body == "" {
+		return
+	}
+	script.WriteString("\n# " + name + "\n")
+	script.WriteString(body)
+}
+
+// writeCandidate writes content next to path as path.new and syntax-checks
+// it. A script that does not parse is moved to path.rejected and returned
+// as an *InitValidationError.
+func writeCandidate(shellType ShellType, path string, content []byte) (string, error) {
+	candidatePath := path + ".new"
+	if err := util.WriteFileAtomic(candidatePath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write shell script: %w", err)
+	}
+	if err := ValidateScript(shellType, candidatePath); err != nil {
+		var verr *InitValidationError
+		if errors.As(err, &verr) {
+			verr.RejectedPath = path + ".rejected"
+			if renameErr := os.Rename(candidatePath, verr.RejectedPath); renameErr != nil {
+				_ = os.Remove(candidatePath)
+				verr.RejectedPath = ""
+			}
+			return "", verr
+		}
+		_ = os.Remove(candidatePath)
+		return "", fmt.Errorf("validating shell script: %w", err)
+	}
+	return candidatePath, nil
+}
+
 // generateHeader generates the shell script header.
 func (g *Generator) generateHeader(shellType ShellType) string {
 	var shebang string
@@ -113,6 +169,8 @@ func (g *Generator) generateHeader(shellType ShellType) string {
 		shebang = "#!/bin/bash"
 	case Zsh:
 		shebang = "#!/bin/zsh"
+	case Fish:
+		shebang = "#!/usr/bin/env fish"
 	default:
 		shebang = "#!/bin/sh"
 	}
@@ -124,8 +182,9 @@ func (g *Generator) generateHeader(shellType ShellType) string {
 }
 
 // generateAliases generates alias definitions from bundles and global aliases.
-// App bundle aliases take precedence over global aliases with the same name.
-func (g *Generator) generateAliases(bundles []*apps.Bundle, globalAliases map[string]string) string {
+// App bundle aliases take precedence over global aliases with the same name;
+// an app alias whose conditions do not match plat is left out.
+func (g *Generator) generateAliases(bundles []*apps.Bundle, globalAliases map[string]string, shellType ShellType, plat *platform.Platform) (string, error) {
 	// Start with global aliases (app aliases override these)
 	aliases := make(map[string]string)
 	for name, cmd := range globalAliases {
@@ -136,13 +195,19 @@ func (g *Generator) generateAliases(bundles []*apps.Bundle, globalAliases map[st
 		if bundle.Shell == nil || bundle.Shell.Aliases == nil {
 			continue
 		}
-		for name, cmd := range bundle.Shell.Aliases {
-			aliases[name] = cmd
+		for name, values := range bundle.Shell.Aliases {
+			cmd, ok, err := ResolveValue(values, shellType, plat)
+			if err != nil {
+				return "", fmt.Errorf("app %s: alias %s%w", bundle.Name, name, err)
+			}
+			if ok {
+				aliases[name] = cmd
+			}
 		}
 	}
 
 	if len(aliases) == 0 {
-		return ""
+		return "", nil
 	}
 
 	// Sort by alias name for deterministic output
@@ -154,75 +219,93 @@ func (g *Generator) generateAliases(bundles []*apps.Bundle, globalAliases map[st
 
 	var out strings.Builder
 	for _, name := range names {
-		fmt.Fprintf(&out, "alias %s='%s'\n", name, aliases[name])
+		fmt.Fprintf(&out, "alias %s=%s\n", name, QuoteLiteral(shellType, aliases[name]))
 	}
 
-	return out.String()
+	return out.String(), nil
 }
 
-// generateEnvVars generates environment variable exports.
-func (g *Generator) generateEnvVars(bundles []*apps.Bundle) string {
-	// Collect all env vars (last bundle wins for duplicates)
-	envVars := make(map[string]string)
-	for _, bundle := range bundles {
-		if bundle.Shell == nil || bundle.Shell.Env == nil {
-			continue
+// generateEnvVars generates environment variable exports in dependency
+// order. Values are double-quoted so $VAR expands, or single-quoted when
+// expand is false.
+func (g *Generator) generateEnvVars(envVars []EnvExport, shellType ShellType) string {
+	var out strings.Builder
+	for _, env := range envVars {
+		value := QuoteExpandable(shellType, env.Value)
+		if !env.Expand {
+			value = QuoteLiteral(shellType, env.Value)
 		}
-		for name, value := range bundle.Shell.Env {
-			envVars[name] = value
+		if shellType == Fish {
+			fmt.Fprintf(&out, "set -gx %s %s\n", env.Name, value)
+			continue
 		}
+		fmt.Fprintf(&out, "export %s=%s\n", env.Name, value)
 	}
-
-	if len(envVars) == 0 {
-		return ""
-	}
-
-	// Sort by variable name for deterministic output
-	names := make([]string, 0, len(envVars))
-	for name := range envVars {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	var out strings.Builder
-	for _, name := range names {
-		fmt.Fprintf(&out, "export %s=\"%s\"\n", name, envVars[name])
-	}
-
 	return out.String()
 }
 
-// generateFunctions generates shell function definitions.
-func (g *Generator) generateFunctions(bundles []*apps.Bundle) string {
+// generateFunctions generates shell function definitions for functions
+// whose conditions match plat. Bodies without a shell are POSIX shell, so
+// fish only gets functions with a `shell: fish` body and a comment naming
+// the others.
+func (g *Generator) generateFunctions(bundles []*apps.Bundle, shellType ShellType, plat *platform.Platform) (string, error) {
 	// Collect all functions (last bundle wins for duplicates)
 	functions := make(map[string]string)
+	skipped := make(map[string]bool)
 	for _, bundle := range bundles {
 		if bundle.Shell == nil || bundle.Shell.Functions == nil {
 			continue
 		}
-		for name, body := range bundle.Shell.Functions {
-			functions[name] = body
+		for name, values := range bundle.Shell.Functions {
+			if shellType == Fish {
+				values = fishValues(values)
+			}
+			body, ok, err := ResolveValue(values, shellType, plat)
+			if err != nil {
+				return "", fmt.Errorf("app %s: function %s%w", bundle.Name, name, err)
+			}
+			if ok {
+				functions[name] = body
+				delete(skipped, name)
+			} else if shellType == Fish && len(values) < len(bundle.Shell.Functions[name]) {
+				skipped[name] = true
+			}
 		}
 	}
 
-	if len(functions) == 0 {
-		return ""
+	if len(functions) == 0 && len(skipped) == 0 {
+		return "", nil
 	}
 
-	// Sort by function name for deterministic output
-	names := make([]string, 0, len(functions))
-	for name := range functions {
-		names = append(names, name)
+	var out strings.Builder
+	for _, name := range util.SortedKeys(functions) {
+		out.WriteString(renderFunction(shellType, name, functions[name]))
+		out.WriteString("\n")
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&out, "# Not generated for %s (POSIX function bodies): %s\n", shellType, strings.Join(util.SortedKeys(skipped), ", "))
 	}
-	sort.Strings(names)
 
-	var out strings.Builder
-	for _, name := range names {
-		body := functions[name]
-		fmt.Fprintf(&out, "%s() {\n  %s\n}\n\n", name, body)
+	return out.String(), nil
+}
+
+// renderFunction renders a function definition in shellType's syntax.
+func renderFunction(shellType ShellType, name, body string) string {
+	if shellType == Fish {
+		return fmt.Sprintf("function %s\n  %s\nend\n", name, body)
 	}
+	return fmt.Sprintf("%s() {\n  %s\n}\n", name, body)
+}
 
-	return out.String()
+// fishValues returns the values limited to fish.
+func fishValues(values apps.ConditionalValues) apps.ConditionalValues {
+	var out apps.ConditionalValues
+	for _, v := range values {
+		if v.Shell == Fish.String() {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
 // generateCompletions generates shell completion loading commands.
@@ -283,6 +366,8 @@ func (g *Generator) generateInit(bundles []*apps.Bundle, shellType ShellType) st
 				if snippet.Zsh != "" {
 					cmd = snippet.Zsh
 				}
+			case Fish:
+				cmd = snippet.Fish
 			}
 
 			if cmd == "" {
@@ -291,7 +376,15 @@ func (g *Generator) generateInit(bundles []*apps.Bundle, shellType ShellType) st
 
 			hasInit = true
 			fmt.Fprintf(&out, "# %s:%s\n", bundle.Name, snippet.Name)
-			if snippet.Guard != "" {
+			if len(snippet.Lazy) > 0 {
+				fmt.Fprintf(&out, "# lazy: %s\n", strings.Join(snippet.Lazy, " "))
+				cmd = lazyInit(bundle.Name, snippet, cmd, shellType)
+			}
+			if snippet.Guard != "" && shellType == Fish {
+				fmt.Fprintf(&out, "if %s\n", snippet.Guard)
+				writeIndentedLines(&out, cmd, "  ")
+				out.WriteString("end\n")
+			} else if snippet.Guard != "" {
 				fmt.Fprintf(&out, "if %s; then\n", snippet.Guard)
 				writeIndentedLines(&out, cmd, "  ")
 				out.WriteString("fi\n")
@@ -307,6 +400,47 @@ func (g *Generator) generateInit(bundles []*apps.Bundle, shellType ShellType) st
 	return out.String()
 }
 
+// lazyInit wraps an init snippet in a loader function and defines a stub for
+// each of snippet.Lazy. The first stub called removes all stubs, runs the
+// snippet, then re-runs itself so the real command (or the function the
+// snippet defined) receives the arguments.
+func lazyInit(app string, snippet apps.InitSnippet, cmd string, shellType ShellType) string {
+	loader := "__gdf_lazy_" + shellIdentifier(app) + "_" + shellIdentifier(snippet.Name)
+	stubs := strings.Join(snippet.Lazy, " ")
+
+	var out strings.Builder
+	if shellType == Fish {
+		fmt.Fprintf(&out, "function %s\n", loader)
+		fmt.Fprintf(&out, "  functions -e %s\n", stubs)
+		writeIndentedLines(&out, cmd, "  ")
+		out.WriteString("end\n")
+		for _, name := range snippet.Lazy {
+			fmt.Fprintf(&out, "function %s\n  %s\n  %s $argv\nend\n", name, loader, name)
+		}
+		return strings.TrimSuffix(out.String(), "\n")
+	}
+
+	fmt.Fprintf(&out, "%s() {\n", loader)
+	fmt.Fprintf(&out, "  unset -f %s\n", stubs)
+	writeIndentedLines(&out, cmd, "  ")
+	out.WriteString("}\n")
+	for _, name := range snippet.Lazy {
+		fmt.Fprintf(&out, "%s() { %s; %s \"$@\"; }\n", name, loader, name)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// shellIdentifier maps s to characters valid in a function or variable name
+// in every supported shell.
+func shellIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
 func writeIndentedLines(out *strings.Builder, script, indent string) {
 	lines := strings.Split(script, "\n")
 	for _, line := range lines {
@@ -331,7 +465,11 @@ func extractCommand(cmd string) string {
 
 // ExportAliases generates a file containing all aliases from bundles and global aliases.
 func (g *Generator) ExportAliases(bundles []*apps.Bundle, globalAliases map[string]string, outputPath string) error {
-	aliases := g.generateAliases(bundles, globalAliases)
+	// The exported file is plain POSIX alias syntax.
+	aliases, err := g.generateAliases(bundles, globalAliases, Bash, platform.Detect())
+	if err != nil {
+		return err
+	}
 	if aliases == "" {
 		aliases = "# No aliases found\n"
 	}
@@ -346,16 +484,19 @@ func (g *Generator) ExportAliases(bundles []*apps.Bundle, globalAliases map[stri
 	return nil
 }
 
+// generateAutoReload emits a prompt hook that re-sources the shell's own
+// init script when its modification time changes.
 func (g *Generator) generateAutoReload(shellType ShellType) string {
+	var hook string
 	switch shellType {
 	case Bash:
-		return `
+		hook = `
 _gdf_auto_reload_check() {
   [ -n "${PS1-}" ] || return
-  [ -f "$HOME/.gdf/generated/init.sh" ] || return
+  [ -f "$HOME/.gdf/generated/@INIT@" ] || return
 
   local _gdf_mtime
-  _gdf_mtime="$(stat -c %Y "$HOME/.gdf/generated/init.sh" 2>/dev/null || stat -f %m "$HOME/.gdf/generated/init.sh" 2>/dev/null)"
+  _gdf_mtime="$(stat -c %Y "$HOME/.gdf/generated/@INIT@" 2>/dev/null || stat -f %m "$HOME/.gdf/generated/@INIT@" 2>/dev/null)"
   [ -n "$_gdf_mtime" ] || return
 
   if [ -z "${GDF_INIT_MTIME:-}" ]; then
@@ -365,7 +506,7 @@ _gdf_auto_reload_check() {
 
   if [ "$GDF_INIT_MTIME" != "$_gdf_mtime" ]; then
     GDF_INIT_MTIME="$_gdf_mtime"
-    source "$HOME/.gdf/generated/init.sh"
+    source "$HOME/.gdf/generated/@INIT@"
   fi
 }
 
@@ -378,13 +519,13 @@ if [[ ";${PROMPT_COMMAND};" != *";_gdf_auto_reload_check;"* ]]; then
 fi
 `
 	case Zsh:
-		return `
+		hook = `
 _gdf_auto_reload_check() {
   [ -n "${PS1-}" ] || return
-  [ -f "$HOME/.gdf/generated/init.sh" ] || return
+  [ -f "$HOME/.gdf/generated/@INIT@" ] || return
 
   local _gdf_mtime
-  _gdf_mtime="$(stat -c %Y "$HOME/.gdf/generated/init.sh" 2>/dev/null || stat -f %m "$HOME/.gdf/generated/init.sh" 2>/dev/null)"
+  _gdf_mtime="$(stat -c %Y "$HOME/.gdf/generated/@INIT@" 2>/dev/null || stat -f %m "$HOME/.gdf/generated/@INIT@" 2>/dev/null)"
   [ -n "$_gdf_mtime" ] || return
 
   if [ -z "${GDF_INIT_MTIME:-}" ]; then
@@ -394,7 +535,7 @@ _gdf_auto_reload_check() {
 
   if [ "$GDF_INIT_MTIME" != "$_gdf_mtime" ]; then
     GDF_INIT_MTIME="$_gdf_mtime"
-    source "$HOME/.gdf/generated/init.sh"
+    source "$HOME/.gdf/generated/@INIT@"
   fi
 }
 
@@ -402,8 +543,28 @@ typeset -ga precmd_functions
 if (( ${precmd_functions[(Ie)_gdf_auto_reload_check]} == 0 )); then
   precmd_functions=(_gdf_auto_reload_check ${precmd_functions[@]})
 fi
+`
+	case Fish:
+		hook = `
+function _gdf_auto_reload_check --on-event fish_prompt
+  set -l _gdf_init "$HOME/.gdf/generated/@INIT@"
+  test -f $_gdf_init; or return
+  set -l _gdf_mtime (stat -c %Y $_gdf_init 2>/dev/null; or stat -f %m $_gdf_init 2>/dev/null)
+  test -n "$_gdf_mtime"; or return
+
+  if not set -q GDF_INIT_MTIME
+    set -g GDF_INIT_MTIME $_gdf_mtime
+    return
+  end
+
+  if test "$GDF_INIT_MTIME" != "$_gdf_mtime"
+    set -g GDF_INIT_MTIME $_gdf_mtime
+    source $_gdf_init
+  end
+end
 `
 	default:
 		return ""
 	}
+	return strings.ReplaceAll(hook, "@INIT@", shellType.InitFileName())
 }
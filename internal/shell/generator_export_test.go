@@ -14,8 +14,8 @@ func TestGenerator_ExportAliases(t *testing.T) {
 		{
 			Name: "kubectl",
 			Shell: &apps.Shell{
-				Aliases: map[string]string{
-					"k": "kubectl",
+				Aliases: map[string]apps.ConditionalValues{
+					"k": apps.Value("kubectl"),
 				},
 			},
 		},
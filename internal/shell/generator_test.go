@@ -1,7 +1,9 @@
 package shell
 
 import (
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -23,9 +25,9 @@ func TestGenerator_Generate(t *testing.T) {
 				{
 					Name: "kubectl",
 					Shell: &apps.Shell{
-						Aliases: map[string]string{
-							"k":   "kubectl",
-							"kgp": "kubectl get pods",
+						Aliases: map[string]apps.ConditionalValues{
+							"k":   apps.Value("kubectl"),
+							"kgp": apps.Value("kubectl get pods"),
 						},
 					},
 				},
@@ -43,17 +45,17 @@ func TestGenerator_Generate(t *testing.T) {
 				{
 					Name: "kubectl",
 					Shell: &apps.Shell{
-						Aliases: map[string]string{
-							"k": "kubectl",
+						Aliases: map[string]apps.ConditionalValues{
+							"k": apps.Value("kubectl"),
 						},
 					},
 				},
 				{
 					Name: "git",
 					Shell: &apps.Shell{
-						Aliases: map[string]string{
-							"g":   "git",
-							"gst": "git status",
+						Aliases: map[string]apps.ConditionalValues{
+							"g":   apps.Value("git"),
+							"gst": apps.Value("git status"),
 						},
 					},
 				},
@@ -72,9 +74,9 @@ func TestGenerator_Generate(t *testing.T) {
 				{
 					Name: "kubectl",
 					Shell: &apps.Shell{
-						Env: map[string]string{
-							"KUBECONFIG": "$HOME/.kube/config",
-							"EDITOR":     "vim",
+						Env: map[string]apps.EnvVar{
+							"KUBECONFIG": {Value: "$HOME/.kube/config"},
+							"EDITOR":     {Value: "vim"},
 						},
 					},
 				},
@@ -92,8 +94,8 @@ func TestGenerator_Generate(t *testing.T) {
 				{
 					Name: "kubectl",
 					Shell: &apps.Shell{
-						Functions: map[string]string{
-							"kns": "kubectl config set-context --current --namespace=\"$1\"",
+						Functions: map[string]apps.ConditionalValues{
+							"kns": apps.Value("kubectl config set-context --current --namespace=\"$1\""),
 						},
 					},
 				},
@@ -147,14 +149,14 @@ func TestGenerator_Generate(t *testing.T) {
 				{
 					Name: "kubectl",
 					Shell: &apps.Shell{
-						Aliases: map[string]string{
-							"k": "kubectl",
+						Aliases: map[string]apps.ConditionalValues{
+							"k": apps.Value("kubectl"),
 						},
-						Env: map[string]string{
-							"KUBECONFIG": "$HOME/.kube/config",
+						Env: map[string]apps.EnvVar{
+							"KUBECONFIG": {Value: "$HOME/.kube/config"},
 						},
-						Functions: map[string]string{
-							"kns": "kubectl config set-context --current --namespace=\"$1\"",
+						Functions: map[string]apps.ConditionalValues{
+							"kns": apps.Value("kubectl config set-context --current --namespace=\"$1\""),
 						},
 						Completions: &apps.Completions{
 							Bash: "kubectl completion bash",
@@ -234,16 +236,16 @@ func TestGenerator_DuplicateAliases(t *testing.T) {
 		{
 			Name: "bundle1",
 			Shell: &apps.Shell{
-				Aliases: map[string]string{
-					"k": "kubectl",
+				Aliases: map[string]apps.ConditionalValues{
+					"k": apps.Value("kubectl"),
 				},
 			},
 		},
 		{
 			Name: "bundle2",
 			Shell: &apps.Shell{
-				Aliases: map[string]string{
-					"k": "k9s", // Override
+				Aliases: map[string]apps.ConditionalValues{
+					"k": apps.Value("k9s"), // Override
 				},
 			},
 		},
@@ -333,6 +335,65 @@ func TestGenerator_ShellSpecific(t *testing.T) {
 	}
 }
 
+func TestGenerator_LazyInitSnippet(t *testing.T) {
+	bundles := []*apps.Bundle{
+		{
+			Name: "greeter",
+			Shell: &apps.Shell{
+				Init: []apps.InitSnippet{
+					{
+						Name:   "hello-env",
+						Common: "GDF_LAZY_LOADS=$((${GDF_LAZY_LOADS:-0} + 1))\nhello() { echo \"hello $*\"; }",
+						Fish:   "function hello; echo hello $argv; end",
+						Lazy:   []string{"hello", "hi"},
+					},
+				},
+			},
+		},
+	}
+
+	g := NewGenerator()
+	dir := t.TempDir()
+	fishPath := filepath.Join(dir, "init.fish")
+	if err := g.Generate(bundles, Fish, fishPath, nil); err != nil {
+		t.Fatalf("Generate(fish) error = %v", err)
+	}
+	fish, _ := os.ReadFile(fishPath)
+	for _, want := range []string{
+		"# lazy: hello hi",
+		"function __gdf_lazy_greeter_hello_env\n  functions -e hello hi\n",
+		"function hi\n  __gdf_lazy_greeter_hello_env\n  hi $argv\nend",
+	} {
+		if !strings.Contains(string(fish), want) {
+			t.Errorf("fish init missing %q\n%s", want, fish)
+		}
+	}
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	bashPath := filepath.Join(dir, "init.bash")
+	if err := g.Generate(bundles, Bash, bashPath, nil); err != nil {
+		t.Fatalf("Generate(bash) error = %v", err)
+	}
+	// Sourcing defines stubs only; the first call loads the snippet once and
+	// runs the real function, and later calls skip the loader.
+	script := `. "$1"; echo "loads=${GDF_LAZY_LOADS:-0}"; hello world; hello again; type -t hi || echo "hi=gone"; echo "loads=$GDF_LAZY_LOADS"`
+	out, err := exec.Command("bash", "-c", script, "test", bashPath).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash: %v\n%s", err, out)
+	}
+	want := "loads=0\nhello world\nhello again\nhi=gone\nloads=1\n"
+	if string(out) != want {
+		t.Errorf("lazy init output = %q, want %q", out, want)
+	}
+
+	bundles[0].Shell.Init[0].Lazy = []string{"bad name"}
+	if err := g.Generate(bundles, Bash, bashPath, nil); err == nil || !strings.Contains(err.Error(), "lazy command") {
+		t.Errorf("Generate() error = %v, want invalid lazy command error", err)
+	}
+}
+
 func TestGenerator_InitSnippets(t *testing.T) {
 	bundles := []*apps.Bundle{
 		{
@@ -453,6 +514,113 @@ func TestGenerator_AutoReloadHooks(t *testing.T) {
 	if !strings.Contains(string(zshContent), "precmd_functions") {
 		t.Fatalf("expected zsh precmd hook in output:\n%s", string(zshContent))
 	}
+	if !strings.Contains(string(zshContent), "$HOME/.gdf/generated/init.zsh") {
+		t.Fatalf("expected zsh hook to watch init.zsh:\n%s", string(zshContent))
+	}
+
+	fishOutput := filepath.Join(tmpDir, "init.fish")
+	err = g.GenerateWithOptions(nil, Fish, fishOutput, nil, GenerateOptions{EnableAutoReload: true})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() fish error = %v", err)
+	}
+	fishContent, err := os.ReadFile(fishOutput)
+	if err != nil {
+		t.Fatalf("reading generated fish file: %v", err)
+	}
+	if !strings.Contains(string(fishContent), "--on-event fish_prompt") {
+		t.Fatalf("expected fish prompt event hook in output:\n%s", string(fishContent))
+	}
+}
+
+func TestGenerator_Fish(t *testing.T) {
+	bundles := []*apps.Bundle{
+		{
+			Name: "tools",
+			Shell: &apps.Shell{
+				Path:      []apps.PathEntry{{Dir: "~/.local/bin"}, {Dir: "/opt/tools", Position: apps.PathAppend}},
+				Aliases:   map[string]apps.ConditionalValues{"ll": apps.Value("ls -la")},
+				Env:       map[string]apps.EnvVar{"EDITOR": {Value: "nvim"}, "TOOLS_HOME": {Value: "${HOME}/tools"}},
+				Functions: map[string]apps.ConditionalValues{"mkcd": apps.Value(`mkdir -p "$1" && cd "$1"`)},
+				Init: []apps.InitSnippet{
+					{Name: "posix-only", Common: "eval \"$(tool init)\""},
+					{Name: "fish", Fish: "tool init fish | source", Guard: "type -q tool"},
+				},
+				Completions: &apps.Completions{Bash: "tool completion bash"},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "init.fish")
+	if err := NewGenerator().Generate(bundles, Fish, outputPath, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"#!/usr/bin/env fish",
+		"if test -d \"$HOME/.local/bin\"\n  while contains -- \"$HOME/.local/bin\" $PATH\n    set -e PATH[(contains -i -- \"$HOME/.local/bin\" $PATH)]\n  end\n  set -gx PATH \"$HOME/.local/bin\" $PATH\nend",
+		"set -gx PATH $PATH \"/opt/tools\"",
+		"alias ll='ls -la'",
+		"set -gx EDITOR \"nvim\"",
+		"set -gx TOOLS_HOME \"{$HOME}/tools\"",
+		"# Not generated for fish (POSIX function bodies): mkcd",
+		"if type -q tool\n  tool init fish | source\nend",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("fish script missing %q\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"export ", "mkcd()", "tool init)", "completion bash", "__gdf_path_add"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("fish script contains POSIX content %q\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestGenerator_PerShellFunctionBodies(t *testing.T) {
+	bundles := []*apps.Bundle{
+		{
+			Name: "tools",
+			Shell: &apps.Shell{
+				Functions: map[string]apps.ConditionalValues{"mkcd": {
+					{Value: "mkdir -p $argv[1]; and cd $argv[1]", Shell: "fish"},
+					{Value: "mkdir -p \"$1\"\ncd \"$1\"", Shell: "zsh"},
+					{Value: `mkdir -p "$1" && cd "$1"`},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		shell   ShellType
+		want    string
+		notWant string
+	}{
+		{shell: Fish, want: "function mkcd\n  mkdir -p $argv[1]; and cd $argv[1]\nend\n", notWant: "Not generated for fish"},
+		{shell: Zsh, want: "mkcd() {\n  mkdir -p \"$1\"\ncd \"$1\"\n}\n", notWant: "&&"},
+		{shell: Bash, want: "mkcd() {\n  mkdir -p \"$1\" && cd \"$1\"\n}\n", notWant: "argv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell.String(), func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "init")
+			if err := NewGenerator().Generate(bundles, tt.shell, outputPath, nil); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			data, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("script missing %q\n%s", tt.want, data)
+			}
+			if strings.Contains(string(data), tt.notWant) {
+				t.Errorf("script contains %q\n%s", tt.notWant, data)
+			}
+		})
+	}
 }
 
 func TestGenerator_DisableCompletionCommands(t *testing.T) {
@@ -532,3 +700,40 @@ func TestGenerator_InitOrdering(t *testing.T) {
 		t.Errorf("init snippets are out of order:\n%s", contentStr)
 	}
 }
+
+func TestGenerator_RejectsInvalidScriptAndKeepsPrevious(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	outputPath := filepath.Join(t.TempDir(), "init.sh")
+	previous := "# previous working init\n"
+	if err := os.WriteFile(outputPath, []byte(previous), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundles := []*apps.Bundle{{
+		Name: "broken",
+		Shell: &apps.Shell{
+			Init: []apps.InitSnippet{{Name: "setup", Bash: "if true; then\n  echo missing fi"}},
+		},
+	}}
+	err := NewGenerator().Generate(bundles, Bash, outputPath, nil)
+	var verr *InitValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Generate() error = %v, want *InitValidationError", err)
+	}
+	if verr.Context != "broken:setup" {
+		t.Errorf("Context = %q, want broken:setup", verr.Context)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != previous {
+		t.Fatalf("init.sh was replaced by an invalid script:\n%s", got)
+	}
+	if _, err := os.Stat(verr.RejectedPath); err != nil {
+		t.Fatalf("rejected script not kept at %q: %v", verr.RejectedPath, err)
+	}
+}
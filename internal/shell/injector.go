@@ -6,74 +6,115 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+	"github.com/rztaylor/GoDotFiles/internal/util"
 )
 
 const sourceLineComment = "# Added by gdf for shell integration"
-const sourceLine = "[ -f ~/.gdf/generated/init.sh ] && source ~/.gdf/generated/init.sh"
+
+// sourceIdentifier matches source lines for init.sh and every init.<shell>.
+const sourceIdentifier = ".gdf/generated/init."
+
+// SourceLine returns the rc file line that sources the shell's init script.
+func SourceLine(shellType ShellType) string {
+	path := "~/.gdf/generated/" + shellType.InitFileName()
+	if shellType == Fish {
+		return fmt.Sprintf("test -f %s; and source %s", path, path)
+	}
+	return fmt.Sprintf("[ -f %s ] && source %s", path, path)
+}
 
 // Injector handles injecting the source line into RC files.
-type Injector struct{}
+type Injector struct {
+	snapshot func(path string) error
+}
 
 // NewInjector creates a new RC injector.
 func NewInjector() *Injector {
 	return &Injector{}
 }
 
-// InjectSourceLine adds the GDF source line to the appropriate RC file.
+// SetSnapshotFunc sets a function called with the path of each RC file
+// before it is modified, so callers can keep a copy for rollback. An error
+// from snapshot aborts the change.
+func (i *Injector) SetSnapshotFunc(snapshot func(path string) error) {
+	i.snapshot = snapshot
+}
+
+// InjectSourceLine adds the GDF source line to the default RC file of the shell.
 func (i *Injector) InjectSourceLine(shellType ShellType) error {
-	rcPath := i.getRCPath(shellType)
+	rcPath := i.RCPath(shellType)
 	if rcPath == "" {
 		return fmt.Errorf("cannot determine RC file for shell type: %s", shellType)
 	}
+	_, err := i.InjectInto(shellType, rcPath)
+	return err
+}
 
-	// Check if already injected
-	hasSource, err := i.hasSourceLine(rcPath)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to check RC file: %w", err)
+// InjectInto adds the GDF source line for shellType to rcPath unless the file
+// already sources a GDF init script, and reports whether it changed the file.
+// When a snapshot function is set, it is called before the file is modified.
+func (i *Injector) InjectInto(shellType ShellType, rcPath string) (bool, error) {
+	if shellType == Unknown {
+		return false, fmt.Errorf("cannot inject source line for shell type: %s", shellType)
 	}
 
+	// Check if already injected
+	hasSource, err := HasSourceLine(rcPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to check RC file: %w", err)
+	}
 	if hasSource {
-		// Already injected, nothing to do
-		return nil
+		return false, nil
 	}
 
-	// Backup existing RC file if it exists
-	if _, err := os.Stat(rcPath); err == nil {
-		backupPath := rcPath + ".gdf.backup"
-		if err := copyFile(rcPath, backupPath); err != nil {
-			return fmt.Errorf("failed to backup RC file: %w", err)
+	if i.snapshot != nil {
+		if err := i.snapshot(rcPath); err != nil {
+			return false, fmt.Errorf("failed to snapshot RC file: %w", err)
 		}
 	}
 
-	// Append source line
-	f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open RC file: %w", err)
+	content, err := os.ReadFile(rcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read RC file: %w", err)
 	}
-	defer f.Close()
-
-	// Add newlines if file doesn't end with one
-	stat, _ := f.Stat()
-	if stat.Size() > 0 {
-		// Read last byte to check for newline
-		content, _ := os.ReadFile(rcPath)
-		if len(content) > 0 && content[len(content)-1] != '\n' {
-			f.WriteString("\n")
+	var out strings.Builder
+	out.Write(content)
+	if len(content) > 0 {
+		// Add newlines if file doesn't end with one
+		if content[len(content)-1] != '\n' {
+			out.WriteString("\n")
 		}
-		f.WriteString("\n")
+		out.WriteString("\n")
 	}
+	fmt.Fprintf(&out, "%s\n%s\n", sourceLineComment, sourceLineFor(shellType, rcPath))
 
-	// Write source line with comment
-	_, err = f.WriteString(fmt.Sprintf("%s\n%s\n", sourceLineComment, sourceLine))
-	if err != nil {
-		return fmt.Errorf("failed to write source line: %w", err)
+	if err := os.MkdirAll(filepath.Dir(rcPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create RC directory: %w", err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(rcPath); err == nil {
+		mode = info.Mode().Perm()
 	}
+	if err := util.WriteFileAtomic(rcPath, []byte(out.String()), mode); err != nil {
+		return false, fmt.Errorf("failed to write source line: %w", err)
+	}
+	return true, nil
+}
 
-	return nil
+// sourceLineFor returns the line to inject into rcPath. ~/.profile is also
+// read by POSIX sh, so there the bash script is only sourced under bash.
+func sourceLineFor(shellType ShellType, rcPath string) string {
+	if filepath.Base(rcPath) == ".profile" && shellType == Bash {
+		path := "~/.gdf/generated/" + shellType.InitFileName()
+		return fmt.Sprintf(`[ -n "${BASH_VERSION-}" ] && [ -f %s ] && . %s`, path, path)
+	}
+	return SourceLine(shellType)
 }
 
-// getRCPath returns the RC file path for the given shell type.
-func (i *Injector) getRCPath(shellType ShellType) string {
+// RCPath returns the default RC file path for the given shell type.
+func (i *Injector) RCPath(shellType ShellType) string {
 	home := os.Getenv("HOME")
 	if home == "" {
 		return ""
@@ -89,16 +130,51 @@ func (i *Injector) getRCPath(shellType ShellType) string {
 		// Fall back to .bash_profile
 		return filepath.Join(home, ".bash_profile")
 	case Zsh:
-		return filepath.Join(home, ".zshrc")
+		return filepath.Join(zshDotDir(home), ".zshrc")
+	case Fish:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "fish", "config.fish")
 	default:
 		return ""
 	}
 }
 
-// hasSourceLine checks if the RC file already contains the GDF source line.
-func (i *Injector) hasSourceLine(rcPath string) (bool, error) {
+// RCPaths returns the RC files to inject into for shellType: the configured
+// paths (with ~ and $VARS expanded) or, when none are configured, RCPath.
+func (i *Injector) RCPaths(shellType ShellType, configured []string) []string {
+	if len(configured) == 0 {
+		if rcPath := i.RCPath(shellType); rcPath != "" {
+			return []string{rcPath}
+		}
+		return nil
+	}
+	paths := make([]string, 0, len(configured))
+	for _, p := range configured {
+		paths = append(paths, platform.ExpandPath(p))
+	}
+	return paths
+}
+
+// zshDotDir returns $ZDOTDIR, where zsh reads its startup files, or home.
+func zshDotDir(home string) string {
+	if dir := os.Getenv("ZDOTDIR"); dir != "" {
+		return dir
+	}
+	return home
+}
+
+// HasSourceLine reports whether rcPath already sources a GDF init script.
+// A line sourcing init.sh counts, since init.sh dispatches to init.<shell>.
+// A missing file has no source line.
+func HasSourceLine(rcPath string) (bool, error) {
 	f, err := os.Open(rcPath)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
 		return false, err
 	}
 	defer f.Close()
@@ -106,7 +182,10 @@ func (i *Injector) hasSourceLine(rcPath string) (bool, error) {
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if strings.Contains(line, "~/.gdf/generated/init.sh") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.Contains(line, sourceIdentifier) {
 			return true, nil
 		}
 	}
@@ -114,28 +193,38 @@ func (i *Injector) hasSourceLine(rcPath string) (bool, error) {
 	return false, scanner.Err()
 }
 
-// copyFile copies a file from src to dst.
-func copyFile(src, dst string) error {
-	data, err := os.ReadFile(src)
-	if err != nil {
-		return err
+// RestoreSourceLine replaces the GDF source line in each of rcPaths with a
+// line sourcing the alias file. When none of them has a GDF source line, the
+// alias line is appended to the first one that exists.
+func (i *Injector) RestoreSourceLine(aliasPath string, rcPaths []string) error {
+	if len(rcPaths) == 0 {
+		return fmt.Errorf("no RC files to update")
 	}
-	return os.WriteFile(dst, data, 0644)
-}
-
-// RestoreSourceLine replaces the GDF source line with a line sourcing the alias file.
-func (i *Injector) RestoreSourceLine(aliasPath string, shellType ShellType) error {
-	rcPath := i.getRCPath(shellType)
-	if rcPath == "" {
-		return fmt.Errorf("cannot determine RC file for shell type: %s", shellType)
+	restored := false
+	for _, rcPath := range rcPaths {
+		found, err := restoreSourceLineIn(rcPath, aliasPath, false)
+		if err != nil {
+			return err
+		}
+		restored = restored || found
+	}
+	if restored {
+		return nil
 	}
+	_, err := restoreSourceLineIn(rcPaths[0], aliasPath, true)
+	return err
+}
 
+// restoreSourceLineIn replaces the GDF source line in rcPath and reports
+// whether there was one. Without one, the alias line is appended when
+// appendMissing is set. A missing rcPath is left alone.
+func restoreSourceLineIn(rcPath, aliasPath string, appendMissing bool) (bool, error) {
 	content, err := os.ReadFile(rcPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil // No RC file, nothing to do
+			return false, nil // No RC file, nothing to do
 		}
-		return err
+		return false, err
 	}
 
 	lines := strings.Split(string(content), "\n")
@@ -143,7 +232,7 @@ func (i *Injector) RestoreSourceLine(aliasPath string, shellType ShellType) erro
 	found := false
 
 	// Define what we are looking for
-	oldIdentifier := ".gdf/generated/init.sh"
+	oldIdentifier := sourceIdentifier
 	newSourceLine := fmt.Sprintf("[ -f %s ] && source %s", aliasPath, aliasPath)
 
 	skipNext := false
@@ -166,8 +255,9 @@ func (i *Injector) RestoreSourceLine(aliasPath string, shellType ShellType) erro
 			}
 		}
 
-		// Case 2: Source line without comment (or we missed the comment)
-		if strings.Contains(trimmed, oldIdentifier) && strings.Contains(trimmed, "source") {
+		// Case 2: Source line without comment (or we missed the comment),
+		// including the `. file` form written to ~/.profile
+		if strings.Contains(trimmed, oldIdentifier) && !strings.HasPrefix(trimmed, "#") {
 			if !found {
 				newLines = append(newLines, "# Added by gdf recover restore")
 				newLines = append(newLines, newSourceLine)
@@ -180,6 +270,9 @@ func (i *Injector) RestoreSourceLine(aliasPath string, shellType ShellType) erro
 	}
 
 	if !found {
+		if !appendMissing {
+			return false, nil
+		}
 		// If GDF integration wasn't found, append the new alias loading
 		newLines = append(newLines, "")
 		newLines = append(newLines, "# Added by gdf recover restore")
@@ -188,7 +281,7 @@ func (i *Injector) RestoreSourceLine(aliasPath string, shellType ShellType) erro
 
 	output := strings.Join(newLines, "\n")
 	if err := os.WriteFile(rcPath, []byte(output), 0644); err != nil {
-		return fmt.Errorf("writing RC file: %w", err)
+		return false, fmt.Errorf("writing RC file: %w", err)
 	}
-	return nil
+	return found, nil
 }
@@ -22,7 +22,7 @@ func TestInjector_RestoreSourceLine(t *testing.T) {
 	}
 
 	injector := NewInjector()
-	err := injector.RestoreSourceLine("~/.aliases", Bash)
+	err := injector.RestoreSourceLine("~/.aliases", []string{rcPath})
 	if err != nil {
 		t.Fatalf("RestoreSourceLine() error = %v", err)
 	}
@@ -40,3 +40,34 @@ func TestInjector_RestoreSourceLine(t *testing.T) {
 		t.Errorf("New source line missing. Got:\n%s", newStr)
 	}
 }
+
+func TestInjector_RestoreSourceLineAllRCFiles(t *testing.T) {
+	home := t.TempDir()
+	bashrc := filepath.Join(home, ".bashrc")
+	profile := filepath.Join(home, ".profile")
+	other := filepath.Join(home, ".bash_aliases")
+	files := map[string]string{
+		bashrc:  sourceLineComment + "\n" + SourceLine(Bash) + "\n",
+		profile: "export EDITOR=vim\n" + sourceLineComment + "\n" + sourceLineFor(Bash, profile) + "\n",
+		other:   "alias ll='ls -l'\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := NewInjector().RestoreSourceLine("~/.aliases", []string{bashrc, profile, other}); err != nil {
+		t.Fatalf("RestoreSourceLine() error = %v", err)
+	}
+	for _, path := range []string{bashrc, profile} {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), sourceIdentifier) || !strings.Contains(string(data), "[ -f ~/.aliases ] && source ~/.aliases") {
+			t.Errorf("%s not restored:\n%s", filepath.Base(path), data)
+		}
+	}
+	// Files without a GDF line are left alone when another file had one.
+	if data, _ := os.ReadFile(other); string(data) != files[other] {
+		t.Errorf(".bash_aliases changed:\n%s", data)
+	}
+}
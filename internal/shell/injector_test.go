@@ -13,31 +13,39 @@ func TestInjector_InjectSourceLine(t *testing.T) {
 		shellType    ShellType
 		existingRC   string
 		wantContains string
-		wantBackup   bool
+		wantSnapshot bool
 		wantErr      bool
 	}{
 		{
 			name:         "inject into new bashrc",
 			shellType:    Bash,
 			existingRC:   "",
-			wantContains: "~/.gdf/generated/init.sh",
-			wantBackup:   false,
+			wantContains: "[ -f ~/.gdf/generated/init.bash ] && source ~/.gdf/generated/init.bash",
+			wantSnapshot: false,
 			wantErr:      false,
 		},
 		{
 			name:         "inject into existing bashrc",
 			shellType:    Bash,
 			existingRC:   "export PATH=$PATH:~/bin\n",
-			wantContains: "~/.gdf/generated/init.sh",
-			wantBackup:   true,
+			wantContains: "~/.gdf/generated/init.bash",
+			wantSnapshot: true,
 			wantErr:      false,
 		},
 		{
 			name:         "inject into zshrc",
 			shellType:    Zsh,
 			existingRC:   "",
-			wantContains: "~/.gdf/generated/init.sh",
-			wantBackup:   false,
+			wantContains: "~/.gdf/generated/init.zsh",
+			wantSnapshot: false,
+			wantErr:      false,
+		},
+		{
+			name:         "inject into fish config",
+			shellType:    Fish,
+			existingRC:   "",
+			wantContains: "test -f ~/.gdf/generated/init.fish; and source ~/.gdf/generated/init.fish",
+			wantSnapshot: false,
 			wantErr:      false,
 		},
 		{
@@ -54,6 +62,7 @@ func TestInjector_InjectSourceLine(t *testing.T) {
 			originalHome := os.Getenv("HOME")
 			defer os.Setenv("HOME", originalHome)
 			os.Setenv("HOME", tmpHome)
+			t.Setenv("XDG_CONFIG_HOME", "")
 
 			// Create existing RC file if specified
 			var rcPath string
@@ -72,10 +81,20 @@ func TestInjector_InjectSourceLine(t *testing.T) {
 			}
 
 			injector := NewInjector()
-			err := injector.InjectSourceLine(tt.shellType)
+			var snapshot []byte
+			snapshotted := false
+			injector.SetSnapshotFunc(func(path string) error {
+				data, err := os.ReadFile(path)
+				if err == nil {
+					snapshot, snapshotted = data, true
+				}
+				return nil
+			})
+			rcPath = injector.RCPath(tt.shellType)
+			_, err := injector.InjectInto(tt.shellType, rcPath)
 
 			if (err != nil) != tt.wantErr {
-				t.Errorf("InjectSourceLine() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("InjectInto() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
@@ -83,12 +102,6 @@ func TestInjector_InjectSourceLine(t *testing.T) {
 				return
 			}
 
-			// Determine RC path
-			rcPath = injector.getRCPath(tt.shellType)
-			if rcPath == "" {
-				t.Fatal("getRCPath returned empty string")
-			}
-
 			// Check RC file contains source line
 			content, err := os.ReadFile(rcPath)
 			if err != nil {
@@ -99,13 +112,16 @@ func TestInjector_InjectSourceLine(t *testing.T) {
 				t.Errorf("RC file missing expected content %q. Got:\n%s", tt.wantContains, string(content))
 			}
 
-			// Check backup was created if expected
-			backupPath := rcPath + ".gdf.backup"
-			_, backupErr := os.Stat(backupPath)
-			backupExists := backupErr == nil
-
-			if backupExists != tt.wantBackup {
-				t.Errorf("Backup file exists = %v, want %v", backupExists, tt.wantBackup)
+			// The original is handed to the snapshot function, not kept as
+			// a .gdf.backup file.
+			if snapshotted != tt.wantSnapshot {
+				t.Errorf("snapshot taken = %v, want %v", snapshotted, tt.wantSnapshot)
+			}
+			if snapshotted && string(snapshot) != tt.existingRC {
+				t.Errorf("snapshot content = %q, want %q", snapshot, tt.existingRC)
+			}
+			if _, err := os.Stat(rcPath + ".gdf.backup"); err == nil {
+				t.Error("unexpected .gdf.backup file")
 			}
 		})
 	}
@@ -118,7 +134,7 @@ func TestInjector_DuplicateInjection(t *testing.T) {
 	defer os.Setenv("HOME", originalHome)
 	os.Setenv("HOME", tmpHome)
 
-	// Create .bashrc so getRCPath returns .bashrc (not .bash_profile fallback)
+	// Create .bashrc so RCPath returns .bashrc (not .bash_profile fallback)
 	rcPath := filepath.Join(tmpHome, ".bashrc")
 	os.WriteFile(rcPath, []byte("# existing content\n"), 0644)
 
@@ -149,17 +165,37 @@ func TestInjector_DuplicateInjection(t *testing.T) {
 	t.Logf("File content after second injection:\n%s", secondContent)
 
 	// Count occurrences of the actual source line (not just the path which appears in comment too)
-	count := strings.Count(string(secondContent), "[ -f ~/.gdf/generated/init.sh ] && source")
+	count := strings.Count(string(secondContent), "[ -f ~/.gdf/generated/init.bash ] && source")
 	if count != 1 {
 		t.Errorf("Source line appears %d times, want 1.\nFull content:\n%s", count, secondContent)
 	}
 }
 
+func TestInjector_KeepsLegacyInitShLine(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	rcPath := filepath.Join(tmpHome, ".zshrc")
+	legacy := "[ -f ~/.gdf/generated/init.sh ] && source ~/.gdf/generated/init.sh\n"
+	if err := os.WriteFile(rcPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewInjector().InjectSourceLine(Zsh); err != nil {
+		t.Fatalf("InjectSourceLine() error = %v", err)
+	}
+	content, _ := os.ReadFile(rcPath)
+	if string(content) != legacy {
+		t.Errorf("rc file changed; init.sh dispatches to init.zsh so no new line is needed:\n%s", content)
+	}
+}
+
 func TestInjector_GetRCPath(t *testing.T) {
 	tmpHome := t.TempDir()
 	originalHome := os.Getenv("HOME")
 	defer os.Setenv("HOME", originalHome)
 	os.Setenv("HOME", tmpHome)
+	xdgConfig := filepath.Join(tmpHome, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdgConfig)
 
 	injector := NewInjector()
 
@@ -178,6 +214,11 @@ func TestInjector_GetRCPath(t *testing.T) {
 			shellType: Zsh,
 			want:      filepath.Join(tmpHome, ".zshrc"),
 		},
+		{
+			name:      "fish respects XDG_CONFIG_HOME",
+			shellType: Fish,
+			want:      filepath.Join(xdgConfig, "fish", "config.fish"),
+		},
 		{
 			name:      "unknown",
 			shellType: Unknown,
@@ -187,15 +228,51 @@ func TestInjector_GetRCPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := injector.getRCPath(tt.shellType)
+			got := injector.RCPath(tt.shellType)
 			// For bash, create .bashrc to ensure it's preferred
 			if tt.shellType == Bash {
 				os.WriteFile(filepath.Join(tmpHome, ".bashrc"), []byte{}, 0644)
 			}
-			got = injector.getRCPath(tt.shellType)
+			got = injector.RCPath(tt.shellType)
 			if got != tt.want {
-				t.Errorf("getRCPath() = %v, want %v", got, tt.want)
+				t.Errorf("RCPath() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestInjector_ProfileLineIsGuarded(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	rcPath := filepath.Join(tmpHome, ".profile")
+	if _, err := NewInjector().InjectInto(Bash, rcPath); err != nil {
+		t.Fatalf("InjectInto() error = %v", err)
+	}
+	content, _ := os.ReadFile(rcPath)
+	want := `[ -n "${BASH_VERSION-}" ] && [ -f ~/.gdf/generated/init.bash ] && . ~/.gdf/generated/init.bash`
+	if !strings.Contains(string(content), want) {
+		t.Errorf(".profile missing guarded source line %q:\n%s", want, content)
+	}
+}
+
+func TestInjector_RCPathsAndZDOTDIR(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	zdot := filepath.Join(tmpHome, "zsh")
+	t.Setenv("ZDOTDIR", zdot)
+
+	injector := NewInjector()
+	if got, want := injector.RCPath(Zsh), filepath.Join(zdot, ".zshrc"); got != want {
+		t.Errorf("RCPath(Zsh) = %s, want %s", got, want)
+	}
+
+	got := injector.RCPaths(Bash, []string{"~/.bashrc", "$HOME/.bash_profile"})
+	want := []string{filepath.Join(tmpHome, ".bashrc"), filepath.Join(tmpHome, ".bash_profile")}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("RCPaths() = %v, want %v", got, want)
+	}
+	if got := injector.RCPaths(Zsh, nil); len(got) != 1 || got[0] != filepath.Join(zdot, ".zshrc") {
+		t.Errorf("RCPaths(Zsh, nil) = %v, want default rc", got)
+	}
+}
@@ -0,0 +1,233 @@
+package shell
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// Login environment targets accepted by shell_integration.login_env.
+const (
+	// LoginEnvProfile is a POSIX env file for ~/.profile to source.
+	LoginEnvProfile = "profile"
+	// LoginEnvSystemd is a systemd user environment.d drop-in (Linux).
+	LoginEnvSystemd = "systemd"
+	// LoginEnvLaunchd is a launchd agent that runs `launchctl setenv` (macOS).
+	LoginEnvLaunchd = "launchd"
+)
+
+// LoginEnvLabel is the launchd label of the environment agent.
+const LoginEnvLabel = "io.github.rztaylor.gdf.environment"
+
+// loginEnvMarker identifies files GDF generated, so stale ones can be removed.
+const loginEnvMarker = "Generated by gdf"
+
+// LoginEnvTargets returns every supported login environment target.
+func LoginEnvTargets() []string {
+	return []string{LoginEnvProfile, LoginEnvSystemd, LoginEnvLaunchd}
+}
+
+// LoginEnvSupported reports whether target can be used on plat.
+func LoginEnvSupported(target string, plat *platform.Platform) bool {
+	switch target {
+	case LoginEnvProfile:
+		return true
+	case LoginEnvSystemd:
+		return plat.OS == "linux" || plat.OS == "wsl"
+	case LoginEnvLaunchd:
+		return plat.OS == "macos"
+	default:
+		return false
+	}
+}
+
+// LoginEnvPath returns the file written for target. The profile file lives
+// in gdfDir/generated; the others live where the service manager reads them.
+func LoginEnvPath(target, gdfDir string) string {
+	home := os.Getenv("HOME")
+	switch target {
+	case LoginEnvProfile:
+		return filepath.Join(gdfDir, "generated", "env.sh")
+	case LoginEnvSystemd:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "environment.d", "60-gdf.conf")
+	case LoginEnvLaunchd:
+		return filepath.Join(home, "Library", "LaunchAgents", LoginEnvLabel+".plist")
+	default:
+		return ""
+	}
+}
+
+// IsGeneratedLoginEnv reports whether data is a file GDF generated.
+func IsGeneratedLoginEnv(data []byte) bool {
+	return bytes.Contains(data, []byte(loginEnvMarker))
+}
+
+// GenerateLoginEnv renders the login environment file for target from the
+// env vars and PATH entries of bundles. Values use the shell-neutral value of
+// each variable, as there is no interactive shell to pick a variant for.
+// warnings name the variables and PATH entries the target cannot express.
+func (g *Generator) GenerateLoginEnv(target string, bundles []*apps.Bundle, plat *platform.Platform) (content []byte, warnings []string, err error) {
+	if err := ValidateNames(bundles, nil, Bash); err != nil {
+		return nil, nil, err
+	}
+	pathDirs, err := ResolvePath(bundles, plat)
+	if err != nil {
+		return nil, nil, err
+	}
+	envExports, err := ResolveEnv(bundles, Unknown, plat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch target {
+	case LoginEnvProfile:
+		return []byte(g.loginEnvScript(pathDirs, envExports)), nil, nil
+	case LoginEnvSystemd:
+		out, warnings := systemdEnvironment(pathDirs, envExports)
+		return []byte(out), warnings, nil
+	case LoginEnvLaunchd:
+		content, err := launchdPlist(g.launchdScript(pathDirs, envExports))
+		return content, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown login environment target %q (expected %s)", target, strings.Join(LoginEnvTargets(), ", "))
+	}
+}
+
+// loginEnvScript renders a POSIX sh script that sets PATH and env vars.
+func (g *Generator) loginEnvScript(pathDirs []PathDir, envExports []EnvExport) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# %s - DO NOT EDIT MANUALLY\n", loginEnvMarker)
+	out.WriteString("# Login environment for POSIX shells. Source it from ~/.profile:\n")
+	out.WriteString("#   [ -f ~/.gdf/generated/env.sh ] && . ~/.gdf/generated/env.sh\n")
+	if env := g.generateEnvVars(envExports, Bash); env != "" {
+		out.WriteString("\n# Environment variables\n")
+		out.WriteString(env)
+	}
+	if path := g.generatePath(pathDirs, Bash); path != "" {
+		out.WriteString("\n# PATH\n")
+		out.WriteString(path)
+	}
+	return out.String()
+}
+
+// launchdScript is loginEnvScript followed by a `launchctl setenv` for each
+// variable, so GUI apps launched by launchd see the expanded values.
+func (g *Generator) launchdScript(pathDirs []PathDir, envExports []EnvExport) string {
+	var out strings.Builder
+	out.WriteString(g.loginEnvScript(pathDirs, envExports))
+	out.WriteString("\n")
+	if len(pathDirs) > 0 {
+		out.WriteString("launchctl setenv PATH \"$PATH\"\n")
+	}
+	for _, env := range envExports {
+		fmt.Fprintf(&out, "launchctl setenv %s \"$%s\"\n", env.Name, env.Name)
+	}
+	return out.String()
+}
+
+// systemdEnvironment renders an environment.d drop-in. systemd expands $VAR
+// and ${VAR} but runs no commands, so PATH entries are joined at generation
+// time: directories that must exist are checked now, not at login. Env vars
+// are written first so PATH entries can reference them. Variables whose value
+// needs command substitution, and anything referencing them, are left out
+// and reported as warnings rather than written as literal text.
+func systemdEnvironment(pathDirs []PathDir, envExports []EnvExport) (string, []string) {
+	var out strings.Builder
+	var warnings []string
+	fmt.Fprintf(&out, "# %s - DO NOT EDIT MANUALLY\n", loginEnvMarker)
+	out.WriteString("# systemd user environment (environment.d). Applies to new login sessions.\n")
+
+	skipped := make(map[string]bool)
+	for _, env := range envExports {
+		if env.Expand && (runsCommand(env.Value) || referencesAny(env.Value, skipped)) {
+			skipped[env.Name] = true
+			warnings = append(warnings, fmt.Sprintf("skipped %s (%s): environment.d cannot run the command in its value", env.Name, env.App))
+			continue
+		}
+		fmt.Fprintf(&out, "%s=%s\n", env.Name, systemdQuote(env.Value, env.Expand))
+	}
+
+	var prepend, appendDirs []string
+	for _, d := range pathDirs {
+		if runsCommand(d.Dir) || referencesAny(d.Dir, skipped) {
+			warnings = append(warnings, fmt.Sprintf("skipped PATH entry %s (%s): environment.d cannot run the command it depends on", d.Dir, d.App))
+			continue
+		}
+		if d.IfExists {
+			dir, ok := ExpandPathDir(d.Dir, envExports)
+			if !ok {
+				continue
+			}
+			if _, err := os.Stat(dir); err != nil {
+				continue
+			}
+		}
+		if d.Position == apps.PathAppend {
+			appendDirs = append(appendDirs, d.Dir)
+		} else {
+			prepend = append(prepend, d.Dir)
+		}
+	}
+	if len(prepend)+len(appendDirs) > 0 {
+		parts := append(append(prepend, "${PATH}"), appendDirs...)
+		fmt.Fprintf(&out, "PATH=%s\n", systemdQuote(strings.Join(parts, ":"), true))
+	}
+	return out.String(), warnings
+}
+
+// referencesAny reports whether value references one of names as $VAR or
+// ${VAR}.
+func referencesAny(value string, names map[string]bool) bool {
+	found := false
+	os.Expand(value, func(name string) string {
+		found = found || names[name]
+		return ""
+	})
+	return found
+}
+
+// systemdQuote double-quotes an environment.d value. Literal values also
+// escape $ so systemd does not expand them.
+func systemdQuote(s string, expand bool) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	if !expand {
+		r = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	}
+	return `"` + r.Replace(s) + `"`
+}
+
+// launchdPlist wraps script in a launchd agent that runs it once at login.
+func launchdPlist(script string) ([]byte, error) {
+	var escaped bytes.Buffer
+	if err := xml.EscapeText(&escaped, []byte(script)); err != nil {
+		return nil, fmt.Errorf("encoding launchd script: %w", err)
+	}
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- %s - DO NOT EDIT MANUALLY -->
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>%s</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>/bin/sh</string>
+    <string>-c</string>
+    <string>%s</string>
+  </array>
+  <key>RunAtLoad</key>
+  <true/>
+</dict>
+</plist>
+`, loginEnvMarker, LoginEnvLabel, escaped.String())), nil
+}
@@ -0,0 +1,187 @@
+package shell
+
+import (
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+func loginEnvBundles() []*apps.Bundle {
+	off := false
+	return []*apps.Bundle{
+		{
+			Name: "go",
+			Shell: &apps.Shell{
+				Path: []apps.PathEntry{{Dir: "/opt/go/bin", IfExists: &off}, {Dir: "/usr/local/extra", Position: apps.PathAppend, IfExists: &off}},
+				Env: map[string]apps.EnvVar{
+					"GOPATH":  {Value: "$HOME/go", Zsh: "$HOME/zsh-go"},
+					"GOBIN":   {Value: "$GOPATH/bin"},
+					"PROMPT":  {Value: `$ "<x>"`, Expand: &off},
+					"EDITOR":  {Value: "vim", When: "os == 'macos'"},
+					"PAGER":   {Value: "less"},
+					"GDF_ALT": {Values: []apps.ConditionalValue{{Value: "linux-only", When: "os == 'linux'"}}},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateLoginEnv_Profile(t *testing.T) {
+	g := NewGenerator()
+	data, _, err := g.GenerateLoginEnv(LoginEnvProfile, loginEnvBundles(), &platform.Platform{OS: "linux"})
+	if err != nil {
+		t.Fatalf("GenerateLoginEnv() error = %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"# Generated by gdf",
+		`__gdf_path_add prepend "/opt/go/bin" always`,
+		`export GOPATH="$HOME/go"`,
+		`export GOBIN="$GOPATH/bin"`,
+		`export PROMPT='$ "<x>"'`,
+		`export GDF_ALT="linux-only"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("profile env missing %q\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "EDITOR") || strings.Contains(content, "zsh-go") {
+		t.Errorf("profile env has values that do not apply to a login shell on linux:\n%s", content)
+	}
+	if strings.Index(content, "export GOPATH=") > strings.Index(content, "export GOBIN=") {
+		t.Errorf("GOPATH must be exported before GOBIN:\n%s", content)
+	}
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		return
+	}
+	path := filepath.Join(t.TempDir(), "env.sh")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", `. "$1" && printf '%s|%s' "$GOBIN" "$PROMPT"`, "sh", path)
+	cmd.Env = []string{"HOME=/home/me", "PATH=/usr/bin:/bin"}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sourcing env.sh: %v", err)
+	}
+	if want := `/home/me/go/bin|$ "<x>"`; string(out) != want {
+		t.Errorf("sourced values = %q, want %q", out, want)
+	}
+}
+
+func TestGenerateLoginEnv_Systemd(t *testing.T) {
+	g := NewGenerator()
+	data, _, err := g.GenerateLoginEnv(LoginEnvSystemd, loginEnvBundles(), &platform.Platform{OS: "linux"})
+	if err != nil {
+		t.Fatalf("GenerateLoginEnv() error = %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		`PATH="/opt/go/bin:${PATH}:/usr/local/extra"`,
+		`GOPATH="$HOME/go"`,
+		`PROMPT="\$ \"<x>\""`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("environment.d missing %q\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "export ") {
+		t.Errorf("environment.d must not contain shell syntax:\n%s", content)
+	}
+}
+
+func TestGenerateLoginEnv_SystemdSkipsCommandSubstitution(t *testing.T) {
+	off := false
+	bundles := []*apps.Bundle{{
+		Name: "brew",
+		Shell: &apps.Shell{
+			Path: []apps.PathEntry{{Dir: "$BREW_PREFIX/bin", IfExists: &off}, {Dir: "/opt/tools", IfExists: &off}},
+			Env: map[string]apps.EnvVar{
+				"BREW_PREFIX": {Value: "$(brew --prefix)"},
+				"BREW_SBIN":   {Value: "$BREW_PREFIX/sbin"},
+				"HOST":        {Value: "`hostname`"},
+				"LITERAL":     {Value: "$(not run)", Expand: &off},
+				"PAGER":       {Value: "less"},
+			},
+		},
+	}}
+	data, warnings, err := NewGenerator().GenerateLoginEnv(LoginEnvSystemd, bundles, &platform.Platform{OS: "linux"})
+	if err != nil {
+		t.Fatalf("GenerateLoginEnv() error = %v", err)
+	}
+	content := string(data)
+	for _, unwanted := range []string{"BREW_PREFIX=", "BREW_SBIN=", "HOST=", "$BREW_PREFIX/bin"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("environment.d contains %q, which needs a shell\n%s", unwanted, content)
+		}
+	}
+	for _, want := range []string{`PAGER="less"`, `LITERAL="\$(not run)"`, `PATH="/opt/tools:${PATH}"`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("environment.d missing %q\n%s", want, content)
+		}
+	}
+	if len(warnings) != 4 {
+		t.Errorf("warnings = %q, want BREW_PREFIX, BREW_SBIN, HOST and the PATH entry", warnings)
+	}
+}
+
+func TestGenerateLoginEnv_Launchd(t *testing.T) {
+	g := NewGenerator()
+	data, _, err := g.GenerateLoginEnv(LoginEnvLaunchd, loginEnvBundles(), &platform.Platform{OS: "macos"})
+	if err != nil {
+		t.Fatalf("GenerateLoginEnv() error = %v", err)
+	}
+	var plist struct {
+		Strings []string `xml:"dict>array>string"`
+	}
+	if err := xml.Unmarshal(data, &plist); err != nil {
+		t.Fatalf("plist is not valid XML: %v\n%s", err, data)
+	}
+	if len(plist.Strings) != 3 || plist.Strings[0] != "/bin/sh" {
+		t.Fatalf("ProgramArguments = %q", plist.Strings)
+	}
+	script := plist.Strings[2]
+	for _, want := range []string{
+		`export EDITOR="vim"`,
+		`export PROMPT='$ "<x>"'`,
+		`launchctl setenv PATH "$PATH"`,
+		`launchctl setenv GOBIN "$GOBIN"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("launchd script missing %q\n%s", want, script)
+		}
+	}
+}
+
+func TestGenerateLoginEnv_UnknownTarget(t *testing.T) {
+	if _, _, err := NewGenerator().GenerateLoginEnv("cron", nil, &platform.Platform{OS: "linux"}); err == nil {
+		t.Fatal("GenerateLoginEnv() error = nil, want unknown target error")
+	}
+}
+
+func TestLoginEnvSupported(t *testing.T) {
+	tests := []struct {
+		target string
+		os     string
+		want   bool
+	}{
+		{target: LoginEnvProfile, os: "macos", want: true},
+		{target: LoginEnvSystemd, os: "linux", want: true},
+		{target: LoginEnvSystemd, os: "macos", want: false},
+		{target: LoginEnvLaunchd, os: "macos", want: true},
+		{target: LoginEnvLaunchd, os: "wsl", want: false},
+		{target: "cron", os: "linux", want: false},
+	}
+	for _, tt := range tests {
+		if got := LoginEnvSupported(tt.target, &platform.Platform{OS: tt.os}); got != tt.want {
+			t.Errorf("LoginEnvSupported(%q, %s) = %v, want %v", tt.target, tt.os, got, tt.want)
+		}
+	}
+}
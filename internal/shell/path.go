@@ -0,0 +1,206 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/config"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// PathDir is a PATH entry resolved for the current platform.
+type PathDir struct {
+	App      string
+	Dir      string
+	Position string
+	IfExists bool
+}
+
+// ResolvePath merges shell.path entries from bundles in bundle order. Entries
+// whose condition is false are dropped, and a directory listed more than once
+// keeps its first occurrence.
+func ResolvePath(bundles []*apps.Bundle, plat *platform.Platform) ([]PathDir, error) {
+	var out []PathDir
+	seen := make(map[string]bool)
+	for _, bundle := range bundles {
+		if bundle.Shell == nil {
+			continue
+		}
+		for i, entry := range bundle.Shell.Path {
+			if entry.When != "" {
+				ok, err := config.EvaluateCondition(entry.When, plat)
+				if err != nil {
+					return nil, fmt.Errorf("app %s: shell.path[%d]: %w", bundle.Name, i, err)
+				}
+				if !ok {
+					continue
+				}
+			}
+			dir := normalizePathDir(entry.Dir)
+			if dir == "" || seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			out = append(out, PathDir{
+				App:      bundle.Name,
+				Dir:      dir,
+				Position: entry.PositionDefault(),
+				IfExists: entry.IfExistsDefault(),
+			})
+		}
+	}
+	return out, nil
+}
+
+// normalizePathDir rewrites a leading ~ to $HOME, which still expands inside
+// double quotes, and drops trailing slashes so equal directories dedupe.
+func normalizePathDir(dir string) string {
+	dir = strings.TrimSpace(dir)
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		dir = "$HOME" + strings.TrimPrefix(dir, "~")
+	}
+	if len(dir) > 1 {
+		dir = strings.TrimRight(dir, "/")
+	}
+	return dir
+}
+
+// ExpandPathDir expands a resolved PATH directory as the generated script
+// would: $VAR references resolve to the exported env vars first, then to the
+// current environment. ok is false when a referenced variable is not set or
+// a value runs a command, so the directory cannot be known in advance.
+func ExpandPathDir(dir string, envExports []EnvExport) (string, bool) {
+	ok := true
+	values := make(map[string]string)
+	lookup := func(name string) string {
+		if v, found := values[name]; found {
+			return v
+		}
+		v, found := os.LookupEnv(name)
+		if !found {
+			ok = false
+		}
+		return v
+	}
+	for _, env := range envExports {
+		value := env.Value
+		if env.Expand {
+			if runsCommand(value) {
+				// Only mark it unknown when a PATH entry uses it.
+				values[env.Name] = "$(" + env.Name + ")"
+				continue
+			}
+			value = os.Expand(value, lookup)
+		}
+		values[env.Name] = value
+	}
+	ok = true
+
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		dir = "$HOME" + strings.TrimPrefix(dir, "~")
+	}
+	expanded := os.Expand(dir, lookup)
+	if runsCommand(expanded) {
+		return expanded, false
+	}
+	return filepath.Clean(expanded), ok
+}
+
+// runsCommand reports whether an expanded value contains command
+// substitution, which only a shell can evaluate.
+func runsCommand(value string) bool {
+	return strings.Contains(value, "$(") || strings.Contains(value, "`")
+}
+
+// generatePath emits PATH updates. Prepends are written in reverse so the
+// first resolved entry ends up first in PATH. A prepended directory that is
+// already in PATH moves to the front, so it takes precedence over system
+// directories; an appended one is left where it is. Both keep re-sourcing
+// the init script idempotent.
+func (g *Generator) generatePath(dirs []PathDir, shellType ShellType) string {
+	if len(dirs) == 0 {
+		return ""
+	}
+	if shellType == Fish {
+		return generateFishPath(dirs)
+	}
+
+	var out strings.Builder
+	out.WriteString(`__gdf_path_add() {
+  if [ "$3" = check ] && [ ! -d "$2" ]; then return 0; fi
+  case ":${PATH}:" in
+    *":$2:"*)
+      if [ "$1" = append ]; then return 0; fi
+      case "${PATH}:" in "$2:"*) return 0 ;; esac
+      __gdf_p=":${PATH}:"
+      while :; do
+        case "$__gdf_p" in
+          *":$2:"*) __gdf_p="${__gdf_p%%":$2:"*}:${__gdf_p#*":$2:"}" ;;
+          *) break ;;
+        esac
+      done
+      __gdf_p="${__gdf_p#:}"
+      __gdf_p="${__gdf_p%:}"
+      PATH="$2${__gdf_p:+:$__gdf_p}"
+      unset __gdf_p
+      return 0 ;;
+  esac
+  if [ "$1" = prepend ]; then PATH="$2${PATH:+:$PATH}"; else PATH="${PATH:+$PATH:}$2"; fi
+}
+`)
+	write := func(d PathDir) {
+		check := "always"
+		if d.IfExists {
+			check = "check"
+		}
+		fmt.Fprintf(&out, "__gdf_path_add %s %s %s\n", d.Position, QuoteExpandable(shellType, d.Dir), check)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i].Position == apps.PathPrepend {
+			write(dirs[i])
+		}
+	}
+	for _, d := range dirs {
+		if d.Position == apps.PathAppend {
+			write(d)
+		}
+	}
+	out.WriteString("unset -f __gdf_path_add\nexport PATH\n")
+	return out.String()
+}
+
+// generateFishPath emits the fish equivalent of generatePath using
+// `contains` and `set -gx PATH`.
+func generateFishPath(dirs []PathDir) string {
+	var out strings.Builder
+	write := func(d PathDir) {
+		dir := QuoteExpandable(Fish, d.Dir)
+		if d.Position == apps.PathAppend {
+			cond := fmt.Sprintf("not contains -- %s $PATH", dir)
+			if d.IfExists {
+				cond = fmt.Sprintf("test -d %s; and %s", dir, cond)
+			}
+			fmt.Fprintf(&out, "if %s\n  set -gx PATH $PATH %s\nend\n", cond, dir)
+			return
+		}
+		if d.IfExists {
+			fmt.Fprintf(&out, "if test -d %[1]s\n  while contains -- %[1]s $PATH\n    set -e PATH[(contains -i -- %[1]s $PATH)]\n  end\n  set -gx PATH %[1]s $PATH\nend\n", dir)
+			return
+		}
+		fmt.Fprintf(&out, "while contains -- %[1]s $PATH\n  set -e PATH[(contains -i -- %[1]s $PATH)]\nend\nset -gx PATH %[1]s $PATH\n", dir)
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i].Position == apps.PathPrepend {
+			write(dirs[i])
+		}
+	}
+	for _, d := range dirs {
+		if d.Position == apps.PathAppend {
+			write(d)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,195 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+func TestResolvePath(t *testing.T) {
+	noCheck := false
+	bundles := []*apps.Bundle{
+		{
+			Name: "rust",
+			Shell: &apps.Shell{Path: []apps.PathEntry{
+				{Dir: "~/.cargo/bin"},
+				{Dir: "/opt/homebrew/bin", When: "os == 'macos'"},
+			}},
+		},
+		{
+			Name: "go",
+			Shell: &apps.Shell{Path: []apps.PathEntry{
+				{Dir: "$HOME/go/bin", Position: apps.PathAppend, IfExists: &noCheck},
+				{Dir: "~/.cargo/bin/"},
+			}},
+		},
+	}
+
+	got, err := ResolvePath(bundles, &platform.Platform{OS: "linux"})
+	if err != nil {
+		t.Fatalf("ResolvePath() error = %v", err)
+	}
+	want := []PathDir{
+		{App: "rust", Dir: "$HOME/.cargo/bin", Position: apps.PathPrepend, IfExists: true},
+		{App: "go", Dir: "$HOME/go/bin", Position: apps.PathAppend, IfExists: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolvePath() = %+v, want %+v", got, want)
+	}
+
+	bundles[0].Shell.Path[1].When = "os =="
+	if _, err := ResolvePath(bundles, &platform.Platform{OS: "linux"}); err == nil {
+		t.Error("ResolvePath() error = nil, want invalid condition error")
+	}
+}
+
+func TestGenerator_PathOrderingAndChecks(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first")
+	second := filepath.Join(tmpDir, "second")
+	last := filepath.Join(tmpDir, "last")
+	for _, dir := range []string{first, second, last} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	noCheck := false
+	bundles := []*apps.Bundle{
+		{Name: "a", Shell: &apps.Shell{Path: []apps.PathEntry{
+			{Dir: first},
+			{Dir: filepath.Join(tmpDir, "missing")},
+			{Dir: last, Position: apps.PathAppend},
+		}}},
+		{Name: "b", Shell: &apps.Shell{Path: []apps.PathEntry{
+			{Dir: second},
+			{Dir: filepath.Join(tmpDir, "forced"), Position: apps.PathAppend, IfExists: &noCheck},
+			{Dir: "/usr/bin"},
+		}}},
+	}
+
+	outputPath := filepath.Join(tmpDir, "init.sh")
+	g := NewGenerator()
+	if err := g.GenerateWithOptions(bundles, Bash, outputPath, nil, GenerateOptions{Platform: &platform.Platform{OS: "linux"}}); err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+
+	// Source twice to check re-sourcing does not duplicate entries.
+	script := ". " + outputPath + "; . " + outputPath + `; printf '%s' "$PATH"`
+	cmd := exec.Command("bash", "--norc", "--noprofile", "-c", script)
+	cmd.Env = []string{"PATH=/usr/bin:/bin", "HOME=" + tmpDir}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sourcing init.sh: %v", err)
+	}
+	want := strings.Join([]string{first, second, "/usr/bin", "/bin", last, filepath.Join(tmpDir, "forced")}, ":")
+	if string(out) != want {
+		t.Errorf("PATH = %q, want %q", out, want)
+	}
+}
+
+func TestGenerator_PathReferencesEnv(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	goBin := filepath.Join(home, "go", "bin")
+	if err := os.MkdirAll(goBin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	bundles := []*apps.Bundle{{Name: "go", Shell: &apps.Shell{
+		Env:  map[string]apps.EnvVar{"GOPATH": {Value: "$HOME/go"}},
+		Path: []apps.PathEntry{{Dir: "$GOPATH/bin"}},
+	}}}
+
+	outputPath := filepath.Join(home, "init.sh")
+	g := NewGenerator()
+	if err := g.GenerateWithOptions(bundles, Bash, outputPath, nil, GenerateOptions{Platform: &platform.Platform{OS: "linux"}}); err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+
+	cmd := exec.Command("bash", "--norc", "--noprofile", "-c", ". "+outputPath+`; printf '%s' "$PATH"`)
+	cmd.Env = []string{"PATH=/usr/bin:/bin", "HOME=" + home}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("sourcing init.sh: %v", err)
+	}
+	if want := goBin + ":/usr/bin:/bin"; string(out) != want {
+		t.Errorf("PATH = %q, want %q", out, want)
+	}
+
+	systemd, _ := systemdEnvironment([]PathDir{{App: "go", Dir: "$GOPATH/bin", Position: apps.PathPrepend, IfExists: true}},
+		[]EnvExport{{Name: "GOPATH", App: "go", Value: home + "/go", Expand: true}})
+	if !strings.Contains(systemd, `PATH="$GOPATH/bin:${PATH}"`) || strings.Index(systemd, "GOPATH=") > strings.Index(systemd, "PATH=\"$GOPATH") {
+		t.Errorf("systemdEnvironment() = %q, want GOPATH set before PATH references it", systemd)
+	}
+}
+
+func TestExpandPathDir(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	exports := []EnvExport{
+		{Name: "GOPATH", Value: "$HOME/go", Expand: true},
+		{Name: "TOOLS", Value: "$(brew --prefix)", Expand: true},
+		{Name: "RAW", Value: "/opt/$x", Expand: false},
+	}
+	tests := []struct {
+		dir    string
+		want   string
+		wantOK bool
+	}{
+		{"$GOPATH/bin", "/home/me/go/bin", true},
+		{"$HOME/.local/bin", "/home/me/.local/bin", true},
+		{"$RAW/bin", "/opt/$x/bin", true},
+		{"$TOOLS/bin", "", false},
+		{"$GDF_TEST_UNSET_VAR/bin", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ExpandPathDir(tt.dir, exports)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("ExpandPathDir(%q) = %q, %v, want %q, %v", tt.dir, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestGenerator_PathPrependMovesToFront(t *testing.T) {
+	tmpDir := t.TempDir()
+	local := filepath.Join(tmpDir, "local")
+	if err := os.MkdirAll(local, 0755); err != nil {
+		t.Fatal(err)
+	}
+	bundles := []*apps.Bundle{{Name: "tools", Shell: &apps.Shell{Path: []apps.PathEntry{
+		{Dir: local},
+		{Dir: "/bin", Position: apps.PathAppend},
+	}}}}
+
+	outputPath := filepath.Join(tmpDir, "init.sh")
+	g := NewGenerator()
+	if err := g.GenerateWithOptions(bundles, Bash, outputPath, nil, GenerateOptions{Platform: &platform.Platform{OS: "linux"}}); err != nil {
+		t.Fatalf("GenerateWithOptions() error = %v", err)
+	}
+
+	for _, sh := range []string{"sh", "bash"} {
+		if _, err := exec.LookPath(sh); err != nil {
+			continue
+		}
+		// Source twice to check re-sourcing keeps a single, leading entry.
+		script := ". " + outputPath + "; . " + outputPath + `; printf '%s' "$PATH"`
+		cmd := exec.Command(sh, "-c", script)
+		cmd.Env = []string{"PATH=/usr/bin:" + local + ":/bin:" + local, "HOME=" + tmpDir}
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("%s: sourcing init.sh: %v", sh, err)
+		}
+		if want := local + ":/usr/bin:/bin"; string(out) != want {
+			t.Errorf("%s: PATH = %q, want %q", sh, out, want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrShellUnavailable is returned when the shell to profile is not installed.
+var ErrShellUnavailable = errors.New("shell not installed")
+
+// StartupSegment is the time spent in one section or init snippet of the
+// generated script.
+type StartupSegment struct {
+	// Name is a section heading ("Aliases") or an "app:snippet" marker.
+	Name string
+	// App is set for init snippet segments.
+	App      string
+	Duration time.Duration
+	// Status is the exit status of the segment's last command.
+	Status int
+	// Output is anything the segment wrote to stderr.
+	Output string
+	// Lazy is set for init snippets that only define lazy-loading stubs.
+	Lazy bool
+}
+
+// Failed reports whether the segment exited non-zero or wrote to stderr.
+func (s StartupSegment) Failed() bool {
+	return s.Status != 0 || s.Output != ""
+}
+
+// StartupProfile is the result of ProfileStartup.
+type StartupProfile struct {
+	// Total is the wall time of a full interactive startup, rc files included.
+	Total time.Duration
+	// Init is the wall time of sourcing the init script on its own.
+	Init time.Duration
+	// Segments is empty when the shell cannot report sub-second timestamps
+	// (bash < 5 has no EPOCHREALTIME).
+	Segments []StartupSegment
+}
+
+const startupMarkPrefix = "__GDF_MARK__ "
+
+// ProfileStartup times a real interactive startup of shellType, then sources
+// initPath in a clean interactive shell with timing marks before each
+// section and init snippet.
+func ProfileStartup(ctx context.Context, shellType ShellType, initPath string) (*StartupProfile, error) {
+	if shellType != Bash && shellType != Zsh {
+		return nil, fmt.Errorf("cannot profile startup for shell %s", shellType)
+	}
+	bin, err := exec.LookPath(shellType.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrShellUnavailable, shellType)
+	}
+	data, err := os.ReadFile(initPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading init script: %w", err)
+	}
+	script := inlineFragments(string(data))
+
+	profile := &StartupProfile{}
+
+	start := time.Now()
+	full := exec.CommandContext(ctx, bin, "-i", "-c", "exit")
+	_ = full.Run() // rc files may exit non-zero; only the timing matters here
+	profile.Total = time.Since(start)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("interactive %s startup timed out", shellType)
+	}
+
+	instrumented, err := os.CreateTemp("", "gdf-startup-*.sh")
+	if err != nil {
+		return nil, fmt.Errorf("creating instrumented script: %w", err)
+	}
+	defer os.Remove(instrumented.Name())
+	if _, err := instrumented.WriteString(instrumentInitScript(script, shellType)); err != nil {
+		instrumented.Close()
+		return nil, fmt.Errorf("writing instrumented script: %w", err)
+	}
+	instrumented.Close()
+
+	args := []string{"--norc", "--noprofile", "-i", "-c", `. "$1"`, "gdf-profile", instrumented.Name()}
+	if shellType == Zsh {
+		args = []string{"-f", "-i", "-c", `. "$1"`, "gdf-profile", instrumented.Name()}
+	}
+	var stderr bytes.Buffer
+	run := exec.CommandContext(ctx, bin, args...)
+	run.Stderr = &stderr
+	start = time.Now()
+	_ = run.Run()
+	profile.Init = time.Since(start)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("sourcing init script timed out")
+	}
+
+	profile.Segments = parseStartupMarks(stderr.String())
+	lazy := lazySnippets(script)
+	for i := range profile.Segments {
+		profile.Segments[i].Lazy = lazy[profile.Segments[i].Name]
+	}
+	return profile, nil
+}
+
+// lazySnippets returns the "app:snippet" markers in script that are followed
+// by a "# lazy:" line.
+func lazySnippets(script string) map[string]bool {
+	lazy := make(map[string]bool)
+	lines := strings.Split(script, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		if name, ok := markerName(lines[i]); ok && strings.HasPrefix(lines[i+1], "# lazy: ") {
+			lazy[name] = true
+		}
+	}
+	return lazy
+}
+
+// inlineFragments replaces each guarded block of a split loader that
+// sources a fragment with the fragment's content, so its sections and
+// snippets get their own timing marks and exit statuses.
+func inlineFragments(script string) string {
+	lines := strings.Split(script, "\n")
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], `if [ "${`+fragmentVarPrefix) && i+3 < len(lines) && lines[i+3] == "fi" {
+			path, ok := strings.CutPrefix(strings.TrimSpace(lines[i+1]), ". '")
+			if ok && strings.HasSuffix(path, "'") {
+				if fragment, err := os.ReadFile(strings.TrimSuffix(path, "'")); err == nil {
+					out = append(out, strings.TrimSuffix(string(fragment), "\n"))
+					i += 3
+					continue
+				}
+			}
+		}
+		out = append(out, lines[i])
+	}
+	return strings.Join(out, "\n")
+}
+
+// instrumentInitScript inserts a timing mark before every section heading
+// and init snippet marker, plus a final mark at the end.
+func instrumentInitScript(script string, shellType ShellType) string {
+	var out strings.Builder
+	if shellType == Zsh {
+		out.WriteString("zmodload zsh/datetime 2>/dev/null\n")
+	}
+	out.WriteString(`__gdf_mark() { printf '\n` + startupMarkPrefix + `%s %s %s\n' "$?" "${EPOCHREALTIME:-}" "$1" >&2; }` + "\n")
+	for _, line := range strings.Split(script, "\n") {
+		if name, ok := markerName(line); ok {
+			fmt.Fprintf(&out, "__gdf_mark '%s'\n", name)
+		}
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	out.WriteString("__gdf_mark __end__\n")
+	return out.String()
+}
+
+type startupMark struct {
+	status int
+	at     float64
+	name   string
+	output strings.Builder
+}
+
+// parseStartupMarks turns mark lines into segments. Output between two marks
+// belongs to the earlier segment; its status is reported by the later mark.
+func parseStartupMarks(stderr string) []StartupSegment {
+	var marks []*startupMark
+	scanner := bufio.NewScanner(strings.NewReader(stderr))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, startupMarkPrefix) {
+			if len(marks) > 0 && strings.TrimSpace(line) != "" {
+				cur := marks[len(marks)-1]
+				if cur.output.Len() > 0 {
+					cur.output.WriteString("\n")
+				}
+				cur.output.WriteString(line)
+			}
+			continue
+		}
+		fields := strings.SplitN(strings.TrimPrefix(line, startupMarkPrefix), " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		status, _ := strconv.Atoi(fields[0])
+		// EPOCHREALTIME uses the locale decimal separator.
+		at, err := strconv.ParseFloat(strings.Replace(fields[1], ",", ".", 1), 64)
+		if err != nil {
+			// No sub-second clock available.
+			return nil
+		}
+		marks = append(marks, &startupMark{status: status, at: at, name: fields[2]})
+	}
+
+	segments := make([]StartupSegment, 0, len(marks))
+	for i := 0; i+1 < len(marks); i++ {
+		m, next := marks[i], marks[i+1]
+		seg := StartupSegment{
+			Name:     m.name,
+			Duration: time.Duration((next.at - m.at) * float64(time.Second)),
+			Status:   next.status,
+			Output:   m.output.String(),
+		}
+		if app, _, ok := strings.Cut(m.name, ":"); ok {
+			seg.App = app
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
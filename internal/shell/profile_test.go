@@ -0,0 +1,106 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func TestParseStartupMarks(t *testing.T) {
+	stderr := "bash: no job control in this shell\n" +
+		"\n__GDF_MARK__ 0 100.000000 Aliases\n" +
+		"\n__GDF_MARK__ 0 100.010000 fnm:fnm-env\n" +
+		"fnm: command not found\n" +
+		"\n__GDF_MARK__ 127 100.260000 __end__\n"
+
+	segments := parseStartupMarks(stderr)
+	if len(segments) != 2 {
+		t.Fatalf("segments = %#v, want 2", segments)
+	}
+	if segments[0].Name != "Aliases" || segments[0].Failed() {
+		t.Errorf("segments[0] = %#v", segments[0])
+	}
+	snippet := segments[1]
+	if snippet.App != "fnm" || snippet.Status != 127 || snippet.Output != "fnm: command not found" {
+		t.Errorf("segments[1] = %#v", snippet)
+	}
+	if snippet.Duration < 249*time.Millisecond || snippet.Duration > 251*time.Millisecond {
+		t.Errorf("segments[1].Duration = %v, want ~250ms", snippet.Duration)
+	}
+}
+
+func TestParseStartupMarks_NoClock(t *testing.T) {
+	if got := parseStartupMarks("\n__GDF_MARK__ 0  Aliases\n"); got != nil {
+		t.Fatalf("parseStartupMarks() = %#v, want nil without EPOCHREALTIME", got)
+	}
+}
+
+func TestProfileStartup_Bash(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	initPath := filepath.Join(home, "init.sh")
+	script := "# Aliases\nalias ll='ls -l'\n\n# Init\n# tool:broken\nfalse\n"
+	if err := os.WriteFile(initPath, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	profile, err := ProfileStartup(ctx, Bash, initPath)
+	if err != nil {
+		t.Fatalf("ProfileStartup() error = %v", err)
+	}
+	if profile.Total <= 0 || profile.Init <= 0 {
+		t.Fatalf("profile timings = %+v", profile)
+	}
+	if len(profile.Segments) == 0 {
+		t.Skip("bash has no EPOCHREALTIME; per-segment timing unavailable")
+	}
+	last := profile.Segments[len(profile.Segments)-1]
+	if last.Name != "tool:broken" || last.Status == 0 {
+		t.Fatalf("last segment = %#v, want failing tool:broken", last)
+	}
+}
+
+func TestProfileStartup_InlinesSplitFragments(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	initPath := filepath.Join(home, "generated", "init.bash")
+	bundles := []*apps.Bundle{{
+		Name:  "tool",
+		Shell: &apps.Shell{Init: []apps.InitSnippet{{Name: "broken", Common: "false"}}},
+	}}
+	if err := NewGenerator().GenerateWithOptions(bundles, Bash, initPath, nil, GenerateOptions{Split: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	profile, err := ProfileStartup(ctx, Bash, initPath)
+	if err != nil {
+		t.Fatalf("ProfileStartup() error = %v", err)
+	}
+	if len(profile.Segments) == 0 {
+		t.Skip("bash has no EPOCHREALTIME; per-segment timing unavailable")
+	}
+	for _, seg := range profile.Segments {
+		if seg.Name == "tool:broken" {
+			if seg.Status == 0 {
+				t.Errorf("tool:broken status = 0, want failure")
+			}
+			return
+		}
+	}
+	t.Fatalf("no tool:broken segment in %#v", profile.Segments)
+}
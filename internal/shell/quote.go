@@ -0,0 +1,127 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+	"github.com/rztaylor/GoDotFiles/internal/util"
+)
+
+// QuoteLiteral quotes s so the target shell reads it as a single word with
+// no expansion of any kind. Bash and zsh share POSIX single-quote rules: an
+// embedded quote closes the string, is escaped, and the string reopens. Fish
+// allows \' and \\ inside single quotes.
+func QuoteLiteral(shellType ShellType, s string) string {
+	if shellType == Fish {
+		r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+		return "'" + r.Replace(s) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuoteExpandable quotes s so the target shell expands parameters and
+// command substitutions ($VAR, ${VAR}, $(cmd)) but treats quotes, backticks
+// and backslashes literally. For fish, ${VAR} is rewritten to {$VAR}.
+func QuoteExpandable(shellType ShellType, s string) string {
+	if shellType == Fish {
+		s = fishBracedVar.ReplaceAllString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s), "{$$$1}")
+		return `"` + s + `"`
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`")
+	return `"` + r.Replace(s) + `"`
+}
+
+// fishBracedVar matches ${NAME}, which fish spells {$NAME}.
+var fishBracedVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var (
+	envNamePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	functionNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.:@+-]*$`)
+	// Alias names may use punctuation (for example ".." or "g+"), but not
+	// characters the shell treats as syntax.
+	aliasNameInvalid = regexp.MustCompile("[\\s'\"`$=/\\\\;&|<>(){}\\[\\]*?#~!]")
+)
+
+// reservedWords cannot be used as function or alias names.
+var reservedWords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"case": true, "esac": true, "for": true, "select": true, "while": true,
+	"until": true, "do": true, "done": true, "in": true, "function": true,
+	"time": true, "coproc": true, "repeat": true, "foreach": true, "end": true,
+}
+
+// ValidateEnvName checks that name is a legal environment variable name.
+func ValidateEnvName(shellType ShellType, name string) error {
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name %q for %s: use letters, digits and underscores, not starting with a digit", name, shellType)
+	}
+	return nil
+}
+
+// ValidateAliasName checks that name can be defined as an alias.
+func ValidateAliasName(shellType ShellType, name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("alias name is empty")
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("invalid alias name %q for %s: must not start with '-'", name, shellType)
+	case aliasNameInvalid.MatchString(name):
+		return fmt.Errorf("invalid alias name %q for %s: contains whitespace, quotes or shell syntax characters", name, shellType)
+	case reservedWords[name]:
+		return fmt.Errorf("invalid alias name %q for %s: reserved word", name, shellType)
+	}
+	return nil
+}
+
+// ValidateFunctionName checks that name can be defined as a function.
+func ValidateFunctionName(shellType ShellType, name string) error {
+	if !functionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid function name %q for %s: use letters, digits and _ . : @ + -, starting with a letter or underscore", name, shellType)
+	}
+	if reservedWords[name] {
+		return fmt.Errorf("invalid function name %q for %s: reserved word", name, shellType)
+	}
+	return nil
+}
+
+// ValidateNames checks every alias, function, env and lazy stub name that
+// would be generated for shellType and reports all invalid ones together.
+func ValidateNames(bundles []*apps.Bundle, globalAliases map[string]string, shellType ShellType) error {
+	var errs []error
+	for _, name := range util.SortedKeys(globalAliases) {
+		if err := ValidateAliasName(shellType, name); err != nil {
+			errs = append(errs, fmt.Errorf("global aliases: %w", err))
+		}
+	}
+	for _, bundle := range bundles {
+		if bundle.Shell == nil {
+			continue
+		}
+		for _, name := range util.SortedKeys(bundle.Shell.Aliases) {
+			if err := ValidateAliasName(shellType, name); err != nil {
+				errs = append(errs, fmt.Errorf("app %s: %w", bundle.Name, err))
+			}
+		}
+		for _, name := range util.SortedKeys(bundle.Shell.Functions) {
+			if err := ValidateFunctionName(shellType, name); err != nil {
+				errs = append(errs, fmt.Errorf("app %s: %w", bundle.Name, err))
+			}
+		}
+		for _, name := range util.SortedKeys(bundle.Shell.Env) {
+			if err := ValidateEnvName(shellType, name); err != nil {
+				errs = append(errs, fmt.Errorf("app %s: %w", bundle.Name, err))
+			}
+		}
+		for _, snippet := range bundle.Shell.Init {
+			for _, name := range snippet.Lazy {
+				if err := ValidateFunctionName(shellType, name); err != nil {
+					errs = append(errs, fmt.Errorf("app %s: init %s: lazy command: %w", bundle.Name, snippet.Name, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
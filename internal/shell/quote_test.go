@@ -0,0 +1,164 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rztaylor/GoDotFiles/internal/apps"
+)
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "kubectl", want: "'kubectl'"},
+		{in: "", want: "''"},
+		{in: "echo 'hi'", want: `'echo '\''hi'\'''`},
+		{in: "echo $HOME `id` \"x\"", want: "'echo $HOME `id` \"x\"'"},
+	}
+	for _, tt := range tests {
+		if got := QuoteLiteral(Bash, tt.in); got != tt.want {
+			t.Errorf("QuoteLiteral(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteExpandable(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "$HOME/.kube/config", want: `"$HOME/.kube/config"`},
+		{in: `say "hi"`, want: `"say \"hi\""`},
+		{in: "a`b`", want: "\"a\\`b\\`\""},
+		{in: `C:\path`, want: `"C:\\path"`},
+	}
+	for _, tt := range tests {
+		if got := QuoteExpandable(Bash, tt.in); got != tt.want {
+			t.Errorf("QuoteExpandable(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestQuoting_RoundTrip checks that bash reads quoted values back unchanged.
+func TestQuoting_RoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	values := []string{
+		"plain",
+		"it's",
+		`back\slash`,
+		"tick`s",
+		`dollar $NOPE ${NOPE} $(echo no)`,
+		`"double"`,
+		"multi\nline",
+	}
+	for _, v := range values {
+		script := "printf '%s' " + QuoteLiteral(Bash, v)
+		out, err := exec.Command("bash", "-c", script).Output()
+		if err != nil {
+			t.Fatalf("bash -c %q: %v", script, err)
+		}
+		if string(out) != v {
+			t.Errorf("QuoteLiteral round trip = %q, want %q", out, v)
+		}
+	}
+
+	cmd := exec.Command("bash", "-c", "printf '%s' "+QuoteExpandable(Bash, "$GDF_QUOTE_TEST/\"x\"`y`"))
+	cmd.Env = append(os.Environ(), "GDF_QUOTE_TEST=/base")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("bash: %v", err)
+	}
+	if want := "/base/\"x\"`y`"; string(out) != want {
+		t.Errorf("QuoteExpandable round trip = %q, want %q", out, want)
+	}
+}
+
+func TestValidateNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   func(ShellType, string) error
+		value   string
+		wantErr bool
+	}{
+		{name: "alias simple", check: ValidateAliasName, value: "k"},
+		{name: "alias punctuation", check: ValidateAliasName, value: ".."},
+		{name: "alias with space", check: ValidateAliasName, value: "g st", wantErr: true},
+		{name: "alias with quote", check: ValidateAliasName, value: "it's", wantErr: true},
+		{name: "alias with equals", check: ValidateAliasName, value: "a=b", wantErr: true},
+		{name: "alias leading dash", check: ValidateAliasName, value: "-x", wantErr: true},
+		{name: "alias reserved word", check: ValidateAliasName, value: "if", wantErr: true},
+		{name: "alias empty", check: ValidateAliasName, value: "", wantErr: true},
+		{name: "function simple", check: ValidateFunctionName, value: "mkcd"},
+		{name: "function namespaced", check: ValidateFunctionName, value: "git:clean-up"},
+		{name: "function leading digit", check: ValidateFunctionName, value: "1up", wantErr: true},
+		{name: "function with paren", check: ValidateFunctionName, value: "f()", wantErr: true},
+		{name: "function reserved word", check: ValidateFunctionName, value: "done", wantErr: true},
+		{name: "env simple", check: ValidateEnvName, value: "GOPATH"},
+		{name: "env underscore", check: ValidateEnvName, value: "_X1"},
+		{name: "env dash", check: ValidateEnvName, value: "MY-VAR", wantErr: true},
+		{name: "env leading digit", check: ValidateEnvName, value: "1VAR", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check(Bash, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerator_QuotesValuesAndRejectsInvalidNames(t *testing.T) {
+	noExpand := false
+	bundles := []*apps.Bundle{
+		{
+			Name: "quoting",
+			Shell: &apps.Shell{
+				Aliases: map[string]apps.ConditionalValues{"greet": apps.Value("echo 'hello world'")},
+				Env: map[string]apps.EnvVar{
+					"GDF_LITERAL": {Value: "$HOME `id` \"q\"", Expand: &noExpand},
+					"GDF_EXPAND":  {Value: "$HOME/bin"},
+				},
+			},
+		},
+	}
+
+	g := NewGenerator()
+	outputPath := filepath.Join(t.TempDir(), "init.sh")
+	if err := g.Generate(bundles, Bash, outputPath, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		`alias greet='echo '\''hello world'\'''`,
+		"export GDF_LITERAL='$HOME `id` \"q\"'",
+		`export GDF_EXPAND="$HOME/bin"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated script missing %q\n%s", want, content)
+		}
+	}
+
+	bundles[0].Shell.Aliases["bad name"] = apps.Value("true")
+	bundles[0].Shell.Env["BAD-NAME"] = apps.EnvVar{Value: "x"}
+	err = g.Generate(bundles, Bash, outputPath, nil)
+	if err == nil {
+		t.Fatal("Generate() error = nil, want invalid name error")
+	}
+	for _, want := range []string{"bad name", "BAD-NAME"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
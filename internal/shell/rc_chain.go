@@ -0,0 +1,164 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/platform"
+)
+
+// RCChainIssue is a startup file the shell reads that never reaches a GDF
+// source line, either directly or by sourcing one of the injection targets.
+type RCChainIssue struct {
+	Shell  ShellType
+	Path   string
+	Detail string
+}
+
+// CheckRCChain checks the startup files shellType actually reads against the
+// RC files GDF injects into (targets). For example, bash login shells read
+// ~/.bash_profile and skip ~/.bashrc unless ~/.bash_profile sources it, and
+// ignore ~/.profile whenever ~/.bash_profile exists.
+func (i *Injector) CheckRCChain(shellType ShellType, targets []string) ([]RCChainIssue, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return nil, nil
+	}
+
+	var issues []RCChainIssue
+	for _, entry := range startupFiles(shellType, home) {
+		if containsPath(targets, entry.path) {
+			continue
+		}
+		hasSource, err := HasSourceLine(entry.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", entry.path, err)
+		}
+		if hasSource {
+			continue
+		}
+		chained, err := sourcesAnyOf(entry.path, targets)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", entry.path, err)
+		}
+		if chained {
+			continue
+		}
+		issues = append(issues, RCChainIssue{
+			Shell:  shellType,
+			Path:   entry.path,
+			Detail: fmt.Sprintf("%s reads %s, which sources neither %s nor the GDF init script", entry.reader, entry.path, describeTargets(targets)),
+		})
+	}
+	return issues, nil
+}
+
+type startupFile struct {
+	path   string
+	reader string
+}
+
+// startupFiles returns the existing files shellType reads at startup that
+// must reach GDF: the bash login file bash picks and ~/.bashrc, or ~/.zshrc.
+func startupFiles(shellType ShellType, home string) []startupFile {
+	var files []startupFile
+	switch shellType {
+	case Bash:
+		// Bash login shells read only the first of these that exists.
+		for _, name := range []string{".bash_profile", ".bash_login", ".profile"} {
+			path := filepath.Join(home, name)
+			if fileExists(path) {
+				files = append(files, startupFile{path: path, reader: "bash login shells"})
+				break
+			}
+		}
+		if path := filepath.Join(home, ".bashrc"); fileExists(path) {
+			files = append(files, startupFile{path: path, reader: "interactive bash"})
+		}
+	case Zsh:
+		if path := filepath.Join(zshDotDir(home), ".zshrc"); fileExists(path) {
+			files = append(files, startupFile{path: path, reader: "interactive zsh"})
+		}
+	}
+	return files
+}
+
+// sourcesAnyOf reports whether a non-comment line of path runs a `source`
+// or `.` command whose argument is one of targets, as in `. ~/.bashrc` or
+// `[ -f "$HOME/.profile" ] && source "$HOME/.profile"`.
+func sourcesAnyOf(path string, targets []string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, sourced := range sourcedFiles(line, filepath.Dir(path)) {
+			if sourced != filepath.Clean(path) && containsPath(targets, sourced) {
+				return true, nil
+			}
+		}
+	}
+	return false, scanner.Err()
+}
+
+// sourcedFiles returns the expanded arguments of the `source` and `.`
+// commands on line. Relative arguments are taken relative to dir, the
+// directory shells start in when reading their startup files.
+func sourcedFiles(line, dir string) []string {
+	for _, sep := range []string{";", "&&", "||", "(", ")"} {
+		line = strings.ReplaceAll(line, sep, " ; ")
+	}
+	fields := strings.Fields(line)
+	var files []string
+	for idx := 0; idx+1 < len(fields); idx++ {
+		if fields[idx] != "source" && fields[idx] != "." {
+			continue
+		}
+		// Only a word in command position runs a command.
+		if idx > 0 {
+			switch fields[idx-1] {
+			case ";", "then", "else", "do", "{":
+			default:
+				continue
+			}
+		}
+		arg := platform.ExpandPath(strings.Trim(fields[idx+1], `"'`))
+		if !filepath.IsAbs(arg) {
+			arg = filepath.Join(dir, arg)
+		}
+		files = append(files, arg)
+	}
+	return files
+}
+
+func describeTargets(targets []string) string {
+	names := make([]string, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, filepath.Base(t))
+	}
+	return strings.Join(names, " or ")
+}
+
+func containsPath(paths []string, path string) bool {
+	for _, p := range paths {
+		if filepath.Clean(p) == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,125 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInjector_CheckRCChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		shellType ShellType
+		files     map[string]string
+		targets   []string
+		wantPaths []string
+	}{
+		{
+			name:      "bash_profile that does not source bashrc",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": "export EDITOR=vim\n",
+				".bashrc":       SourceLine(Bash) + "\n",
+			},
+			targets:   []string{".bashrc"},
+			wantPaths: []string{".bash_profile"},
+		},
+		{
+			name:      "bash_profile that sources bashrc",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": "[ -f ~/.bashrc ] && . ~/.bashrc\n",
+				".bashrc":       SourceLine(Bash) + "\n",
+			},
+			targets: []string{".bashrc"},
+		},
+		{
+			name:      "commented source line does not chain",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": "# . ~/.bashrc\n",
+				".bashrc":       SourceLine(Bash) + "\n",
+			},
+			targets:   []string{".bashrc"},
+			wantPaths: []string{".bash_profile"},
+		},
+		{
+			name:      "bash_profile that sources bashrc with an if block",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": "if [ -f \"$HOME/.bashrc\" ]; then source \"$HOME/.bashrc\"; fi\n",
+				".bashrc":       SourceLine(Bash) + "\n",
+			},
+			targets: []string{".bashrc"},
+		},
+		{
+			name:      "mentioning bashrc does not chain",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": "alias rc='vim ~/.bashrc'\n. ~/.bashrc.local\n",
+				".bashrc":       SourceLine(Bash) + "\n",
+			},
+			targets:   []string{".bashrc"},
+			wantPaths: []string{".bash_profile"},
+		},
+		{
+			name:      "profile target shadowed by bash_profile",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": "export EDITOR=vim\n",
+				".profile":      SourceLine(Bash) + "\n",
+			},
+			targets:   []string{".profile"},
+			wantPaths: []string{".bash_profile"},
+		},
+		{
+			name:      "bashrc outside the targets",
+			shellType: Bash,
+			files: map[string]string{
+				".bash_profile": SourceLine(Bash) + "\n",
+				".bashrc":       "alias ll='ls -l'\n",
+			},
+			targets:   []string{".bash_profile"},
+			wantPaths: []string{".bashrc"},
+		},
+		{
+			name:      "zshrc is a target",
+			shellType: Zsh,
+			files:     map[string]string{".zshrc": ""},
+			targets:   []string{".zshrc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("ZDOTDIR", "")
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(home, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var targets []string
+			for _, name := range tt.targets {
+				targets = append(targets, filepath.Join(home, name))
+			}
+
+			issues, err := NewInjector().CheckRCChain(tt.shellType, targets)
+			if err != nil {
+				t.Fatalf("CheckRCChain() error = %v", err)
+			}
+			var got []string
+			for _, issue := range issues {
+				got = append(got, filepath.Base(issue.Path))
+				if issue.Detail == "" {
+					t.Errorf("issue for %s has no detail", issue.Path)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("CheckRCChain() paths = %v, want %v", got, tt.wantPaths)
+			}
+		})
+	}
+}
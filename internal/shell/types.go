@@ -10,8 +10,13 @@ const (
 	Bash
 	// Zsh represents the Zsh shell.
 	Zsh
+	// Fish represents the fish shell.
+	Fish
 )
 
+// SupportedShells lists the shells GDF generates init scripts for.
+var SupportedShells = []ShellType{Bash, Zsh, Fish}
+
 // String returns the string representation of the shell type.
 func (s ShellType) String() string {
 	switch s {
@@ -19,6 +24,8 @@ func (s ShellType) String() string {
 		return "bash"
 	case Zsh:
 		return "zsh"
+	case Fish:
+		return "fish"
 	default:
 		return "unknown"
 	}
@@ -31,7 +38,21 @@ func ParseShellType(shell string) ShellType {
 		return Bash
 	case "zsh":
 		return Zsh
+	case "fish":
+		return Fish
 	default:
 		return Unknown
 	}
 }
+
+// IsPOSIX reports whether the shell accepts POSIX sh syntax, which is what
+// app function bodies and common init snippets are written in.
+func (s ShellType) IsPOSIX() bool {
+	return s == Bash || s == Zsh
+}
+
+// InitFileName returns the generated init script name for the shell,
+// for example "init.bash".
+func (s ShellType) InitFileName() string {
+	return "init." + s.String()
+}
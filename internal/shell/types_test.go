@@ -20,6 +20,11 @@ func TestShellType_String(t *testing.T) {
 			shellType: Zsh,
 			want:      "zsh",
 		},
+		{
+			name:      "fish",
+			shellType: Fish,
+			want:      "fish",
+		},
 		{
 			name:      "unknown",
 			shellType: Unknown,
@@ -53,8 +58,13 @@ func TestParseShellType(t *testing.T) {
 			want:  Zsh,
 		},
 		{
-			name:  "parse unknown",
+			name:  "parse fish",
 			shell: "fish",
+			want:  Fish,
+		},
+		{
+			name:  "parse unknown",
+			shell: "tcsh",
 			want:  Unknown,
 		},
 		{
@@ -0,0 +1,131 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rztaylor/GoDotFiles/internal/syntax"
+)
+
+// InitValidationError reports generated init content that the target shell
+// failed to parse. The previous init script is left in place.
+type InitValidationError struct {
+	Shell   ShellType
+	Line    int
+	Message string
+	// Context is the nearest section or "app:snippet" marker above Line.
+	Context string
+	// RejectedPath holds the rejected script for inspection.
+	RejectedPath string
+}
+
+func (e *InitValidationError) Error() string {
+	msg := fmt.Sprintf("generated %s init failed syntax check", e.Shell)
+	if e.Line > 0 {
+		msg += fmt.Sprintf(" at line %d", e.Line)
+	}
+	if e.Context != "" {
+		msg += fmt.Sprintf(" (in %s)", e.Context)
+	}
+	return msg + ": " + e.Message
+}
+
+// ValidateScript runs the shell in no-exec mode (`bash -n`, `zsh -n`) against
+// the script at path. It returns nil when the shell is not installed.
+func ValidateScript(shellType ShellType, path string) error {
+	err := syntax.CheckFile(path, shellType.String())
+	if err == nil || errors.Is(err, syntax.ErrCheckerUnavailable) {
+		return nil
+	}
+	var syntaxErr *syntax.Error
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+	verr := &InitValidationError{Shell: shellType, Line: syntaxErr.Line, Message: syntaxErr.Message}
+	if data, readErr := os.ReadFile(path); readErr == nil {
+		verr.Context = scriptContextAt(string(data), syntaxErr.Line)
+	}
+	return verr
+}
+
+// scriptContextAt returns the closest marker comment at or above line, so
+// errors can be traced back to the app that contributed the content.
+func scriptContextAt(script string, line int) string {
+	lines := strings.Split(script, "\n")
+	if line <= 0 || line > len(lines) {
+		return ""
+	}
+	for i := line - 1; i >= 0; i-- {
+		if name, ok := markerName(lines[i]); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// markerName recognizes section headings ("# Aliases") and init snippet
+// markers ("# app:snippet") written by the generator.
+func markerName(line string) (string, bool) {
+	if !strings.HasPrefix(line, "# ") {
+		return "", false
+	}
+	name := strings.TrimPrefix(line, "# ")
+	for _, section := range generatedSections {
+		if name == section {
+			return name, true
+		}
+	}
+	if app, snippet, ok := strings.Cut(name, ":"); ok && isValidMarkerPart(app) && snippet != "" && !strings.Contains(snippet, " ") {
+		return name, true
+	}
+	return "", false
+}
+
+// generatedSections are the section headings written by GenerateWithOptions
+// and split generation.
+var generatedSections = []string{"PATH", "Aliases", "Environment variables", "Functions", "Init", "Completions", "Fragments", "Auto-reload"}
+
+func isValidMarkerPart(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateFunctionBody checks that a function named name with body parses in
+// shellType, using the shell's no-exec mode. It returns nil when the shell is
+// not installed.
+func ValidateFunctionBody(shellType ShellType, name, body string) error {
+	if err := ValidateFunctionName(shellType, name); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "gdf-function-*")
+	if err != nil {
+		return fmt.Errorf("creating function check file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(renderFunction(shellType, name, body)); err != nil {
+		f.Close()
+		return fmt.Errorf("writing function check file: %w", err)
+	}
+	f.Close()
+
+	err = ValidateScript(shellType, f.Name())
+	var verr *InitValidationError
+	if errors.As(err, &verr) {
+		// Line 1 is the function header; report lines of the body.
+		line := verr.Line - 1
+		if line < 1 {
+			line = 1
+		}
+		return fmt.Errorf("function %s does not parse in %s (body line %d): %s", name, shellType, line, verr.Message)
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+// Package syntax checks dotfile contents for syntax errors before they are
+// linked into place.
+//
+// # Responsibility
+//
+// This package handles:
+//   - Detecting a dotfile format from its path (extension or well-known name)
+//   - Parsing JSON (strict or JSONC), YAML, TOML, INI/gitconfig and ssh_config
+//     content
+//   - Running shell syntax checks via `sh -n`, `bash -n` or `zsh -n` when the
+//     interpreter is installed
+//
+// # Key Types
+//
+//   - Error: A syntax error with the format and line number
+//
+// # Dependencies
+//
+//   - gopkg.in/yaml.v3: YAML parsing
+//   - github.com/BurntSushi/toml: TOML parsing
+package syntax
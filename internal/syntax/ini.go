@@ -0,0 +1,165 @@
+package syntax
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	gitSectionName = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	gitKeyName     = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+)
+
+// checkINI validates INI-style files. With gitconfig set it applies git's
+// stricter rules: keys must live in a section, names are restricted, and
+// quotes and escapes in values must be well formed.
+func checkINI(data []byte, gitconfig bool) error {
+	format := FormatINI
+	if gitconfig {
+		format = FormatGitConfig
+	}
+	errorf := func(line int, msg string, args ...interface{}) error {
+		return &Error{Format: format, Line: line, Message: fmt.Sprintf(msg, args...)}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	inSection := false
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if gitconfig {
+				end = gitSectionEnd(line)
+			}
+			if end < 0 {
+				return errorf(lineNo, "unterminated section header")
+			}
+			if gitconfig {
+				if err := checkGitSectionHeader(line[1:end]); err != nil {
+					return errorf(lineNo, "%v", err)
+				}
+			} else if strings.TrimSpace(line[1:end]) == "" {
+				return errorf(lineNo, "empty section name")
+			}
+			inSection = true
+
+			rest := strings.TrimSpace(line[end+1:])
+			if rest == "" || rest[0] == '#' || rest[0] == ';' {
+				continue
+			}
+			if !gitconfig {
+				return errorf(lineNo, "unexpected content after section header")
+			}
+			// Git allows "key = value" after a header on the same line.
+			lines[i] = rest
+			i--
+			continue
+		}
+
+		if !gitconfig {
+			sep := strings.IndexAny(line, "=:")
+			if sep == 0 {
+				return errorf(lineNo, "missing key before %q", line[0])
+			}
+			continue
+		}
+
+		if !inSection {
+			return errorf(lineNo, "key outside of a section")
+		}
+		key, value := line, ""
+		if sep := strings.IndexByte(line, '='); sep >= 0 {
+			key, value = strings.TrimSpace(line[:sep]), line[sep+1:]
+		}
+		if !gitKeyName.MatchString(key) {
+			return errorf(lineNo, "invalid key name %q", key)
+		}
+		// Values may continue onto following lines with a trailing backslash.
+		inQuote := false
+		for {
+			cont, err := scanGitValue(value, &inQuote)
+			if err != nil {
+				return errorf(lineNo, "%v", err)
+			}
+			if !cont {
+				break
+			}
+			i++
+			if i >= len(lines) {
+				return errorf(lineNo, "line continuation at end of file")
+			}
+			value = lines[i]
+		}
+	}
+	return nil
+}
+
+// gitSectionEnd finds the closing bracket of a header, skipping a quoted
+// subsection name.
+func gitSectionEnd(line string) int {
+	inQuote := false
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '"':
+			inQuote = !inQuote
+		case ']':
+			if !inQuote {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func checkGitSectionHeader(header string) error {
+	header = strings.TrimSpace(header)
+	name, sub, hasSub := strings.Cut(header, " ")
+	if !gitSectionName.MatchString(name) {
+		return fmt.Errorf("invalid section name %q", name)
+	}
+	if !hasSub {
+		return nil
+	}
+	sub = strings.TrimSpace(sub)
+	if len(sub) < 2 || sub[0] != '"' || sub[len(sub)-1] != '"' {
+		return fmt.Errorf("subsection name must be quoted: %s", sub)
+	}
+	return nil
+}
+
+// scanGitValue checks quoting and escapes in one line of a value and reports
+// whether it continues on the next line. inQuote carries across lines.
+func scanGitValue(value string, inQuote *bool) (bool, error) {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			*inQuote = !*inQuote
+		case '\\':
+			if i+1 == len(value) {
+				return true, nil
+			}
+			i++
+			if !strings.ContainsRune(`"\ntb`, rune(value[i])) {
+				return false, fmt.Errorf("invalid escape sequence \\%c", value[i])
+			}
+		case '#', ';':
+			if !*inQuote {
+				return false, nil
+			}
+		}
+	}
+	if *inQuote {
+		return false, fmt.Errorf("unterminated quoted value")
+	}
+	return false, nil
+}
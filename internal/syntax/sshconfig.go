@@ -0,0 +1,91 @@
+package syntax
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// sshKeywords lists ssh_config(5) keywords, lowercased. Deprecated keywords
+// that OpenSSH still accepts are included so older configs pass.
+var sshKeywords = map[string]bool{}
+
+func init() {
+	for _, k := range strings.Fields(`
+		AddKeysToAgent AddressFamily BatchMode BindAddress BindInterface
+		CanonicalDomains CanonicalizeFallbackLocal CanonicalizeHostname
+		CanonicalizeMaxDots CanonicalizePermittedCNAMEs CASignatureAlgorithms
+		CertificateFile ChallengeResponseAuthentication ChannelTimeout CheckHostIP
+		Cipher Ciphers ClearAllForwardings Compression ConnectionAttempts
+		ConnectTimeout ControlMaster ControlPath ControlPersist DynamicForward
+		EnableEscapeCommandline EnableSSHKeysign EscapeChar ExitOnForwardFailure
+		FingerprintHash ForkAfterAuthentication ForwardAgent ForwardX11
+		ForwardX11Timeout ForwardX11Trusted GatewayPorts GlobalKnownHostsFile
+		GSSAPIAuthentication GSSAPIDelegateCredentials GSSAPIKeyExchange
+		GSSAPIRenewalForcesRekey GSSAPIServerIdentity GSSAPITrustDns
+		HashKnownHosts Host HostbasedAcceptedAlgorithms HostbasedAuthentication
+		HostbasedKeyTypes HostKeyAlgorithms HostKeyAlias Hostname IdentitiesOnly
+		IdentityAgent IdentityFile IgnoreUnknown Include IPQoS
+		KbdInteractiveAuthentication KbdInteractiveDevices KexAlgorithms
+		KnownHostsCommand LocalCommand LocalForward LogLevel LogVerbose MACs Match
+		NoHostAuthenticationForLocalhost NumberOfPasswordPrompts
+		ObscureKeystrokeTiming PasswordAuthentication PermitLocalCommand
+		PermitRemoteOpen PKCS11Provider Port PreferredAuthentications Protocol
+		ProxyCommand ProxyJump ProxyUseFdpass PubkeyAcceptedAlgorithms
+		PubkeyAcceptedKeyTypes PubkeyAuthentication RekeyLimit RemoteCommand
+		RemoteForward RequestTTY RequiredRSASize RevokedHostKeys
+		RhostsRSAAuthentication RSAAuthentication SecurityKeyProvider SendEnv
+		ServerAliveCountMax ServerAliveInterval SessionType SetEnv StdinNull
+		StreamLocalBindMask StreamLocalBindUnlink StrictHostKeyChecking
+		SyslogFacility Tag TCPKeepAlive Tunnel TunnelDevice UpdateHostKeys
+		UseKeychain UsePrivilegedPort User UserKnownHostsFile UseRoaming
+		VerifyHostKeyDNS VisualHostKey XAuthLocation`) {
+		sshKeywords[strings.ToLower(k)] = true
+	}
+}
+
+// checkSSHConfig validates ssh_config keywords, arguments and quoting.
+// Unknown keywords are accepted when listed by a preceding IgnoreUnknown.
+func checkSSHConfig(data []byte) error {
+	var ignoreUnknown []string
+	for i, raw := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		end := strings.IndexAny(line, " \t=")
+		keyword, args := line, ""
+		if end >= 0 {
+			keyword = line[:end]
+			args = strings.TrimSpace(line[end:])
+			args = strings.TrimSpace(strings.TrimPrefix(args, "="))
+		}
+		lower := strings.ToLower(keyword)
+		if !sshKeywords[lower] && !matchesAnySSHPattern(lower, ignoreUnknown) {
+			return &Error{Format: FormatSSHConfig, Line: lineNo, Message: fmt.Sprintf("unknown keyword %q", keyword)}
+		}
+		if args == "" {
+			return &Error{Format: FormatSSHConfig, Line: lineNo, Message: fmt.Sprintf("missing argument for %s", keyword)}
+		}
+		if strings.Count(args, `"`)%2 != 0 {
+			return &Error{Format: FormatSSHConfig, Line: lineNo, Message: "unbalanced quotes"}
+		}
+		if lower == "ignoreunknown" {
+			for _, p := range strings.Split(args, ",") {
+				ignoreUnknown = append(ignoreUnknown, strings.ToLower(strings.Trim(strings.TrimSpace(p), `"`)))
+			}
+		}
+	}
+	return nil
+}
+
+func matchesAnySSHPattern(keyword string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, keyword); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,292 @@
+package syntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported format names for Check and the dotfile `format:` field.
+const (
+	FormatJSON = "json"
+	// FormatJSONC is JSON with // and /* */ comments and trailing commas,
+	// as used by VS Code settings.json, tsconfig.json and devcontainer.json.
+	FormatJSONC     = "jsonc"
+	FormatYAML      = "yaml"
+	FormatTOML      = "toml"
+	FormatINI       = "ini"
+	FormatGitConfig = "gitconfig"
+	FormatSSHConfig = "ssh_config"
+	FormatSh        = "sh"
+	FormatBash      = "bash"
+	FormatZsh       = "zsh"
+	FormatFish      = "fish"
+	// FormatNone disables checking for a dotfile.
+	FormatNone = "none"
+)
+
+// ErrCheckerUnavailable is returned when the tool needed to check a format
+// (such as zsh for zsh scripts) is not installed.
+var ErrCheckerUnavailable = errors.New("syntax checker unavailable")
+
+// Error describes a syntax error in a checked file.
+type Error struct {
+	Format  string
+	Line    int // 1-based; 0 when unknown
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", e.Format, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Format, e.Message)
+}
+
+// Formats returns every format name accepted by the dotfile `format:` field.
+func Formats() []string {
+	return []string{FormatJSON, FormatJSONC, FormatYAML, FormatTOML, FormatINI, FormatGitConfig, FormatSSHConfig, FormatSh, FormatBash, FormatZsh, FormatFish, FormatNone}
+}
+
+// IsKnown reports whether format is a supported format name.
+func IsKnown(format string) bool {
+	for _, f := range Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectFormat guesses a format from a file path. It returns "" when the
+// path does not match a known format.
+func DetectFormat(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	parent := strings.TrimPrefix(strings.ToLower(filepath.Base(filepath.Dir(path))), ".")
+
+	switch base {
+	case ".gitconfig", "gitconfig":
+		return FormatGitConfig
+	case "ssh_config":
+		return FormatSSHConfig
+	case ".bashrc", ".bash_profile", ".bash_aliases", ".bash_logout", "bashrc", "bash_profile":
+		return FormatBash
+	case ".zshrc", ".zshenv", ".zprofile", ".zlogin", ".zlogout", "zshrc", "zshenv", "zprofile":
+		return FormatZsh
+	case ".profile", "profile":
+		return FormatSh
+	case "config":
+		switch parent {
+		case "git":
+			return FormatGitConfig
+		case "ssh":
+			return FormatSSHConfig
+		}
+	}
+
+	switch filepath.Ext(base) {
+	case ".json", ".jsonc":
+		// Many .json dotfiles are JSONC, so only an explicit `format: json`
+		// rejects comments and trailing commas.
+		return FormatJSONC
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	case ".ini":
+		return FormatINI
+	case ".sh":
+		return FormatSh
+	case ".bash":
+		return FormatBash
+	case ".zsh":
+		return FormatZsh
+	case ".fish":
+		return FormatFish
+	}
+	return ""
+}
+
+// CheckFile checks the file at path in the given format. An empty format is
+// detected from the path; unknown or "none" formats are not checked.
+func CheckFile(path, format string) error {
+	if format == "" {
+		format = DetectFormat(path)
+	}
+	if format == "" || format == FormatNone {
+		return nil
+	}
+	if isShellFormat(format) {
+		return checkShell(path, format)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return Check(data, format)
+}
+
+// Check parses data in the given format. Shell formats must use CheckFile.
+func Check(data []byte, format string) error {
+	switch format {
+	case FormatJSON:
+		return checkJSON(data, FormatJSON)
+	case FormatJSONC:
+		return checkJSON(stripJSONC(data), FormatJSONC)
+	case FormatYAML:
+		return checkYAML(data)
+	case FormatTOML:
+		return checkTOML(data)
+	case FormatINI:
+		return checkINI(data, false)
+	case FormatGitConfig:
+		return checkINI(data, true)
+	case FormatSSHConfig:
+		return checkSSHConfig(data)
+	case FormatNone, "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func isShellFormat(format string) bool {
+	return format == FormatSh || format == FormatBash || format == FormatZsh || format == FormatFish
+}
+
+func checkJSON(data []byte, format string) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return &Error{Format: format, Line: lineAtOffset(data, syntaxErr.Offset), Message: syntaxErr.Error()}
+			}
+			return &Error{Format: format, Message: err.Error()}
+		}
+		// A second top-level value is almost always a mistake in config files.
+		if dec.More() {
+			return &Error{Format: format, Line: lineAtOffset(data, dec.InputOffset()), Message: "unexpected content after top-level value"}
+		}
+	}
+}
+
+// stripJSONC blanks out comments and trailing commas so JSONC parses as
+// JSON. Replaced bytes become spaces, keeping offsets and line numbers.
+func stripJSONC(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	inString := false
+	lastComma := -1 // offset of a comma that may turn out to be trailing
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
+func checkYAML(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return &Error{Format: FormatYAML, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		}
+	}
+}
+
+func checkShell(path, format string) error {
+	bin, err := exec.LookPath(format)
+	if err != nil {
+		return fmt.Errorf("%w: %s not found", ErrCheckerUnavailable, format)
+	}
+	out, err := exec.Command(bin, "-n", path).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("running %s -n: %w", format, err)
+	}
+	msg := firstLine(strings.TrimSpace(string(out)))
+	if m := shellErrorLine.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &Error{Format: format, Line: line, Message: m[2]}
+	}
+	if msg == "" {
+		msg = "syntax check failed"
+	}
+	return &Error{Format: format, Message: msg}
+}
+
+// shellErrorLine matches "line 3: msg" (sh, bash), "file:3: msg" (zsh) and
+// "file (line 3): msg" (fish).
+var shellErrorLine = regexp.MustCompile(`(?:line |:)(\d+)\)?: (.+)$`)
+
+func lineAtOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
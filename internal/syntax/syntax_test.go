@@ -0,0 +1,169 @@
+package syntax
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestShellErrorLine(t *testing.T) {
+	tests := []struct {
+		out      string
+		wantLine string
+		wantMsg  string
+	}{
+		{out: "/tmp/x.sh: line 3: syntax error near unexpected token `fi'", wantLine: "3", wantMsg: "syntax error near unexpected token `fi'"},
+		{out: "/tmp/x.zsh:4: parse error near `}'", wantLine: "4", wantMsg: "parse error near `}'"},
+		{out: "/tmp/x.fish (line 5): Missing end to balance this if statement", wantLine: "5", wantMsg: "Missing end to balance this if statement"},
+	}
+	for _, tt := range tests {
+		m := shellErrorLine.FindStringSubmatch(tt.out)
+		if m == nil || m[1] != tt.wantLine || m[2] != tt.wantMsg {
+			t.Errorf("shellErrorLine(%q) = %q, want line %s msg %q", tt.out, m, tt.wantLine, tt.wantMsg)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"git/.gitconfig", FormatGitConfig},
+		{"git/config", FormatGitConfig},
+		{"ssh/config", FormatSSHConfig},
+		{"zsh/.zshrc", FormatZsh},
+		{"bash/.bashrc", FormatBash},
+		{"shell/.profile", FormatSh},
+		{"Code/User/settings.json", FormatJSONC},
+		{"tool/tsconfig.jsonc", FormatJSONC},
+		{"tool/config.yml", FormatYAML},
+		{"alacritty/alacritty.toml", FormatTOML},
+		{"tool/setup.ini", FormatINI},
+		{"scripts/env.sh", FormatSh},
+		{"fish/conf.d/paths.fish", FormatFish},
+		{"tool/config", ""},
+		{"nvim/init.lua", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		content  string
+		wantLine int // 0 means the content is valid
+	}{
+		{"json valid", FormatJSON, `{"a": [1, 2], "b": {"c": true}}`, 0},
+		{"json trailing comma", FormatJSON, "{\n  \"a\": 1,\n}\n", 3},
+		{"json comment", FormatJSON, "{\n  // comment\n  \"a\": 1\n}\n", 2},
+		{"jsonc comments and trailing commas", FormatJSONC, "{\n  // line comment\n  \"url\": \"https://x//y\", /* block\n  comment */\n  \"list\": [1, 2,],\n}\n", 0},
+		{"jsonc missing comma", FormatJSONC, "{\n  /* a */ \"a\": 1\n  \"b\": 2\n}\n", 3},
+		{"yaml valid", FormatYAML, "a: 1\nb:\n  - x\n---\nc: 2\n", 0},
+		{"yaml invalid", FormatYAML, "a: [1, 2\nb: 3\n", -1},
+		{"toml valid", FormatTOML, "title = \"x\"\n[font]\nsize = 11.5\nnormal = { family = 'Hack', style = \"Regular\" }\ncolors = [\n  \"#fff\", # comment\n  \"#000\",\n]\nwhen = 1979-05-27 07:32:00Z\n[[bindings]]\nkey = \"N\"\n[[bindings]]\nkey = \"M\"\nbody = \"\"\"\nmulti\nline\"\"\"\n", 0},
+		{"toml missing equals", FormatTOML, "[font]\nsize 11\n", 2},
+		{"toml unterminated string", FormatTOML, "a = \"x\nb = 1\n", 1},
+		{"toml duplicate key", FormatTOML, "a = 1\nb = 2\na = 3\n", 3},
+		{"toml duplicate table", FormatTOML, "[a]\nx = 1\n[b]\n[a]\n", 4},
+		{"toml invalid value", FormatTOML, "a = yes\n", 1},
+		{"toml unterminated array", FormatTOML, "a = [1, 2\n", -1},
+		{"gitconfig valid", FormatGitConfig, "[user]\n\tname = Jane Doe\n\temail = \"jane@example.com\" # comment\n[alias]\n\tlg = log --graph \\\n\t  --oneline\n[remote \"origin\"]\n\turl = git@example.com:x.git\n[core] editor = vim\n", 0},
+		{"gitconfig unterminated header", FormatGitConfig, "[user\n\tname = x\n", 1},
+		{"gitconfig key outside section", FormatGitConfig, "name = x\n", 1},
+		{"gitconfig unbalanced quote", FormatGitConfig, "[alias]\n\tx = \"!git log\n", 2},
+		{"gitconfig bad escape", FormatGitConfig, "[core]\n\tpath = C:\\Users\n", 2},
+		{"ini valid", FormatINI, "key=value\n[section]\n; comment\nother: 1\nflag\n", 0},
+		{"ini empty section", FormatINI, "[]\n", 1},
+		{"ssh valid", FormatSSHConfig, "Host github.com\n  HostName github.com\n  IdentityFile ~/.ssh/id_ed25519\nIgnoreUnknown UseKeychain,AddKeysToAgent\nMatch host *.corp exec \"test -f /x\"\n  User=me\n", 0},
+		{"ssh unknown keyword", FormatSSHConfig, "Host x\n  HostNmae x.example.com\n", 2},
+		{"ssh ignore unknown", FormatSSHConfig, "IgnoreUnknown Custom*\nHost x\n  CustomOption yes\n", 0},
+		{"ssh missing argument", FormatSSHConfig, "Host\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check([]byte(tt.content), tt.format)
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			var syntaxErr *Error
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Check() error = %v, want *Error", err)
+			}
+			if tt.wantLine > 0 && syntaxErr.Line != tt.wantLine {
+				t.Errorf("line = %d, want %d (%v)", syntaxErr.Line, tt.wantLine, err)
+			}
+		})
+	}
+}
+
+func TestCheckFile_Shell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.sh")
+	bad := filepath.Join(dir, "bad.sh")
+	if err := os.WriteFile(good, []byte("if true; then\n  echo ok\nfi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("if true; then\n  echo ok\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckFile(good, ""); err != nil {
+		t.Fatalf("CheckFile(good) error = %v", err)
+	}
+	var syntaxErr *Error
+	if err := CheckFile(bad, ""); !errors.As(err, &syntaxErr) {
+		t.Fatalf("CheckFile(bad) error = %v, want *Error", err)
+	}
+}
+
+func TestCheckFile_JSONC(t *testing.T) {
+	// A VS Code settings.json, detected from its .json extension.
+	settings := `{
+  // Editor
+  "editor.fontSize": 14,
+  "editor.rulers": [80, 120,], /* trailing commas are fine */
+  "files.exclude": {
+    "**/.git": true,
+    "**/*.js": { "when": "$(basename).ts" },
+  },
+}
+`
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckFile(path, ""); err != nil {
+		t.Fatalf("CheckFile(settings.json) error = %v", err)
+	}
+	var syntaxErr *Error
+	if err := CheckFile(path, FormatJSON); !errors.As(err, &syntaxErr) || syntaxErr.Line != 2 {
+		t.Fatalf("CheckFile(settings.json, json) error = %v, want error on line 2", err)
+	}
+}
+
+func TestCheckFile_NoneSkipsCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckFile(path, FormatNone); err != nil {
+		t.Fatalf("CheckFile(none) error = %v", err)
+	}
+	if err := CheckFile(path, ""); err == nil {
+		t.Fatal("expected error for broken JSON with detected format")
+	}
+}
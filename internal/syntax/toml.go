@@ -0,0 +1,19 @@
+package syntax
+
+import (
+	"errors"
+
+	"github.com/BurntSushi/toml"
+)
+
+func checkTOML(data []byte) error {
+	var v map[string]interface{}
+	if _, err := toml.Decode(string(data), &v); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return &Error{Format: FormatTOML, Line: parseErr.Position.Line, Message: parseErr.Message}
+		}
+		return &Error{Format: FormatTOML, Message: err.Error()}
+	}
+	return nil
+}
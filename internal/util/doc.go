@@ -3,7 +3,9 @@
 // Key helpers:
 //   - WriteFileAtomic: atomically replaces files by writing to a temporary
 //     file in the destination directory and renaming into place.
+//   - IgnoreMatcher: evaluates gitignore-style patterns (used for .gdfignore).
+//   - SortedKeys: returns map keys in sorted order for deterministic output.
 //
 // Dependencies:
-//   - Standard library filesystem primitives (`os`, `path/filepath`).
+//   - Standard library filesystem primitives (`os`, `path/filepath`, `regexp`, `sort`).
 package util
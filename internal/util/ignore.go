@@ -0,0 +1,168 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreMatcher evaluates gitignore-style patterns against slash-separated
+// relative paths. Later patterns take precedence, and "!" negates a match.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	re       *regexp.Regexp
+}
+
+// ParseIgnorePatterns builds a matcher from gitignore-style pattern lines.
+// Blank lines and lines starting with "#" are skipped.
+func ParseIgnorePatterns(lines []string) (*IgnoreMatcher, error) {
+	m := &IgnoreMatcher{}
+	for i, raw := range lines {
+		line := strings.TrimRight(raw, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{pattern: line}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("^" + ignoreGlobToRegexp(line) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern on line %d (%q): %w", i+1, raw, err)
+		}
+		rule.re = re
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// LoadIgnoreFile reads patterns from path. A missing file yields a nil matcher
+// and no error so callers can fall back to defaults.
+func LoadIgnoreFile(filePath string) (*IgnoreMatcher, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening ignore file: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading ignore file: %w", err)
+	}
+	return ParseIgnorePatterns(lines)
+}
+
+// Match reports whether relPath (slash-separated, relative to the ignore root)
+// is ignored. A path is also ignored when any of its parent directories is.
+func (m *IgnoreMatcher) Match(relPath string) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+	relPath = strings.Trim(path.Clean("/"+relPath), "/")
+	if relPath == "" {
+		return false
+	}
+
+	parts := strings.Split(relPath, "/")
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.matches(parts) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(parts []string) bool {
+	for i := range parts {
+		isDir := i < len(parts)-1
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.anchored {
+			if r.re.MatchString(strings.Join(parts[:i+1], "/")) {
+				return true
+			}
+			continue
+		}
+		if r.re.MatchString(parts[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreGlobToRegexp translates a gitignore glob into a regular expression body.
+func ignoreGlobToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
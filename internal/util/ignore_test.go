@@ -0,0 +1,79 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	m, err := ParseIgnorePatterns([]string{
+		"# comment",
+		"",
+		".cache/",
+		"*.lock",
+		"*_history",
+		"/.config/app/state.json",
+		"**/node_modules/**",
+		"!keep.lock",
+	})
+	if err != nil {
+		t.Fatalf("ParseIgnorePatterns() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".cache/foo/bar", true},
+		{".cache", false},
+		{"Cargo.lock", true},
+		{"projects/x/Cargo.lock", true},
+		{"keep.lock", false},
+		{".bash_history", true},
+		{".zsh_history", true},
+		{".config/app/state.json", true},
+		{"other/.config/app/state.json", false},
+		{"src/node_modules/pkg/index.js", true},
+		{".gitconfig", false},
+		{".config/alacritty/alacritty.toml", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Match(tt.path); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_NilMatchesNothing(t *testing.T) {
+	var m *IgnoreMatcher
+	if m.Match(".bash_history") {
+		t.Fatal("nil matcher should not match")
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := LoadIgnoreFile(filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("LoadIgnoreFile(missing) error = %v", err)
+	}
+	if m != nil {
+		t.Fatal("LoadIgnoreFile(missing) should return nil matcher")
+	}
+
+	path := filepath.Join(dir, ".gdfignore")
+	if err := os.WriteFile(path, []byte("*.log\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err = LoadIgnoreFile(path)
+	if err != nil {
+		t.Fatalf("LoadIgnoreFile() error = %v", err)
+	}
+	if !m.Match("debug.log") {
+		t.Fatal("expected debug.log to be ignored")
+	}
+}
@@ -0,0 +1,13 @@
+package util
+
+import "strings"
+
+// SplitLinesKeepEnds splits content into lines that still carry their
+// newline, so joining them reproduces the input exactly.
+func SplitLinesKeepEnds(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}